@@ -0,0 +1,67 @@
+package assets
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+	"github.com/gofiber/template/html/v2"
+)
+
+// StaticMaxAge caché de los assets embebidos (en desarrollo no se cachean)
+const StaticMaxAge = 24 * time.Hour
+
+// Open subdirectorio de los archivos embebidos o, en desarrollo, del disco
+// Parámetros:
+//   - embedded: Archivos embebidos con go:embed
+//   - dir: Subdirectorio dentro de embedded (por ejemplo "templates")
+//   - diskDir: Directorio en disco equivalente a embedded
+//   - dev: Leer del disco; los cambios se ven sin recompilar
+//
+// Retorna: Sistema de archivos con raíz en dir
+func Open(embedded fs.FS, dir, diskDir string, dev bool) (fs.FS, error) {
+	if !dev {
+		return fs.Sub(embedded, dir)
+	}
+
+	path := filepath.Join(diskDir, dir)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("modo desarrollo: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("modo desarrollo: %s no es un directorio", path)
+	}
+	return os.DirFS(path), nil
+}
+
+// Templates motor de plantillas HTML sobre fsys con las funciones del sitio
+// Parámetros:
+//   - fsys: Plantillas (resultado de Open)
+//   - dev: Recargar las plantillas en cada render
+func Templates(fsys fs.FS, dev bool) *html.Engine {
+	engine := html.NewFileSystem(http.FS(fsys), ".html")
+	engine.Reload(dev)
+	engine.AddFunc("add", func(a, b int) int { return a + b })
+	return engine
+}
+
+// Static handler de archivos estáticos para montar con app.Use(prefijo, ...)
+// Parámetros:
+//   - fsys: Archivos a servir (resultado de Open)
+//   - dev: Sin caché en el navegador para ver los cambios al recargar
+func Static(fsys fs.FS, dev bool) fiber.Handler {
+	maxAge := int(StaticMaxAge.Seconds())
+	if dev {
+		maxAge = 0
+	}
+	return filesystem.New(filesystem.Config{
+		Root:   http.FS(fsys),
+		MaxAge: maxAge,
+	})
+}
@@ -0,0 +1,108 @@
+package assets
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"website/frontend"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestEmbeddedFrontendTemplatesLoad(t *testing.T) {
+	templates, err := Open(frontend.FS, "templates", "", false)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := Templates(templates, false).Load(); err != nil {
+		t.Fatalf("las plantillas embebidas no cargan: %v", err)
+	}
+	if _, err := Open(frontend.FS, "assets", "", false); err != nil {
+		t.Fatalf("Open assets: %v", err)
+	}
+}
+
+func TestDevModeReadsDiskAndReloadsTemplates(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "templates", "page.html")
+	write := func(content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(page), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(page, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("antes")
+
+	embedded := fstest.MapFS{"templates/page.html": {Data: []byte("embebido")}}
+	render := func(dev bool) string {
+		t.Helper()
+		templates, err := Open(embedded, "templates", dir, dev)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		engine := Templates(templates, dev)
+		var out bytes.Buffer
+		if err := engine.Render(&out, "page", nil); err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		return out.String()
+	}
+
+	if got := render(false); got != "embebido" {
+		t.Errorf("sin modo desarrollo = %q, se esperaba el embebido", got)
+	}
+
+	templates, err := Open(embedded, "templates", dir, true)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	engine := Templates(templates, true)
+	for _, want := range []string{"antes", "después"} {
+		write(want)
+		var out bytes.Buffer
+		if err := engine.Render(&out, "page", nil); err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		if out.String() != want {
+			t.Errorf("modo desarrollo = %q, se esperaba %q", out.String(), want)
+		}
+	}
+
+	if _, err := Open(embedded, "templates", filepath.Join(dir, "no-existe"), true); err == nil {
+		t.Error("se esperaba error con un directorio inexistente")
+	}
+}
+
+func TestStaticCacheControl(t *testing.T) {
+	files := fstest.MapFS{"css/style.css": {Data: []byte("body{}")}}
+	for _, tt := range []struct {
+		dev  bool
+		want string
+	}{
+		{false, "public, max-age=86400"},
+		{true, ""},
+	} {
+		app := fiber.New()
+		app.Use("/assets", Static(files, tt.dev))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/assets/css/style.css", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 || string(body) != "body{}" {
+			t.Fatalf("dev=%v: %d %q", tt.dev, resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != tt.want {
+			t.Errorf("dev=%v: Cache-Control = %q, se esperaba %q", tt.dev, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"website/backend/migrate"
+	"website/backend/models"
+	"website/backend/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Backup copia del contenido del sitio (los usuarios no se incluyen)
+type Backup struct {
+	Timestamp     time.Time `json:"timestamp"`
+	SchemaVersion int64     `json:"schema_version"` // Última migración aplicada al crearla
+	Data          Data      `json:"data"`
+}
+
+// Data contenido de cada tabla
+type Data struct {
+	Configs     []models.SiteConfig          `json:"configs"`
+	Categories  []models.Category            `json:"categories"`
+	Products    []models.Product             `json:"products"`
+	Variants    []models.ProductVariant      `json:"variants"`
+	Currencies  []models.Currency            `json:"currencies"`
+	Prices      []models.ProductPrice        `json:"prices"`
+	Relations   []models.ProductRelation     `json:"relations"`
+	Movements   []models.StockMovement       `json:"stock_movements"`
+	Attributes  []models.AttributeDefinition `json:"attributes"`
+	Values      []models.ProductAttribute    `json:"attribute_values"`
+	Tags        []models.Tag                 `json:"tags"`
+	ProductTags []models.ProductTag          `json:"product_tags"`
+	Collections []models.Collection          `json:"collections"`
+	Items       []models.CollectionProduct   `json:"collection_products"`
+	Slides      []models.Slide               `json:"slides"`
+	Contacts    []models.ContactInfo         `json:"contacts"`
+}
+
+// Counts número de filas por tabla
+func (d Data) Counts() map[string]int {
+	return map[string]int{
+		"configs":      len(d.Configs),
+		"categories":   len(d.Categories),
+		"products":     len(d.Products),
+		"variants":     len(d.Variants),
+		"currencies":   len(d.Currencies),
+		"prices":       len(d.Prices),
+		"relations":    len(d.Relations),
+		"movements":    len(d.Movements),
+		"attributes":   len(d.Attributes),
+		"values":       len(d.Values),
+		"tags":         len(d.Tags),
+		"product_tags": len(d.ProductTags),
+		"collections":  len(d.Collections),
+		"items":        len(d.Items),
+		"slides":       len(d.Slides),
+		"contacts":     len(d.Contacts),
+	}
+}
+
+// Create lee todo el contenido en una transacción de solo lectura (instantánea coherente)
+// Parámetros:
+//   - ctx: Contexto de la operación
+//   - store: Repositorios del contenido
+func Create(ctx context.Context, store repository.Store) (*Backup, error) {
+	backup := &Backup{Timestamp: time.Now().UTC()}
+	all := repository.Filter{Sort: repository.SortByID}
+
+	err := store.Snapshot(ctx, func(tx repository.Store) error {
+		var err error
+		if backup.SchemaVersion, err = tx.SchemaVersion(ctx); err != nil {
+			return err
+		}
+		if backup.Data.Configs, err = tx.Configs().List(ctx); err != nil {
+			return err
+		}
+		if backup.Data.Categories, err = tx.Categories().List(ctx, all); err != nil {
+			return err
+		}
+		if backup.Data.Products, err = tx.Products().List(ctx, all); err != nil {
+			return err
+		}
+		// La categoría, los atributos, las variantes y las etiquetas van en sus propias tablas
+		for i := range backup.Data.Products {
+			backup.Data.Products[i].Category = models.Category{}
+			backup.Data.Products[i].Attributes = nil
+			backup.Data.Products[i].Variants = nil
+			backup.Data.Products[i].Tags = nil
+		}
+		if backup.Data.Variants, err = tx.Variants().List(ctx, all); err != nil {
+			return err
+		}
+		if backup.Data.Currencies, err = tx.Currencies().List(ctx, all); err != nil {
+			return err
+		}
+		if backup.Data.Prices, err = tx.Prices().ForProducts(ctx, nil, ""); err != nil {
+			return err
+		}
+		if backup.Data.Relations, err = tx.Relations().ForProducts(ctx, nil); err != nil {
+			return err
+		}
+		if backup.Data.Movements, err = tx.Stock().List(ctx, repository.StockFilter{}); err != nil {
+			return err
+		}
+		// Los usuarios no se copian: cada movimiento conserva el nombre de quien lo hizo
+		for i := range backup.Data.Movements {
+			backup.Data.Movements[i].UserID = nil
+		}
+		if backup.Data.Attributes, err = tx.Attributes().List(ctx, all); err != nil {
+			return err
+		}
+		if backup.Data.Values, err = tx.Attributes().Values(ctx, nil); err != nil {
+			return err
+		}
+		for i := range backup.Data.Values {
+			backup.Data.Values[i].Attribute = models.AttributeDefinition{}
+		}
+		if backup.Data.Tags, err = tx.Tags().List(ctx, all); err != nil {
+			return err
+		}
+		if backup.Data.ProductTags, err = tx.Tags().ForProducts(ctx, nil); err != nil {
+			return err
+		}
+		if backup.Data.Collections, err = tx.Collections().List(ctx, all); err != nil {
+			return err
+		}
+		if backup.Data.Items, err = tx.Collections().Items(ctx, nil); err != nil {
+			return err
+		}
+		if backup.Data.Slides, err = tx.Slides().List(ctx, all); err != nil {
+			return err
+		}
+		backup.Data.Contacts, err = tx.Contacts().List(ctx, all)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el contenido: %w", err)
+	}
+
+	return backup, nil
+}
+
+// Restore sustituye todo el contenido por el de la copia en una única transacción
+// Parámetros:
+//   - ctx: Contexto de la operación
+//   - db: Conexión de GORM
+//   - backup: Copia a restaurar; debe ser de la misma versión del esquema
+func Restore(ctx context.Context, db *gorm.DB, backup *Backup) error {
+	if err := backup.Data.numberValues(); err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current int64
+		if err := schemaVersion(tx, &current); err != nil {
+			return err
+		}
+		if backup.SchemaVersion != current {
+			return fmt.Errorf("la copia es del esquema %03d y la base de datos está en el %03d",
+				backup.SchemaVersion, current)
+		}
+
+		// Orden inverso a las claves foráneas para borrar, directo para insertar
+		tables := []struct {
+			name string
+			rows interface{}
+			size int
+		}{
+			{"site_configs", &backup.Data.Configs, len(backup.Data.Configs)},
+			{"currencies", &backup.Data.Currencies, len(backup.Data.Currencies)},
+			{"categories", &backup.Data.Categories, len(backup.Data.Categories)},
+			{"tags", &backup.Data.Tags, len(backup.Data.Tags)},
+			{"attribute_definitions", &backup.Data.Attributes, len(backup.Data.Attributes)},
+			{"products", &backup.Data.Products, len(backup.Data.Products)},
+			{"product_variants", &backup.Data.Variants, len(backup.Data.Variants)},
+			{"product_prices", &backup.Data.Prices, len(backup.Data.Prices)},
+			{"product_relations", &backup.Data.Relations, len(backup.Data.Relations)},
+			{"stock_movements", &backup.Data.Movements, len(backup.Data.Movements)},
+			{"product_attributes", &backup.Data.Values, len(backup.Data.Values)},
+			{"product_tags", &backup.Data.ProductTags, len(backup.Data.ProductTags)},
+			{"collections", &backup.Data.Collections, len(backup.Data.Collections)},
+			{"collection_products", &backup.Data.Items, len(backup.Data.Items)},
+			{"slides", &backup.Data.Slides, len(backup.Data.Slides)},
+			{"contact_infos", &backup.Data.Contacts, len(backup.Data.Contacts)},
+		}
+
+		for i := len(tables) - 1; i >= 0; i-- {
+			if err := tx.Exec("DELETE FROM " + tables[i].name).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, table := range tables {
+			if table.size > 0 {
+				if err := tx.Omit(clause.Associations).CreateInBatches(table.rows, 100).Error; err != nil {
+					return fmt.Errorf("error al restaurar %s: %w", table.name, err)
+				}
+			}
+
+			// Las filas conservan su id: avanzar la secuencia para los siguientes INSERT
+			err := tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)",
+				table.name, table.name,
+			)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// numberValues rellena Number de los valores de atributos number a partir de Value:
+// no viaja en el JSON de la copia y los filtros por rango solo comparan number_value
+func (d *Data) numberValues() error {
+	numeric := make(map[uint]bool, len(d.Attributes))
+	for _, definition := range d.Attributes {
+		numeric[definition.ID] = definition.Type == models.AttributeNumber
+	}
+	for i := range d.Values {
+		value := &d.Values[i]
+		value.Number = nil
+		if !numeric[value.AttributeID] {
+			continue
+		}
+		number, err := strconv.ParseFloat(value.Value, 64)
+		if err != nil {
+			return fmt.Errorf("el valor %d del atributo %d no es un número: %q", value.ID, value.AttributeID, value.Value)
+		}
+		value.Number = &number
+	}
+	return nil
+}
+
+// schemaVersion última migración aplicada
+func schemaVersion(tx *gorm.DB, version *int64) error {
+	err := tx.Raw("SELECT COALESCE(MAX(version), 0) FROM " + migrate.TableName).Scan(version).Error
+	if err != nil {
+		return fmt.Errorf("error al leer la versión del esquema: %w", err)
+	}
+	return nil
+}
+
+// Write escribe la copia como JSON indentado
+func Write(w io.Writer, backup *Backup) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(backup)
+}
+
+// Read lee una copia escrita con Write
+func Read(r io.Reader) (*Backup, error) {
+	var backup Backup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("copia inválida: %w", err)
+	}
+	return &backup, nil
+}
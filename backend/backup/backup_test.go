@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"website/backend/models"
+	"website/backend/repository"
+)
+
+func TestRoundTripKeepsAttributeNumbers(t *testing.T) {
+	ctx := context.Background()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := repository.NewMemoryStore()
+	category := models.Category{Name: "Cámaras", Slug: "camaras", Path: "/1/", SlugPath: "camaras", Active: true}
+	must(store.Categories().Create(ctx, &category))
+	megapixels := models.AttributeDefinition{CategoryID: category.ID, Name: "Megapíxeles", Key: "megapixeles", Type: models.AttributeNumber, Filterable: true}
+	must(store.Attributes().Create(ctx, &megapixels))
+	mount := models.AttributeDefinition{CategoryID: category.ID, Name: "Montura", Key: "montura", Type: models.AttributeText}
+	must(store.Attributes().Create(ctx, &mount))
+	for _, camera := range []struct{ name, megapixels string }{{"R5", "45"}, {"R6", "20.1"}} {
+		product := models.Product{CategoryID: category.ID, Name: camera.name, Slug: strings.ToLower(camera.name), Active: true}
+		must(store.Products().Create(ctx, &product))
+		number, _ := strconv.ParseFloat(camera.megapixels, 64)
+		must(store.Attributes().SetValues(ctx, product.ID, []models.ProductAttribute{
+			{AttributeID: megapixels.ID, Value: camera.megapixels, Number: &number},
+			{AttributeID: mount.ID, Value: "RF"},
+		}))
+	}
+
+	created, err := Create(ctx, store)
+	must(err)
+	var buffer bytes.Buffer
+	must(Write(&buffer, created))
+	restored, err := Read(&buffer)
+	must(err)
+
+	// Restore rellena los números antes de insertar las filas tal como vienen; aquí se
+	// insertan en otro almacén en memoria
+	must(restored.Data.numberValues())
+	target := repository.NewMemoryStore()
+	for _, category := range restored.Data.Categories {
+		must(target.Categories().Create(ctx, &category))
+	}
+	for _, definition := range restored.Data.Attributes {
+		must(target.Attributes().Create(ctx, &definition))
+	}
+	for _, product := range restored.Data.Products {
+		must(target.Products().Create(ctx, &product))
+	}
+	values := map[uint][]models.ProductAttribute{}
+	for _, value := range restored.Data.Values {
+		values[value.ProductID] = append(values[value.ProductID], value)
+	}
+	for productID, productValues := range values {
+		must(target.Attributes().SetValues(ctx, productID, productValues))
+	}
+
+	min := 30.0
+	products, err := target.Products().List(ctx, repository.Filter{
+		Attributes: []repository.AttributeFilter{{Key: "megapixeles", Min: &min}},
+	})
+	must(err)
+	if len(products) != 1 || products[0].Name != "R5" {
+		t.Errorf("productos con más de 30 megapíxeles tras restaurar = %+v", products)
+	}
+	numeric := -1
+	for i, value := range restored.Data.Values {
+		if value.AttributeID == mount.ID && value.Number != nil {
+			t.Errorf("un atributo de texto no debería tener número: %+v", value)
+		}
+		if value.AttributeID == megapixels.ID {
+			numeric = i
+		}
+	}
+
+	restored.Data.Values[numeric].Value = "muchos"
+	if err := restored.Data.numberValues(); err == nil {
+		t.Error("un valor no numérico de un atributo number debería fallar")
+	}
+}
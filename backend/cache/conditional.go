@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ========================================
+// PETICIONES CONDICIONALES
+// ========================================
+
+// Validators fija ETag y Last-Modified en la respuesta en curso
+// Parámetros:
+//   - etag: ETag entrecomillado ("" = sin ETag)
+//   - lastModified: Última modificación (cero = sin Last-Modified)
+func Validators(c *fiber.Ctx, etag string, lastModified time.Time) {
+	if etag != "" {
+		c.Set(fiber.HeaderETag, etag)
+	}
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// NotModified indica si la copia del cliente sigue vigente según los validadores ya
+// fijados en la respuesta (RFC 9110): If-None-Match tiene prioridad y, si no viene,
+// se compara If-Modified-Since con Last-Modified. Solo aplica a GET y HEAD.
+// A diferencia de fiber.Ctx.Fresh, If-Modified-Since sin If-None-Match también se evalúa.
+func NotModified(c *fiber.Ctx) bool {
+	method := c.Method()
+	if method != fiber.MethodGet && method != fiber.MethodHead {
+		return false
+	}
+
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		etag := string(c.Response().Header.Peek(fiber.HeaderETag))
+		return etag != "" && etagMatches(noneMatch, etag)
+	}
+
+	modifiedSince := c.Get(fiber.HeaderIfModifiedSince)
+	lastModified := string(c.Response().Header.Peek(fiber.HeaderLastModified))
+	if modifiedSince == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(modifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// SendNotModified responde 304 sin cuerpo conservando las cabeceras ya fijadas
+func SendNotModified(c *fiber.Ctx) error {
+	c.Status(fiber.StatusNotModified)
+	c.Response().ResetBody()
+	return nil
+}
+
+// etagMatches comparación débil de If-None-Match (lista separada por comas o "*")
+func etagMatches(noneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(noneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultExpiration TTL de las rutas sin Policy
+const DefaultExpiration = 30 * time.Minute
+
+// HeaderCache cabecera que indica si la respuesta salió de la cache (HIT, MISS o BYPASS)
+const HeaderCache = "X-Cache"
+
+// Claves de c.Locals con la política de la ruta
+const (
+	localsTTL  = "cache_ttl"
+	localsTags = "cache_tags"
+)
+
+// uncachedHeaders cabeceras propias de cada petición que no se guardan con la respuesta
+var uncachedHeaders = map[string]bool{
+	"Set-Cookie":            true,
+	"Date":                  true,
+	"Content-Length":        true,
+	"Connection":            true,
+	"X-Request-Id":          true,
+	"X-Cache":               true,
+	"Age":                   true,
+	"X-Ratelimit-Limit":     true,
+	"X-Ratelimit-Remaining": true,
+	"X-Ratelimit-Reset":     true,
+	"Retry-After":           true,
+	"Traceparent":           true,
+	"Tracestate":            true,
+}
+
+// Config configuración del middleware de cache de respuestas
+type Config struct {
+	Store      *Store                  // Almacenamiento (NewStore si es nil)
+	Expiration time.Duration           // TTL de las rutas sin Policy (DefaultExpiration si es 0)
+	Headers    []string                // Cabeceras de la petición que forman parte de la clave (se añaden a Vary)
+	Next       func(c *fiber.Ctx) bool // Saltar la cache para la petición
+}
+
+// response respuesta almacenada
+type response struct {
+	Status  int         `json:"status"`
+	Headers [][2]string `json:"headers"`
+	Body    []byte      `json:"body"`
+	Stored  time.Time   `json:"stored"`
+}
+
+// New middleware de cache de respuestas GET
+// La clave incluye la ruta, la query normalizada y las cabeceras de Config.Headers y del
+// Vary de la respuesta. No se cachean peticiones con Authorization ni respuestas que no sean
+// 200, que fijen cookies o que sean private/no-store. Cada ruta puede fijar su TTL y sus
+// etiquetas con Policy y Tag; Store.Invalidate elimina las entradas de una etiqueta.
+// Las entradas con ETag o Last-Modified responden 304 a las peticiones condicionales.
+// Parámetros:
+//   - config: Almacenamiento, TTL por defecto y cabeceras de la clave
+//
+// Retorna: Handler de Fiber
+func New(config Config) fiber.Handler {
+	if config.Store == nil {
+		config.Store = NewStore()
+	}
+	if config.Expiration <= 0 {
+		config.Expiration = DefaultExpiration
+	}
+	baseHeaders := canonicalHeaders(config.Headers)
+
+	return func(c *fiber.Ctx) error {
+		if config.Next != nil && config.Next(c) {
+			return c.Next()
+		}
+		method := c.Method()
+		if method != fiber.MethodGet && method != fiber.MethodHead {
+			return c.Next()
+		}
+		// Las respuestas autenticadas dependen del usuario
+		if c.Get(fiber.HeaderAuthorization) != "" {
+			c.Set(HeaderCache, "BYPASS")
+			return c.Next()
+		}
+
+		// Cabeceras de la clave: las de Config más el Vary de la última respuesta, guardado
+		// en el Store para que también cuente en su límite de entradas
+		base := baseKey(c)
+		headers := baseHeaders
+		if known, _ := config.Store.Get(varyKey(base)); known != nil {
+			headers = strings.Split(string(known), ",")
+		}
+		if raw, _ := config.Store.Get(requestKey(c, base, headers)); raw != nil {
+			var cached response
+			if err := json.Unmarshal(raw, &cached); err == nil {
+				return cached.write(c)
+			}
+		}
+
+		c.Set(HeaderCache, "MISS")
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		// HEAD comparte las entradas de GET pero no las crea (no tiene cuerpo)
+		ttl := config.Expiration
+		if routeTTL, ok := c.Locals(localsTTL).(time.Duration); ok {
+			ttl = routeTTL
+		}
+		if method != fiber.MethodGet || ttl <= 0 || !cacheable(c) {
+			return nil
+		}
+
+		responseVary, ok := varyHeaders(c)
+		if !ok {
+			return nil
+		}
+		for _, header := range config.Headers {
+			c.Vary(header)
+		}
+		headers = canonicalHeaders(append(append([]string{}, baseHeaders...), responseVary...))
+		tags, _ := c.Locals(localsTags).([]string)
+		config.Store.SetTagged(varyKey(base), []byte(strings.Join(headers, ",")), ttl, tags...)
+
+		if len(c.Response().Header.Peek(fiber.HeaderCacheControl)) == 0 {
+			c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(int(ttl.Seconds())))
+		}
+		cached := response{
+			Status: c.Response().StatusCode(),
+			Body:   append([]byte(nil), c.Response().Body()...),
+			Stored: time.Now(),
+		}
+		c.Response().Header.VisitAll(func(key, value []byte) {
+			if !uncachedHeaders[string(key)] {
+				cached.Headers = append(cached.Headers, [2]string{string(key), string(value)})
+			}
+		})
+		raw, err := json.Marshal(cached)
+		if err != nil {
+			return nil
+		}
+		return config.Store.SetTagged(requestKey(c, base, headers), raw, ttl, tags...)
+	}
+}
+
+// Policy TTL y etiquetas de una ruta; se monta delante del handler
+// Parámetros:
+//   - ttl: Tiempo en cache (0 o negativo = no se cachea)
+//   - tags: Etiquetas cuya invalidación elimina las respuestas de la ruta
+func Policy(ttl time.Duration, tags ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localsTTL, ttl)
+		Tag(c, tags...)
+		return c.Next()
+	}
+}
+
+// Tag añade etiquetas a la respuesta en curso (por ejemplo la categoría consultada)
+func Tag(c *fiber.Ctx, tags ...string) {
+	current, _ := c.Locals(localsTags).([]string)
+	c.Locals(localsTags, append(current, tags...))
+}
+
+// write envía la respuesta almacenada, o 304 si el cliente tiene la misma versión
+func (r response) write(c *fiber.Ctx) error {
+	for _, header := range r.Headers {
+		c.Set(header[0], header[1])
+	}
+	c.Set(HeaderCache, "HIT")
+	c.Set(fiber.HeaderAge, strconv.Itoa(int(time.Since(r.Stored).Seconds())))
+	if NotModified(c) {
+		return SendNotModified(c)
+	}
+	return c.Status(r.Status).Send(r.Body)
+}
+
+// baseKey ruta y query normalizada (parámetros ordenados)
+func baseKey(c *fiber.Ctx) string {
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil || len(query) == 0 {
+		return c.Path()
+	}
+	return c.Path() + "?" + query.Encode()
+}
+
+// varyKey clave de las cabeceras de la petición que forman parte de la clave de base
+// (las rutas empiezan por "/", así que no coincide con ninguna respuesta)
+func varyKey(base string) string {
+	return "vary:" + base
+}
+
+// requestKey clave con los valores de las cabeceras de la petición
+func requestKey(c *fiber.Ctx, base string, headers []string) string {
+	var key strings.Builder
+	key.WriteString(base)
+	for _, header := range headers {
+		key.WriteString("|")
+		key.WriteString(header)
+		key.WriteString("=")
+		key.WriteString(c.Get(header))
+	}
+	return key.String()
+}
+
+// cacheable indica si la respuesta es pública y reutilizable
+func cacheable(c *fiber.Ctx) bool {
+	if c.Response().StatusCode() != fiber.StatusOK {
+		return false
+	}
+	if len(c.Response().Header.Peek(fiber.HeaderSetCookie)) > 0 {
+		return false
+	}
+	control := strings.ToLower(string(c.Response().Header.Peek(fiber.HeaderCacheControl)))
+	return !strings.Contains(control, "private") && !strings.Contains(control, "no-store")
+}
+
+// varyHeaders cabeceras del Vary de la respuesta; false con "Vary: *"
+func varyHeaders(c *fiber.Ctx) ([]string, bool) {
+	var headers []string
+	for _, header := range strings.Split(string(c.Response().Header.Peek(fiber.HeaderVary)), ",") {
+		header = strings.TrimSpace(header)
+		if header == "*" {
+			return nil, false
+		}
+		if header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers, true
+}
+
+// canonicalHeaders nombres de cabecera normalizados, ordenados y sin repetir
+func canonicalHeaders(headers []string) []string {
+	seen := make(map[string]bool, len(headers))
+	var result []string
+	for _, header := range headers {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header != "" && !seen[header] {
+			seen[header] = true
+			result = append(result, header)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
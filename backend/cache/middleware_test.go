@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newCachedApp app whose handlers count their calls, behind the cache
+func newCachedApp(store *Store) (*fiber.App, map[string]int) {
+	calls := map[string]int{}
+	app := fiber.New()
+	app.Use(New(Config{Store: store, Headers: []string{"Accept-Language"}}))
+
+	handler := func(name string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			calls[name]++
+			return c.SendString(fmt.Sprintf("%s#%d?%s", name, calls[name], c.Request().URI().QueryString()))
+		}
+	}
+	app.Get("/products", Policy(time.Minute, TagProducts), func(c *fiber.Ctx) error {
+		if category := c.Query("category_id"); category != "" {
+			Tag(c, "category:"+category)
+		}
+		return handler("products")(c)
+	})
+	app.Get("/nocache", Policy(0), handler("nocache"))
+	app.Get("/private", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "private")
+		return handler("private")(c)
+	})
+	app.Get("/cookie", func(c *fiber.Ctx) error {
+		c.Cookie(&fiber.Cookie{Name: "session", Value: "1"})
+		return handler("cookie")(c)
+	})
+	app.Get("/origin", func(c *fiber.Ctx) error {
+		c.Vary("Origin")
+		return handler("origin")(c)
+	})
+	return app, calls
+}
+
+// request performs a request and returns the body and the X-Cache header
+func request(t *testing.T, app *fiber.App, method, path string, headers map[string]string) (string, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.Header.Get(HeaderCache)
+}
+
+func TestCacheKeysOnQueryAndHeaders(t *testing.T) {
+	app, calls := newCachedApp(NewStore())
+
+	for _, path := range []string{"/products?category_id=3&sort=name", "/products?sort=name&category_id=3"} {
+		request(t, app, "GET", path, nil)
+	}
+	if calls["products"] != 1 {
+		t.Errorf("el orden de la query no debería cambiar la clave: %d llamadas", calls["products"])
+	}
+
+	request(t, app, "GET", "/products?category_id=4", nil)
+	if calls["products"] != 2 {
+		t.Errorf("otra query debería ser otra entrada: %d llamadas", calls["products"])
+	}
+
+	request(t, app, "GET", "/products?category_id=4", map[string]string{"Accept-Language": "en"})
+	if calls["products"] != 3 {
+		t.Errorf("otra cabecera de Config.Headers debería ser otra entrada: %d llamadas", calls["products"])
+	}
+
+	// Vary de la respuesta: la segunda petición con el mismo Origin ya es HIT
+	request(t, app, "GET", "/origin", map[string]string{"Origin": "https://a.example"})
+	request(t, app, "GET", "/origin", map[string]string{"Origin": "https://b.example"})
+	if _, status := request(t, app, "GET", "/origin", map[string]string{"Origin": "https://b.example"}); status != "HIT" || calls["origin"] != 2 {
+		t.Errorf("Vary: Origin debería separar las entradas: %s, %d llamadas", status, calls["origin"])
+	}
+}
+
+func TestCacheIsBoundedWithArbitraryQueries(t *testing.T) {
+	store := NewStoreWithLimit(10)
+	app, calls := newCachedApp(store)
+
+	for i := 0; i < 50; i++ {
+		request(t, app, "GET", fmt.Sprintf("/products?x=%d", i), nil)
+	}
+	if store.Len() > 10 || calls["products"] != 50 {
+		t.Errorf("%d entradas con un límite de 10, %d llamadas", store.Len(), calls["products"])
+	}
+	if _, status := request(t, app, "GET", "/products?x=49", nil); status != "HIT" {
+		t.Errorf("la última query debería seguir en cache: %s", status)
+	}
+}
+
+func TestCacheSkipsPrivateResponses(t *testing.T) {
+	app, calls := newCachedApp(NewStore())
+
+	for i := 0; i < 2; i++ {
+		request(t, app, "GET", "/products", map[string]string{"Authorization": "Bearer x"})
+		request(t, app, "GET", "/nocache", nil)
+		request(t, app, "GET", "/private", nil)
+		request(t, app, "GET", "/cookie", nil)
+		request(t, app, "HEAD", "/products?head", nil)
+	}
+	for name, want := range map[string]int{"products": 4, "nocache": 2, "private": 2, "cookie": 2} {
+		if calls[name] != want {
+			t.Errorf("%s: %d llamadas, se esperaban %d", name, calls[name], want)
+		}
+	}
+
+	// HEAD usa las entradas de GET
+	request(t, app, "GET", "/products?head", nil)
+	if _, status := request(t, app, "HEAD", "/products?head", nil); status != "HIT" {
+		t.Errorf("HEAD tras GET = %s, se esperaba HIT", status)
+	}
+}
+
+func TestCacheInvalidationByTag(t *testing.T) {
+	store := NewStore()
+	app, calls := newCachedApp(store)
+
+	request(t, app, "GET", "/products", nil)
+	request(t, app, "GET", "/products?category_id=3", nil)
+	request(t, app, "GET", "/products?category_id=4", nil)
+	if body, status := request(t, app, "GET", "/products?category_id=3", nil); status != "HIT" || body != "products#2?category_id=3" {
+		t.Fatalf("se esperaba HIT con la respuesta guardada: %s %q", status, body)
+	}
+
+	// Una notificación de la categoría 3 solo invalida sus respuestas (y todas llevan "products")
+	store.Purge(JoinTags([]string{"category:3"}))
+	request(t, app, "GET", "/products?category_id=3", nil)
+	request(t, app, "GET", "/products?category_id=4", nil)
+	if calls["products"] != 4 {
+		t.Errorf("tras invalidar category:3: %d llamadas, se esperaban 4", calls["products"])
+	}
+
+	store.Purge(TagProducts)
+	if store.Len() != 0 {
+		t.Errorf("la etiqueta products debería vaciar la cache: %d entradas", store.Len())
+	}
+}
+
+func TestStoreInvalidateKeepsIndexConsistent(t *testing.T) {
+	store := NewStore()
+	store.SetTagged("a", []byte("1"), time.Minute, "x", "y")
+	store.SetTagged("b", []byte("2"), time.Minute, "y")
+	store.SetTagged("a", []byte("3"), time.Minute, "z")
+
+	if removed := store.Invalidate("x"); removed != 0 {
+		t.Errorf("a ya no tiene la etiqueta x: %d eliminadas", removed)
+	}
+	if removed := store.Invalidate("y"); removed != 1 {
+		t.Errorf("Invalidate(y) = %d, se esperaba 1", removed)
+	}
+	if value, _ := store.Get("a"); string(value) != "3" {
+		t.Errorf("a = %q", value)
+	}
+
+	store.Purge(" " + PurgeAll)
+	if store.Len() != 0 {
+		t.Errorf("PurgeAll no vació la cache: %d entradas", store.Len())
+	}
+}
+
+func TestCacheHitAnswersConditionalRequests(t *testing.T) {
+	calls := 0
+	app := fiber.New()
+	app.Use(New(Config{Store: NewStore()}))
+	app.Get("/versioned", func(c *fiber.Ctx) error {
+		calls++
+		Validators(c, `"v1"`, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+		if NotModified(c) {
+			return SendNotModified(c)
+		}
+		return c.SendString("contenido")
+	})
+
+	request(t, app, "GET", "/versioned", nil)
+	for _, headers := range []map[string]string{
+		{"If-None-Match": `W/"v1"`},
+		{"If-Modified-Since": "Wed, 01 May 2024 10:00:00 GMT"},
+	} {
+		body, status := request(t, app, "GET", "/versioned", headers)
+		if status != "HIT" || body != "" {
+			t.Errorf("%v: %s %q, se esperaba un 304 desde la cache", headers, status, body)
+		}
+	}
+	if body, _ := request(t, app, "GET", "/versioned", map[string]string{"If-Modified-Since": "Tue, 30 Apr 2024 10:00:00 GMT"}); body != "contenido" {
+		t.Errorf("copia anterior: %q, se esperaba el contenido", body)
+	}
+	if calls != 1 {
+		t.Errorf("%d llamadas al handler, se esperaba 1", calls)
+	}
+}
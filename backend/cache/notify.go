@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PurgeChannel canal de PostgreSQL (LISTEN/NOTIFY) por el que se piden purgas de cache
+const PurgeChannel = "cache_purge"
+
+// PurgeAll contenido de la notificación que vacía toda la cache
+const PurgeAll = "*"
+
+// Etiquetas de las respuestas públicas; los cambios del CMS publican las afectadas
+const (
+	TagConfig      = "config"
+	TagSlides      = "slides"
+	TagCategories  = "categories"
+	TagProducts    = "products"
+	TagContacts    = "contacts"
+	TagCurrencies  = "currencies" // Tipos de cambio: todas las respuestas con precios
+	TagCollections = "collections"
+)
+
+// CategoryTag etiqueta de las respuestas limitadas a una categoría
+func CategoryTag(id uint) string {
+	return "category:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// JoinTags contenido de la notificación que invalida las etiquetas
+func JoinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// ParseTags etiquetas de una notificación (sin vacías)
+func ParseTags(payload string) []string {
+	var tags []string
+	for _, tag := range strings.Split(payload, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Purge aplica una notificación: PurgeAll vacía la cache y el resto invalida sus etiquetas
+func (s *Store) Purge(payload string) {
+	if strings.TrimSpace(payload) == PurgeAll {
+		s.Reset()
+		return
+	}
+	s.Invalidate(ParseTags(payload)...)
+}
+
+// reconnectDelay espera antes de reconectar el listener tras un error
+const reconnectDelay = 5 * time.Second
+
+// Publish pide a todos los procesos que escuchan PurgeChannel que purguen su cache
+// Parámetros:
+//   - ctx: Contexto de la operación
+//   - db: Pool de conexiones a la misma base de datos que los servidores
+//   - payload: Qué purgar (PurgeAll para todo o JoinTags con las etiquetas)
+func Publish(ctx context.Context, db *sql.DB, payload string) error {
+	_, err := db.ExecContext(ctx, "SELECT pg_notify($1, $2)", PurgeChannel, payload)
+	return err
+}
+
+// Listen escucha PurgeChannel en una conexión dedicada y llama a onPurge por
+// cada notificación; reconecta tras un error hasta que se cancele el contexto.
+// Cada vez que empieza a escuchar pide además PurgeAll: las notificaciones enviadas
+// mientras estaba desconectado se han perdido
+// Parámetros:
+//   - ctx: Contexto que detiene la escucha al cancelarse
+//   - dsn: Cadena de conexión de PostgreSQL
+//   - onPurge: Función que recibe el contenido de la notificación
+func Listen(ctx context.Context, dsn string, onPurge func(payload string)) {
+	for ctx.Err() == nil {
+		err := listenOnce(ctx, dsn, onPurge)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("listener de purga de cache desconectado, reintentando", "error", err, "retry_in", reconnectDelay.String())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// listenOnce mantiene una conexión con LISTEN hasta que falle o se cancele el contexto
+func listenOnce(ctx context.Context, dsn string, onPurge func(payload string)) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+PurgeChannel); err != nil {
+		return err
+	}
+	onPurge(PurgeAll)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("purga de cache recibida", "payload", notification.Payload)
+		onPurge(notification.Payload)
+	}
+}
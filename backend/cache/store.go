@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries límite de entradas de NewStore: la clave incluye la query, así que
+// sin límite cualquiera podría llenar la memoria con parámetros inventados
+const DefaultMaxEntries = 10000
+
+// Store almacenamiento en memoria para el middleware de cache (también cumple fiber.Storage)
+// con índice de etiquetas: Invalidate elimina las entradas de una etiqueta y Publish
+// pide la invalidación desde fuera del proceso. Lleno, descarta la entrada usada hace más tiempo
+type Store struct {
+	mu         sync.Mutex
+	entries    map[string]entry
+	tags       map[string]map[string]struct{} // Etiqueta -> claves
+	recent     *list.List                     // Claves de la más a la menos usada
+	maxEntries int
+}
+
+// entry valor almacenado con su caducidad (cero = sin caducidad) y sus etiquetas
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+	tags      []string
+	element   *list.Element // Posición en recent
+}
+
+// NewStore crea un almacenamiento vacío de hasta DefaultMaxEntries entradas
+func NewStore() *Store {
+	return NewStoreWithLimit(DefaultMaxEntries)
+}
+
+// NewStoreWithLimit crea un almacenamiento vacío con un número máximo de entradas
+// Parámetros:
+//   - maxEntries: Entradas a conservar (0 o negativo = sin límite)
+func NewStoreWithLimit(maxEntries int) *Store {
+	return &Store{
+		entries:    make(map[string]entry),
+		tags:       make(map[string]map[string]struct{}),
+		recent:     list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get devuelve el valor de la clave o nil si no existe o caducó
+func (s *Store) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.entries[key]
+	if !ok || (!item.expiresAt.IsZero() && time.Now().After(item.expiresAt)) {
+		return nil, nil
+	}
+	s.recent.MoveToFront(item.element)
+	return item.value, nil
+}
+
+// Set guarda el valor durante exp (0 = sin caducidad)
+func (s *Store) Set(key string, value []byte, exp time.Duration) error {
+	return s.SetTagged(key, value, exp)
+}
+
+// SetTagged guarda el valor durante exp (0 = sin caducidad) asociado a las etiquetas
+// Parámetros:
+//   - key: Clave de la entrada
+//   - value: Contenido (vacío = no se guarda)
+//   - exp: Caducidad
+//   - tags: Etiquetas que la invalidan (por ejemplo "products" o "category:3")
+func (s *Store) SetTagged(key string, value []byte, exp time.Duration, tags ...string) error {
+	if key == "" || len(value) == 0 {
+		return nil
+	}
+
+	item := entry{value: value, tags: tags}
+	if exp > 0 {
+		item.expiresAt = time.Now().Add(exp)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(key)
+	item.element = s.recent.PushFront(key)
+	s.entries[key] = item
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	for s.maxEntries > 0 && len(s.entries) > s.maxEntries {
+		s.remove(s.recent.Back().Value.(string))
+	}
+	return nil
+}
+
+// Delete elimina la clave
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	s.remove(key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Invalidate elimina las entradas asociadas a alguna de las etiquetas
+// Retorna: Número de entradas eliminadas
+func (s *Store) Invalidate(tags ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.remove(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Reset elimina todas las entradas
+func (s *Store) Reset() error {
+	s.mu.Lock()
+	s.entries = make(map[string]entry)
+	s.tags = make(map[string]map[string]struct{})
+	s.recent.Init()
+	s.mu.Unlock()
+	return nil
+}
+
+// Close no hace nada; existe para cumplir fiber.Storage
+func (s *Store) Close() error {
+	return nil
+}
+
+// Len número de entradas almacenadas (incluidas las caducadas aún no eliminadas)
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Cleanup elimina periódicamente las entradas caducadas hasta que se cierre stop
+// Parámetros:
+//   - interval: Frecuencia de la limpieza
+//   - stop: Canal que detiene la limpieza al cerrarse
+func (s *Store) Cleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, item := range s.entries {
+				if !item.expiresAt.IsZero() && now.After(item.expiresAt) {
+					s.remove(key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// remove elimina la clave y su rastro en el índice de etiquetas (con el lock tomado)
+func (s *Store) remove(key string) {
+	item, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	s.recent.Remove(item.element)
+	for _, tag := range item.tags {
+		delete(s.tags[tag], key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
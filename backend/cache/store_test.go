@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreExpirationAndReset(t *testing.T) {
+	store := NewStore()
+	store.Set("fresh", []byte("a"), time.Minute)
+	store.Set("stale", []byte("b"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if value, _ := store.Get("fresh"); string(value) != "a" {
+		t.Errorf("fresh = %q", value)
+	}
+	if value, _ := store.Get("stale"); value != nil {
+		t.Errorf("stale debería haber caducado, es %q", value)
+	}
+
+	store.Reset()
+	if value, _ := store.Get("fresh"); value != nil || store.Len() != 0 {
+		t.Errorf("Reset no vació la cache: %q, %d entradas", value, store.Len())
+	}
+}
+
+func TestStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewStoreWithLimit(2)
+	store.SetTagged("a", []byte("1"), time.Minute, "x")
+	store.Set("b", []byte("2"), time.Minute)
+	store.Get("a")
+	store.Set("c", []byte("3"), time.Minute)
+
+	if value, _ := store.Get("b"); value != nil {
+		t.Errorf("b era la menos usada y debería haberse descartado, es %q", value)
+	}
+	if value, _ := store.Get("a"); string(value) != "1" || store.Len() != 2 {
+		t.Errorf("a = %q, %d entradas", value, store.Len())
+	}
+
+	// Descartar una entrada la saca también del índice de etiquetas
+	store.Set("d", []byte("4"), time.Minute)
+	store.Set("e", []byte("5"), time.Minute)
+	if removed := store.Invalidate("x"); removed != 0 || store.Len() != 2 {
+		t.Errorf("Invalidate(x) = %d tras descartar a, %d entradas", removed, store.Len())
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultJWTSecret valor de ejemplo de JWT_SECRET; no se permite en producción
+const DefaultJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+// Config configuración completa de la aplicación
+// Cada campo parte de Defaults(), se sobrescribe con el YAML opcional y después con
+// la variable de entorno de su etiqueta env (las estructuras anidadas añaden su prefijo)
+type Config struct {
+	Environment string `yaml:"environment" env:"ENVIRONMENT"`
+	Debug       bool   `yaml:"debug" env:"DEBUG"`
+
+	Database    DatabaseConfig  `yaml:"database" env:"DB"`
+	CMSDatabase DatabaseConfig  `yaml:"cms_database" env:"CMS_DB"`
+	Server      ServerConfig    `yaml:"server"`
+	JWT         JWTConfig       `yaml:"jwt"`
+	Security    SecurityConfig  `yaml:"security"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+	Log         LogConfig       `yaml:"log"`
+	Metrics     MetricsConfig   `yaml:"metrics"`
+	Tracing     TracingConfig   `yaml:"tracing"`
+	Migrate     MigrateConfig   `yaml:"migrate"`
+	Upload      UploadConfig    `yaml:"upload"`
+	SMTP        SMTPConfig      `yaml:"smtp"`
+	Site        SiteConfig      `yaml:"site"`
+	Assets      AssetsConfig    `yaml:"assets"`
+}
+
+// DatabaseConfig conexión a PostgreSQL; las variables llevan el prefijo del campo padre (DB_ o CMS_DB_)
+type DatabaseConfig struct {
+	Host     string `yaml:"host" env:"HOST"`
+	Port     int    `yaml:"port" env:"PORT"`
+	User     string `yaml:"user" env:"USER"`
+	Password string `yaml:"password" env:"PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"NAME"`
+	SSLMode  string `yaml:"sslmode" env:"SSLMODE"`
+}
+
+// DSN cadena de conexión para el driver de PostgreSQL
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		quoteDSN(d.Host), quoteDSN(d.User), quoteDSN(d.Password), quoteDSN(d.Name), d.Port, quoteDSN(d.SSLMode))
+}
+
+// quoteDSN entrecomilla un valor del DSN (admite espacios y comillas en contraseñas)
+func quoteDSN(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// ServerConfig puertos, TLS y ciclo de vida de los servidores HTTP
+type ServerConfig struct {
+	BackendHost     string        `yaml:"backend_host" env:"BACKEND_HOST"` // Interfaz del sitio público (vacío = todas)
+	BackendPort     int           `yaml:"backend_port" env:"BACKEND_PORT"`
+	CMSHost         string        `yaml:"cms_host" env:"CMS_HOST"` // Interfaz del CMS (vacío = todas)
+	CMSPort         int           `yaml:"cms_port" env:"CMS_PORT"`
+	EnableHTTPS     bool          `yaml:"enable_https" env:"ENABLE_HTTPS"`
+	CertFile        string        `yaml:"cert_file" env:"SSL_CERT_FILE"`
+	KeyFile         string        `yaml:"key_file" env:"SSL_KEY_FILE"`
+	TrustedProxies  []string      `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+}
+
+// BackendAddr dirección de escucha del sitio público (host:puerto)
+func (s ServerConfig) BackendAddr() string {
+	return net.JoinHostPort(s.BackendHost, strconv.Itoa(s.BackendPort))
+}
+
+// CMSAddr dirección de escucha del CMS (host:puerto)
+func (s ServerConfig) CMSAddr() string {
+	return net.JoinHostPort(s.CMSHost, strconv.Itoa(s.CMSPort))
+}
+
+// JWTConfig firma y caducidad de los tokens
+type JWTConfig struct {
+	Secret     string        `yaml:"secret" env:"JWT_SECRET" secret:"true"`
+	Expiration time.Duration `yaml:"expiration" env:"JWT_EXPIRATION"`
+}
+
+// SecurityConfig headers de seguridad
+type SecurityConfig struct {
+	EnableHSTS      bool `yaml:"enable_hsts" env:"ENABLE_HSTS"`
+	EnableCSP       bool `yaml:"enable_csp" env:"ENABLE_CSP"`
+	EnableXSS       bool `yaml:"enable_xss" env:"ENABLE_XSS"`
+	EnableFrameDeny bool `yaml:"enable_frame_deny" env:"ENABLE_FRAME_DENY"`
+	EnableNoSniff   bool `yaml:"enable_no_sniff" env:"ENABLE_NO_SNIFF"`
+	EnableReferrer  bool `yaml:"enable_referrer" env:"ENABLE_REFERRER"`
+}
+
+// RateLimit límite de peticiones por ventana de tiempo
+type RateLimit struct {
+	Max    int           `yaml:"max" env:"MAX"`
+	Window time.Duration `yaml:"window" env:"WINDOW"`
+}
+
+// RateLimitConfig límites de los presets (RATE_LIMIT_<PRESET>_MAX y RATE_LIMIT_<PRESET>_WINDOW)
+type RateLimitConfig struct {
+	Strict   RateLimit `yaml:"strict" env:"RATE_LIMIT_STRICT"`
+	Moderate RateLimit `yaml:"moderate" env:"RATE_LIMIT_MODERATE"`
+	Relaxed  RateLimit `yaml:"relaxed" env:"RATE_LIMIT_RELAXED"`
+	API      RateLimit `yaml:"api" env:"RATE_LIMIT_API"`
+	Auth     RateLimit `yaml:"auth" env:"RATE_LIMIT_AUTH"`
+}
+
+// LogConfig nivel, salidas y redacción de los logs
+type LogConfig struct {
+	Level         string   `yaml:"level" env:"LOG_LEVEL"`
+	Output        string   `yaml:"output" env:"LOG_OUTPUT"`
+	RedactHeaders []string `yaml:"redact_headers" env:"LOG_REDACT_HEADERS"`
+	RedactFields  []string `yaml:"redact_fields" env:"LOG_REDACT_FIELDS"`
+}
+
+// MetricsConfig protección del endpoint /metrics
+type MetricsConfig struct {
+	Username   string   `yaml:"username" env:"METRICS_USERNAME"`
+	Password   string   `yaml:"password" env:"METRICS_PASSWORD" secret:"true"`
+	AllowedIPs []string `yaml:"allowed_ips" env:"METRICS_ALLOWED_IPS"`
+}
+
+// TracingConfig exportador de trazas de OpenTelemetry
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+// MigrateConfig migraciones del esquema al arrancar
+type MigrateConfig struct {
+	OnStart bool `yaml:"on_start" env:"MIGRATE_ON_START"` // El CMS aplica las pendientes antes de verificar
+}
+
+// UploadConfig subida de archivos
+type UploadConfig struct {
+	Path        string `yaml:"path" env:"UPLOAD_PATH"`
+	MaxFileSize int64  `yaml:"max_file_size" env:"MAX_FILE_SIZE"`
+}
+
+// SMTPConfig servidor de correo para formularios de contacto
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+	User     string `yaml:"user" env:"SMTP_USER"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+}
+
+// SiteConfig datos públicos del sitio
+type SiteConfig struct {
+	Name string `yaml:"name" env:"SITE_NAME"`
+	URL  string `yaml:"url" env:"SITE_URL"`
+}
+
+// AssetsConfig origen de plantillas, assets y panel del CMS
+// Por defecto se sirven los embebidos en el binario; en desarrollo se leen del disco
+// y las plantillas se recargan en cada petición
+type AssetsConfig struct {
+	Dev bool   `yaml:"dev" env:"ASSETS_DEV"`
+	Dir string `yaml:"dir" env:"ASSETS_DIR"` // Raíz del repositorio con frontend/ y cms/admin/
+}
+
+// Defaults configuración por defecto, equivalente a .env.example sin secretos
+func Defaults() *Config {
+	return &Config{
+		Environment: "development",
+		Database: DatabaseConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "postgres",
+			Name:    "website_db",
+			SSLMode: "disable",
+		},
+		CMSDatabase: DatabaseConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "postgres",
+			Name:    "website_db",
+			SSLMode: "disable",
+		},
+		Server: ServerConfig{
+			BackendPort:     3000,
+			CMSPort:         4000,
+			TrustedProxies:  []string{"127.0.0.1/32", "::1/128"},
+			ShutdownTimeout: 30 * time.Second,
+		},
+		JWT: JWTConfig{
+			Expiration: 24 * time.Hour,
+		},
+		Security: SecurityConfig{
+			EnableHSTS:      true,
+			EnableCSP:       true,
+			EnableXSS:       true,
+			EnableFrameDeny: true,
+			EnableNoSniff:   true,
+			EnableReferrer:  true,
+		},
+		RateLimit: RateLimitConfig{
+			Strict:   RateLimit{Max: 10, Window: time.Minute},
+			Moderate: RateLimit{Max: 60, Window: time.Minute},
+			Relaxed:  RateLimit{Max: 300, Window: time.Minute},
+			API:      RateLimit{Max: 1000, Window: time.Hour},
+			Auth:     RateLimit{Max: 5, Window: 15 * time.Minute},
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Migrate: MigrateConfig{
+			OnStart: true,
+		},
+		Upload: UploadConfig{
+			Path:        "./uploads",
+			MaxFileSize: 10 * 1024 * 1024,
+		},
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
+		Site: SiteConfig{
+			Name: "Mi Sitio Web",
+			URL:  "http://localhost:3000",
+		},
+		Assets: AssetsConfig{
+			Dir: ".",
+		},
+	}
+}
+
+// IsProduction indica si la aplicación corre en producción
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
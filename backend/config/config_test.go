@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPrecedenceDefaultsYAMLEnv(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config.yaml")
+	os.WriteFile(yamlFile, []byte("database:\n  host: yaml-db\n  port: 6543\nrate_limit:\n  api:\n    max: 50\n"), 0o600)
+
+	t.Setenv("DB_HOST", "env-db")
+	t.Setenv("RATE_LIMIT_API_WINDOW", "30m")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+
+	cfg, err := Load(LoadOptions{EnvFiles: []string{}, ConfigFile: yamlFile})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Database.Host != "env-db" {
+		t.Errorf("el entorno debe tener prioridad sobre el YAML: %q", cfg.Database.Host)
+	}
+	if cfg.Database.Port != 6543 {
+		t.Errorf("el YAML debe sobrescribir el valor por defecto: %d", cfg.Database.Port)
+	}
+	if cfg.RateLimit.API != (RateLimit{Max: 50, Window: 30 * time.Minute}) {
+		t.Errorf("rate limit API = %+v", cfg.RateLimit.API)
+	}
+	if strings.Join(cfg.Server.TrustedProxies, "|") != "10.0.0.0/8|192.168.1.1" {
+		t.Errorf("TRUSTED_PROXIES = %v", cfg.Server.TrustedProxies)
+	}
+	if cfg.CMSDatabase.Host != "localhost" {
+		t.Errorf("CMS_DB_HOST no debe heredar DB_HOST: %q", cfg.CMSDatabase.Host)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	t.Setenv("JWT_EXPIRATION", "un día")
+	if _, err := Load(LoadOptions{EnvFiles: []string{}}); err == nil || !strings.Contains(err.Error(), "JWT_EXPIRATION") {
+		t.Fatalf("se esperaba error de JWT_EXPIRATION, err = %v", err)
+	}
+}
+
+func TestValidateRefusesDefaultSecretInProduction(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = "production"
+	cfg.JWT.Secret = DefaultJWTSecret
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "valor de ejemplo") {
+		t.Fatalf("se esperaba rechazo del secreto por defecto, err = %v", err)
+	}
+
+	cfg.JWT.Secret = strings.Repeat("k", 48)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("configuración de producción válida rechazada: %v", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := Defaults()
+	cfg.JWT.Secret = "super-secreto-jwt"
+	cfg.Database.Password = "clave-db"
+	cfg.Metrics.Username = "prometheus"
+
+	for _, format := range []string{"yaml", "env"} {
+		var out bytes.Buffer
+		if err := cfg.Print(&out, format); err != nil {
+			t.Fatalf("Print(%s): %v", format, err)
+		}
+		if strings.Contains(out.String(), "super-secreto-jwt") || strings.Contains(out.String(), "clave-db") {
+			t.Errorf("%s: secretos visibles:\n%s", format, out.String())
+		}
+		if !strings.Contains(out.String(), "prometheus") || !strings.Contains(out.String(), redactedValue) {
+			t.Errorf("%s: salida inesperada:\n%s", format, out.String())
+		}
+	}
+
+	if cfg.JWT.Secret != "super-secreto-jwt" {
+		t.Error("Print no debe modificar la configuración original")
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadOptions origen de la configuración
+type LoadOptions struct {
+	EnvFiles   []string // Archivos .env a cargar (por defecto ".env"; no sobrescriben el entorno)
+	ConfigFile string   // YAML opcional (por defecto CONFIG_FILE; vacío = sin YAML)
+}
+
+// Load carga la configuración: valores por defecto, YAML opcional y variables de entorno
+// Parámetros:
+//   - options: Archivos .env y YAML a leer
+//
+// Retorna: Configuración cargada (sin validar) y error si algún valor no se pudo interpretar
+func Load(options LoadOptions) (*Config, error) {
+	envFiles := options.EnvFiles
+	if envFiles == nil {
+		envFiles = []string{".env"}
+	}
+	for _, file := range envFiles {
+		if err := godotenv.Load(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error al leer %s: %w", file, err)
+		}
+	}
+
+	cfg := Defaults()
+
+	configFile := options.ConfigFile
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+	if configFile != "" {
+		if err := loadYAML(cfg, configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadYAML sobrescribe la configuración con los campos presentes en el archivo
+func loadYAML(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error al leer %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error en %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv recorre la estructura y asigna las variables de entorno de las etiquetas env
+// Las estructuras anidadas con etiqueta env añaden su valor como prefijo (DB + HOST = DB_HOST)
+func applyEnv(value reflect.Value, prefix string) error {
+	var errs []error
+	valueType := value.Type()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := valueType.Field(i)
+		key := field.Tag.Get("env")
+		if prefix != "" && key != "" {
+			key = prefix + "_" + key
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			if err := applyEnv(value.Field(i), key); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if key == "" {
+			continue
+		}
+		raw, ok := os.LookupEnv(key)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := setField(value.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// setField interpreta el texto según el tipo del campo
+func setField(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch field.Interface().(type) {
+	case time.Duration:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("duración inválida %q (use p. ej. 30s, 15m, 24h)", raw)
+		}
+		field.SetInt(int64(duration))
+		return nil
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("booleano inválido %q", raw)
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("entero inválido %q", raw)
+		}
+		field.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("número inválido %q", raw)
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("tipo no soportado %s", field.Type())
+	}
+	return nil
+}
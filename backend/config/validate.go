@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue texto que sustituye a los secretos al imprimir la configuración
+const redactedValue = "[REDACTED]"
+
+// minJWTSecretLength longitud mínima del secreto JWT en producción
+const minJWTSecretLength = 32
+
+// Validate verifica la configuración y devuelve todos los problemas encontrados
+// Retorna: Errores combinados (nil si la configuración es válida)
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(c.Environment, "development", "staging", "production"),
+		"ENVIRONMENT debe ser development, staging o production (es %q)", c.Environment)
+
+	// Secreto JWT: obligatorio siempre, fuerte en producción
+	check(c.JWT.Secret != "", "JWT_SECRET es obligatorio")
+	if c.IsProduction() {
+		check(c.JWT.Secret != DefaultJWTSecret, "JWT_SECRET tiene el valor de ejemplo; no se permite en producción")
+		check(len(c.JWT.Secret) >= minJWTSecretLength || c.JWT.Secret == "",
+			"JWT_SECRET debe tener al menos %d caracteres en producción", minJWTSecretLength)
+		check(!c.Debug, "DEBUG debe estar desactivado en producción")
+		check(!c.Assets.Dev, "ASSETS_DEV debe estar desactivado en producción")
+	}
+	check(c.JWT.Expiration > 0, "JWT_EXPIRATION debe ser positivo")
+
+	for name, db := range map[string]DatabaseConfig{"DB": c.Database, "CMS_DB": c.CMSDatabase} {
+		check(db.Host != "", "%s_HOST es obligatorio", name)
+		check(db.Name != "", "%s_NAME es obligatorio", name)
+		check(validPort(db.Port), "%s_PORT inválido: %d", name, db.Port)
+	}
+
+	check(validPort(c.Server.BackendPort), "BACKEND_PORT inválido: %d", c.Server.BackendPort)
+	check(validPort(c.Server.CMSPort), "CMS_PORT inválido: %d", c.Server.CMSPort)
+	if c.Server.EnableHTTPS {
+		check(c.Server.CertFile != "" && c.Server.KeyFile != "",
+			"ENABLE_HTTPS requiere SSL_CERT_FILE y SSL_KEY_FILE")
+	}
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT debe ser positivo")
+	check(c.Server.DrainDelay >= 0, "SHUTDOWN_DRAIN_DELAY no puede ser negativo")
+	for _, proxy := range c.Server.TrustedProxies {
+		check(validIPOrCIDR(proxy), "TRUSTED_PROXIES contiene un valor inválido: %q", proxy)
+	}
+
+	for name, limit := range map[string]RateLimit{
+		"STRICT":   c.RateLimit.Strict,
+		"MODERATE": c.RateLimit.Moderate,
+		"RELAXED":  c.RateLimit.Relaxed,
+		"API":      c.RateLimit.API,
+		"AUTH":     c.RateLimit.Auth,
+	} {
+		check(limit.Max > 0, "RATE_LIMIT_%s_MAX debe ser positivo", name)
+		check(limit.Window > 0, "RATE_LIMIT_%s_WINDOW debe ser positivo", name)
+	}
+
+	check(c.Log.Level == "" || oneOf(strings.ToLower(c.Log.Level), "debug", "info", "warn", "error"),
+		"LOG_LEVEL debe ser debug, info, warn o error (es %q)", c.Log.Level)
+
+	check((c.Metrics.Username == "") == (c.Metrics.Password == ""),
+		"METRICS_USERNAME y METRICS_PASSWORD deben definirse juntos")
+	for _, ip := range c.Metrics.AllowedIPs {
+		check(validIPOrCIDR(ip), "METRICS_ALLOWED_IPS contiene un valor inválido: %q", ip)
+	}
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "otlp"),
+		"OTEL_TRACES_EXPORTER debe ser none, stdout u otlp (es %q)", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"OTEL_TRACES_SAMPLER_ARG debe estar entre 0 y 1")
+
+	check(c.Upload.MaxFileSize > 0, "MAX_FILE_SIZE debe ser positivo")
+	check(!c.Assets.Dev || c.Assets.Dir != "", "ASSETS_DEV requiere ASSETS_DIR")
+
+	return errors.Join(errs...)
+}
+
+// oneOf verifica si el valor está en la lista
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// validPort verifica el rango de un puerto TCP
+func validPort(port int) bool {
+	return port > 0 && port < 65536
+}
+
+// validIPOrCIDR verifica que el valor sea una IP o un rango CIDR
+func validIPOrCIDR(value string) bool {
+	value = strings.TrimSpace(value)
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return true
+	}
+	return net.ParseIP(value) != nil
+}
+
+// ========================================
+// CONFIGURACIÓN EFECTIVA
+// ========================================
+
+// Redacted devuelve una copia con los campos secret:"true" ocultos
+func (c *Config) Redacted() *Config {
+	clone := *c
+	redactSecrets(reflect.ValueOf(&clone).Elem())
+	return &clone
+}
+
+// redactSecrets sustituye los secretos no vacíos por [REDACTED]
+func redactSecrets(value reflect.Value) {
+	valueType := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			redactSecrets(field)
+		case valueType.Field(i).Tag.Get("secret") == "true" && field.String() != "":
+			field.SetString(redactedValue)
+		}
+	}
+}
+
+// Print escribe la configuración efectiva con los secretos ocultos
+// Parámetros:
+//   - w: Destino de la salida
+//   - format: yaml (por defecto) o env
+func (c *Config) Print(w io.Writer, format string) error {
+	redacted := c.Redacted()
+	if format == "env" {
+		return printEnv(w, reflect.ValueOf(redacted).Elem(), "")
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(redacted); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// printEnv escribe la configuración como variables de entorno KEY=valor
+func printEnv(w io.Writer, value reflect.Value, prefix string) error {
+	valueType := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := valueType.Field(i)
+		key := field.Tag.Get("env")
+		if prefix != "" && key != "" {
+			key = prefix + "_" + key
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := printEnv(w, value.Field(i), key); err != nil {
+				return err
+			}
+			continue
+		}
+		if key == "" {
+			continue
+		}
+
+		formatted := fmt.Sprint(value.Field(i).Interface())
+		if items, ok := value.Field(i).Interface().([]string); ok {
+			formatted = strings.Join(items, ",")
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, formatted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,275 +1,685 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+	"website/backend/cache"
 	"website/backend/middleware"
 	"website/backend/models"
+	"website/backend/pagination"
+	"website/backend/repository"
+	"website/backend/services"
+	"website/backend/tracing"
+	"website/backend/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-func SetupRoutes(app *fiber.App, db *gorm.DB) {
+// Defaults used when the site settings are missing
+const (
+	defaultSiteName        = "Mi Sitio Web"
+	defaultSiteDescription = "Descripción del sitio web"
+)
+
+// Response cache TTLs; CMS changes invalidate the tags of each route before they expire
+const (
+	apiCacheTTL  = 10 * time.Minute
+	pageCacheTTL = 30 * time.Minute
+)
+
+// apiCacheControl lets browsers and nginx reuse an API response for a minute and then
+// revalidate it with If-None-Match / If-Modified-Since (a 304 costs a few aggregate queries)
+const apiCacheControl = "public, max-age=60, must-revalidate"
+
+// pageTags data every page includes through getCommonData
+var pageTags = []string{cache.TagConfig, cache.TagContacts}
+
+// SetupRoutes mounts the public API and pages. siteURL is the public origin used in
+// canonical links (e.g. https://example.com); empty uses the host of each request.
+func SetupRoutes(app *fiber.App, svc *services.Services, siteURL string) {
 	// Public API with rate limiting
 	api := app.Group("/api")
 
 	// Rate limiting específico para APIs
 	api.Use(middleware.RateLimitAPI())
 
-	api.Get("/config", getSiteConfig(db))
-	api.Get("/slides", getActiveSlides(db))
-	api.Get("/categories", getActiveCategories(db))
-	api.Get("/products", getActiveProducts(db))
-	api.Get("/contacts", getActiveContacts(db))
-
-	// Web Pages with relaxed rate limiting
-	app.Get("/", homeHandler(db))
-	app.Get("/productos", productsHandler(db))
-	app.Get("/productos/:category", categoryHandler(db))
-	app.Get("/contacto", contactHandler(db))
-	app.Get("/ubicaciones", locationsHandler(db))
-	app.Get("/catalogo", catalogHandler(db))
+	api.Get("/config", cache.Policy(apiCacheTTL, cache.TagConfig), getSiteConfig(svc))
+	api.Get("/slides", cache.Policy(apiCacheTTL, cache.TagSlides), getActiveSlides(svc))
+	api.Get("/categories", cache.Policy(apiCacheTTL, cache.TagCategories), getActiveCategories(svc))
+	api.Get("/categories/tree", cache.Policy(apiCacheTTL, cache.TagCategories), getCategoryTree(svc))
+	api.Get("/currencies", cache.Policy(apiCacheTTL, cache.TagCurrencies), getActiveCurrencies(svc))
+	// Product listings depend on the hide_out_of_stock setting too
+	api.Get("/products", cache.Policy(apiCacheTTL, cache.TagCurrencies, cache.TagConfig), getActiveProducts(svc))
+	api.Get("/products/:slug", cache.Policy(apiCacheTTL, cache.TagProducts, cache.TagCategories, cache.TagCurrencies, cache.TagConfig), getProduct(svc))
+	// Collection products come from any category, or from the rules of a smart one
+	api.Get("/collections/:slug", cache.Policy(apiCacheTTL, cache.TagCollections, cache.TagProducts, cache.TagCurrencies, cache.TagConfig), getCollection(svc))
+	api.Get("/contacts", cache.Policy(apiCacheTTL, cache.TagContacts), getActiveContacts(svc))
+	api.Get("/search", cache.Policy(apiCacheTTL, cache.TagProducts, cache.TagCategories, cache.TagCurrencies, cache.TagConfig), searchProducts(svc))
+
+	// Web Pages with relaxed rate limiting; the ones with prices accept ?currency= too
+	app.Get("/", pagePolicy(cache.TagSlides, cache.TagCategories, cache.TagProducts), homeHandler(svc))
+	app.Get("/productos", pagePolicy(cache.TagCategories, cache.TagProducts, cache.TagCurrencies), productsHandler(svc))
+	// Category pages nest the slugs of their parents; product pages add their own slug
+	app.Get("/productos/*", pagePolicy(cache.TagCurrencies), catalogPathHandler(svc, siteURL))
+	app.Get("/colecciones/:slug", pagePolicy(cache.TagCollections, cache.TagProducts, cache.TagCurrencies), collectionHandler(svc, siteURL))
+	app.Get("/contacto", pagePolicy(), contactHandler(svc))
+	app.Get("/ubicaciones", pagePolicy(), locationsHandler(svc))
+	app.Get("/catalogo", pagePolicy(cache.TagProducts, cache.TagCurrencies), catalogHandler(svc))
+	app.Get("/buscar", pagePolicy(cache.TagProducts, cache.TagCategories, cache.TagCurrencies), searchHandler(svc))
+}
+
+// pagePolicy caches a page under its own tags plus the common ones
+func pagePolicy(tags ...string) fiber.Handler {
+	return cache.Policy(pageCacheTTL, append(tags, pageTags...)...)
 }
 
 // API Handlers
-func getSiteConfig(db *gorm.DB) fiber.Handler {
+func getSiteConfig(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var configs []models.SiteConfig
-		if err := db.Find(&configs).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener configuración"})
+		if fresh, err := conditionalVersion(c, svc.Config.Version); err != nil || fresh {
+			return err
 		}
 
-		// Convert to map for easier frontend consumption
-		configMap := make(map[string]string)
-		for _, config := range configs {
-			configMap[config.Key] = config.Value
+		// Map for easier frontend consumption
+		configMap, err := svc.Config.Map(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener configuración")
 		}
-
 		return c.JSON(configMap)
 	}
 }
 
-func getActiveSlides(db *gorm.DB) fiber.Handler {
+func getActiveSlides(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query, err := pagination.Parse(c, pagination.Slides)
+		if err != nil {
+			return err
+		}
+		if fresh, err := conditional(c, query, svc.Slides.ActiveVersion); err != nil || fresh {
+			return err
+		}
+
+		page, err := svc.Slides.ActivePage(c.UserContext(), query.Filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener slides")
+		}
+		return pagination.Send(c, query, page)
+	}
+}
+
+func getActiveCategories(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var slides []models.Slide
-		if err := db.Where("active = ?", true).Order("order ASC").Find(&slides).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener slides"})
+		query, err := pagination.Parse(c, pagination.Categories)
+		if err != nil {
+			return err
 		}
-		return c.JSON(slides)
+		if fresh, err := conditional(c, query, svc.Categories.ActiveVersion); err != nil || fresh {
+			return err
+		}
+
+		page, err := svc.Categories.ActivePage(c.UserContext(), query.Filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener categorías")
+		}
+		return pagination.Send(c, query, page)
 	}
 }
 
-func getActiveCategories(db *gorm.DB) fiber.Handler {
+// getCategoryTree nests the visible categories under their parents (children)
+func getCategoryTree(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var categories []models.Category
-		if err := db.Where("active = ?", true).Order("order ASC").Find(&categories).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener categorías"})
+		if fresh, err := conditionalVersion(c, func(ctx context.Context) (services.Version, error) {
+			return svc.Categories.ActiveVersion(ctx, repository.Filter{}, c.Path())
+		}); err != nil || fresh {
+			return err
+		}
+
+		tree, err := svc.Categories.ActiveTree(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener categorías")
 		}
-		return c.JSON(categories)
+		return c.JSON(tree)
 	}
 }
 
-func getActiveProducts(db *gorm.DB) fiber.Handler {
+func getActiveProducts(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var products []models.Product
-		query := db.Where("active = ?", true).Preload("Category")
+		// Filters: category_id, category (slug), tag (slug), min_price, max_price, has_image, stock_status, attr.<key>
+		query, err := pagination.Parse(c, pagination.Products)
+		if err != nil {
+			return err
+		}
+		currency, err := requestCurrency(c, svc)
+		if err != nil {
+			return err
+		}
+		// Price bounds come in the requested currency; stored prices are in the base one
+		if query.Filter.MinPrice != nil {
+			*query.Filter.MinPrice = svc.Currencies.ToBase(currency, *query.Filter.MinPrice)
+		}
+		if query.Filter.MaxPrice != nil {
+			*query.Filter.MaxPrice = svc.Currencies.ToBase(currency, *query.Filter.MaxPrice)
+		}
+		if categoryID := query.Filter.CategoryID; categoryID != 0 {
+			cache.Tag(c, cache.CategoryTag(categoryID))
+		} else {
+			cache.Tag(c, cache.TagProducts)
+		}
+		if fresh, err := conditional(c, query, svc.Products.ActiveVersion); err != nil || fresh {
+			return err
+		}
+
+		page, err := svc.Products.ActivePage(c.UserContext(), query.Filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener productos")
+		}
+		if err := svc.Currencies.PriceList(c.UserContext(), currency, page.Items); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener productos")
+		}
+		// Listings of one category also count the products per attribute value
+		facets, err := svc.Products.Facets(c.UserContext(), query.Filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener productos")
+		}
+		return pagination.SendFaceted(c, query, page, facets)
+	}
+}
 
-		// Filter by category if provided
-		if categoryID := c.Query("category_id"); categoryID != "" {
-			query = query.Where("category_id = ?", categoryID)
+func getProduct(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		currency, err := requestCurrency(c, svc)
+		if err != nil {
+			return err
+		}
+		// Any product, category or relation change may alter the detail or its related products
+		fresh, err := conditionalVersion(c, func(ctx context.Context) (services.Version, error) {
+			return svc.Products.DetailVersion(ctx, c.Path()+"?currency="+currency.Code)
+		})
+		if err != nil || fresh {
+			return err
 		}
 
-		if err := query.Order("created_at DESC").Find(&products).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener productos"})
+		detail, err := svc.Products.Detail(c.UserContext(), c.Params("slug"))
+		if errors.Is(err, repository.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Producto no encontrado")
 		}
-		return c.JSON(products)
+		if err == nil {
+			err = svc.Currencies.PriceDetail(c.UserContext(), currency, detail)
+		}
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener el producto")
+		}
+		return c.JSON(detail)
 	}
 }
 
-func getActiveContacts(db *gorm.DB) fiber.Handler {
+// getCollection an active collection with its visible products in order
+func getCollection(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var contacts []models.ContactInfo
-		if err := db.Where("active = ?", true).Order("order ASC").Find(&contacts).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener contactos"})
+		currency, err := requestCurrency(c, svc)
+		if err != nil {
+			return err
+		}
+		fresh, err := conditionalVersion(c, func(ctx context.Context) (services.Version, error) {
+			return svc.Collections.ActiveVersion(ctx, repository.Filter{}, c.Path()+"?currency="+currency.Code)
+		})
+		if err != nil || fresh {
+			return err
 		}
-		return c.JSON(contacts)
+
+		collection, err := svc.Collections.Detail(c.UserContext(), c.Params("slug"))
+		if errors.Is(err, repository.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Colección no encontrada")
+		}
+		if err == nil {
+			err = svc.Currencies.PriceList(c.UserContext(), currency, collection.Products)
+		}
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener la colección")
+		}
+		return c.JSON(collection)
 	}
 }
 
-// Helper function to get common data for all pages
-func getCommonData(db *gorm.DB) fiber.Map {
-	var siteConfig models.SiteConfig
-	var contacts []models.ContactInfo
+func getActiveContacts(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query, err := pagination.Parse(c, pagination.Contacts)
+		if err != nil {
+			return err
+		}
+		if fresh, err := conditional(c, query, svc.Contacts.ActiveVersion); err != nil || fresh {
+			return err
+		}
+
+		page, err := svc.Contacts.ActivePage(c.UserContext(), query.Filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener contactos")
+		}
+		return pagination.Send(c, query, page)
+	}
+}
+
+// searchProducts full-text search over the visible products, by relevance (q, limit, page)
+func searchProducts(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query, err := pagination.Parse(c, pagination.Search)
+		if err != nil {
+			return err
+		}
+		currency, err := requestCurrency(c, svc)
+		if err != nil {
+			return err
+		}
+		if fresh, err := conditional(c, query, svc.Products.ActiveVersion); err != nil || fresh {
+			return err
+		}
+
+		page, err := svc.Products.Search(c.UserContext(), c.Query("q"), query.Filter)
+		var validation *utils.ValidationError
+		if errors.As(err, &validation) {
+			return err
+		}
+		if err == nil {
+			err = svc.Currencies.PriceResults(c.UserContext(), currency, page.Items)
+		}
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al buscar productos")
+		}
+		return pagination.Send(c, query, page)
+	}
+}
+
+// getActiveCurrencies lists the currencies a visitor can pick with ?currency=
+func getActiveCurrencies(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if fresh, err := conditionalVersion(c, func(ctx context.Context) (services.Version, error) {
+			return svc.Currencies.ActiveVersion(ctx, repository.Filter{}, c.Path())
+		}); err != nil || fresh {
+			return err
+		}
+
+		currencies, err := svc.Currencies.Active(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener monedas")
+		}
+		return c.JSON(currencies)
+	}
+}
+
+// requestCurrency resolves the ?currency= parameter; without it prices are in the base
+// currency. The error handler answers an unknown or inactive code with 400.
+func requestCurrency(c *fiber.Ctx, svc *services.Services) (models.Currency, error) {
+	return svc.Currencies.Resolve(c.UserContext(), c.Query("currency"))
+}
+
+// pageCurrency currency of the prices on a page: ?currency= or, when it is missing or
+// not available, the base currency
+func pageCurrency(c *fiber.Ctx, svc *services.Services) (models.Currency, error) {
+	currency, err := svc.Currencies.Resolve(c.UserContext(), c.Query("currency"))
+	var validation *utils.ValidationError
+	if errors.As(err, &validation) {
+		return svc.Currencies.Base(c.UserContext())
+	}
+	return currency, err
+}
+
+// listVersion computes the validators of a listing from its filters and normalized query
+type listVersion func(ctx context.Context, filter repository.Filter, scope string) (services.Version, error)
+
+// conditional sets the validators of an API listing and answers 304 when the client
+// copy is current, before loading the rows. fresh reports that the response was sent.
+func conditional(c *fiber.Ctx, query pagination.Query, version listVersion) (fresh bool, err error) {
+	return conditionalVersion(c, func(ctx context.Context) (services.Version, error) {
+		return version(ctx, query.Filter, query.Key())
+	})
+}
 
-	db.Where("key = ?", "site_name").First(&siteConfig)
-	siteName := siteConfig.Value
-	if siteName == "" {
-		siteName = "Mi Sitio Web"
+// conditionalVersion sets the given validators and answers 304 when the client copy is current
+func conditionalVersion(c *fiber.Ctx, version func(ctx context.Context) (services.Version, error)) (fresh bool, err error) {
+	current, err := version(c.UserContext())
+	if err != nil {
+		return true, fiber.NewError(fiber.StatusInternalServerError, "Error al obtener la versión")
 	}
 
-	db.Where("key = ?", "site_description").First(&siteConfig)
-	siteDescription := siteConfig.Value
-	if siteDescription == "" {
-		siteDescription = "Descripción del sitio web"
+	c.Set(fiber.HeaderCacheControl, apiCacheControl)
+	cache.Validators(c, current.ETag, current.LastModified)
+	if cache.NotModified(c) {
+		return true, cache.SendNotModified(c)
 	}
+	return false, nil
+}
+
+// Helper function to get common data for all pages
+func getCommonData(ctx context.Context, svc *services.Services) (fiber.Map, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "getCommonData")
+	defer span.End()
 
-	db.Where("active = ?", true).Order("order ASC").Find(&contacts)
+	siteName, err := svc.Config.Value(ctx, "site_name", defaultSiteName)
+	if err != nil {
+		return nil, err
+	}
+	siteDescription, err := svc.Config.Value(ctx, "site_description", defaultSiteDescription)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := svc.Contacts.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return fiber.Map{
 		"SiteName":        siteName,
 		"SiteDescription": siteDescription,
 		"CurrentYear":     time.Now().Year(),
 		"Contacts":        contacts,
+	}, nil
+}
+
+// render merges the common data into data and renders the page
+func render(c *fiber.Ctx, svc *services.Services, page string, data fiber.Map) error {
+	commonData, err := getCommonData(c.UserContext(), svc)
+	if err != nil {
+		return c.Status(500).SendString("Error interno del servidor")
 	}
+
+	// Page data wins over common data (e.g. Contacts on the contact page)
+	for k, v := range commonData {
+		if _, ok := data[k]; !ok {
+			data[k] = v
+		}
+	}
+
+	return tracing.Render(c, page, data)
 }
 
 // Web Page Handlers
-func homeHandler(db *gorm.DB) fiber.Handler {
+func homeHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var slides []models.Slide
-		var categories []models.Category
-		var siteConfig models.SiteConfig
+		ctx := c.UserContext()
 
-		// Get active slides
-		db.Where("active = ?", true).Order("order ASC").Find(&slides)
+		slides, err := svc.Slides.Active(ctx)
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
 
-		// Get active categories with some products
-		db.Preload("Products", "active = ?", true).Where("active = ?", true).Order("order ASC").Limit(6).Find(&categories)
+		// Active categories with some products
+		categories, err := svc.Categories.Home(ctx)
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
 
-		// Get site config
-		db.Where("key = ?", "home_videos").First(&siteConfig)
+		videos, err := svc.Config.List(ctx, "home_videos")
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
 
-		// Prepare data for template
-		commonData := getCommonData(db)
-		data := fiber.Map{
+		return render(c, svc, "pages/index", fiber.Map{
 			"Title":       "Inicio",
 			"CurrentPage": "home",
 			"Slides":      slides,
 			"Categories":  categories,
-			"Videos":      strings.Split(siteConfig.Value, ","),
-		}
-
-		// Merge common data
-		for k, v := range commonData {
-			data[k] = v
-		}
-
-		return c.Render("pages/index", data)
+			"Videos":      videos,
+		})
 	}
 }
 
-func productsHandler(db *gorm.DB) fiber.Handler {
+func productsHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var categories []models.Category
-		if err := db.Preload("Products", "active = ?", true).Where("active = ?", true).Order("order ASC").Find(&categories).Error; err != nil {
+		currency, err := pageCurrency(c, svc)
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
+		categories, err := svc.Categories.WithProducts(c.UserContext(), 0)
+		if err == nil {
+			err = svc.Currencies.PriceCategories(c.UserContext(), currency, categories)
+		}
+		if err != nil {
 			return c.Status(500).SendString("Error interno del servidor")
 		}
 
-		commonData := getCommonData(db)
-		data := fiber.Map{
+		return render(c, svc, "pages/products", fiber.Map{
 			"Title":       "Productos",
 			"CurrentPage": "products",
 			"Categories":  categories,
+		})
+	}
+}
+
+// catalogPathHandler resolves /productos/<category slugs>[/<product slug>]. Slugs are
+// unique across the catalog, so the last one names the category (or else the product)
+// and any other path to it, such as an old flat URL or one from before a move,
+// redirects to the canonical path.
+func catalogPathHandler(svc *services.Services, siteURL string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		path := strings.Trim(c.Params("*"), "/")
+		slug := path[strings.LastIndex(path, "/")+1:]
+
+		category, err := svc.Categories.BySlug(ctx, slug)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
+		if category != nil && category.SlugPath == path {
+			return categoryPage(c, svc, siteURL, category)
 		}
 
-		// Merge common data
-		for k, v := range commonData {
-			data[k] = v
+		cache.Tag(c, cache.TagProducts, cache.TagCategories)
+		var detail *services.ProductDetail
+		if strings.Contains(path, "/") {
+			detail, err = svc.Products.Detail(ctx, slug)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return c.Status(500).SendString("Error interno del servidor")
+			}
+			if detail != nil && detail.URL == "/productos/"+path {
+				return productPage(c, svc, siteURL, detail)
+			}
 		}
 
-		return c.Render("pages/products", data)
+		switch {
+		case category != nil:
+			return c.Redirect(category.URL(), fiber.StatusMovedPermanently)
+		case detail != nil:
+			return c.Redirect(detail.URL, fiber.StatusMovedPermanently)
+		case strings.Contains(path, "/"):
+			return c.Status(404).SendString("Producto no encontrado")
+		default:
+			return c.Status(404).SendString("Categoría no encontrada")
+		}
 	}
 }
 
-func categoryHandler(db *gorm.DB) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		categorySlug := c.Params("category")
+// categoryPage renders a category with the products of its whole subtree
+func categoryPage(c *fiber.Ctx, svc *services.Services, siteURL string, category *services.CategoryDetail) error {
+	// Its breadcrumbs name every parent, and their listings include this one
+	for _, id := range category.PathIDs() {
+		cache.Tag(c, cache.CategoryTag(id))
+	}
 
-		var category models.Category
-		if err := db.Preload("Products", "active = ?", true).Where("slug = ? AND active = ?", categorySlug, true).First(&category).Error; err != nil {
-			return c.Status(404).SendString("Categoría no encontrada")
-		}
+	currency, err := pageCurrency(c, svc)
+	if err == nil {
+		err = svc.Currencies.PriceList(c.UserContext(), currency, category.Products)
+	}
+	if err != nil {
+		return c.Status(500).SendString("Error interno del servidor")
+	}
+	specs, err := svc.Attributes.SpecTable(c.UserContext(), &category.Category)
+	if err != nil {
+		return c.Status(500).SendString("Error interno del servidor")
+	}
 
-		commonData := getCommonData(db)
-		data := fiber.Map{
-			"Title":       category.Name,
-			"CurrentPage": "category",
-			"Category":    category,
-		}
+	return render(c, svc, "pages/category", fiber.Map{
+		"Title":       category.Name,
+		"CurrentPage": "category",
+		"Category":    category,
+		"Specs":       specs,
+		"Canonical":   canonicalURL(c, siteURL, category.URL()),
+	})
+}
 
-		// Merge common data
-		for k, v := range commonData {
-			data[k] = v
+// productPage renders the detail of a product at its canonical path
+func productPage(c *fiber.Ctx, svc *services.Services, siteURL string, detail *services.ProductDetail) error {
+	currency, err := pageCurrency(c, svc)
+	if err == nil {
+		err = svc.Currencies.PriceDetail(c.UserContext(), currency, detail)
+	}
+	if err != nil {
+		return c.Status(500).SendString("Error interno del servidor")
+	}
+
+	return render(c, svc, "pages/product", fiber.Map{
+		"Title":       detail.Name,
+		"CurrentPage": "product",
+		"Product":     detail,
+		"Canonical":   canonicalURL(c, siteURL, detail.URL),
+	})
+}
+
+// collectionHandler renders an active collection with its visible products
+func collectionHandler(svc *services.Services, siteURL string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		collection, err := svc.Collections.Detail(c.UserContext(), c.Params("slug"))
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(404).SendString("Colección no encontrada")
+		}
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
+		currency, err := pageCurrency(c, svc)
+		if err == nil {
+			err = svc.Currencies.PriceList(c.UserContext(), currency, collection.Products)
+		}
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
 		}
 
-		return c.Render("pages/category", data)
+		return render(c, svc, "pages/collection", fiber.Map{
+			"Title":       collection.Name,
+			"CurrentPage": "collection",
+			"Collection":  collection,
+			"Canonical":   canonicalURL(c, siteURL, collection.URL()),
+		})
 	}
 }
 
-func contactHandler(db *gorm.DB) fiber.Handler {
+// canonicalURL absolute URL of path under the public origin
+func canonicalURL(c *fiber.Ctx, siteURL, path string) string {
+	if siteURL == "" {
+		siteURL = c.BaseURL()
+	}
+	return strings.TrimRight(siteURL, "/") + path
+}
+
+func contactHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var contacts []models.ContactInfo
-		if err := db.Where("active = ?", true).Order("order ASC").Find(&contacts).Error; err != nil {
+		contacts, err := svc.Contacts.Active(c.UserContext())
+		if err != nil {
 			return c.Status(500).SendString("Error interno del servidor")
 		}
 
-		commonData := getCommonData(db)
-		data := fiber.Map{
+		return render(c, svc, "pages/contact", fiber.Map{
 			"Title":       "Contacto",
 			"CurrentPage": "contact",
 			"Contacts":    contacts,
-		}
+		})
+	}
+}
 
-		// Merge common data
-		for k, v := range commonData {
-			data[k] = v
+func locationsHandler(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		locations, err := svc.Config.List(c.UserContext(), "locations")
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
 		}
 
-		return c.Render("pages/contact", data)
+		return render(c, svc, "pages/locations", fiber.Map{
+			"Title":       "Ubicaciones",
+			"CurrentPage": "locations",
+			"Locations":   locations,
+		})
 	}
 }
 
-func locationsHandler(db *gorm.DB) fiber.Handler {
+func searchHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var siteConfig models.SiteConfig
-		db.Where("key = ?", "locations").First(&siteConfig)
-
-		commonData := getCommonData(db)
+		text := strings.TrimSpace(c.Query("q"))
 		data := fiber.Map{
-			"Title":       "Ubicaciones",
-			"CurrentPage": "locations",
-			"Locations":   strings.Split(siteConfig.Value, ","),
+			"Title":       "Buscar",
+			"CurrentPage": "search",
+			"Query":       text,
+		}
+		// Without q the page only shows the search form
+		if text == "" {
+			return render(c, svc, "pages/search", data)
 		}
+		data["Title"] = "Buscar: " + text
 
-		// Merge common data
-		for k, v := range commonData {
-			data[k] = v
+		currency, err := pageCurrency(c, svc)
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
+		query, err := pagination.Parse(c, pagination.Search)
+		var page services.Page[services.SearchResult]
+		if err == nil {
+			page, err = svc.Products.Search(c.UserContext(), text, query.Filter)
+		}
+		var validation *utils.ValidationError
+		if errors.As(err, &validation) {
+			data["Error"] = strings.Join(validation.Details, ". ")
+			return render(c.Status(400), svc, "pages/search", data)
+		}
+		if err == nil {
+			err = svc.Currencies.PriceResults(c.UserContext(), currency, page.Items)
+		}
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
 		}
 
-		return c.Render("pages/locations", data)
+		limit := int64(query.Filter.Limit)
+		pages := int((page.Total + limit - 1) / limit)
+		data["Results"] = page.Items
+		data["Total"] = page.Total
+		data["Page"], data["Pages"] = query.Page, pages
+		if query.Page > 1 {
+			data["PrevURL"] = searchURL(text, min(query.Page-1, max(pages, 1)))
+		}
+		if query.Page < pages {
+			data["NextURL"] = searchURL(text, query.Page+1)
+		}
+		return render(c, svc, "pages/search", data)
 	}
 }
 
-func catalogHandler(db *gorm.DB) fiber.Handler {
+// searchURL link to another page of the search results
+func searchURL(text string, page int) string {
+	values := url.Values{"q": {text}}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	return "/buscar?" + values.Encode()
+}
+
+func catalogHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var products []models.Product
-		if err := db.Preload("Category").Where("active = ?", true).Order("created_at DESC").Find(&products).Error; err != nil {
+		currency, err := pageCurrency(c, svc)
+		if err != nil {
+			return c.Status(500).SendString("Error interno del servidor")
+		}
+		products, err := svc.Products.Active(c.UserContext(), 0)
+		if err == nil {
+			err = svc.Currencies.PriceList(c.UserContext(), currency, products)
+		}
+		if err != nil {
 			return c.Status(500).SendString("Error interno del servidor")
 		}
 
-		commonData := getCommonData(db)
-		data := fiber.Map{
+		return render(c, svc, "pages/catalog", fiber.Map{
 			"Title":       "Catálogo",
 			"CurrentPage": "catalog",
 			"Products":    products,
-		}
-
-		// Merge common data
-		for k, v := range commonData {
-			data[k] = v
-		}
-
-		return c.Render("pages/catalog", data)
+		})
 	}
 }
@@ -0,0 +1,1033 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"website/backend/middleware"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/repository"
+	"website/backend/services"
+	"website/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/template/html/v2"
+)
+
+// testTemplates minimal pages that print the data each handler passes
+var testTemplates = fstest.MapFS{
+	"pages/index.html":      {Data: []byte(`{{.Title}}|{{.SiteName}}|{{range .Slides}}slide:{{.Title}};{{end}}{{range .Categories}}category:{{.Name}}({{len .Products}});{{end}}{{range .Videos}}video:{{.}};{{end}}`)},
+	"pages/products.html":   {Data: []byte(`{{.Title}}|{{range .Categories}}category:{{.Name}}({{len .Products}});{{end}}`)},
+	"pages/category.html":   {Data: []byte(`{{range .Category.Breadcrumbs}}crumb:{{.Name}};{{end}}{{range .Category.Children}}sub:{{.Name}};{{end}}{{.Title}}|{{range .Category.Products}}product:{{.Name}};{{end}}{{with .Specs}}|{{range .Columns}}{{.Name}};{{end}}{{range .Rows}}{{.Product.Name}}={{range .Values}}{{.}};{{end}}{{end}}{{end}}`)},
+	"pages/contact.html":    {Data: []byte(`{{.Title}}|{{.SiteDescription}}|{{range .Contacts}}contact:{{.Value}};{{end}}`)},
+	"pages/locations.html":  {Data: []byte(`{{.Title}}|{{range .Locations}}location:{{.}};{{end}}`)},
+	"pages/catalog.html":    {Data: []byte(`{{.Title}}|{{range .Products}}product:{{.Name}}@{{.Category.Name}};{{end}}`)},
+	"pages/product.html":    {Data: []byte(`{{.Title}}|{{.Canonical}}|{{.Product.Category.Name}}|{{range .Product.FeatureList}}feature:{{.}};{{end}}{{range .Product.Related}}related:{{.Name}};{{end}}{{range .Product.Accessories}}accessory:{{.Name}};{{end}}{{with .Product.Pricing}}|{{.Formatted}}{{end}}`)},
+	"pages/collection.html": {Data: []byte(`{{.Title}}|{{.Canonical}}|{{range .Collection.Products}}product:{{.Name}};{{end}}`)},
+	"pages/search.html":     {Data: []byte(`{{.Title}}|{{.Error}}|{{.Total}}|{{range .Results}}result:{{.NameHTML}}={{.Snippet}};{{end}}|{{.NextURL}}`)},
+}
+
+// newTestApp public routes over an in-memory store with sample content
+func newTestApp(t *testing.T) (*fiber.App, *services.Services) {
+	t.Helper()
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("datos de prueba: %v", err)
+		}
+	}
+	for _, config := range []models.SiteConfig{
+		{Key: "site_name", Value: "Estudio"},
+		{Key: "home_videos", Value: "https://v/1, https://v/2,"},
+		{Key: "locations", Value: "Madrid,Sevilla"},
+	} {
+		must(store.Configs().Create(ctx, &config))
+	}
+	for _, slide := range []models.Slide{
+		{Title: "Segundo", Order: 2, Active: true},
+		{Title: "Primero", Order: 1, Active: true},
+		{Title: "Oculto", Order: 0, Active: false},
+	} {
+		must(store.Slides().Create(ctx, &slide))
+	}
+
+	photo := models.Category{Name: "Fotografía", Slug: "fotografia", Path: "/1/", SlugPath: "fotografia", Order: 1, Active: true}
+	hidden := models.Category{Name: "Borrador", Slug: "borrador", Path: "/2/", SlugPath: "borrador", Order: 2, Active: false}
+	must(store.Categories().Create(ctx, &photo))
+	must(store.Categories().Create(ctx, &hidden))
+	for _, product := range []models.Product{
+		{CategoryID: photo.ID, Name: "Retrato", Slug: "retrato", Active: true},
+		{CategoryID: photo.ID, Name: "Boda", Slug: "boda", Active: true},
+		{CategoryID: photo.ID, Name: "Retirado", Slug: "retirado", Active: false},
+		{CategoryID: hidden.ID, Name: "Prueba", Slug: "prueba", Active: true},
+	} {
+		must(store.Products().Create(ctx, &product))
+	}
+	for _, contact := range []models.ContactInfo{
+		{Type: "email", Value: "hola@example.com", Order: 1, Active: true},
+		{Type: "phone", Value: "000", Order: 2, Active: false},
+	} {
+		must(store.Contacts().Create(ctx, &contact))
+	}
+
+	app := fiber.New(fiber.Config{
+		Views:        html.NewFileSystem(http.FS(testTemplates), ".html"),
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	app.Use(middleware.RequestID())
+	svc := services.New(store)
+	SetupRoutes(app, svc, "https://example.com")
+	return app, svc
+}
+
+// get performs a request and returns the status and body
+func get(t *testing.T, app *fiber.App, path string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+// names extracts the "name", "title" or "value" field of each item of a listing
+func names(t *testing.T, body string) []string {
+	t.Helper()
+	var envelope struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil || envelope.Data == nil {
+		t.Fatalf("respuesta no es un listado JSON: %v\n%s", err, body)
+	}
+	var result []string
+	for _, item := range envelope.Data {
+		for _, key := range []string{"name", "title", "value"} {
+			if value, ok := item[key].(string); ok {
+				result = append(result, value)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func TestAPIErrorsIncludeRequestID(t *testing.T) {
+	app, _ := newTestApp(t)
+
+	for _, path := range []string{"/api/products/no-existe", "/api/collections/no-existe", "/api/products?limit=x"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		var body struct {
+			Error     string `json:"error"`
+			RequestID string `json:"request_id"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode < 400 || body.Error == "" || body.RequestID == "" || body.RequestID != resp.Header.Get(fiber.HeaderXRequestID) {
+			t.Errorf("%s = %d %+v, X-Request-ID %q", path, resp.StatusCode, body, resp.Header.Get(fiber.HeaderXRequestID))
+		}
+	}
+}
+
+func TestAPIRoutes(t *testing.T) {
+	app, _ := newTestApp(t)
+
+	status, body := get(t, app, "/api/config")
+	var config map[string]string
+	if status != 200 || json.Unmarshal([]byte(body), &config) != nil || config["site_name"] != "Estudio" {
+		t.Fatalf("/api/config = %d %s", status, body)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/slides", "Primero,Segundo"},
+		{"/api/categories", "Fotografía"},
+		{"/api/products", "Prueba,Boda,Retrato"},
+		{"/api/products?category_id=1", "Boda,Retrato"},
+		{"/api/products?category_id=2", "Prueba"},
+		{"/api/contacts", "hola@example.com"},
+	}
+	for _, tt := range tests {
+		status, body := get(t, app, tt.path)
+		if status != 200 {
+			t.Fatalf("%s = %d %s", tt.path, status, body)
+		}
+		if got := strings.Join(names(t, body), ","); got != tt.want {
+			t.Errorf("%s = %q, se esperaba %q", tt.path, got, tt.want)
+		}
+	}
+
+	if status, _ := get(t, app, "/api/products?category_id=abc"); status != 400 {
+		t.Errorf("category_id inválido = %d, se esperaba 400", status)
+	}
+}
+
+func TestPageRoutes(t *testing.T) {
+	app, _ := newTestApp(t)
+
+	tests := []struct {
+		path    string
+		status  int
+		want    []string
+		notWant []string
+	}{
+		{"/", 200,
+			[]string{"Inicio|Estudio|", "slide:Primero;slide:Segundo;", "category:Fotografía(2);", "video:https://v/1;video:https://v/2;"},
+			[]string{"Oculto", "Borrador", "video:;"}},
+		{"/productos", 200, []string{"Productos|category:Fotografía(2);"}, []string{"Borrador"}},
+		{"/productos/fotografia", 200, []string{"Fotografía|product:Boda;product:Retrato;"}, []string{"Retirado"}},
+		{"/productos/borrador", 404, nil, nil},
+		{"/productos/no-existe", 404, nil, nil},
+		{"/contacto", 200, []string{"Contacto|Descripción del sitio web|contact:hola@example.com;"}, []string{"000"}},
+		{"/ubicaciones", 200, []string{"Ubicaciones|location:Madrid;location:Sevilla;"}, nil},
+		{"/catalogo", 200, []string{"Catálogo|product:Prueba@Borrador;product:Boda@Fotografía;product:Retrato@Fotografía;"}, []string{"Retirado"}},
+	}
+	for _, tt := range tests {
+		status, body := get(t, app, tt.path)
+		if status != tt.status {
+			t.Fatalf("%s = %d, se esperaba %d\n%s", tt.path, status, tt.status, body)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s no contiene %q:\n%s", tt.path, want, body)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(body, notWant) {
+				t.Errorf("%s contiene %q:\n%s", tt.path, notWant, body)
+			}
+		}
+	}
+}
+
+// conditionalGet performs a GET with the given conditional headers
+func conditionalGet(t *testing.T, app *fiber.App, path string, headers map[string]string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestAPIConditionalRequests(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	for _, path := range []string{"/api/config", "/api/slides", "/api/categories", "/api/products", "/api/contacts"} {
+		resp := conditionalGet(t, app, path, nil)
+		etag, modified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if resp.StatusCode != 200 || !strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, "W/") || modified == "" {
+			t.Fatalf("%s = %d, ETag %q, Last-Modified %q", path, resp.StatusCode, etag, modified)
+		}
+		if control := resp.Header.Get("Cache-Control"); control != apiCacheControl {
+			t.Errorf("%s Cache-Control = %q", path, control)
+		}
+
+		for _, headers := range []map[string]string{
+			{"If-None-Match": etag},
+			{"If-None-Match": `"otro", ` + etag},
+			{"If-Modified-Since": modified},
+		} {
+			if resp := conditionalGet(t, app, path, headers); resp.StatusCode != 304 || resp.Header.Get("ETag") != etag {
+				t.Errorf("%s %v = %d, se esperaba 304 con el mismo ETag", path, headers, resp.StatusCode)
+			}
+		}
+		// If-None-Match tiene prioridad sobre If-Modified-Since
+		if resp := conditionalGet(t, app, path, map[string]string{"If-None-Match": `"otro"`, "If-Modified-Since": modified}); resp.StatusCode != 200 {
+			t.Errorf("%s con otro ETag = %d, se esperaba 200", path, resp.StatusCode)
+		}
+		if resp := conditionalGet(t, app, path, map[string]string{"If-Modified-Since": "Mon, 01 Jan 2001 00:00:00 GMT"}); resp.StatusCode != 200 {
+			t.Errorf("%s modificado desde 2001 = %d, se esperaba 200", path, resp.StatusCode)
+		}
+	}
+
+	etag := func(path string) string {
+		return conditionalGet(t, app, path, nil).Header.Get("ETag")
+	}
+	if etag("/api/products") == etag("/api/products?category_id=1") {
+		t.Error("el filtro por categoría debería cambiar el ETag")
+	}
+
+	// Los productos incluyen su categoría: editarla cambia su versión
+	products := etag("/api/products")
+	category, err := svc.Categories.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	category.Name = "Fotos"
+	if err := svc.Categories.Update(ctx, services.Actor{}, category.ID, category); err != nil {
+		t.Fatal(err)
+	}
+	if etag("/api/products") == products {
+		t.Error("editar una categoría debería cambiar el ETag de /api/products")
+	}
+
+	// Borrar no deja updated_at, pero cambia el ETag
+	contacts := etag("/api/contacts")
+	if err := svc.Contacts.Delete(ctx, services.Actor{}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if resp := conditionalGet(t, app, "/api/contacts", map[string]string{"If-None-Match": contacts}); resp.StatusCode != 200 {
+		t.Errorf("tras borrar un contacto = %d, se esperaba 200", resp.StatusCode)
+	}
+
+	// Desactivar saca el producto del listado, pero su updated_at adelanta Last-Modified
+	product, err := svc.Products.Get(ctx, 1)
+	if err != nil || !product.Active {
+		t.Fatalf("producto 1 = %+v, %v", product, err)
+	}
+	product.Price, product.Active = money.Units(10), false
+	if err := svc.Products.Update(ctx, services.Actor{}, product.ID, product); err != nil {
+		t.Fatal(err)
+	}
+	deactivated, err := svc.Products.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := svc.Products.ActiveVersion(ctx, repository.Filter{}, "/api/products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !version.LastModified.Equal(deactivated.UpdatedAt) {
+		t.Errorf("Last-Modified tras desactivar = %v, se esperaba %v", version.LastModified, deactivated.UpdatedAt)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+	for _, product := range []models.Product{
+		{CategoryID: 1, Price: money.Units(10), Name: "Cámara réflex", Description: "Cuerpo <b>robusto</b> para fotógrafos que buscan una cámara fiable", Active: true},
+		{CategoryID: 1, Price: money.Units(10), Name: "Trípode", Description: "Soporte estable para cámaras pequeñas", Active: true},
+		{CategoryID: 1, Price: money.Units(10), Name: "Cámara antigua", Active: false},
+		{CategoryID: 2, Price: money.Units(10), Name: "Cámara de prueba", Active: true},
+	} {
+		if err := svc.Products.Create(ctx, services.Actor{}, &product); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Sin acentos, en plural y con una errata en el nombre
+	for _, q := range []string{"camara", "CÁMARAS", "camra"} {
+		status, body := get(t, app, "/api/search?q="+url.QueryEscape(q))
+		if status != 200 {
+			t.Fatalf("%s = %d %s", q, status, body)
+		}
+		if got := names(t, body); len(got) == 0 || got[0] != "Cámara réflex" || strings.Contains(body, "antigua") || strings.Contains(body, "de prueba") {
+			t.Errorf("%s = %v", q, got)
+		}
+	}
+
+	// El nombre pesa más que la descripción; el texto se escapa antes de resaltarlo
+	var result struct {
+		Data []struct {
+			Name     string `json:"name"`
+			NameHTML string `json:"name_html"`
+			Snippet  string `json:"snippet"`
+		} `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	_, body := get(t, app, "/api/search?q=camara")
+	if err := json.Unmarshal([]byte(body), &result); err != nil || result.Meta.Total != 2 {
+		t.Fatalf("camara = %s", body)
+	}
+	if first := result.Data[0]; first.NameHTML != "<mark>Cámara</mark> réflex" || !strings.Contains(first.Snippet, "&lt;b&gt;robusto&lt;/b&gt;") ||
+		!strings.Contains(first.Snippet, "una <mark>cámara</mark> fiable") {
+		t.Errorf("resaltado = %+v", first)
+	}
+	if result.Data[1].Name != "Trípode" {
+		t.Errorf("segundo resultado = %+v", result.Data[1])
+	}
+
+	// Categoría por nombre, paginación y consultas inválidas
+	if got := names(t, mustGet(t, app, "/api/search?q=fotografia&limit=1&page=2")); len(got) != 1 {
+		t.Errorf("búsqueda por categoría, página 2 = %v", got)
+	}
+	for _, path := range []string{"/api/search", "/api/search?q=a", "/api/search?q=camara&sort=name", "/api/search?q=camara&cursor="} {
+		if status, _ := get(t, app, path); status != 400 {
+			t.Errorf("%s = %d, se esperaba 400", path, status)
+		}
+	}
+
+	// Página HTML
+	status, page := get(t, app, "/buscar?q=camara&limit=1")
+	if status != 200 || !strings.Contains(page, "Buscar: camara||2|result:<mark>Cámara</mark> réflex=") || !strings.Contains(page, "/buscar?page=2&amp;q=camara") {
+		t.Errorf("/buscar = %d %s", status, page)
+	}
+	if status, page := get(t, app, "/buscar?q=x"); status != 400 || !strings.Contains(page, "entre 2 y 100") {
+		t.Errorf("/buscar?q=x = %d %s", status, page)
+	}
+	if status, page := get(t, app, "/buscar"); status != 200 || !strings.HasPrefix(page, "Buscar|||") {
+		t.Errorf("/buscar = %d %s", status, page)
+	}
+}
+
+// mustGet GETs a path that must answer 200
+func mustGet(t *testing.T, app *fiber.App, path string) string {
+	t.Helper()
+	status, body := get(t, app, path)
+	if status != 200 {
+		t.Fatalf("%s = %d %s", path, status, body)
+	}
+	return body
+}
+
+func TestProductDetail(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	lens := models.Product{CategoryID: 1, Name: "Objetivo 50mm", Slug: "objetivo-50mm", Price: money.Units(200), Features: "- Apertura f/1.8\n- Enfoque automático\n", Active: true}
+	if err := svc.Products.Create(ctx, services.Actor{}, &lens); err != nil {
+		t.Fatal(err)
+	}
+
+	status, body := get(t, app, "/api/products/objetivo-50mm")
+	var detail services.ProductDetail
+	if status != 200 || json.Unmarshal([]byte(body), &detail) != nil {
+		t.Fatalf("/api/products/objetivo-50mm = %d %s", status, body)
+	}
+	if detail.URL != "/productos/fotografia/objetivo-50mm" || strings.Join(detail.FeatureList, "|") != "Apertura f/1.8|Enfoque automático" {
+		t.Errorf("detalle = %+v", detail)
+	}
+	var related []string
+	for _, product := range detail.Related {
+		related = append(related, product.Name)
+	}
+	if strings.Join(related, ",") != "Boda,Retrato" {
+		t.Errorf("relacionados = %v", related)
+	}
+
+	// Inactivos, de categorías inactivas o inexistentes
+	for _, slug := range []string{"retirado", "prueba", "no-existe"} {
+		if status, _ := get(t, app, "/api/products/"+slug); status != 404 {
+			t.Errorf("/api/products/%s = %d, se esperaba 404", slug, status)
+		}
+		if status, _ := get(t, app, "/productos/fotografia/"+slug); status != 404 {
+			t.Errorf("/productos/fotografia/%s = %d, se esperaba 404", slug, status)
+		}
+	}
+
+	status, page := get(t, app, "/productos/fotografia/objetivo-50mm")
+	want := "Objetivo 50mm|https://example.com/productos/fotografia/objetivo-50mm|Fotografía|" +
+		"feature:Apertura f/1.8;feature:Enfoque automático;related:Boda;related:Retrato;|$200.00"
+	if status != 200 || page != want {
+		t.Errorf("página = %d %q", status, page)
+	}
+
+	// Al cambiar de categoría la URL antigua redirige a la nueva
+	video := models.Category{Name: "Vídeo", Slug: "video", Active: true}
+	if err := svc.Categories.Create(ctx, services.Actor{}, &video); err != nil {
+		t.Fatal(err)
+	}
+	lens.CategoryID = video.ID
+	if err := svc.Products.Update(ctx, services.Actor{}, lens.ID, &lens); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := app.Test(httptest.NewRequest("GET", "/productos/fotografia/objetivo-50mm", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 301 || resp.Header.Get("Location") != "/productos/video/objetivo-50mm" {
+		t.Errorf("URL antigua = %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+}
+
+func TestAttributeFacets(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	definitions := []models.AttributeDefinition{
+		{CategoryID: 1, Name: "Formato", Type: models.AttributeEnum, Options: []string{"Digital", "Álbum"}, Filterable: true, Order: 1},
+		{CategoryID: 1, Name: "Horas", Type: models.AttributeNumber, Unit: "h", Filterable: true, Order: 2},
+		{CategoryID: 1, Name: "Notas", Type: models.AttributeText, Order: 3},
+	}
+	for i := range definitions {
+		if err := svc.Attributes.Create(ctx, services.Actor{}, &definitions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Retrato, Boda y Retirado (inactivo)
+	values := map[uint][]string{1: {"digital", "1,5"}, 2: {"Álbum", "8"}, 3: {"Álbum", "2"}}
+	for id, value := range values {
+		product, err := svc.Products.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		product.Price = money.Units(100)
+		product.Attributes = []models.ProductAttribute{
+			{AttributeID: definitions[0].ID, Value: value[0]},
+			{AttributeID: definitions[1].ID, Value: value[1]},
+		}
+		if err := svc.Products.Update(ctx, services.Actor{}, id, product); err != nil {
+			t.Fatalf("producto %d: %v", id, err)
+		}
+	}
+
+	facets := func(path string) ([]string, string) {
+		t.Helper()
+		body := mustGet(t, app, path)
+		var envelope struct {
+			Facets []services.Facet `json:"facets"`
+		}
+		if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+			t.Fatal(err)
+		}
+		var counts []string
+		for _, facet := range envelope.Facets {
+			for _, value := range facet.Values {
+				counts = append(counts, fmt.Sprintf("%s=%s:%d", facet.Key, value.Value, value.Count))
+			}
+		}
+		return names(t, body), strings.Join(counts, ",")
+	}
+
+	// Cada faceta cuenta sin su propio filtro; los inactivos no cuentan
+	got, counts := facets("/api/products?category=fotografia&sort=name")
+	if strings.Join(got, ",") != "Boda,Retrato" || counts != "formato=Digital:1,formato=Álbum:1,horas=1.5:1,horas=8:1" {
+		t.Errorf("sin filtros = %v %s", got, counts)
+	}
+	got, counts = facets("/api/products?category_id=1&attr.formato=Digital")
+	if strings.Join(got, ",") != "Retrato" || counts != "formato=Digital:1,formato=Álbum:1,horas=1.5:1" {
+		t.Errorf("formato = %v %s", got, counts)
+	}
+	got, _ = facets("/api/products?category_id=1&attr.horas=2..10")
+	if strings.Join(got, ",") != "Boda" {
+		t.Errorf("rango = %v", got)
+	}
+	got, _ = facets("/api/products?category_id=1&attr.horas=..2&attr.formato=Álbum,Digital")
+	if strings.Join(got, ",") != "Retrato" {
+		t.Errorf("rango abierto = %v", got)
+	}
+	if body := mustGet(t, app, "/api/products?sort=name"); strings.Contains(body, `"facets"`) {
+		t.Errorf("facetas sin categoría: %s", body)
+	}
+	for _, query := range []string{"attr.horas=a..b", "attr.=x", "attr.formato="} {
+		if status, _ := get(t, app, "/api/products?category_id=1&"+query); status != 400 {
+			t.Errorf("%s = %d, se esperaba 400", query, status)
+		}
+	}
+
+	// Tabla de especificaciones: solo las columnas con valores
+	status, page := get(t, app, "/productos/fotografia")
+	if status != 200 || !strings.HasSuffix(page, "|Formato;Horas;Boda=Álbum;8 h;Retrato=Digital;1.5 h;") {
+		t.Errorf("categoría = %d %q", status, page)
+	}
+}
+
+func TestProductVariants(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	for _, variant := range []models.ProductVariant{
+		{ProductID: 1, SKU: "RET-30", Options: map[string]string{"duracion": "30 min"}, Price: money.Units(80), Active: true},
+		{ProductID: 1, SKU: "RET-60", Options: map[string]string{"duracion": "60 min"}, Price: money.Units(120), Order: 1, Active: true},
+		{ProductID: 1, SKU: "RET-90", Options: map[string]string{"duracion": "90 min"}, Price: money.Units(50), Order: 2},
+	} {
+		if err := svc.Variants.Create(ctx, services.Actor{}, &variant); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Solo las variantes activas, y el precio es el menor de ellas
+	var detail services.ProductDetail
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products/retrato")), &detail); err != nil {
+		t.Fatal(err)
+	}
+	var skus []string
+	for _, variant := range detail.Variants {
+		skus = append(skus, variant.SKU)
+	}
+	if detail.Price != money.Units(80) || detail.Pricing == nil || !detail.Pricing.From || strings.Join(skus, ",") != "RET-30,RET-60" {
+		t.Errorf("ficha = %v %v", detail.Price, skus)
+	}
+
+	var listing struct {
+		Data []models.Product `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products?category=fotografia&sort=name")), &listing); err != nil {
+		t.Fatal(err)
+	}
+	if len(listing.Data) != 2 || listing.Data[1].Name != "Retrato" || len(listing.Data[1].Variants) != 2 || listing.Data[0].Variants != nil {
+		t.Errorf("listado = %+v", listing.Data)
+	}
+}
+
+func TestCurrencyPricing(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	for id, price := range map[uint]money.Amount{1: 123450, 2: money.Units(20)} {
+		product, err := svc.Products.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		product.Price = price
+		if err := svc.Products.Update(ctx, services.Actor{}, id, product); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eur, _ := money.ParseRate("0.05")
+	for _, currency := range []models.Currency{
+		{Code: "mxn", Name: "Peso mexicano", Symbol: "$", Locale: "es-MX", Decimals: 2, Base: true},
+		{Code: "EUR", Name: "Euro", Symbol: "€", Locale: "es-ES", Decimals: 2, Rate: eur, Active: true},
+		{Code: "CLP", Name: "Peso chileno", Symbol: "$", Locale: "es-CL", Rate: 45 * money.One, Active: true},
+		{Code: "USD", Name: "Dólar", Symbol: "$", Locale: "en-US", Decimals: 2, Rate: eur, Active: false},
+	} {
+		if err := svc.Currencies.Create(ctx, services.Actor{}, &currency); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.Currencies.SetPrices(ctx, services.Actor{}, 1, []models.ProductPrice{{Currency: "eur", Price: money.Units(60)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	formatted := func(path string) []string {
+		t.Helper()
+		var listing struct {
+			Data []models.Product `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(mustGet(t, app, path)), &listing); err != nil {
+			t.Fatal(err)
+		}
+		var prices []string
+		for _, product := range listing.Data {
+			prices = append(prices, product.Name+"="+product.Pricing.Formatted)
+		}
+		return prices
+	}
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/api/products?category=fotografia&sort=name", "Boda=$20.00|Retrato=$1,234.50"},
+		// Conversión redondeada a los decimales de la moneda y precio fijado a mano
+		{"/api/products?category=fotografia&sort=name&currency=clp", "Boda=$900|Retrato=$55.553"},
+		{"/api/products?category=fotografia&sort=name&currency=EUR", "Boda=1,00 €|Retrato=60,00 €"},
+		// min_price en la moneda pedida: 50 EUR son 1000 MXN
+		{"/api/products?category=fotografia&currency=EUR&min_price=50", "Retrato=60,00 €"},
+	}
+	for _, c := range cases {
+		if got := strings.Join(formatted(c.path), "|"); got != c.want {
+			t.Errorf("%s = %q, se esperaba %q", c.path, got, c.want)
+		}
+	}
+
+	var detail services.ProductDetail
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products/retrato?currency=EUR")), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.Price != 123450 || detail.Pricing.Currency != "EUR" || detail.Pricing.Amount != money.Units(60) || detail.Related[0].Pricing.Formatted != "1,00 €" {
+		t.Errorf("ficha = %v %+v", detail.Price, detail.Pricing)
+	}
+	for _, path := range []string{"/api/products?currency=USD", "/api/products/retrato?currency=XYZ", "/api/search?q=boda&currency=usd"} {
+		if status, _ := get(t, app, path); status != 400 {
+			t.Errorf("%s = %d, se esperaba 400", path, status)
+		}
+	}
+
+	// Las páginas formatean en la moneda pedida y, si no está disponible, en la base
+	if _, page := get(t, app, "/productos/fotografia/boda?currency=CLP"); !strings.HasSuffix(page, "|$900") {
+		t.Errorf("página = %q", page)
+	}
+	if _, page := get(t, app, "/productos/fotografia/boda?currency=USD"); !strings.HasSuffix(page, "|$20.00") {
+		t.Errorf("página = %q", page)
+	}
+}
+
+func TestStockAvailability(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	// Boda lleva control de existencias y no tiene ninguna
+	product, err := svc.Products.Get(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product.Price, product.StockPolicy = money.Units(900), models.StockTracked
+	if err := svc.Products.Update(ctx, services.Actor{}, 2, product); err != nil {
+		t.Fatal(err)
+	}
+
+	var listing struct {
+		Data []models.Product `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products?category=fotografia&sort=name")), &listing); err != nil {
+		t.Fatal(err)
+	}
+	if len(listing.Data) != 2 || listing.Data[0].StockStatus != models.StockStatusOut || listing.Data[0].Availability() != "Agotado" {
+		t.Fatalf("productos = %+v", listing.Data)
+	}
+	if got := strings.Join(names(t, mustGet(t, app, "/api/products?stock_status=out_of_stock,backorder")), ","); got != "Boda" {
+		t.Errorf("agotados = %q", got)
+	}
+	etag := conditionalGet(t, app, "/api/products", nil).Header.Get("ETag")
+
+	// Con hide_out_of_stock los agotados desaparecen del catálogo, pero su ficha sigue
+	if err := svc.Config.Set(ctx, services.Actor{}, &models.SiteConfig{Key: services.HideOutOfStockKey, Value: "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if resp := conditionalGet(t, app, "/api/products", map[string]string{"If-None-Match": etag}); resp.StatusCode != 200 {
+		t.Errorf("listado tras ocultar agotados = %d, se esperaba 200", resp.StatusCode)
+	}
+	if got := strings.Join(names(t, mustGet(t, app, "/api/products?category=fotografia")), ","); got != "Retrato" {
+		t.Errorf("productos visibles = %q", got)
+	}
+	if got := strings.Join(names(t, mustGet(t, app, "/api/search?q=boda")), ","); got != "" {
+		t.Errorf("búsqueda = %q", got)
+	}
+	if _, page := get(t, app, "/productos/fotografia"); !strings.HasSuffix(page, "|product:Retrato;") {
+		t.Errorf("categoría = %q", page)
+	}
+	if status, _ := get(t, app, "/api/products/boda"); status != 200 {
+		t.Errorf("ficha de un agotado = %d", status)
+	}
+	if _, page := get(t, app, "/productos/fotografia/retrato"); strings.Contains(page, "related:Boda") {
+		t.Errorf("relacionados con agotados = %q", page)
+	}
+}
+
+func TestCategoryTree(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	// Cámaras > Mirrorless > Full frame, con un producto en la última
+	cameras := models.Category{Name: "Cámaras", Order: 3, Active: true}
+	if err := svc.Categories.Create(ctx, services.Actor{}, &cameras); err != nil {
+		t.Fatal(err)
+	}
+	mirrorless := models.Category{Name: "Mirrorless", ParentID: &cameras.ID, Active: true}
+	if err := svc.Categories.Create(ctx, services.Actor{}, &mirrorless); err != nil {
+		t.Fatal(err)
+	}
+	fullFrame := models.Category{Name: "Full frame", ParentID: &mirrorless.ID, Active: true}
+	if err := svc.Categories.Create(ctx, services.Actor{}, &fullFrame); err != nil {
+		t.Fatal(err)
+	}
+	if fullFrame.SlugPath != "camaras/mirrorless/full-frame" || fullFrame.Depth != 2 || len(fullFrame.PathIDs()) != 3 {
+		t.Fatalf("full frame = %+v", fullFrame)
+	}
+	alpha := models.Product{CategoryID: fullFrame.ID, Name: "Alfa", Price: money.Units(1000), Active: true}
+	if err := svc.Products.Create(ctx, services.Actor{}, &alpha); err != nil {
+		t.Fatal(err)
+	}
+
+	var tree []models.Category
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/categories/tree")), &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 || tree[1].Name != "Cámaras" || len(tree[1].Children) != 1 || tree[1].Children[0].Children[0].Name != "Full frame" {
+		t.Fatalf("árbol = %+v", tree)
+	}
+
+	// Los listados de una categoría incluyen los productos de sus subcategorías
+	for _, path := range []string{"/api/products?category=camaras", "/api/products?category_id=" + strconv.Itoa(int(mirrorless.ID))} {
+		if got := strings.Join(names(t, mustGet(t, app, path)), ","); got != "Alfa" {
+			t.Errorf("%s = %q", path, got)
+		}
+	}
+	if status, page := get(t, app, "/productos/camaras/mirrorless"); status != 200 || page != "crumb:Cámaras;sub:Full frame;Mirrorless|product:Alfa;" {
+		t.Errorf("página de la subcategoría = %d %q", status, page)
+	}
+
+	var detail services.ProductDetail
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products/alfa")), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.URL != "/productos/camaras/mirrorless/full-frame/alfa" || len(detail.Breadcrumbs) != 3 || detail.Breadcrumbs[1].URL != "/productos/camaras/mirrorless" {
+		t.Errorf("ficha = %s %+v", detail.URL, detail.Breadcrumbs)
+	}
+	if status, _ := get(t, app, detail.URL); status != 200 {
+		t.Errorf("%s = %d", detail.URL, status)
+	}
+
+	// Mover una categoría reescribe las rutas de su subárbol; las antiguas redirigen
+	mirrorless.ParentID = &tree[0].ID
+	if err := svc.Categories.Update(ctx, services.Actor{}, mirrorless.ID, &mirrorless); err != nil {
+		t.Fatal(err)
+	}
+	redirects := map[string]string{
+		"/productos/mirrorless":                         "/productos/fotografia/mirrorless",
+		"/productos/camaras/mirrorless/full-frame":      "/productos/fotografia/mirrorless/full-frame",
+		"/productos/camaras/mirrorless/full-frame/alfa": "/productos/fotografia/mirrorless/full-frame/alfa",
+	}
+	for from, to := range redirects {
+		resp := conditionalGet(t, app, from, nil)
+		if resp.StatusCode != 301 || resp.Header.Get("Location") != to {
+			t.Errorf("%s = %d %s, se esperaba 301 a %s", from, resp.StatusCode, resp.Header.Get("Location"), to)
+		}
+	}
+
+	// Ciclos y borrado de categorías con subcategorías
+	var validation *utils.ValidationError
+	for _, parent := range []uint{mirrorless.ID, fullFrame.ID} {
+		mirrorless.ParentID = &parent
+		if err := svc.Categories.Update(ctx, services.Actor{}, mirrorless.ID, &mirrorless); !errors.As(err, &validation) {
+			t.Errorf("padre %d: err = %v, se esperaba un error de validación", parent, err)
+		}
+	}
+	if err := svc.Categories.Delete(ctx, services.Actor{}, mirrorless.ID); !errors.As(err, &validation) {
+		t.Errorf("borrar con subcategorías: err = %v", err)
+	}
+
+	// Una categoría inactiva oculta sus subcategorías y sus productos
+	current, err := svc.Categories.Get(ctx, mirrorless.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.Active = false
+	if err := svc.Categories.Update(ctx, services.Actor{}, current.ID, current); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/productos/fotografia/mirrorless/full-frame", "/productos/fotografia/mirrorless/full-frame/alfa"} {
+		if status, _ := get(t, app, path); status != 404 {
+			t.Errorf("%s = %d, se esperaba 404", path, status)
+		}
+	}
+	tree = nil
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/categories/tree")), &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 || len(tree[0].Children) != 0 || len(tree[1].Children) != 0 {
+		t.Errorf("árbol con una categoría inactiva = %+v", tree)
+	}
+}
+
+func TestTagsAndCollections(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+
+	// Las etiquetas se crean por nombre y se reutilizan sin distinguir mayúsculas
+	tagged := map[uint][]models.Tag{
+		1: {{Name: "Oferta"}},
+		2: {{Name: "oferta"}, {Name: "Nuevo"}},
+	}
+	prices := map[uint]money.Amount{1: money.Units(50), 2: money.Units(900)}
+	for id := uint(1); id <= 2; id++ {
+		tags := tagged[id]
+		product, err := svc.Products.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		product.Price, product.Tags = prices[id], tags
+		if err := svc.Products.Update(ctx, services.Actor{}, id, product); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tags, err := svc.Tags.List(ctx)
+	if err != nil || len(tags) != 2 || tags[0].Slug != "nuevo" || tags[1].Name != "Oferta" {
+		t.Fatalf("etiquetas = %+v, %v", tags, err)
+	}
+	if got := strings.Join(names(t, mustGet(t, app, "/api/products?tag=oferta&sort=name")), ","); got != "Boda,Retrato" {
+		t.Errorf("?tag=oferta = %q", got)
+	}
+	var detail services.ProductDetail
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products/boda")), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Tags) != 2 || detail.Tags[0].Name != "Nuevo" {
+		t.Errorf("etiquetas de la ficha = %+v", detail.Tags)
+	}
+
+	// Sin Tags en la actualización se conservan las guardadas
+	product, err := svc.Products.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product.Tags = nil
+	if err := svc.Products.Update(ctx, services.Actor{}, 1, product); err != nil || len(product.Tags) != 1 {
+		t.Errorf("actualizar sin etiquetas = %+v, %v", product.Tags, err)
+	}
+
+	// Manual: los productos elegidos en su orden, sin los inactivos
+	featured := models.Collection{Name: "Destacados", Active: true}
+	if err := svc.Collections.Create(ctx, services.Actor{}, &featured); err != nil {
+		t.Fatal(err)
+	}
+	if featured.Slug != "destacados" || featured.Type != models.CollectionManual {
+		t.Fatalf("colección = %+v", featured)
+	}
+	if err := svc.Collections.SetProducts(ctx, services.Actor{}, featured.ID, []uint{2, 3, 1}); err != nil {
+		t.Fatal(err)
+	}
+	var collection models.Collection
+	if err := json.Unmarshal([]byte(mustGet(t, app, "/api/collections/destacados")), &collection); err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.Products) != 2 || collection.Products[0].Name != "Boda" || collection.Products[1].Name != "Retrato" {
+		t.Errorf("productos = %+v", collection.Products)
+	}
+	if status, page := get(t, app, "/colecciones/destacados"); status != 200 || page != "Destacados|https://example.com/colecciones/destacados|product:Boda;product:Retrato;" {
+		t.Errorf("página = %d %q", status, page)
+	}
+
+	var validation *utils.ValidationError
+	for _, ids := range [][]uint{{1, 1}, {99}} {
+		if err := svc.Collections.SetProducts(ctx, services.Actor{}, featured.ID, ids); !errors.As(err, &validation) {
+			t.Errorf("SetProducts(%v): err = %v, se esperaba un error de validación", ids, err)
+		}
+	}
+
+	// Smart: los que cumplen las reglas, en su orden y hasta MaxProducts
+	minPrice := money.Units(100)
+	premium := models.Collection{Name: "Premium", Type: models.CollectionSmart, Rules: &models.CollectionRules{MinPrice: &minPrice}, Active: true}
+	recent := models.Collection{Name: "Nuevos", Type: models.CollectionSmart, Rules: &models.CollectionRules{CategoryID: 1, TagID: tags[1].ID, NewerThanDays: 30}, Sort: "name", MaxProducts: 1, Active: true}
+	for _, smart := range []*models.Collection{&premium, &recent} {
+		if err := svc.Collections.Create(ctx, services.Actor{}, smart); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, want := range map[string]string{"/colecciones/premium": "product:Boda;", "/colecciones/nuevos": "product:Boda;"} {
+		if _, page := get(t, app, path); !strings.HasSuffix(page, "|"+want) {
+			t.Errorf("%s = %q", path, page)
+		}
+	}
+	if err := svc.Collections.SetProducts(ctx, services.Actor{}, premium.ID, []uint{1}); !errors.As(err, &validation) {
+		t.Errorf("SetProducts de una smart: err = %v", err)
+	}
+	invalid := []models.Collection{
+		{Name: "Con reglas", Rules: &models.CollectionRules{InStock: true}},
+		{Name: "Sin reglas", Type: models.CollectionSmart},
+		{Name: "Etiqueta", Type: models.CollectionSmart, Rules: &models.CollectionRules{TagID: 99}},
+	}
+	for _, collection := range invalid {
+		if err := svc.Collections.Create(ctx, services.Actor{}, &collection); !errors.As(err, &validation) {
+			t.Errorf("%s: err = %v, se esperaba un error de validación", collection.Name, err)
+		}
+	}
+
+	// Lo que usan las reglas no se puede borrar
+	if err := svc.Tags.Delete(ctx, services.Actor{}, tags[1].ID); !errors.As(err, &validation) {
+		t.Errorf("borrar una etiqueta de las reglas: err = %v", err)
+	}
+	if err := svc.Tags.Delete(ctx, services.Actor{}, tags[0].ID); err != nil {
+		t.Errorf("borrar una etiqueta sin reglas: %v", err)
+	}
+
+	// Las colecciones inactivas no existen para el público
+	featured.Active = false
+	if err := svc.Collections.Update(ctx, services.Actor{}, featured.ID, &featured); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/collections/destacados", "/colecciones/destacados", "/api/collections/no-existe"} {
+		if status, _ := get(t, app, path); status != 404 {
+			t.Errorf("%s = %d, se esperaba 404", path, status)
+		}
+	}
+}
+
+func TestProductRelations(t *testing.T) {
+	app, svc := newTestApp(t)
+	ctx := context.Background()
+	productNames := func(products []models.Product) string {
+		var result []string
+		for _, product := range products {
+			result = append(result, product.Name)
+		}
+		return strings.Join(result, ",")
+	}
+	detail := func(slug string) services.ProductDetail {
+		t.Helper()
+		var detail services.ProductDetail
+		if err := json.Unmarshal([]byte(mustGet(t, app, "/api/products/"+slug)), &detail); err != nil {
+			t.Fatal(err)
+		}
+		return detail
+	}
+
+	video := models.Category{Name: "Vídeo", Active: true}
+	if err := svc.Categories.Create(ctx, services.Actor{}, &video); err != nil {
+		t.Fatal(err)
+	}
+	lens := models.Product{CategoryID: 1, Name: "Objetivo", Price: money.Units(200), Tags: []models.Tag{{Name: "Pro"}}, Active: true}
+	camera := models.Product{CategoryID: video.ID, Name: "Cámara", Price: money.Units(210), Tags: []models.Tag{{Name: "Pro"}}, Active: true}
+	for _, product := range []*models.Product{&lens, &camera} {
+		if err := svc.Products.Create(ctx, services.Actor{}, product); err != nil {
+			t.Fatal(err)
+		}
+	}
+	portrait, err := svc.Products.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	portrait.Price = money.Units(190)
+	if err := svc.Products.Update(ctx, services.Actor{}, portrait.ID, portrait); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sin relaciones: misma categoría, etiquetas en común y precio parecido
+	if got := productNames(detail("objetivo").Related); got != "Retrato,Boda,Cámara" {
+		t.Errorf("relacionados automáticos = %q", got)
+	}
+	etag := conditionalGet(t, app, "/api/products/objetivo", nil).Header.Get("ETag")
+
+	// Las elegidas en el CMS van primero y las demás no se repiten
+	relations := []models.ProductRelation{
+		{RelatedID: camera.ID, Type: models.RelationAccessory},
+		{RelatedID: 2, Type: models.RelationRelated},
+		{RelatedID: 4, Type: models.RelationUpgrade},
+	}
+	if err := svc.Relations.SetRelations(ctx, services.Actor{}, lens.ID, relations); err != nil {
+		t.Fatal(err)
+	}
+	if resp := conditionalGet(t, app, "/api/products/objetivo", map[string]string{"If-None-Match": etag}); resp.StatusCode != 200 {
+		t.Errorf("ficha tras cambiar relaciones = %d, se esperaba 200", resp.StatusCode)
+	}
+	got := detail("objetivo")
+	// Prueba es de una categoría inactiva
+	if productNames(got.Related) != "Boda,Retrato" || productNames(got.Accessories) != "Cámara" || len(got.Upgrades) != 0 || got.Alternatives == nil {
+		t.Errorf("relaciones = %q %q %q", productNames(got.Related), productNames(got.Accessories), productNames(got.Upgrades))
+	}
+	if _, page := get(t, app, "/productos/fotografia/objetivo"); !strings.Contains(page, "related:Boda;related:Retrato;accessory:Cámara;") {
+		t.Errorf("página = %q", page)
+	}
+
+	var validation *utils.ValidationError
+	invalid := [][]models.ProductRelation{
+		{{RelatedID: 3, Type: models.RelationRelated}},
+		{{RelatedID: lens.ID, Type: models.RelationRelated}},
+		{{RelatedID: 99, Type: models.RelationRelated}},
+		{{RelatedID: 2, Type: models.RelationRelated}, {RelatedID: 2, Type: models.RelationUpgrade}},
+		{{RelatedID: 2, Type: "similar"}},
+	}
+	for _, relations := range invalid {
+		if err := svc.Relations.SetRelations(ctx, services.Actor{}, lens.ID, relations); !errors.As(err, &validation) {
+			t.Errorf("SetRelations(%+v): err = %v, se esperaba un error de validación", relations, err)
+		}
+	}
+
+	// Un producto desactivado desaparece de las relaciones de los demás
+	camera.Active = false
+	if err := svc.Products.Update(ctx, services.Actor{}, camera.ID, &camera); err != nil {
+		t.Fatal(err)
+	}
+	if got := detail("objetivo"); len(got.Accessories) != 0 {
+		t.Errorf("accesorios tras desactivar = %q", productNames(got.Accessories))
+	}
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DatabaseCheck verifica la conexión con la base de datos (PING)
+// Parámetros:
+//   - db: Pool de conexiones subyacente de GORM
+func DatabaseCheck(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// TemplateLoader motor de plantillas que puede (re)cargarlas; html.Engine lo implementa
+type TemplateLoader interface {
+	Load() error
+}
+
+// TemplatesCheck verifica que las plantillas se cargaron sin errores
+// Parámetros:
+//   - engine: Motor de plantillas (Load no hace nada si ya están cargadas)
+func TemplatesCheck(engine TemplateLoader) Check {
+	return func(ctx context.Context) error {
+		if err := engine.Load(); err != nil {
+			return fmt.Errorf("error al cargar plantillas: %w", err)
+		}
+		return nil
+	}
+}
+
+// TLSCertificateCheck verifica que el certificado se puede cargar y está vigente
+// Parámetros:
+//   - certFile: Ruta del certificado PEM
+//   - keyFile: Ruta de la clave privada PEM
+func TLSCertificateCheck(certFile, keyFile string) Check {
+	return func(ctx context.Context) error {
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("certificado TLS inválido: %w", err)
+		}
+		if len(pair.Certificate) == 0 {
+			return errors.New("certificado TLS vacío")
+		}
+
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("certificado TLS inválido: %w", err)
+		}
+
+		now := time.Now()
+		if now.Before(leaf.NotBefore) {
+			return fmt.Errorf("el certificado TLS no es válido hasta %s", leaf.NotBefore.Format(time.RFC3339))
+		}
+		if now.After(leaf.NotAfter) {
+			return fmt.Errorf("el certificado TLS expiró el %s", leaf.NotAfter.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
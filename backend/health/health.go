@@ -0,0 +1,197 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Estados del proceso para la readiness
+const (
+	StateStarting     int32 = iota // Arrancando: /readyz devuelve 503
+	StateReady                     // Listo: /readyz ejecuta los checks
+	StateShuttingDown              // Apagando: /readyz devuelve 503
+)
+
+// DefaultTimeout tiempo máximo por defecto de cada check
+const DefaultTimeout = 2 * time.Second
+
+// Check verifica una dependencia; devuelve error si no está disponible
+type Check func(ctx context.Context) error
+
+// namedCheck check registrado con su nombre y timeout
+type namedCheck struct {
+	name    string
+	check   Check
+	timeout time.Duration
+}
+
+// CheckResult resultado de un check individual
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report respuesta JSON de /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Registry registro de checks de readiness y estado del proceso
+type Registry struct {
+	mutex  sync.RWMutex
+	checks []namedCheck
+	state  atomic.Int32
+}
+
+// NewRegistry crea un registro vacío en estado "starting"
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register añade un check con el timeout por defecto
+// Parámetros:
+//   - name: Nombre del check en el JSON de respuesta
+//   - check: Función que verifica la dependencia
+func (r *Registry) Register(name string, check Check) {
+	r.RegisterWithTimeout(name, check, DefaultTimeout)
+}
+
+// RegisterWithTimeout añade un check con un timeout propio
+// Parámetros:
+//   - name: Nombre del check en el JSON de respuesta
+//   - check: Función que verifica la dependencia
+//   - timeout: Tiempo máximo antes de darlo por fallido
+func (r *Registry) RegisterWithTimeout(name string, check Check, timeout time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check, timeout: timeout})
+}
+
+// SetReady marca el proceso como listo para recibir tráfico
+func (r *Registry) SetReady() {
+	r.state.Store(StateReady)
+}
+
+// SetShuttingDown marca el proceso como en apagado; /readyz pasa a devolver 503
+func (r *Registry) SetShuttingDown() {
+	r.state.Store(StateShuttingDown)
+}
+
+// State devuelve el estado actual del proceso
+func (r *Registry) State() int32 {
+	return r.state.Load()
+}
+
+// Run ejecuta todos los checks en paralelo
+// Parámetros:
+//   - ctx: Contexto de la petición
+//
+// Retorna: Informe con el resultado de cada check
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mutex.RLock()
+	checks := make([]namedCheck, len(r.checks))
+	copy(checks, r.checks)
+	r.mutex.RUnlock()
+
+	report := Report{Status: "ok", Checks: make(map[string]CheckResult, len(checks))}
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, nc namedCheck) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, nc)
+		}(i, nc)
+	}
+	wg.Wait()
+
+	for i, nc := range checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != "ok" {
+			report.Status = "fail"
+		}
+	}
+
+	return report
+}
+
+// runCheck ejecuta un check respetando su timeout
+func runCheck(ctx context.Context, nc namedCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, nc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- nc.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: "ok", DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ========================================
+// HANDLERS
+// ========================================
+
+// LivenessHandler responde 200 mientras el proceso esté vivo (/healthz)
+func LivenessHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(fiber.Map{"status": "ok"})
+	}
+}
+
+// ReadinessHandler responde 200 solo si el proceso está listo y todos los checks pasan (/readyz)
+// Parámetros:
+//   - registry: Registro de checks y estado del proceso
+//
+// Retorna: Handler que devuelve 503 al arrancar, al apagar o si falla algún check
+func ReadinessHandler(registry *Registry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+
+		switch registry.State() {
+		case StateStarting:
+			return c.Status(fiber.StatusServiceUnavailable).JSON(Report{Status: "starting"})
+		case StateShuttingDown:
+			return c.Status(fiber.StatusServiceUnavailable).JSON(Report{Status: "shutting_down"})
+		}
+
+		report := registry.Run(c.UserContext())
+		if report.Status != "ok" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+		}
+		return c.JSON(report)
+	}
+}
+
+// Mount registra /healthz y /readyz en la aplicación y marca el registro
+// como listo cuando el servidor empieza a escuchar
+func Mount(app *fiber.App, registry *Registry) {
+	app.Get("/healthz", LivenessHandler())
+	app.Get("/readyz", ReadinessHandler(registry))
+
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		registry.SetReady()
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func readyz(t *testing.T, app *fiber.App) (int, Report) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("respuesta no es JSON: %v", err)
+	}
+	return resp.StatusCode, report
+}
+
+func TestReadinessFollowsLifecycleAndChecks(t *testing.T) {
+	registry := NewRegistry()
+	failing := false
+	registry.Register("database", func(ctx context.Context) error {
+		if failing {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	registry.RegisterWithTimeout("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}, 10*time.Millisecond)
+
+	app := fiber.New()
+	Mount(app, registry)
+
+	if status, report := readyz(t, app); status != 503 || report.Status != "starting" {
+		t.Fatalf("al arrancar = %d %q, se esperaba 503 starting", status, report.Status)
+	}
+
+	registry.SetReady()
+	status, report := readyz(t, app)
+	if status != 503 || report.Checks["slow"].Status != "fail" || report.Checks["database"].Status != "ok" {
+		t.Fatalf("check lento no expiró: %d %+v", status, report)
+	}
+
+	failing = true
+	if _, report := readyz(t, app); report.Checks["database"].Error != "connection refused" {
+		t.Fatalf("error del check no incluido: %+v", report)
+	}
+
+	registry.SetShuttingDown()
+	if status, report := readyz(t, app); status != 503 || report.Status != "shutting_down" {
+		t.Fatalf("al apagar = %d %q, se esperaba 503 shutting_down", status, report.Status)
+	}
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("/healthz = %d, se esperaba 200", resp.StatusCode)
+	}
+}
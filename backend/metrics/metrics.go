@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace prefijo común de todas las métricas de la aplicación
+const Namespace = "website"
+
+// Registry registro de Prometheus compartido por el backend y el CMS
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests cuenta las peticiones HTTP por ruta, método y estado
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "Número de peticiones HTTP procesadas.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPDuration mide la latencia de las peticiones HTTP
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latencia de las peticiones HTTP en segundos.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// RateLimitRejections cuenta las peticiones rechazadas por cada rate limiter
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Peticiones rechazadas por rate limiting.",
+	}, []string{"limiter"})
+
+	// DBQueryDuration mide la duración de las consultas de GORM
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duración de las consultas a la base de datos en segundos.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// TemplateRenderDuration mide el tiempo de renderizado de plantillas
+	TemplateRenderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "template_render_duration_seconds",
+		Help:      "Tiempo de renderizado de plantillas HTML en segundos.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+	}, []string{"template"})
+)
+
+func init() {
+	Registry.MustRegister(
+		HTTPRequests,
+		HTTPDuration,
+		RateLimitRejections,
+		DBQueryDuration,
+		TemplateRenderDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDB expone las estadísticas del pool de conexiones (sqlDB.Stats())
+// Parámetros:
+//   - db: Pool de conexiones subyacente de GORM
+//   - name: Nombre de la base de datos para la etiqueta db_name
+//
+// Retorna: Error si ya había un pool registrado con ese nombre
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
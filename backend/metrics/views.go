@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InstrumentedViews envuelve un motor de plantillas de Fiber y mide el tiempo de renderizado
+type InstrumentedViews struct {
+	fiber.Views
+}
+
+// InstrumentViews crea un motor de plantillas instrumentado
+// Parámetros:
+//   - views: Motor de plantillas original (por ejemplo html.New)
+//
+// Retorna: Motor que registra TemplateRenderDuration en cada render
+func InstrumentViews(views fiber.Views) *InstrumentedViews {
+	return &InstrumentedViews{Views: views}
+}
+
+// Render renderiza la plantilla y observa su duración
+func (v *InstrumentedViews) Render(w io.Writer, name string, binding interface{}, layout ...string) error {
+	start := time.Now()
+	err := v.Views.Render(w, name, binding, layout...)
+	TemplateRenderDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	return err
+}
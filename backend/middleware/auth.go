@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"os"
+	"context"
 	"strings"
 	"time"
 
@@ -380,14 +380,87 @@ func Auth() fiber.Handler {
 	return AuthRequired()
 }
 
+// UserLoader carga el usuario de un token (repositorio o servicio de usuarios)
+type UserLoader interface {
+	Get(ctx context.Context, id uint) (*models.User, error)
+}
+
+// AuthMiddleware autentica con el secreto JWT configurado y carga el usuario actual
+// A diferencia de AuthWithConfig, rechaza tokens de usuarios eliminados o inactivos
+// Parámetros:
+//   - users: Origen de los usuarios
+//
+// Retorna: Middleware de autenticación para el CMS
+func AuthMiddleware(users UserLoader) fiber.Handler {
+	config := AuthConfigs.Required
+	config.SecretKey = settings.JWT.Secret
+
+	return func(c *fiber.Ctx) error {
+		token := extractToken(c, config)
+		if token == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "token de autenticación requerido",
+				"code":  "AUTH_TOKEN_REQUIRED",
+			})
+		}
+
+		claims, err := validateToken(token, config.SecretKey)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "token de autenticación inválido",
+				"code":    "AUTH_TOKEN_INVALID",
+				"details": err.Error(),
+			})
+		}
+
+		// Cargar el usuario para usar su rol y estado actuales (user_id llega como número JSON)
+		userID, ok := claims["user_id"].(float64)
+		if !ok || userID <= 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "token de autenticación inválido",
+				"code":  "AUTH_TOKEN_INVALID",
+			})
+		}
+		user, err := users.Get(c.UserContext(), uint(userID))
+		if err != nil || !user.Active {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "usuario no encontrado o inactivo",
+				"code":  "AUTH_USER_INACTIVE",
+			})
+		}
+
+		// Almacenar información del usuario en el contexto
+		c.Locals("user", claims)
+		c.Locals("currentUser", user)
+		c.Locals("userID", user.ID)
+		c.Locals("username", user.Username)
+		c.Locals("role", user.Role)
+		c.Locals("userRole", user.Role)
+
+		return c.Next()
+	}
+}
+
+// GetCurrentUser obtiene el usuario cargado por AuthMiddleware
+// Parámetros:
+//   - c: Contexto de Fiber
+//
+// Retorna: Usuario autenticado o nil si no está autenticado
+func GetCurrentUser(c *fiber.Ctx) *models.User {
+	if user, ok := c.Locals("currentUser").(*models.User); ok {
+		return user
+	}
+	return nil
+}
+
 // GenerateToken genera un token JWT para un usuario
 // Parámetros:
 //   - user: Usuario para el cual generar el token
 //
 // Retorna: Token JWT firmado y error si ocurre alguno
 func GenerateToken(user models.User) (string, error) {
-	// Configurar tiempo de expiración (24 horas)
-	expirationTime := time.Now().Add(24 * time.Hour)
+	// Configurar tiempo de expiración (JWT_EXPIRATION, 24 horas por defecto)
+	expirationTime := time.Now().Add(settings.JWT.Expiration)
 
 	// Crear las reclamaciones del token usando MapClaims
 	claims := jwt.MapClaims{
@@ -403,7 +476,7 @@ func GenerateToken(user models.User) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Firmar el token con la clave secreta
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return token.SignedString([]byte(settings.JWT.Secret))
 }
 
 // HashPassword hashea una contraseña usando bcrypt
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"website/backend/config"
+)
+
+// settings configuración aplicada con Configure (valores por defecto hasta entonces)
+var settings = config.Defaults()
+
+// Configure aplica la configuración de la aplicación a los middlewares
+// Debe llamarse al arrancar, antes de crear los middlewares: actualiza el secreto
+// de los presets de AuthConfigs, los límites de RateLimitConfigs y los valores
+// que usan las funciones Default*Config
+// Parámetros:
+//   - cfg: Configuración cargada y validada
+func Configure(cfg *config.Config) {
+	settings = cfg
+
+	for _, preset := range []*AuthConfig{
+		&AuthConfigs.Required,
+		&AuthConfigs.Optional,
+		&AuthConfigs.Admin,
+		&AuthConfigs.Editor,
+		&AuthConfigs.User,
+	} {
+		preset.SecretKey = cfg.JWT.Secret
+	}
+
+	for preset, limit := range map[*RateLimitConfig]config.RateLimit{
+		&RateLimitConfigs.Strict:   cfg.RateLimit.Strict,
+		&RateLimitConfigs.Moderate: cfg.RateLimit.Moderate,
+		&RateLimitConfigs.Relaxed:  cfg.RateLimit.Relaxed,
+		&RateLimitConfigs.API:      cfg.RateLimit.API,
+		&RateLimitConfigs.Auth:     cfg.RateLimit.Auth,
+	} {
+		preset.MaxRequests = limit.Max
+		preset.Window = limit.Window
+	}
+}
+
+// DefaultLogConfig configuración de logs según el entorno (Production o Development)
+// con el nivel y las salidas de la configuración
+func DefaultLogConfig() LogConfig {
+	logConfig := LogConfigs.Development
+	if settings.IsProduction() {
+		logConfig = LogConfigs.Production
+	}
+	if settings.Log.Level != "" {
+		logConfig.Level = settings.Log.Level
+	}
+	if settings.Log.Output != "" {
+		logConfig.Output = settings.Log.Output
+	}
+	return logConfig
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"website/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorHandler respuesta JSON común para los errores devueltos por los handlers
+//   - *fiber.Error: su código y mensaje
+//   - *utils.ValidationError: 400 con la lista de problemas en "details"
+//   - cualquier otro: 500 sin exponer el detalle interno
+//
+// Parámetros:
+//   - c: Contexto de Fiber
+//   - err: Error devuelto por el handler
+//
+// Retorna: Error al escribir la respuesta
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	response := fiber.Map{"request_id": GetRequestIDFromContext(c)}
+	code := fiber.StatusInternalServerError
+
+	var fiberErr *fiber.Error
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &fiberErr):
+		code = fiberErr.Code
+		response["error"] = fiberErr.Message
+	case errors.As(err, &validationErr):
+		code = fiber.StatusBadRequest
+		response["error"] = validationErr.Message
+		response["details"] = validationErr.Details
+	default:
+		response["error"] = "Error interno del servidor"
+	}
+
+	return c.Status(code).JSON(response)
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger adapta el logger estructurado de la aplicación a la interfaz de GORM
+// Cada consulta se registra con el request ID tomado del contexto (db.WithContext)
+type GormLogger struct {
+	logger        *slog.Logger        // Logger de destino
+	level         gormlogger.LogLevel // Nivel de log de GORM
+	slowThreshold time.Duration       // Umbral para marcar consultas lentas
+}
+
+// NewGormLogger crea un logger de GORM que escribe en un logger slog
+// Parámetros:
+//   - logger: Logger estructurado de destino
+//   - level: Nivel de log de GORM (Silent, Error, Warn, Info)
+//
+// Retorna: Logger compatible con gorm.Config
+func NewGormLogger(logger *slog.Logger, level gormlogger.LogLevel) gormlogger.Interface {
+	return &GormLogger{
+		logger:        logger,
+		level:         level,
+		slowThreshold: 200 * time.Millisecond,
+	}
+}
+
+// LogMode devuelve una copia del logger con otro nivel
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info registra mensajes informativos de GORM
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...), "request_id", RequestIDFromContext(ctx))
+	}
+}
+
+// Warn registra advertencias de GORM
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...), "request_id", RequestIDFromContext(ctx))
+	}
+}
+
+// Error registra errores de GORM
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...), "request_id", RequestIDFromContext(ctx))
+	}
+}
+
+// Trace registra cada consulta SQL con su duración y filas afectadas
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	var level slog.Level
+	message := "query"
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		level, message = slog.LevelError, "query failed"
+	case elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		level, message = slog.LevelWarn, "slow query"
+	case l.level >= gormlogger.Info:
+		level = slog.LevelInfo
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("request_id", RequestIDFromContext(ctx)),
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	attrs = append(attrs, traceAttrs(ctx)...)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	l.logger.LogAttrs(ctx, level, message, attrs...)
+}
@@ -0,0 +1,382 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RotationConfig configuración de rotación de archivos de log
+type RotationConfig struct {
+	MaxSize    int64         // Tamaño máximo en bytes antes de rotar (0 = sin límite)
+	MaxAge     time.Duration // Tiempo máximo de un archivo antes de rotar (0 = sin límite)
+	MaxBackups int           // Archivos rotados a conservar (0 = conservar todos)
+	Compress   bool          // Si comprimir con gzip los archivos rotados
+}
+
+// DefaultRotationConfig configuración de rotación por defecto: 100 MB o 24 horas, 7 copias comprimidas
+func DefaultRotationConfig() RotationConfig {
+	return RotationConfig{
+		MaxSize:    100 * 1024 * 1024,
+		MaxAge:     24 * time.Hour,
+		MaxBackups: 7,
+		Compress:   true,
+	}
+}
+
+// rotatedTimeFormat formato del sufijo de los archivos rotados (ordenable)
+const rotatedTimeFormat = "20060102T150405.000000"
+
+// RotatingFile es un sink de logs en archivo con rotación por tamaño y tiempo
+type RotatingFile struct {
+	path     string
+	config   RotationConfig
+	mutex    sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	pending  sync.WaitGroup // Compresiones y limpiezas en curso
+	now      func() time.Time
+
+	backups     sync.Mutex
+	compressing map[string]bool // Rotados aún sin comprimir (protegido por backups)
+}
+
+// openSinks registro de archivos abiertos para compartirlos y reabrirlos con SIGHUP
+var openSinks = struct {
+	sync.Mutex
+	files map[string]*RotatingFile
+}{files: make(map[string]*RotatingFile)}
+
+// OpenRotatingFile abre (o reutiliza) un sink de archivo con rotación
+// Parámetros:
+//   - path: Ruta del archivo de log
+//   - config: Configuración de rotación
+//
+// Retorna: Sink listo para escribir y error si no se pudo abrir
+func OpenRotatingFile(path string, config RotationConfig) (*RotatingFile, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	openSinks.Lock()
+	defer openSinks.Unlock()
+
+	// Varias configuraciones pueden apuntar al mismo archivo
+	if existing, ok := openSinks.files[absolute]; ok {
+		return existing, nil
+	}
+
+	rf := &RotatingFile{path: absolute, config: config, now: time.Now, compressing: make(map[string]bool)}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	openSinks.files[absolute] = rf
+
+	return rf, nil
+}
+
+// open abre el archivo en modo append creando el directorio si hace falta; si falla,
+// rf.file sigue siendo el anterior
+func (rf *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	rf.openedAt = rf.now()
+	return nil
+}
+
+// Write escribe en el archivo rotándolo antes si se superan los límites
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	// Si no se puede rotar se sigue escribiendo en el archivo actual
+	if rf.shouldRotate(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "error al rotar log %s: %v\n", rf.path, err)
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// shouldRotate verifica si la siguiente escritura supera el tamaño o la antigüedad
+func (rf *RotatingFile) shouldRotate(next int64) bool {
+	if rf.size == 0 {
+		return false
+	}
+	if rf.config.MaxSize > 0 && rf.size+next > rf.config.MaxSize {
+		return true
+	}
+	return rf.config.MaxAge > 0 && rf.now().Sub(rf.openedAt) >= rf.config.MaxAge
+}
+
+// Rotate fuerza la rotación del archivo actual
+func (rf *RotatingFile) Rotate() error {
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+	return rf.rotate()
+}
+
+// rotate renombra el archivo actual, abre uno nuevo y comprime en segundo plano
+// El archivo se renombra abierto y solo se cierra cuando el nuevo ya está abierto: ante
+// cualquier error se sigue escribiendo en el anterior
+func (rf *RotatingFile) rotate() error {
+	rotated := rf.path + "." + rf.now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(rf.path, rotated); err != nil {
+		return err
+	}
+
+	previous := rf.file
+	if err := rf.open(); err != nil {
+		return err
+	}
+	if err := previous.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "error al cerrar log rotado %s: %v\n", rotated, err)
+	}
+
+	if rf.config.Compress {
+		rf.setCompressing(rotated, true)
+	}
+	rf.pending.Add(1)
+	go func() {
+		defer rf.pending.Done()
+		if rf.config.Compress {
+			if err := compressFile(rotated); err != nil {
+				fmt.Fprintf(os.Stderr, "error al comprimir log rotado %s: %v\n", rotated, err)
+			}
+			rf.setCompressing(rotated, false)
+		}
+		rf.removeOldBackups()
+	}()
+
+	return nil
+}
+
+// setCompressing marca o desmarca un archivo rotado como pendiente de comprimir
+func (rf *RotatingFile) setCompressing(path string, pending bool) {
+	rf.backups.Lock()
+	defer rf.backups.Unlock()
+	if pending {
+		rf.compressing[path] = true
+	} else {
+		delete(rf.compressing, path)
+	}
+}
+
+// Reopen vuelve a abrir la ruta (tras un logrotate externo) y cierra el archivo anterior;
+// si no se puede abrir se sigue escribiendo en el anterior
+func (rf *RotatingFile) Reopen() error {
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+
+	previous := rf.file
+	if err := rf.open(); err != nil {
+		return err
+	}
+	if previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
+
+// Close cierra el archivo y espera a que terminen las compresiones pendientes
+func (rf *RotatingFile) Close() error {
+	rf.mutex.Lock()
+	var err error
+	if rf.file != nil {
+		err = rf.file.Close()
+		rf.file = nil
+	}
+	rf.mutex.Unlock()
+
+	rf.pending.Wait()
+
+	openSinks.Lock()
+	delete(openSinks.files, rf.path)
+	openSinks.Unlock()
+
+	return err
+}
+
+// removeOldBackups elimina los archivos rotados que exceden MaxBackups
+// Los que aún se están comprimiendo no cuentan ni se borran (su .gz contará al terminar)
+func (rf *RotatingFile) removeOldBackups() {
+	if rf.config.MaxBackups <= 0 {
+		return
+	}
+
+	rf.backups.Lock()
+	defer rf.backups.Unlock()
+
+	matches, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		return
+	}
+
+	// Los sufijos de tiempo ordenan cronológicamente; ignorar temporales de compresión
+	var backups []string
+	for _, match := range matches {
+		if !strings.HasSuffix(match, ".tmp") && !rf.compressing[match] {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > rf.config.MaxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
+
+// compressFile comprime un archivo con gzip y elimina el original
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	temporary := path + ".gz.tmp"
+	target, err := os.OpenFile(temporary, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	if _, err := io.Copy(writer, source); err != nil {
+		target.Close()
+		os.Remove(temporary)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		target.Close()
+		os.Remove(temporary)
+		return err
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(temporary)
+		return err
+	}
+
+	if err := os.Rename(temporary, path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// ========================================
+// REAPERTURA CON SIGHUP
+// ========================================
+
+// ReopenLogFiles reabre todos los sinks de archivo abiertos
+// Retorna: Primer error encontrado, si hubo alguno
+func ReopenLogFiles() error {
+	openSinks.Lock()
+	files := make([]*RotatingFile, 0, len(openSinks.files))
+	for _, rf := range openSinks.files {
+		files = append(files, rf)
+	}
+	openSinks.Unlock()
+
+	var firstErr error
+	for _, rf := range files {
+		if err := rf.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ReopenLogFilesOnSIGHUP reabre los sinks de archivo cada vez que llega SIGHUP
+// Permite usar logrotate externo con la opción "create" en lugar de "copytruncate"
+// Retorna: Función para dejar de escuchar la señal
+func ReopenLogFilesOnSIGHUP() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := ReopenLogFiles(); err != nil {
+					fmt.Fprintf(os.Stderr, "error al reabrir archivos de log: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// ========================================
+// SALIDAS MÚLTIPLES
+// ========================================
+
+// multiSink escribe en todos los sinks aunque alguno falle
+type multiSink []io.Writer
+
+// Write escribe en cada sink y devuelve el primer error
+func (m multiSink) Write(p []byte) (int, error) {
+	var firstErr error
+	for _, w := range m {
+		if _, err := w.Write(p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return len(p), firstErr
+}
+
+// openLogSink abre una salida individual: stdout, stderr o una ruta de archivo
+func openLogSink(output string, rotation RotationConfig) io.Writer {
+	switch output {
+	case "", "stdout":
+		return os.Stdout
+	case "stderr":
+		return os.Stderr
+	default:
+		rf, err := OpenRotatingFile(output, rotation)
+		if err != nil {
+			// Si no se puede abrir el archivo, usar stderr
+			fmt.Fprintf(os.Stderr, "error al abrir archivo de log %s: %v\n", output, err)
+			return os.Stderr
+		}
+		return rf
+	}
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesBySizeAndCompresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	rf, err := OpenRotatingFile(path, RotationConfig{MaxSize: 32, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+
+	// Cada línea ocupa 20 bytes: rota en cada escritura a partir de la segunda
+	for i := 0; i < 5; i++ {
+		if _, err := rf.Write([]byte(strings.Repeat("x", 19) + "\n")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("se esperaban 2 copias rotadas, hay %d: %v", len(backups), backups)
+	}
+	for _, backup := range backups {
+		if !strings.HasSuffix(backup, ".gz") {
+			t.Errorf("copia sin comprimir: %s", backup)
+			continue
+		}
+		file, err := os.Open(backup)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("gzip inválido en %s: %v", backup, err)
+		}
+		content, _ := io.ReadAll(reader)
+		file.Close()
+		if len(content) != 20 {
+			t.Errorf("contenido inesperado en %s: %q", backup, content)
+		}
+	}
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf, err := OpenRotatingFile(path, RotationConfig{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time { return current }
+	rf.openedAt = current
+
+	rf.Write([]byte("primera\n"))
+	current = current.Add(2 * time.Hour)
+	rf.Write([]byte("segunda\n"))
+
+	if _, err := os.Stat(path + ".20240101T020000.000000"); err != nil {
+		t.Errorf("no se rotó por antigüedad: %v", err)
+	}
+}
+
+func TestRotatingFileKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf, err := OpenRotatingFile(path, RotationConfig{MaxSize: 16})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time { return current }
+
+	// Un directorio no vacío con el nombre de la copia hace fallar el rename
+	rotated := path + ".20240101T000000.000000"
+	if err := os.MkdirAll(filepath.Join(rotated, "ocupado"), 0o750); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	rf.Write([]byte("primera línea\n"))
+	if err := rf.Rotate(); err == nil {
+		t.Fatal("Rotate no devolvió el error del rename")
+	}
+	if _, err := rf.Write([]byte("segunda línea\n")); err != nil {
+		t.Fatalf("Write tras fallar la rotación: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "primera línea\nsegunda línea\n" {
+		t.Errorf("contenido = %q", content)
+	}
+
+	// Cuando el rename vuelve a funcionar, la rotación se completa
+	current = current.Add(time.Second)
+	rf.Write([]byte("tercera línea\n"))
+	if content, _ := os.ReadFile(path); string(content) != "tercera línea\n" {
+		t.Errorf("contenido tras rotar = %q", content)
+	}
+}
+
+func TestRemoveOldBackupsSkipsFilesBeingCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf, err := OpenRotatingFile(path, RotationConfig{MaxBackups: 1, Compress: true})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	for _, name := range []string{".1.gz", ".2.gz", ".3", ".3.gz.tmp"} {
+		if err := os.WriteFile(path+name, nil, 0o640); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	rf.setCompressing(path+".3", true)
+	rf.removeOldBackups()
+
+	backups, _ := filepath.Glob(path + ".*")
+	want := []string{path + ".2.gz", path + ".3", path + ".3.gz.tmp"}
+	if strings.Join(backups, "|") != strings.Join(want, "|") {
+		t.Errorf("copias = %v, se esperaban %v", backups, want)
+	}
+}
+
+func TestReopenLogFilesAfterExternalRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "security.log")
+	rf, err := OpenRotatingFile(path, RotationConfig{})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	rf.Write([]byte("antes\n"))
+
+	// Simular logrotate: mover el archivo y pedir reapertura
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if err := ReopenLogFiles(); err != nil {
+		t.Fatalf("ReopenLogFiles: %v", err)
+	}
+	rf.Write([]byte("después\n"))
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "después\n" {
+		t.Errorf("contenido tras reabrir = %q", content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o007 != 0 {
+		t.Errorf("el archivo de log es accesible para otros usuarios: %v", perm)
+	}
+}
+
+func TestLogOutputWritesToMultipleSinks(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.log")
+	second := filepath.Join(dir, "b.log")
+
+	logger := NewLogger(LogConfig{Format: "json", Output: first + ", " + second, Level: "info"})
+	logger.Info("hola")
+
+	for _, path := range []string{first, second} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", path, err)
+		}
+		if !strings.Contains(string(content), `"msg":"hola"`) {
+			t.Errorf("%s no recibió el log: %q", path, content)
+		}
+	}
+}
@@ -1,20 +1,21 @@
 package middleware
 
 import (
-	"fmt"
-	"os"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
 // LogConfig configuración para el sistema de logging
 type LogConfig struct {
-	Format     string // Formato del log (json, text, custom)
-	Output     string // Archivo de salida (stdout, stderr, archivo)
+	Format     string // Formato del log (json, text)
+	Output     string // Salidas separadas por comas (stdout, stderr, ruta de archivo)
+	Level      string // Nivel mínimo (debug, info, warn, error)
 	TimeFormat string // Formato de tiempo
 	TimeZone   string // Zona horaria
-	Colors     bool   // Si usar colores en la salida
 	IP         bool   // Si incluir IP del cliente
 	UserAgent  bool   // Si incluir User-Agent
 	Referer    bool   // Si incluir Referer
@@ -25,6 +26,9 @@ type LogConfig struct {
 	Query      bool   // Si incluir query parameters
 	Body       bool   // Si incluir body del request
 	Headers    bool   // Si incluir headers
+
+	Redaction *RedactionConfig // Reglas de redacción (nil usa DefaultRedactionConfig)
+	Rotation  *RotationConfig  // Rotación de archivos (nil usa DefaultRotationConfig)
 }
 
 // ========================================
@@ -39,13 +43,13 @@ var LogConfigs = struct {
 	Security    LogConfig // Para logs de seguridad
 	Minimal     LogConfig // Configuración mínima
 }{
-	// Configuración para desarrollo: logs detallados con colores
+	// Configuración para desarrollo: logs detallados
 	Development: LogConfig{
 		Format:     "json",
 		Output:     "stdout",
+		Level:      "debug",
 		TimeFormat: "2006-01-02 15:04:05",
 		TimeZone:   "Local",
-		Colors:     true,
 		IP:         true,
 		UserAgent:  true,
 		Referer:    true,
@@ -61,9 +65,9 @@ var LogConfigs = struct {
 	Production: LogConfig{
 		Format:     "json",
 		Output:     "stdout",
+		Level:      "info",
 		TimeFormat: "2006-01-02T15:04:05Z07:00",
 		TimeZone:   "UTC",
-		Colors:     false,
 		IP:         true,
 		UserAgent:  true,
 		Referer:    true,
@@ -79,9 +83,9 @@ var LogConfigs = struct {
 	API: LogConfig{
 		Format:     "json",
 		Output:     "stdout",
+		Level:      "info",
 		TimeFormat: "2006-01-02T15:04:05.000Z",
 		TimeZone:   "UTC",
-		Colors:     false,
 		IP:         true,
 		UserAgent:  true,
 		Referer:    false,
@@ -97,9 +101,9 @@ var LogConfigs = struct {
 	Security: LogConfig{
 		Format:     "json",
 		Output:     "security.log",
+		Level:      "info",
 		TimeFormat: "2006-01-02T15:04:05.000Z",
 		TimeZone:   "UTC",
-		Colors:     false,
 		IP:         true,
 		UserAgent:  true,
 		Referer:    true,
@@ -115,9 +119,9 @@ var LogConfigs = struct {
 	Minimal: LogConfig{
 		Format:     "text",
 		Output:     "stdout",
+		Level:      "info",
 		TimeFormat: "15:04:05",
 		TimeZone:   "Local",
-		Colors:     true,
 		IP:         false,
 		UserAgent:  false,
 		Referer:    false,
@@ -135,123 +139,172 @@ var LogConfigs = struct {
 // FUNCIONES DE CONFIGURACIÓN DE LOGGING
 // ========================================
 
-// LogWithConfig crea middleware de logging con configuración personalizada
+// NewLogger crea un logger estructurado (log/slog) a partir de la configuración
 // Parámetros:
-//   - config: Configuración de logging personalizada
+//   - config: Configuración de logging
 //
-// Retorna: Middleware de logging configurado
-func LogWithConfig(config LogConfig) fiber.Handler {
-	// Configurar opciones de logger
-	loggerConfig := logger.Config{
-		Format:     generateLogFormat(config),
-		TimeFormat: config.TimeFormat,
-		TimeZone:   config.TimeZone,
-		Output:     getLogOutput(config.Output),
+// Retorna: Logger que escribe en la salida configurada
+func NewLogger(config LogConfig) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       parseLogLevel(config.Level),
+		ReplaceAttr: timeAttrReplacer(config),
+	}
+
+	output := getLogOutput(config)
+
+	var handler slog.Handler
+	if config.Format == "json" {
+		handler = slog.NewJSONHandler(output, options)
+	} else {
+		handler = slog.NewTextHandler(output, options)
+	}
+
+	// Redactar datos sensibles antes de que lleguen a cualquier salida
+	redaction := DefaultRedactionConfig()
+	if config.Redaction != nil {
+		redaction = *config.Redaction
 	}
 
-	return logger.New(loggerConfig)
+	return slog.New(NewRedactingHandler(handler, NewRedactor(redaction)))
 }
 
-// generateLogFormat genera el formato de log basado en la configuración
+// LogWithConfig crea middleware de logging con configuración personalizada
 // Parámetros:
-//   - config: Configuración de logging
+//   - config: Configuración de logging personalizada
 //
-// Retorna: String con el formato de log
-func generateLogFormat(config LogConfig) string {
-	if config.Format == "json" {
-		return generateJSONFormat(config)
-	}
-	return generateTextFormat(config)
+// Retorna: Middleware de logging configurado
+func LogWithConfig(config LogConfig) fiber.Handler {
+	return LogWithLogger(NewLogger(config), config)
 }
 
-// generateJSONFormat genera formato JSON para logging
+// LogWithLogger crea middleware de logging que escribe en un logger existente
 // Parámetros:
-//   - config: Configuración de logging
+//   - logger: Logger estructurado de destino
+//   - config: Configuración con los campos a incluir
 //
-// Retorna: String con formato JSON
-func generateJSONFormat(config LogConfig) string {
-	format := `{"timestamp":"${time}","level":"${status}","method":"${method}","path":"${path}"`
+// Retorna: Middleware de logging configurado
+func LogWithLogger(logger *slog.Logger, config LogConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
 
-	if config.IP {
-		format += `,"ip":"${ip}"`
-	}
-	if config.UserAgent {
-		format += `,"user_agent":"${ua}"`
-	}
-	if config.Referer {
-		format += `,"referer":"${referer}"`
-	}
-	if config.Latency {
-		format += `,"latency":"${latency}"`
-	}
-	if config.Query {
-		format += `,"query":"${query}"`
-	}
-	if config.Body {
-		format += `,"body":"${body}"`
+		// Ejecutar la cadena y resolver el error aquí para registrar el estado final
+		if chainErr := c.Next(); chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		attrs := []slog.Attr{slog.String("request_id", GetRequestIDFromContext(c))}
+		attrs = append(attrs, traceAttrs(c.UserContext())...)
+
+		if config.Status {
+			attrs = append(attrs, slog.Int("status", status))
+		}
+		if config.Method {
+			attrs = append(attrs, slog.String("method", c.Method()))
+		}
+		if config.Path {
+			attrs = append(attrs, slog.String("path", c.Path()))
+		}
+		if config.IP {
+			attrs = append(attrs, slog.String("ip", c.IP()))
+		}
+		if config.UserAgent {
+			attrs = append(attrs, slog.String("user_agent", c.Get(fiber.HeaderUserAgent)))
+		}
+		if config.Referer {
+			attrs = append(attrs, slog.String("referer", c.Get(fiber.HeaderReferer)))
+		}
+		if config.Latency {
+			attrs = append(attrs, slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000))
+		}
+		if config.Query {
+			attrs = append(attrs, slog.String("query", string(c.Request().URI().QueryString())))
+		}
+		if config.Body {
+			attrs = append(attrs, slog.String("body", string(c.Body())))
+		}
+		if config.Headers {
+			attrs = append(attrs, slog.Any("headers", c.GetReqHeaders()))
+		}
+
+		logger.LogAttrs(c.UserContext(), levelForStatus(status), "request", attrs...)
+		return nil
 	}
-	if config.Headers {
-		format += `,"headers":"${headers}"`
+}
+
+// levelForStatus obtiene el nivel de log según el código de estado HTTP
+func levelForStatus(status int) slog.Level {
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		return slog.LevelError
+	case status >= fiber.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
 	}
+}
 
-	format += `}`
-	return format
+// parseLogLevel convierte un nombre de nivel (debug, info, warn, error) en slog.Level
+// Parámetros:
+//   - level: Nombre del nivel
+//
+// Retorna: Nivel de slog (info si el nombre no es válido)
+func parseLogLevel(level string) slog.Level {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return parsed
 }
 
-// generateTextFormat genera formato de texto para logging
+// timeAttrReplacer aplica el formato y la zona horaria configurados al timestamp
 // Parámetros:
 //   - config: Configuración de logging
 //
-// Retorna: String con formato de texto
-func generateTextFormat(config LogConfig) string {
-	format := "${time} | ${status} | ${method} | ${path}"
-
-	if config.IP {
-		format += ` | ${ip}`
-	}
-	if config.UserAgent {
-		format += ` | ${ua}`
-	}
-	if config.Referer {
-		format += ` | ${referer}`
-	}
-	if config.Latency {
-		format += ` | ${latency}`
-	}
-	if config.Query {
-		format += ` | ${query}`
-	}
-	if config.Body {
-		format += ` | ${body}`
-	}
-	if config.Headers {
-		format += ` | ${headers}`
+// Retorna: Función ReplaceAttr para slog.HandlerOptions
+func timeAttrReplacer(config LogConfig) func(groups []string, a slog.Attr) slog.Attr {
+	location := time.Local
+	if config.TimeZone != "" {
+		if loc, err := time.LoadLocation(config.TimeZone); err == nil {
+			location = loc
+		}
 	}
 
-	return format
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			t := a.Value.Time().In(location)
+			if config.TimeFormat != "" {
+				return slog.String(slog.TimeKey, t.Format(config.TimeFormat))
+			}
+			return slog.Time(slog.TimeKey, t)
+		}
+		return a
+	}
 }
 
 // getLogOutput obtiene el writer de salida para los logs
 // Parámetros:
-//   - output: Tipo de salida (stdout, stderr, archivo)
+//   - config: Configuración de logging; Output admite varias salidas separadas por comas
+//     (stdout, stderr o rutas de archivo, p. ej. "stdout,logs/security.log")
 //
 // Retorna: Writer para la salida de logs
-func getLogOutput(output string) *os.File {
-	switch output {
-	case "stderr":
-		return os.Stderr
-	case "security.log":
-		// Abrir archivo de log de seguridad
-		file, err := os.OpenFile("security.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
-			// Si no se puede abrir el archivo, usar stderr
-			fmt.Fprintf(os.Stderr, "error al abrir archivo de log de seguridad: %v\n", err)
-			return os.Stderr
-		}
-		return file
-	default:
-		return os.Stdout
+func getLogOutput(config LogConfig) io.Writer {
+	rotation := DefaultRotationConfig()
+	if config.Rotation != nil {
+		rotation = *config.Rotation
+	}
+
+	var sinks multiSink
+	for _, output := range strings.Split(config.Output, ",") {
+		sinks = append(sinks, openLogSink(strings.TrimSpace(output), rotation))
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0]
 	}
+	return sinks
 }
 
 // ========================================
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"website/backend/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsConfig configuración del endpoint /metrics
+type MetricsConfig struct {
+	Username   string   // Usuario para basic auth (vacío = sin basic auth)
+	Password   string   // Contraseña para basic auth
+	AllowedIPs []string // IPs o rangos CIDR permitidos (vacío = cualquier IP)
+}
+
+// DefaultMetricsConfig configuración del endpoint de métricas
+// Usa METRICS_USERNAME, METRICS_PASSWORD y METRICS_ALLOWED_IPS de la configuración
+func DefaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{
+		Username:   settings.Metrics.Username,
+		Password:   settings.Metrics.Password,
+		AllowedIPs: settings.Metrics.AllowedIPs,
+	}
+}
+
+// Metrics registra el número y la latencia de las peticiones por plantilla de ruta
+// Retorna: Middleware de métricas HTTP
+func Metrics() fiber.Handler {
+	var once sync.Once
+	registered := make(map[string]bool)
+
+	return func(c *fiber.Ctx) error {
+		// Las rutas se registran antes de la primera petición
+		once.Do(func() {
+			for _, route := range c.App().GetRoutes(true) {
+				registered[route.Method+" "+route.Path] = true
+			}
+		})
+
+		start := time.Now()
+
+		// Resolver el error aquí para registrar el estado final
+		if chainErr := c.Next(); chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := routeTemplate(c, registered)
+		status := strconv.Itoa(c.Response().StatusCode())
+		metrics.HTTPRequests.WithLabelValues(route, c.Method(), status).Inc()
+		metrics.HTTPDuration.WithLabelValues(route, c.Method(), status).Observe(time.Since(start).Seconds())
+
+		return nil
+	}
+}
+
+// routeTemplate obtiene la plantilla de ruta (/productos/:category) para evitar
+// etiquetas con cardinalidad ilimitada; las rutas sin handler se agrupan en "unmatched"
+func routeTemplate(c *fiber.Ctx, registered map[string]bool) string {
+	route := c.Route()
+	if !registered[route.Method+" "+route.Path] {
+		return "unmatched"
+	}
+	if !strings.ContainsAny(route.Path, ":*+") && strings.TrimSuffix(c.Path(), "/") != strings.TrimSuffix(route.Path, "/") {
+		return "unmatched"
+	}
+	return route.Path
+}
+
+// MetricsHandler expone las métricas en formato de texto de Prometheus
+// Parámetros:
+//   - config: Restricciones de acceso (basic auth y/o IPs permitidas)
+//
+// Retorna: Handler para montar en /metrics
+func MetricsHandler(config MetricsConfig) fiber.Handler {
+	allowed := parseTrustedProxies(config.AllowedIPs)
+	handler := adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
+	return func(c *fiber.Ctx) error {
+		if len(allowed) > 0 && !isTrustedProxy(allowed, net.ParseIP(c.IP())) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "acceso a métricas no permitido desde esta IP",
+				"code":  "METRICS_FORBIDDEN",
+			})
+		}
+
+		if config.Username != "" {
+			username, password, ok := parseBasicAuth(c.Get(fiber.HeaderAuthorization))
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(username), []byte(config.Username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(password), []byte(config.Password)) != 1 {
+				c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="metrics"`)
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "credenciales de métricas inválidas",
+					"code":  "METRICS_UNAUTHORIZED",
+				})
+			}
+		}
+
+		return handler(c)
+	}
+}
+
+// parseBasicAuth extrae usuario y contraseña de un header Authorization: Basic
+func parseBasicAuth(header string) (username, password string, ok bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"sync"
 	"time"
+	"website/backend/metrics"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // RateLimitConfig configuración para el sistema de rate limiting
 type RateLimitConfig struct {
+	Name                   string                    // Nombre del limiter para métricas
 	MaxRequests            int                       // Número máximo de requests permitidos
 	Window                 time.Duration             // Ventana de tiempo para el límite
 	KeyGenerator           func(c *fiber.Ctx) string // Función para generar la clave única
@@ -22,8 +24,16 @@ type RateLimiter struct {
 	requests map[string][]time.Time // Mapa de claves a timestamps de requests
 	mutex    sync.RWMutex           // Mutex para acceso concurrente seguro
 	config   RateLimitConfig        // Configuración del rate limiter
+	stop     chan struct{}          // Señal para detener la limpieza en background
+	stopOnce sync.Once              // Garantiza que stop se cierra una sola vez
 }
 
+// activeLimiters registro de rate limiters creados para detenerlos al apagar
+var activeLimiters = struct {
+	sync.Mutex
+	limiters []*RateLimiter
+}{}
+
 // NewRateLimiter crea una nueva instancia de rate limiter
 // Parámetros:
 //   - config: Configuración del rate limiter
@@ -34,22 +44,52 @@ func NewRateLimiter(config RateLimitConfig) *RateLimiter {
 	rl := &RateLimiter{
 		requests: make(map[string][]time.Time),
 		config:   config,
+		stop:     make(chan struct{}),
 	}
 
 	// Iniciar limpieza automática en background
 	go rl.cleanup()
 
+	activeLimiters.Lock()
+	activeLimiters.limiters = append(activeLimiters.limiters, rl)
+	activeLimiters.Unlock()
+
 	return rl
 }
 
+// Stop detiene la limpieza en background del rate limiter
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() {
+		close(rl.stop)
+	})
+}
+
+// StopRateLimiters detiene la limpieza en background de todos los rate limiters
+// Se llama durante el apagado ordenado del servidor
+func StopRateLimiters() {
+	activeLimiters.Lock()
+	defer activeLimiters.Unlock()
+
+	for _, rl := range activeLimiters.limiters {
+		rl.Stop()
+	}
+	activeLimiters.limiters = nil
+}
+
 // cleanup limpia requests antiguos periódicamente para evitar fugas de memoria
 func (rl *RateLimiter) cleanup() {
 	// Crear ticker que se ejecuta cada minuto
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
-	// Ejecutar limpieza en cada tick
-	for range ticker.C {
+	// Ejecutar limpieza en cada tick hasta recibir la señal de parada
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+		}
+
 		rl.mutex.Lock()
 		now := time.Now()
 		cutoff := now.Add(-rl.config.Window)
@@ -73,6 +113,14 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// name obtiene el nombre del limiter para las métricas
+func (rl *RateLimiter) name() string {
+	if rl.config.Name == "" {
+		return "default"
+	}
+	return rl.config.Name
+}
+
 // Limit middleware para aplicar rate limiting
 // Retorna: Middleware de Fiber que aplica rate limiting
 func (rl *RateLimiter) Limit() fiber.Handler {
@@ -97,6 +145,7 @@ func (rl *RateLimiter) Limit() fiber.Handler {
 		// Verificar si se excedió el límite
 		if len(validRequests) >= rl.config.MaxRequests {
 			rl.mutex.Unlock()
+			metrics.RateLimitRejections.WithLabelValues(rl.name()).Inc()
 
 			// Calcular tiempo de espera
 			oldestRequest := validRequests[0]
@@ -188,6 +237,7 @@ var KeyGenerators = struct {
 // Retorna: Middleware de rate limiting por IP
 func RateLimitByIP(maxRequests int, window time.Duration) fiber.Handler {
 	config := RateLimitConfig{
+		Name:         "by_ip",
 		MaxRequests:  maxRequests,
 		Window:       window,
 		KeyGenerator: KeyGenerators.IP,
@@ -203,6 +253,7 @@ func RateLimitByIP(maxRequests int, window time.Duration) fiber.Handler {
 // Retorna: Middleware de rate limiting por usuario
 func RateLimitByUser(maxRequests int, window time.Duration) fiber.Handler {
 	config := RateLimitConfig{
+		Name:         "by_user",
 		MaxRequests:  maxRequests,
 		Window:       window,
 		KeyGenerator: KeyGenerators.UserID,
@@ -218,6 +269,7 @@ func RateLimitByUser(maxRequests int, window time.Duration) fiber.Handler {
 // Retorna: Middleware de rate limiting por endpoint
 func RateLimitByEndpoint(maxRequests int, window time.Duration) fiber.Handler {
 	config := RateLimitConfig{
+		Name:         "by_endpoint",
 		MaxRequests:  maxRequests,
 		Window:       window,
 		KeyGenerator: KeyGenerators.Endpoint,
@@ -233,6 +285,7 @@ func RateLimitByEndpoint(maxRequests int, window time.Duration) fiber.Handler {
 // Retorna: Middleware de rate limiting por IP y endpoint
 func RateLimitByIPAndEndpoint(maxRequests int, window time.Duration) fiber.Handler {
 	config := RateLimitConfig{
+		Name:         "by_ip_endpoint",
 		MaxRequests:  maxRequests,
 		Window:       window,
 		KeyGenerator: KeyGenerators.IPEndpoint,
@@ -254,30 +307,35 @@ var RateLimitConfigs = struct {
 }{
 	// Configuración estricta: 10 requests por minuto
 	Strict: RateLimitConfig{
+		Name:         "strict",
 		MaxRequests:  10,
 		Window:       1 * time.Minute,
 		KeyGenerator: KeyGenerators.IP,
 	},
 	// Configuración moderada: 60 requests por minuto
 	Moderate: RateLimitConfig{
+		Name:         "moderate",
 		MaxRequests:  60,
 		Window:       1 * time.Minute,
 		KeyGenerator: KeyGenerators.IP,
 	},
 	// Configuración relajada: 300 requests por minuto
 	Relaxed: RateLimitConfig{
+		Name:         "relaxed",
 		MaxRequests:  300,
 		Window:       1 * time.Minute,
 		KeyGenerator: KeyGenerators.IP,
 	},
 	// Configuración para APIs: 1000 requests por hora
 	API: RateLimitConfig{
+		Name:         "api",
 		MaxRequests:  1000,
 		Window:       1 * time.Hour,
 		KeyGenerator: KeyGenerators.IP,
 	},
 	// Configuración para autenticación: 5 requests por 15 minutos
 	Auth: RateLimitConfig{
+		Name:         "auth",
 		MaxRequests:  5,
 		Window:       15 * time.Minute,
 		KeyGenerator: KeyGenerators.IP,
@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactionConfig reglas de redacción de datos sensibles en los logs
+type RedactionConfig struct {
+	Headers      []string         // Headers cuyo valor se oculta (sin distinguir mayúsculas)
+	JSONPaths    []string         // Rutas de campos a ocultar (password, token, *.secret)
+	Patterns     []*regexp.Regexp // Expresiones regulares aplicadas a cualquier texto
+	PreserveKeys []string         // Campos del log que nunca se modifican (request_id, etc.)
+	Mask         string           // Texto de reemplazo
+}
+
+// RedactionPatterns contiene expresiones regulares predefinidas para datos personales
+var RedactionPatterns = struct {
+	Email  *regexp.Regexp // Direcciones de correo electrónico
+	Phone  *regexp.Regexp // Números de teléfono (9 dígitos o más)
+	Card   *regexp.Regexp // Números con forma de tarjeta (13 a 19 dígitos)
+	Bcrypt *regexp.Regexp // Hashes bcrypt que aparecen en consultas SQL
+}{
+	Email:  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	Phone:  regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{1,4}\)[\s.-]?)?\b\d{3,4}[\s.-]?\d{3}[\s.-]?\d{3,4}\b`),
+	Card:   regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	Bcrypt: regexp.MustCompile(`\$2[aby]\$\d{2}\$[./A-Za-z0-9]{53}`),
+}
+
+// DefaultRedactionConfig configuración de redacción por defecto
+// Se amplía con LOG_REDACT_HEADERS y LOG_REDACT_FIELDS de la configuración
+func DefaultRedactionConfig() RedactionConfig {
+	config := RedactionConfig{
+		Headers: []string{
+			"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key",
+		},
+		JSONPaths: []string{
+			"password", "new_password", "current_password", "token", "access_token",
+			"refresh_token", "secret", "*.secret", "api_key",
+		},
+		Patterns: []*regexp.Regexp{
+			RedactionPatterns.Bcrypt,
+			RedactionPatterns.Email,
+			RedactionPatterns.Card,
+			RedactionPatterns.Phone,
+		},
+		PreserveKeys: []string{"request_id", "trace_id", "span_id"},
+		Mask:         "[REDACTED]",
+	}
+
+	config.Headers = append(config.Headers, settings.Log.RedactHeaders...)
+	config.JSONPaths = append(config.JSONPaths, settings.Log.RedactFields...)
+
+	return config
+}
+
+// Redactor aplica las reglas de redacción a headers, cuerpos y texto libre
+type Redactor struct {
+	headers  map[string]bool
+	paths    [][]string
+	patterns []*regexp.Regexp
+	preserve map[string]bool
+	mask     string
+}
+
+// NewRedactor crea un redactor a partir de la configuración
+// Parámetros:
+//   - config: Reglas de redacción
+//
+// Retorna: Redactor listo para usar
+func NewRedactor(config RedactionConfig) *Redactor {
+	r := &Redactor{
+		headers:  make(map[string]bool),
+		patterns: config.Patterns,
+		preserve: make(map[string]bool),
+		mask:     config.Mask,
+	}
+	if r.mask == "" {
+		r.mask = "[REDACTED]"
+	}
+
+	for _, header := range config.Headers {
+		if header = strings.TrimSpace(header); header != "" {
+			r.headers[strings.ToLower(header)] = true
+		}
+	}
+	for _, path := range config.JSONPaths {
+		if path = strings.TrimSpace(path); path != "" {
+			r.paths = append(r.paths, strings.Split(strings.ToLower(path), "."))
+		}
+	}
+	for _, key := range config.PreserveKeys {
+		r.preserve[key] = true
+	}
+
+	return r
+}
+
+// Headers devuelve una copia de los headers con los valores sensibles ocultos
+func (r *Redactor) Headers(headers map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if r.headers[strings.ToLower(name)] {
+			result[name] = []string{r.mask}
+			continue
+		}
+		redacted := make([]string, len(values))
+		for i, value := range values {
+			redacted[i] = r.Text(value)
+		}
+		result[name] = redacted
+	}
+	return result
+}
+
+// Text aplica las expresiones regulares a un texto libre
+func (r *Redactor) Text(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, r.mask)
+	}
+	return s
+}
+
+// Body oculta campos sensibles de un cuerpo JSON o de formulario y aplica los patrones
+func (r *Redactor) Body(body string) string {
+	trimmed := strings.TrimSpace(body)
+
+	// Documentos JSON: recorrer y ocultar por ruta
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		var document interface{}
+		if err := json.Unmarshal([]byte(trimmed), &document); err == nil {
+			if out, err := json.Marshal(r.value(nil, document)); err == nil {
+				return string(out)
+			}
+		}
+	}
+
+	// Formularios y query strings: ocultar por nombre de campo conservando el orden
+	if strings.Contains(trimmed, "=") && !strings.ContainsAny(trimmed, " \n") {
+		pairs := strings.Split(trimmed, "&")
+		for i, pair := range pairs {
+			key, _, found := strings.Cut(pair, "=")
+			if name, err := url.QueryUnescape(key); err == nil && found && r.matchesPath([]string{name}) {
+				pairs[i] = key + "=" + r.mask
+			}
+		}
+		return r.Text(strings.Join(pairs, "&"))
+	}
+
+	return r.Text(body)
+}
+
+// matchesPath verifica si una ruta de campos coincide con alguna regla (por sufijo)
+func (r *Redactor) matchesPath(path []string) bool {
+	for _, rule := range r.paths {
+		if len(rule) > len(path) {
+			continue
+		}
+		offset := len(path) - len(rule)
+		matched := true
+		for i, segment := range rule {
+			if segment != "*" && segment != strings.ToLower(path[offset+i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// value recorre un valor JSON genérico ocultando campos y textos sensibles
+func (r *Redactor) value(path []string, v interface{}) interface{} {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			childPath := append(append([]string{}, path...), key)
+			if r.matchesPath(childPath) {
+				result[key] = r.mask
+				continue
+			}
+			result[key] = r.value(childPath, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, item := range typed {
+			result[i] = r.value(path, item)
+		}
+		return result
+	case string:
+		return r.Text(typed)
+	default:
+		return typed
+	}
+}
+
+// attr aplica la redacción a un atributo de slog
+func (r *Redactor) attr(groups []string, a slog.Attr) slog.Attr {
+	if r.preserve[a.Key] {
+		return a
+	}
+
+	path := append(append([]string{}, groups...), a.Key)
+	if r.matchesPath(path) {
+		return slog.String(a.Key, r.mask)
+	}
+
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.Body(value.String()))
+	case slog.KindGroup:
+		attrs := value.Group()
+		redacted := make([]any, len(attrs))
+		for i, child := range attrs {
+			redacted[i] = r.attr(path, child)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		switch typed := value.Any().(type) {
+		case map[string][]string:
+			return slog.Any(a.Key, r.Headers(typed))
+		case error:
+			return slog.String(a.Key, r.Text(typed.Error()))
+		default:
+			// Normalizar a JSON genérico para poder recorrer mapas y structs
+			raw, err := json.Marshal(typed)
+			if err != nil {
+				return a
+			}
+			var document interface{}
+			if err := json.Unmarshal(raw, &document); err != nil {
+				return a
+			}
+			return slog.Any(a.Key, r.value(nil, document))
+		}
+	default:
+		return slog.Attr{Key: a.Key, Value: value}
+	}
+}
+
+// ========================================
+// HANDLER DE SLOG CON REDACCIÓN
+// ========================================
+
+// redactingHandler envuelve un slog.Handler y redacta cada registro antes de escribirlo
+type redactingHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+	groups   []string
+}
+
+// NewRedactingHandler envuelve un handler para que ningún sink reciba datos sensibles
+// Parámetros:
+//   - next: Handler de destino
+//   - redactor: Reglas de redacción a aplicar
+//
+// Retorna: Handler que redacta mensajes y atributos
+func NewRedactingHandler(next slog.Handler, redactor *Redactor) slog.Handler {
+	return &redactingHandler{next: next, redactor: redactor}
+}
+
+// Enabled delega en el handler envuelto
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacta el mensaje y los atributos del registro
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.Text(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.attr(h.groups, a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacta los atributos fijos antes de delegarlos
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactor.attr(h.groups, a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted), redactor: h.redactor, groups: h.groups}
+}
+
+// WithGroup registra el grupo para evaluar rutas anidadas
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	groups := append(append([]string{}, h.groups...), name)
+	return &redactingHandler{next: h.next.WithGroup(name), redactor: h.redactor, groups: groups}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// requestIDKey clave privada para guardar el request ID en un context.Context
+type requestIDKey struct{}
+
+// RequestIDConfig configuración para el middleware de request ID
+type RequestIDConfig struct {
+	Header         string        // Header que transporta el ID (entrada y salida)
+	TrustedProxies []string      // IPs o rangos CIDR cuyo ID entrante se acepta
+	Generator      func() string // Función para generar nuevos IDs
+}
+
+// DefaultRequestIDConfig configuración de request ID por defecto
+// Los proxies de confianza se toman de la configuración (TRUSTED_PROXIES)
+func DefaultRequestIDConfig() RequestIDConfig {
+	return RequestIDConfig{
+		Header:         fiber.HeaderXRequestID,
+		TrustedProxies: settings.Server.TrustedProxies,
+		Generator:      uuid.NewString,
+	}
+}
+
+// RequestIDWithConfig crea middleware de request ID con configuración personalizada
+// Parámetros:
+//   - config: Configuración del request ID
+//
+// Retorna: Middleware que asigna un ID único a cada request
+func RequestIDWithConfig(config RequestIDConfig) fiber.Handler {
+	if config.Header == "" {
+		config.Header = fiber.HeaderXRequestID
+	}
+	if config.Generator == nil {
+		config.Generator = uuid.NewString
+	}
+	trusted := parseTrustedProxies(config.TrustedProxies)
+
+	return func(c *fiber.Ctx) error {
+		// Aceptar el ID entrante solo si viene de un proxy de confianza
+		id := ""
+		if isTrustedProxy(trusted, c.Context().RemoteIP()) {
+			id = sanitizeRequestID(c.Get(config.Header))
+		}
+		if id == "" {
+			id = config.Generator()
+		}
+
+		// Propagar el ID a handlers, logs, GORM y respuesta
+		c.Locals("requestID", id)
+		c.SetUserContext(ContextWithRequestID(c.UserContext(), id))
+		c.Set(config.Header, id)
+
+		return c.Next()
+	}
+}
+
+// RequestID aplica el middleware de request ID con la configuración por defecto
+// Retorna: Middleware de request ID
+func RequestID() fiber.Handler {
+	return RequestIDWithConfig(DefaultRequestIDConfig())
+}
+
+// ContextWithRequestID devuelve una copia del contexto con el request ID asociado
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext obtiene el request ID de un context.Context
+// Retorna: Request ID o string vacío si no existe
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// GetRequestIDFromContext obtiene el request ID desde el contexto de Fiber
+// Parámetros:
+//   - c: Contexto de Fiber
+//
+// Retorna: Request ID o string vacío si el middleware no se aplicó
+func GetRequestIDFromContext(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestID").(string); ok {
+		return id
+	}
+	return ""
+}
+
+// parseTrustedProxies convierte IPs y rangos CIDR en redes
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil {
+				bits := 32
+				if ip.To4() == nil {
+					bits = 128
+				}
+				proxy = proxy + "/" + strconv.Itoa(bits)
+			}
+		}
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// isTrustedProxy verifica si la IP remota pertenece a un proxy de confianza
+func isTrustedProxy(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeRequestID descarta IDs entrantes demasiado largos o con caracteres inseguros
+func sanitizeRequestID(id string) string {
+	if id == "" || len(id) > 128 {
+		return ""
+	}
+	for _, char := range id {
+		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') ||
+			char == '-' || char == '_' || char == '.' || char == ':') {
+			return ""
+		}
+	}
+	return id
+}
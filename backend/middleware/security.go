@@ -3,8 +3,6 @@ package middleware
 import (
 	"crypto/tls"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -80,28 +78,19 @@ func SecurityMiddleware(config SecurityConfig) fiber.Handler {
 	}
 }
 
-// DefaultSecurityConfig configuración de seguridad por defecto
+// DefaultSecurityConfig configuración de seguridad tomada de la configuración (ENABLE_*)
 func DefaultSecurityConfig() SecurityConfig {
 	return SecurityConfig{
-		EnableHTTPS:     getEnvBool("ENABLE_HTTPS", false),
-		EnableHSTS:      getEnvBool("ENABLE_HSTS", true),
-		EnableCSP:       getEnvBool("ENABLE_CSP", true),
-		EnableXSS:       getEnvBool("ENABLE_XSS", true),
-		EnableFrameDeny: getEnvBool("ENABLE_FRAME_DENY", true),
-		EnableNoSniff:   getEnvBool("ENABLE_NO_SNIFF", true),
-		EnableReferrer:  getEnvBool("ENABLE_REFERRER", true),
+		EnableHTTPS:     settings.Server.EnableHTTPS,
+		EnableHSTS:      settings.Security.EnableHSTS,
+		EnableCSP:       settings.Security.EnableCSP,
+		EnableXSS:       settings.Security.EnableXSS,
+		EnableFrameDeny: settings.Security.EnableFrameDeny,
+		EnableNoSniff:   settings.Security.EnableNoSniff,
+		EnableReferrer:  settings.Security.EnableReferrer,
 	}
 }
 
-// getEnvBool obtiene una variable de entorno como booleano
-func getEnvBool(key string, defaultValue bool) bool {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return strings.ToLower(value) == "true" || value == "1"
-}
-
 // TLSServerConfig configuración del servidor TLS
 func TLSServerConfig() *tls.Config {
 	return &tls.Config{
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"website/backend/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fiberHeaderCarrier adapta los headers de Fiber al TextMapCarrier de OpenTelemetry
+type fiberHeaderCarrier struct {
+	c *fiber.Ctx
+}
+
+// Get obtiene un header de la petición
+func (h fiberHeaderCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+// Set establece un header de la respuesta
+func (h fiberHeaderCarrier) Set(key, value string) {
+	h.c.Set(key, value)
+}
+
+// Keys lista los headers de la petición
+func (h fiberHeaderCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Tracing crea un span de servidor por petición continuando el traceparent W3C entrante
+// El span queda en c.UserContext() para que GORM y las plantillas creen spans hijos
+// Retorna: Middleware de trazas HTTP
+func Tracing() fiber.Handler {
+	var once sync.Once
+	registered := make(map[string]bool)
+
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			for _, route := range c.App().GetRoutes(true) {
+				registered[route.Method+" "+route.Path] = true
+			}
+		})
+
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), fiberHeaderCarrier{c})
+		ctx, span := tracing.Tracer().Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				attribute.String("request_id", GetRequestIDFromContext(c)),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		// Resolver el error aquí para registrar el estado final
+		if chainErr := c.Next(); chainErr != nil {
+			span.RecordError(chainErr)
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := routeTemplate(c, registered)
+		status := c.Response().StatusCode()
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+
+		return nil
+	}
+}
+
+// traceAttrs atributos trace_id y span_id del span activo para correlacionar logs y trazas
+// Retorna: Lista vacía si el contexto no tiene un span válido
+func traceAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String("trace_id", spanContext.TraceID().String()),
+		slog.String("span_id", spanContext.SpanID().String()),
+	}
+}
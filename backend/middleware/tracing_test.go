@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"website/backend/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingContinuesW3CTraceparent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(tracing.Config{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Use(Tracing())
+	app.Use(LogWithLogger(logger, LogConfigs.Production))
+
+	var handlerSpan trace.SpanContext
+	app.Get("/productos/:category", func(c *fiber.Ctx) error {
+		handlerSpan = trace.SpanContextFromContext(c.UserContext())
+		return c.SendStatus(fiber.StatusInternalServerError)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/productos/bodas", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := app.Test(req); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	if handlerSpan.TraceID().String() != traceID {
+		t.Errorf("el handler no recibió el trace del traceparent: %s", handlerSpan.TraceID())
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("se esperaba 1 span, hay %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /productos/:category" {
+		t.Errorf("nombre del span = %q", span.Name)
+	}
+	if span.Parent.SpanID().String() != "00f067aa0ba902b7" || !span.Parent.IsRemote() {
+		t.Errorf("el span no continúa el padre remoto: %v", span.Parent)
+	}
+	if span.Status.Code.String() != "Error" {
+		t.Errorf("un 500 debe marcar el span como error, estado = %v", span.Status.Code)
+	}
+
+	if !strings.Contains(logs.String(), `"trace_id":"`+traceID+`"`) {
+		t.Errorf("el log de la petición no incluye trace_id: %s", logs.String())
+	}
+}
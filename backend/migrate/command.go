@@ -0,0 +1,79 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Usage ayuda de los subcomandos de migración
+const Usage = `uso: migrate <comando>
+  up        aplica las migraciones pendientes
+  down [n]  revierte las últimas n migraciones (por defecto 1)
+  status    muestra el estado de cada migración`
+
+// Run ejecuta un subcomando de migración: up, down [n] o status
+// Parámetros:
+//   - ctx: Contexto de la operación
+//   - runner: Runner con las migraciones embebidas
+//   - args: Argumentos tras "migrate" (p. ej. ["down", "2"])
+//   - w: Destino de la salida
+func Run(ctx context.Context, runner *Runner, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("falta el comando\n%s", Usage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(w, "aplicada %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(w, "no hay migraciones pendientes")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("número de migraciones inválido: %q", args[1])
+			}
+			steps = parsed
+		}
+		reverted, err := runner.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(w, "revertida %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(w, "no hay migraciones aplicadas")
+		}
+		return err
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return PrintStatus(w, statuses)
+	default:
+		return fmt.Errorf("comando desconocido %q\n%s", args[0], Usage)
+	}
+}
+
+// PrintStatus escribe el estado de las migraciones como tabla
+func PrintStatus(w io.Writer, statuses []MigrationStatus) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(table, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+	}
+	return table.Flush()
+}
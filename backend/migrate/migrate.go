@@ -0,0 +1,219 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ========================================
+// MIGRACIONES
+// ========================================
+
+// Migration migración versionada con sus scripts de subida y bajada
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 del script de subida
+}
+
+// fileNamePattern formato de los archivos: 001_initial_schema.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load lee las migraciones *.sql de la raíz de fsys ordenadas por versión
+// Parámetros:
+//   - fsys: Sistema de archivos con los scripts (p. ej. migrations.FS)
+//
+// Retorna: Migraciones ordenadas y error si algún archivo no sigue el formato
+// NNN_nombre.up.sql / NNN_nombre.down.sql o falta un script de subida
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		match := fileNamePattern.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("nombre de migración inválido %q (use NNN_nombre.up.sql o NNN_nombre.down.sql)", file)
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("versión de migración inválida en %q", file)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("versión %d duplicada: %q y %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			migration.Checksum = Checksum(migration.Up)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("la migración %d_%s no tiene script de subida", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Checksum SHA-256 en hexadecimal de un script
+func Checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// ========================================
+// ESTADO
+// ========================================
+
+// Estados de una migración respecto a la base de datos
+const (
+	StateApplied  = "applied"  // Aplicada con el mismo checksum
+	StatePending  = "pending"  // Embebida pero no aplicada
+	StateModified = "modified" // Aplicada, pero el script cambió después
+	StateUnknown  = "unknown"  // Aplicada, pero no existe en este binario
+)
+
+// AppliedMigration fila de la tabla schema_migrations
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// MigrationStatus estado de una versión
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// compare cruza las migraciones embebidas con las aplicadas, ordenadas por versión
+func compare(migrations []Migration, applied []AppliedMigration) []MigrationStatus {
+	appliedByVersion := make(map[int64]AppliedMigration, len(applied))
+	for _, row := range applied {
+		appliedByVersion[row.Version] = row
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations)+len(applied))
+	known := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if row, ok := appliedByVersion[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = StateApplied
+			if row.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range applied {
+		if known[row.Version] {
+			continue
+		}
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   row.Version,
+			Name:      row.Name,
+			State:     StateUnknown,
+			AppliedAt: &appliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses
+}
+
+// DriftError diferencias entre las migraciones embebidas y las aplicadas
+type DriftError struct {
+	Pending  []int64 // Versiones sin aplicar
+	Modified []int64 // Versiones aplicadas cuyo script cambió
+	Unknown  []int64 // Versiones aplicadas que este binario no conoce
+}
+
+// Error describe las diferencias encontradas
+func (e *DriftError) Error() string {
+	var parts []string
+	if len(e.Modified) > 0 {
+		parts = append(parts, "checksum distinto en "+formatVersions(e.Modified))
+	}
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "versiones aplicadas desconocidas "+formatVersions(e.Unknown))
+	}
+	if len(e.Pending) > 0 {
+		parts = append(parts, "migraciones pendientes "+formatVersions(e.Pending))
+	}
+	return "el esquema no coincide con las migraciones: " + strings.Join(parts, "; ")
+}
+
+// driftOf construye el error de deriva a partir del estado (nil si todo está aplicado)
+// Parámetros:
+//   - statuses: Estado de cada versión
+//   - allowPending: No considerar deriva las migraciones pendientes
+func driftOf(statuses []MigrationStatus, allowPending bool) error {
+	drift := &DriftError{}
+	for _, status := range statuses {
+		switch status.State {
+		case StatePending:
+			if !allowPending {
+				drift.Pending = append(drift.Pending, status.Version)
+			}
+		case StateModified:
+			drift.Modified = append(drift.Modified, status.Version)
+		case StateUnknown:
+			drift.Unknown = append(drift.Unknown, status.Version)
+		}
+	}
+	if len(drift.Pending) == 0 && len(drift.Modified) == 0 && len(drift.Unknown) == 0 {
+		return nil
+	}
+	return drift
+}
+
+// formatVersions lista de versiones separadas por comas
+func formatVersions(versions []int64) string {
+	items := make([]string, len(versions))
+	for i, version := range versions {
+		items[i] = fmt.Sprintf("%03d", version)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,113 @@
+package migrate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+	"website/cms/migrations"
+)
+
+func TestLoadOrdersAndPairsScripts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.up.sql":    {Data: []byte("CREATE TABLE later ();")},
+		"002_second.up.sql":   {Data: []byte("CREATE TABLE second ();")},
+		"002_second.down.sql": {Data: []byte("DROP TABLE second;")},
+		"001_first.up.sql":    {Data: []byte("CREATE TABLE first ();")},
+		"migrations.go":       {Data: []byte("package migrations")},
+	}
+
+	loaded, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []int64
+	for _, migration := range loaded {
+		versions = append(versions, migration.Version)
+	}
+	if !reflect.DeepEqual(versions, []int64{1, 2, 10}) {
+		t.Fatalf("versiones = %v", versions)
+	}
+	if loaded[1].Name != "second" || loaded[1].Down != "DROP TABLE second;" {
+		t.Errorf("migración 2 = %+v", loaded[1])
+	}
+	if loaded[0].Checksum != Checksum("CREATE TABLE first ();") {
+		t.Errorf("checksum = %s", loaded[0].Checksum)
+	}
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"nombre inválido": {"1-initial.sql": {Data: []byte("SELECT 1;")}},
+		"versión duplicada": {
+			"001_first.up.sql": {Data: []byte("SELECT 1;")},
+			"001_other.up.sql": {Data: []byte("SELECT 2;")},
+		},
+		"sin subida": {"001_first.down.sql": {Data: []byte("SELECT 1;")}},
+	}
+
+	for name, fsys := range cases {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: se esperaba error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, migration := range loaded {
+		if migration.Version != int64(i+1) {
+			t.Errorf("hueco en las versiones: %03d en la posición %d", migration.Version, i)
+		}
+		if migration.Down == "" {
+			t.Errorf("%03d_%s no tiene script de bajada", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestDrift(t *testing.T) {
+	embedded := []Migration{
+		{Version: 1, Name: "first", Checksum: Checksum("a")},
+		{Version: 2, Name: "second", Checksum: Checksum("b")},
+		{Version: 3, Name: "third", Checksum: Checksum("c")},
+	}
+	now := time.Now()
+	applied := []AppliedMigration{
+		{Version: 1, Name: "first", Checksum: Checksum("a"), AppliedAt: now},
+		{Version: 2, Name: "second", Checksum: Checksum("changed"), AppliedAt: now},
+		{Version: 7, Name: "future", Checksum: Checksum("x"), AppliedAt: now},
+	}
+
+	statuses := compare(embedded, applied)
+	var states []string
+	for _, status := range statuses {
+		states = append(states, status.State)
+	}
+	want := []string{StateApplied, StateModified, StatePending, StateUnknown}
+	if !reflect.DeepEqual(states, want) {
+		t.Fatalf("estados = %v, se esperaba %v", states, want)
+	}
+
+	var drift *DriftError
+	if err := driftOf(statuses, false); !errors.As(err, &drift) {
+		t.Fatalf("se esperaba DriftError, se obtuvo %v", err)
+	}
+	if !reflect.DeepEqual(drift.Pending, []int64{3}) ||
+		!reflect.DeepEqual(drift.Modified, []int64{2}) ||
+		!reflect.DeepEqual(drift.Unknown, []int64{7}) {
+		t.Errorf("deriva = %+v", drift)
+	}
+
+	// Up tolera las pendientes pero no los scripts modificados
+	if err := driftOf(statuses, true); err == nil {
+		t.Error("se esperaba deriva por el checksum modificado")
+	}
+	if err := driftOf(compare(embedded, applied[:1]), true); err != nil {
+		t.Errorf("solo pendientes no debería ser deriva para Up: %v", err)
+	}
+}
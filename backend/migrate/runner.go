@@ -0,0 +1,231 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// TableName tabla donde se registran las migraciones aplicadas
+const TableName = "schema_migrations"
+
+// lockKey clave del advisory lock de PostgreSQL que serializa las migraciones
+// entre procesos que arrancan a la vez
+const lockKey int64 = 0x7765627369746501
+
+// Runner aplica y revierte migraciones sobre una base de datos PostgreSQL
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New crea un runner con las migraciones dadas
+// Parámetros:
+//   - db: Pool de conexiones (p. ej. el sql.DB subyacente de GORM)
+//   - migrations: Migraciones cargadas con Load
+func New(db *sql.DB, migrations []Migration) *Runner {
+	return &Runner{db: db, migrations: migrations}
+}
+
+// Migrations devuelve las migraciones embebidas ordenadas por versión
+func (r *Runner) Migrations() []Migration {
+	return r.migrations
+}
+
+// Status estado de cada versión, embebida o aplicada
+// No toma el lock ni crea la tabla: sin schema_migrations todo está pendiente
+func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := r.applied(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	return compare(r.migrations, applied), nil
+}
+
+// Check verifica que la base de datos tiene exactamente las migraciones embebidas
+// Sirve como comprobación de arranque y de readiness (firma de health.Check)
+// Retorna: *DriftError si hay pendientes, scripts modificados o versiones desconocidas
+func (r *Runner) Check(ctx context.Context) error {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return driftOf(statuses, false)
+}
+
+// Up aplica todas las migraciones pendientes en orden, cada una en su transacción
+// Retorna: Migraciones aplicadas y error si falla alguna o hay deriva en las ya aplicadas
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := r.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		statuses := compare(r.migrations, applied)
+		if err := driftOf(statuses, true); err != nil {
+			return err
+		}
+
+		pending := make(map[int64]bool)
+		for _, status := range statuses {
+			if status.State == StatePending {
+				pending[status.Version] = true
+			}
+		}
+
+		for _, migration := range r.migrations {
+			if !pending[migration.Version] {
+				continue
+			}
+			start := time.Now()
+			err := r.inTx(ctx, conn, migration.Up,
+				`INSERT INTO `+TableName+` (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+				migration.Version, migration.Name, migration.Checksum, time.Now().UTC(),
+			)
+			if err != nil {
+				return fmt.Errorf("error al aplicar %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("migración aplicada", "version", migration.Version, "name", migration.Name, "duration", time.Since(start).String())
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down revierte las últimas migraciones aplicadas, de la más reciente a la más antigua
+// Parámetros:
+//   - steps: Número de migraciones a revertir
+//
+// Retorna: Migraciones revertidas y error si alguna no tiene script de bajada o falla
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("el número de migraciones a revertir debe ser positivo")
+	}
+
+	byVersion := make(map[int64]Migration, len(r.migrations))
+	for _, migration := range r.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var done []Migration
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := r.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			row := applied[i]
+			migration, ok := byVersion[row.Version]
+			if !ok {
+				return fmt.Errorf("la versión %03d aplicada no existe en este binario", row.Version)
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("la migración %03d_%s no tiene script de bajada", migration.Version, migration.Name)
+			}
+
+			start := time.Now()
+			err := r.inTx(ctx, conn, migration.Down,
+				`DELETE FROM `+TableName+` WHERE version = $1`, migration.Version,
+			)
+			if err != nil {
+				return fmt.Errorf("error al revertir %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("migración revertida", "version", migration.Version, "name", migration.Name, "duration", time.Since(start).String())
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// ========================================
+// BASE DE DATOS
+// ========================================
+
+// queryer consulta común a *sql.DB y *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// applied lee las migraciones registradas ordenadas por versión (vacío si no existe la tabla)
+func (r *Runner) applied(ctx context.Context, q queryer) ([]AppliedMigration, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, TableName).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error al consultar %s: %w", TableName, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM `+TableName+` ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar %s: %w", TableName, err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var row AppliedMigration
+		if err := rows.Scan(&row.Version, &row.Name, &row.Checksum, &row.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, row)
+	}
+	return applied, rows.Err()
+}
+
+// withLock ejecuta fn en una conexión dedicada con el advisory lock tomado
+// y la tabla schema_migrations creada; otro proceso que migre a la vez espera
+func (r *Runner) withLock(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("error al tomar el lock de migraciones: %w", err)
+	}
+	defer func() {
+		// El lock es de sesión: liberarlo aunque el contexto se haya cancelado
+		_, unlockErr := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		err = errors.Join(err, unlockErr)
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+TableName+` (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(200) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("error al crear %s: %w", TableName, err)
+	}
+
+	return fn(conn)
+}
+
+// inTx ejecuta el script y el registro en schema_migrations en una sola transacción
+func (r *Runner) inTx(ctx context.Context, conn *sql.Conn, script, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Sin argumentos el driver usa el protocolo simple, que admite varias sentencias
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,7 +1,12 @@
 package models
 
 import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"website/backend/money"
 )
 
 type SiteConfig struct {
@@ -24,32 +29,388 @@ type Slide struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Category categoría del catálogo; las subcategorías cuelgan de ParentID sin límite
+// de profundidad. Path, SlugPath y Depth se calculan al guardar
 type Category struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Name        string    `gorm:"not null" json:"name" validate:"required,min=1,max=100"`
-	Slug        string    `gorm:"uniqueIndex;not null" json:"slug" validate:"required,min=1,max=100,alphanum"`
-	Description string    `gorm:"type:text" json:"description" validate:"max=1000"`
-	ImageURL    string    `json:"image_url" validate:"omitempty,url"`
-	Order       int       `json:"order" validate:"gte=0"`
-	Active      bool      `json:"active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Products    []Product `gorm:"foreignKey:CategoryID" json:"products,omitempty"`
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ParentID    *uint      `json:"parent_id"` // nil = categoría raíz
+	Name        string     `gorm:"not null" json:"name" validate:"required,min=1,max=100"`
+	Slug        string     `gorm:"uniqueIndex;not null" json:"slug" validate:"required,min=1,max=100,slug"`
+	Path        string     `gorm:"not null;index" json:"path"` // Ids desde la raíz hasta ella: "/1/4/"
+	SlugPath    string     `gorm:"not null" json:"slug_path"`  // Slugs desde la raíz: "camaras/mirrorless"
+	Depth       int        `gorm:"not null" json:"depth"`      // 0 = raíz
+	Description string     `gorm:"type:text" json:"description" validate:"max=1000"`
+	ImageURL    string     `json:"image_url" validate:"omitempty,url"`
+	Order       int        `json:"order" validate:"gte=0"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Products    []Product  `gorm:"foreignKey:CategoryID" json:"products,omitempty"`
+	Children    []Category `gorm:"-" json:"children,omitempty"` // Solo en el árbol y en su página
+}
+
+// URL ruta pública de la categoría: /productos/ y los slugs desde la raíz
+func (c Category) URL() string {
+	return "/productos/" + c.SlugPath
+}
+
+// PathIDs ids de Path, de la categoría raíz a ella misma
+func (c Category) PathIDs() []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.Trim(c.Path, "/"), "/") {
+		if id, err := strconv.ParseUint(part, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
 }
 
 type Product struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	CategoryID  uint      `json:"category_id" validate:"required"`
-	Name        string    `gorm:"not null" json:"name" validate:"required,min=1,max=200"`
-	Slug        string    `gorm:"uniqueIndex;not null" json:"slug" validate:"required,min=1,max=200,alphanum"`
-	Description string    `gorm:"type:text" json:"description" validate:"max=2000"`
-	Price       float64   `json:"price" validate:"required,gte=0"`
-	ImageURLs   []string  `gorm:"type:text[]" json:"image_urls" validate:"omitempty,dive,url"`
-	Features    string    `gorm:"type:text" json:"features" validate:"max=1000"`
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	CategoryID  uint         `json:"category_id" validate:"required"`
+	Name        string       `gorm:"not null" json:"name" validate:"required,min=1,max=200"`
+	Slug        string       `gorm:"uniqueIndex;not null" json:"slug" validate:"required,min=1,max=200,slug"`
+	Description string       `gorm:"type:text" json:"description" validate:"max=2000"`
+	Price       money.Amount `json:"price" validate:"required,gte=0"` // En la moneda base
+	ImageURLs   []string     `gorm:"type:text[]" json:"image_urls" validate:"omitempty,dive,url"`
+	Features    string       `gorm:"type:text" json:"features" validate:"max=1000"`
+	Active      bool         `json:"active"`
+	// Inventario: Stock solo cambia con los ajustes de existencias y, con variantes
+	// activas, StockStatus es el de la variante más disponible
+	StockPolicy       string    `gorm:"not null;default:untracked" json:"stock_policy" validate:"omitempty,oneof=untracked tracked backorder made_to_order"`
+	Stock             int       `gorm:"not null;default:0" json:"stock"`
+	LowStockThreshold int       `gorm:"not null;default:0" json:"low_stock_threshold" validate:"gte=0"`
+	StockStatus       string    `gorm:"not null;default:in_stock" json:"stock_status"` // Calculado con StockStatusOf
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Category          Category  `gorm:"foreignKey:CategoryID" json:"category,omitempty" validate:"-"`
+	// Attributes valores de los atributos de la categoría; nil al actualizar conserva los guardados
+	Attributes []ProductAttribute `gorm:"foreignKey:ProductID" json:"attributes,omitempty" validate:"-"`
+	// Variants variantes por orden; se gestionan aparte y, si hay alguna activa, Price es
+	// el precio "desde" (el menor de las activas)
+	Variants []ProductVariant `gorm:"foreignKey:ProductID" json:"variants,omitempty" validate:"-"`
+	// Tags etiquetas por nombre; al guardar, las que no existen se crean y nil al
+	// actualizar conserva las guardadas
+	Tags []Tag `gorm:"many2many:product_tags" json:"tags,omitempty" validate:"-"`
+	// Pricing precio en la moneda pedida, solo en las respuestas públicas
+	Pricing *Pricing `gorm:"-" json:"pricing,omitempty" validate:"-"`
+}
+
+// Tag etiqueta libre de los productos; un producto puede tener varias
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name" validate:"required,min=1,max=50"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug" validate:"required,min=1,max=50,slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductTag etiqueta de un producto (tabla product_tags)
+type ProductTag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID uint      `json:"product_id"`
+	TagID     uint      `json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Tipos de colección
+const (
+	CollectionManual = "manual" // Productos elegidos y ordenados a mano
+	CollectionSmart  = "smart"  // Productos que cumplen sus reglas
+)
+
+// Collection agrupación de productos de cualquier categoría ("Ofertas", "Nuevos"):
+// elegidos a mano (manual) o los que cumplen Rules (smart)
+type Collection struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Name        string           `gorm:"not null" json:"name" validate:"required,min=1,max=100"`
+	Slug        string           `gorm:"uniqueIndex;not null" json:"slug" validate:"required,min=1,max=100,slug"`
+	Description string           `gorm:"type:text" json:"description" validate:"max=1000"`
+	ImageURL    string           `json:"image_url" validate:"omitempty,url"`
+	Type        string           `gorm:"not null;default:manual" json:"type" validate:"required,oneof=manual smart"`
+	Rules       *CollectionRules `gorm:"type:jsonb;serializer:json" json:"rules,omitempty"` // Solo smart
+	// Sort orden de los productos de una colección smart ("-created_at" por defecto);
+	// las manuales siguen el suyo
+	Sort        string    `gorm:"not null;default:''" json:"sort" validate:"omitempty,oneof=created_at -created_at price -price name -name"`
+	MaxProducts int       `gorm:"not null;default:0" json:"max_products" validate:"gte=0,lte=100"` // 0 = todos
+	Order       int       `json:"order" validate:"gte=0"`
 	Active      bool      `json:"active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
-	Category    Category  `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Products    []Product `gorm:"-" json:"products,omitempty"` // Solo en su página
+}
+
+// URL ruta pública de la colección
+func (c Collection) URL() string {
+	return "/colecciones/" + c.Slug
+}
+
+// CollectionRules condiciones de una colección smart; un producto entra si cumple todas
+type CollectionRules struct {
+	CategoryID    uint          `json:"category_id,omitempty"`                          // Incluye sus subcategorías
+	TagID         uint          `json:"tag_id,omitempty"`                               // Con esta etiqueta
+	MinPrice      *money.Amount `json:"min_price,omitempty" validate:"omitempty,gte=0"` // En la moneda base (inclusivo)
+	MaxPrice      *money.Amount `json:"max_price,omitempty" validate:"omitempty,gte=0"` // En la moneda base (inclusivo)
+	NewerThanDays int           `json:"newer_than_days,omitempty" validate:"gte=0"`     // Creados en los últimos N días
+	InStock       bool          `json:"in_stock,omitempty"`                             // Sin los agotados
+}
+
+// CollectionProduct producto de una colección manual en su posición
+type CollectionProduct struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CollectionID uint      `json:"collection_id"`
+	ProductID    uint      `json:"product_id"`
+	Position     int       `gorm:"not null" json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Tipos de relación entre productos
+const (
+	RelationRelated     = "related"     // Parecido o complementario
+	RelationAccessory   = "accessory"   // Accesorio del producto
+	RelationAlternative = "alternative" // En lugar del producto
+	RelationUpgrade     = "upgrade"     // Versión superior
+)
+
+// ProductRelation relación elegida en el CMS entre un producto y otro (RelatedID),
+// en su posición dentro de las de su tipo
+type ProductRelation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID uint      `json:"product_id"`
+	RelatedID uint      `gorm:"not null" json:"related_id" validate:"required"`
+	Type      string    `gorm:"size:20;not null" json:"type" validate:"required,oneof=related accessory alternative upgrade"`
+	Position  int       `gorm:"not null" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductVariant versión vendible de un producto (talla, acabado...) con su propio
+// SKU, único en todo el catálogo
+type ProductVariant struct {
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	ProductID      uint              `json:"product_id" validate:"required"`
+	SKU            string            `gorm:"column:sku;uniqueIndex;not null" json:"sku" validate:"required,min=1,max=64"`
+	Options        map[string]string `gorm:"type:jsonb;serializer:json" json:"options" validate:"dive,keys,min=1,max=50,endkeys,min=1,max=100"` // Ej. {"talla": "L", "color": "Negro"}
+	Price          money.Amount      `json:"price" validate:"required,gte=0"`                                                                   // En la moneda base
+	CompareAtPrice money.Amount      `json:"compare_at_price" validate:"gte=0"`                                                                 // Precio anterior tachado; 0 = sin él
+	ImageURLs      []string          `gorm:"type:text[]" json:"image_urls" validate:"omitempty,dive,url"`
+	Active         bool              `json:"active"`
+	Order          int               `json:"order" validate:"gte=0"`
+	// Inventario propio de la variante (ver Product)
+	StockPolicy       string    `gorm:"not null;default:untracked" json:"stock_policy" validate:"omitempty,oneof=untracked tracked backorder made_to_order"`
+	Stock             int       `gorm:"not null;default:0" json:"stock"`
+	LowStockThreshold int       `gorm:"not null;default:0" json:"low_stock_threshold" validate:"gte=0"`
+	StockStatus       string    `gorm:"not null;default:in_stock" json:"stock_status"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Pricing           *Pricing  `gorm:"-" json:"pricing,omitempty" validate:"-"`
+}
+
+// Label opciones ordenadas por nombre, como "color: Negro, talla: L"
+func (v ProductVariant) Label() string {
+	parts := make([]string, 0, len(v.Options))
+	for name, value := range v.Options {
+		parts = append(parts, name+": "+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// Availability disponibilidad para mostrar ("Disponible", "Agotado"...)
+func (p Product) Availability() string {
+	return AvailabilityLabel(p.StockStatus)
+}
+
+// Availability disponibilidad de la variante para mostrar
+func (v ProductVariant) Availability() string {
+	return AvailabilityLabel(v.StockStatus)
+}
+
+// Políticas de inventario de productos y variantes
+const (
+	StockUntracked   = "untracked"     // Sin control de existencias: siempre disponible
+	StockTracked     = "tracked"       // Se agota al llegar a 0
+	StockBackorder   = "backorder"     // Sin existencias se sigue vendiendo bajo pedido
+	StockMadeToOrder = "made_to_order" // Se fabrica por encargo: no lleva existencias
+)
+
+// Estados de disponibilidad (StockStatus)
+const (
+	StockStatusInStock     = "in_stock"
+	StockStatusLow         = "low_stock"
+	StockStatusOut         = "out_of_stock"
+	StockStatusBackorder   = "backorder"
+	StockStatusMadeToOrder = "made_to_order"
+)
+
+// StockStatuses estados de disponibilidad, de más a menos disponible
+var StockStatuses = []string{StockStatusInStock, StockStatusLow, StockStatusMadeToOrder, StockStatusBackorder, StockStatusOut}
+
+// availabilityLabels textos de los estados de disponibilidad
+var availabilityLabels = map[string]string{
+	StockStatusInStock:     "Disponible",
+	StockStatusLow:         "Últimas unidades",
+	StockStatusOut:         "Agotado",
+	StockStatusBackorder:   "Bajo pedido",
+	StockStatusMadeToOrder: "Fabricación por encargo",
+}
+
+// AvailabilityLabel texto del estado de disponibilidad; vacío si no se conoce
+func AvailabilityLabel(status string) string {
+	return availabilityLabels[status]
+}
+
+// StockStatusOf estado de disponibilidad según la política, las existencias y el umbral
+// de pocas existencias (0 = sin aviso)
+func StockStatusOf(policy string, stock, lowStockThreshold int) string {
+	switch policy {
+	case StockTracked, StockBackorder:
+		if stock <= 0 && policy == StockBackorder {
+			return StockStatusBackorder
+		}
+		if stock <= 0 {
+			return StockStatusOut
+		}
+		if stock <= lowStockThreshold {
+			return StockStatusLow
+		}
+		return StockStatusInStock
+	case StockMadeToOrder:
+		return StockStatusMadeToOrder
+	default:
+		return StockStatusInStock
+	}
+}
+
+// Motivos de los movimientos de existencias
+const (
+	StockRestock    = "restock"    // Entrada de mercancía
+	StockSale       = "sale"       // Venta fuera del sitio
+	StockReturn     = "return"     // Devolución
+	StockDamage     = "damage"     // Merma o rotura
+	StockCorrection = "correction" // Corrección de un error
+	StockCount      = "count"      // Recuento de inventario
+)
+
+// StockMovement cambio de las existencias de un producto o de una de sus variantes,
+// con la cantidad resultante y quién lo hizo
+type StockMovement struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID uint      `json:"product_id"`
+	VariantID *uint     `json:"variant_id"` // nil = el producto
+	Delta     int       `json:"delta"`
+	Quantity  int       `json:"quantity"` // Existencias tras el movimiento
+	Reason    string    `gorm:"not null" json:"reason"`
+	Note      string    `gorm:"type:text" json:"note"`
+	UserID    *uint     `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Currency moneda en la que se muestran los precios, con un tipo de cambio mantenido
+// a mano respecto a la moneda base (la de Product.Price)
+//
+// Hay una única moneda base para todo el catálogo: Product.Price y ProductVariant.Price
+// no llevan código de moneda porque siempre están en ella, y por eso la base no se puede
+// cambiar una vez creada. Los precios en otra moneda son filas de ProductPrice, que sí
+// llevan su código
+type Currency struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Code      string     `gorm:"uniqueIndex;not null" json:"code" validate:"required,len=3,alpha"` // ISO 4217 (MXN, USD, EUR)
+	Name      string     `gorm:"not null" json:"name" validate:"required,min=1,max=100"`
+	Symbol    string     `gorm:"not null" json:"symbol" validate:"required,min=1,max=10"`
+	Locale    string     `gorm:"not null" json:"locale" validate:"required"` // Formato: money.Locales
+	Decimals  int        `json:"decimals" validate:"gte=0,lte=2"`
+	Rate      money.Rate `json:"rate" validate:"required,gt=0"` // Unidades por unidad de la moneda base
+	Base      bool       `json:"base"`
+	Active    bool       `json:"active"`
+	Order     int        `json:"order" validate:"gte=0"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Format importe con el símbolo, el locale y los decimales de la moneda
+func (c Currency) Format(amount money.Amount) string {
+	return money.Format(amount, c.Symbol, c.Locale, c.Decimals)
+}
+
+// ProductPrice precio fijado a mano en una moneda para un producto o una de sus
+// variantes; sustituye a la conversión con el tipo de cambio
+type ProductPrice struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	ProductID      uint         `json:"product_id"`
+	VariantID      *uint        `json:"variant_id"` // nil = el producto
+	Currency       string       `gorm:"column:currency_code;not null" json:"currency" validate:"required,len=3"`
+	Price          money.Amount `json:"price" validate:"required,gte=0"`
+	CompareAtPrice money.Amount `json:"compare_at_price" validate:"gte=0"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// Pricing precio de un producto o una variante en una moneda, listo para mostrar
+type Pricing struct {
+	Currency           string       `json:"currency"`
+	Amount             money.Amount `json:"amount"`
+	Formatted          string       `json:"formatted"` // Ej. "$1,234.50"
+	CompareAt          money.Amount `json:"compare_at,omitempty"`
+	CompareAtFormatted string       `json:"compare_at_formatted,omitempty"`
+	From               bool         `json:"from,omitempty"` // Precio "desde": las variantes tienen precios distintos
+}
+
+// Tipos de atributo
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
+)
+
+// AttributeDefinition atributo tipado de los productos de una categoría
+type AttributeDefinition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CategoryID uint      `json:"category_id" validate:"required"`
+	Name       string    `gorm:"not null" json:"name" validate:"required,min=1,max=100"`
+	Key        string    `gorm:"not null" json:"key" validate:"required,min=1,max=100,slug"`
+	Type       string    `gorm:"not null" json:"type" validate:"required,oneof=text number enum boolean"`
+	Unit       string    `json:"unit" validate:"max=20"`                                             // Solo number (ej. "kg", "MP")
+	Options    []string  `gorm:"type:text[]" json:"options" validate:"omitempty,dive,min=1,max=100"` // Solo enum
+	Required   bool      `json:"required"`
+	Filterable bool      `json:"filterable"` // Se ofrece como faceta en los listados
+	Order      int       `json:"order" validate:"gte=0"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ProductAttribute valor de un atributo en un producto, en forma canónica
+// (números sin ceros sobrantes, booleanos "true"/"false", opciones tal como se definieron)
+type ProductAttribute struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	ProductID   uint                `json:"product_id"`
+	AttributeID uint                `json:"attribute_id"`
+	Value       string              `gorm:"not null" json:"value"`
+	Number      *float64            `gorm:"column:number_value" json:"-"` // Value de los atributos number
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Attribute   AttributeDefinition `gorm:"foreignKey:AttributeID" json:"attribute,omitempty"`
+}
+
+// Display valor para mostrar: con la unidad en los números y Sí/No en los booleanos
+func (a ProductAttribute) Display() string {
+	switch a.Attribute.Type {
+	case AttributeBoolean:
+		if a.Value == "true" {
+			return "Sí"
+		}
+		return "No"
+	case AttributeNumber:
+		if a.Attribute.Unit != "" {
+			return a.Value + " " + a.Attribute.Unit
+		}
+	}
+	return a.Value
 }
 
 type ContactInfo struct {
@@ -75,6 +436,21 @@ type User struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// AuditEvent registra quién cambió qué en el CMS, con el estado antes y después
+type AuditEvent struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	UserID     *uint           `gorm:"index" json:"user_id"`
+	Username   string          `json:"username"`
+	Role       string          `json:"role"`
+	IP         string          `json:"ip"`
+	RequestID  string          `gorm:"index" json:"request_id"`
+	Action     string          `gorm:"not null;index" json:"action"`
+	EntityType string          `gorm:"not null;index:idx_audit_events_entity" json:"entity_type"`
+	EntityID   string          `gorm:"index:idx_audit_events_entity" json:"entity_id"`
+	Diff       json.RawMessage `gorm:"type:jsonb" json:"diff"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+}
+
 // GetUsername retorna el nombre de usuario
 func (u *User) GetUsername() string {
 	return u.Username
@@ -0,0 +1,93 @@
+package money
+
+import (
+	"sort"
+	"strings"
+)
+
+// ========================================
+// FORMATO POR IDIOMA Y REGIÓN
+// ========================================
+
+// localeFormat separadores y posición del símbolo de un locale
+type localeFormat struct {
+	thousands string
+	decimal   string
+	pattern   string // "¤#", "¤ #" o "# ¤": símbolo e importe
+}
+
+// locales formatos admitidos en Currency.Locale
+var locales = map[string]localeFormat{
+	"es-AR": {".", ",", "¤ #"},
+	"es-CL": {".", ",", "¤#"},
+	"es-CO": {".", ",", "¤ #"},
+	"es-ES": {".", ",", "# ¤"},
+	"es-MX": {",", ".", "¤#"},
+	"es-US": {",", ".", "¤#"},
+	"en-GB": {",", ".", "¤#"},
+	"en-US": {",", ".", "¤#"},
+	"pt-BR": {".", ",", "¤ #"},
+}
+
+// DefaultLocale formato de las monedas sin locale conocido
+const DefaultLocale = "es-MX"
+
+// Locales locales admitidos, ordenados
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidLocale indica si el locale está admitido
+func ValidLocale(locale string) bool {
+	_, ok := locales[locale]
+	return ok
+}
+
+// Format importe con los separadores del locale, el símbolo de la moneda y sus decimales
+// Parámetros:
+//   - amount: Importe (se redondea a decimals)
+//   - symbol: Símbolo de la moneda ("$", "€"); vacío para solo el número
+//   - locale: Locale de Locales (DefaultLocale si no se conoce)
+//   - decimals: Decimales a mostrar (0 a 2)
+//
+// Retorna: Texto como "$1,234.50" (es-MX) o "1.234,50 €" (es-ES)
+func Format(amount Amount, symbol, locale string, decimals int) string {
+	format, ok := locales[locale]
+	if !ok {
+		format = locales[DefaultLocale]
+	}
+	decimals = min(max(decimals, 0), amountScale)
+
+	text := formatFixed(int64(amount.Round(decimals)), amountScale)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	number := groupThousands(whole, format.thousands)
+	if decimals > 0 {
+		number += format.decimal + fraction[:decimals]
+	}
+	if symbol == "" {
+		return sign + number
+	}
+	return sign + strings.Replace(strings.Replace(format.pattern, "¤", symbol, 1), "#", number, 1)
+}
+
+// groupThousands separa los miles de la parte entera
+func groupThousands(whole, separator string) string {
+	if len(whole) <= 3 {
+		return whole
+	}
+	var parts []string
+	for len(whole) > 3 {
+		parts = append([]string{whole[len(whole)-3:]}, parts...)
+		whole = whole[:len(whole)-3]
+	}
+	return strings.Join(append([]string{whole}, parts...), separator)
+}
@@ -0,0 +1,268 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ========================================
+// IMPORTES EXACTOS
+// ========================================
+
+// Amount importe exacto en centésimas (1234.50 = 123450), como DECIMAL(10,2).
+// En JSON es un número con dos decimales y en SQL un texto decimal, así que nunca
+// pasa por float64.
+type Amount int64
+
+// amountScale decimales de Amount
+const amountScale = 2
+
+// ErrInvalidAmount el texto no es un importe con como máximo dos decimales
+var ErrInvalidAmount = errors.New("importe inválido: se esperan como máximo 2 decimales")
+
+// Units importe de unidades enteras (Units(120) = 120.00)
+func Units(units int64) Amount {
+	return Amount(units * 100)
+}
+
+// ParseAmount lee un importe decimal ("1234.5", "-3", "0.05"); acepta coma decimal
+// Retorna: Importe o ErrInvalidAmount si tiene más de dos decimales o no es un número
+func ParseAmount(text string) (Amount, error) {
+	value, err := parseFixed(text, amountScale)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	return Amount(value), nil
+}
+
+// String forma decimal canónica ("1234.50")
+func (a Amount) String() string {
+	return formatFixed(int64(a), amountScale)
+}
+
+// MarshalJSON número JSON con dos decimales
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON acepta un número o un texto decimal
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" {
+		return nil
+	}
+	value, err := ParseAmount(text)
+	if err != nil {
+		return err
+	}
+	*a = value
+	return nil
+}
+
+// Value columna DECIMAL como texto
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan lee una columna DECIMAL (texto, bytes, entero o float)
+func (a *Amount) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case int64:
+		*a = Units(value)
+		return nil
+	case float64:
+		// Solo algunos drivers devuelven NUMERIC como float; se redondea a centésimas
+		text := strconv.FormatFloat(value, 'f', amountScale, 64)
+		return a.Scan(text)
+	case []byte:
+		return a.Scan(string(value))
+	case string:
+		parsed, err := ParseAmount(value)
+		if err != nil {
+			return fmt.Errorf("money: %q: %w", value, err)
+		}
+		*a = parsed
+		return nil
+	}
+	return fmt.Errorf("money: no se puede leer %T como importe", src)
+}
+
+// ========================================
+// TIPOS DE CAMBIO
+// ========================================
+
+// Rate tipo de cambio exacto en millonésimas: unidades de una moneda por unidad de
+// la moneda base (1.085 = 1085000), como NUMERIC(18,6)
+type Rate int64
+
+// rateScale decimales de Rate
+const rateScale = 6
+
+// One tipo de cambio de la moneda base
+const One = Rate(1000000)
+
+// ErrInvalidRate el texto no es un tipo de cambio con como máximo seis decimales
+var ErrInvalidRate = errors.New("tipo de cambio inválido: se esperan como máximo 6 decimales")
+
+// ParseRate lee un tipo de cambio decimal ("17.25"); acepta coma decimal
+func ParseRate(text string) (Rate, error) {
+	value, err := parseFixed(text, rateScale)
+	if err != nil {
+		return 0, ErrInvalidRate
+	}
+	return Rate(value), nil
+}
+
+// String forma decimal sin ceros sobrantes ("17.25")
+func (r Rate) String() string {
+	text := formatFixed(int64(r), rateScale)
+	text = strings.TrimRight(text, "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+// MarshalJSON número JSON
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON acepta un número o un texto decimal
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" {
+		return nil
+	}
+	value, err := ParseRate(text)
+	if err != nil {
+		return err
+	}
+	*r = value
+	return nil
+}
+
+// Value columna NUMERIC como texto
+func (r Rate) Value() (driver.Value, error) {
+	return formatFixed(int64(r), rateScale), nil
+}
+
+// Scan lee una columna NUMERIC (texto, bytes, entero o float)
+func (r *Rate) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*r = 0
+		return nil
+	case int64:
+		*r = Rate(value) * One
+		return nil
+	case float64:
+		return r.Scan(strconv.FormatFloat(value, 'f', rateScale, 64))
+	case []byte:
+		return r.Scan(string(value))
+	case string:
+		parsed, err := ParseRate(value)
+		if err != nil {
+			return fmt.Errorf("money: %q: %w", value, err)
+		}
+		*r = parsed
+		return nil
+	}
+	return fmt.Errorf("money: no se puede leer %T como tipo de cambio", src)
+}
+
+// Convert convierte un importe entre monedas con sus tipos de cambio respecto a la base
+// y lo redondea (mitad hacia arriba) a los decimales de la moneda de destino
+// Parámetros:
+//   - amount: Importe en la moneda de origen
+//   - from: Tipo de cambio de la moneda de origen (One si es la base)
+//   - to: Tipo de cambio de la moneda de destino
+//   - decimals: Decimales de la moneda de destino (0 a 2)
+func Convert(amount Amount, from, to Rate, decimals int) Amount {
+	if from <= 0 {
+		return 0
+	}
+	// amount * to / from con enteros grandes: sin desbordamiento ni redondeos intermedios
+	value := new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(int64(to)))
+	return Amount(divRound(value, big.NewInt(int64(from)))).Round(decimals)
+}
+
+// Round redondea (mitad hacia arriba) a 0, 1 o 2 decimales
+func (a Amount) Round(decimals int) Amount {
+	if decimals >= amountScale || decimals < 0 {
+		return a
+	}
+	step := int64(1)
+	for i := decimals; i < amountScale; i++ {
+		step *= 10
+	}
+	return Amount(divRound(big.NewInt(int64(a)), big.NewInt(step)) * step)
+}
+
+// divRound cociente redondeado, las mitades lejos de cero
+func divRound(a, b *big.Int) int64 {
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(new(big.Int).Abs(b)) >= 0 {
+		if a.Sign()*b.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+// ========================================
+// DECIMALES DE ESCALA FIJA
+// ========================================
+
+// parseFixed lee un decimal como entero escalado (scale decimales)
+func parseFixed(text string, scale int) (int64, error) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", ".")
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || len(fraction) > scale || !digits(whole) || !digits(fraction) {
+		return 0, strconv.ErrSyntax
+	}
+	fraction += strings.Repeat("0", scale-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+	value, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// formatFixed entero escalado como decimal con scale decimales
+func formatFixed(value int64, scale int) string {
+	sign := ""
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	text := strconv.FormatInt(value, 10)
+	if len(text) <= scale {
+		text = strings.Repeat("0", scale-len(text)+1) + text
+	}
+	return sign + text[:len(text)-scale] + "." + text[len(text)-scale:]
+}
+
+// digits solo dígitos ASCII (vacío incluido)
+func digits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
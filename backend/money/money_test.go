@@ -0,0 +1,115 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	valid := map[string]Amount{
+		"1234.5": 123450,
+		"0,05":   5,
+		"-3":     -300,
+		".5":     50,
+		"7.":     700,
+		" 10 ":   1000,
+	}
+	for text, want := range valid {
+		if got, err := ParseAmount(text); err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %d, %v; se esperaba %d", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"", "1.234", "1e3", "abc", "1.2.3", "--1", "."} {
+		if _, err := ParseAmount(text); err == nil {
+			t.Errorf("ParseAmount(%q) debería fallar", text)
+		}
+	}
+}
+
+func TestAmountJSONAndSQL(t *testing.T) {
+	var value struct {
+		Price Amount `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": 0.1}`), &value); err != nil || value.Price != 10 {
+		t.Fatalf("Unmarshal = %d, %v", value.Price, err)
+	}
+	if err := json.Unmarshal([]byte(`{"price": "19.99"}`), &value); err != nil || value.Price != 1999 {
+		t.Fatalf("Unmarshal texto = %d, %v", value.Price, err)
+	}
+	if err := json.Unmarshal([]byte(`{"price": 0.125}`), &value); err == nil {
+		t.Error("tres decimales deberían fallar")
+	}
+	raw, _ := json.Marshal(struct{ Price Amount }{Units(1234) + 5})
+	if string(raw) != `{"Price":1234.05}` {
+		t.Errorf("Marshal = %s", raw)
+	}
+
+	scans := []struct {
+		src  interface{}
+		want Amount
+	}{
+		{"950.00", 95000},
+		{[]byte("0.30"), 30},
+		{int64(4), 400},
+		{12.345, 1235},
+	}
+	for _, c := range scans {
+		var scanned Amount
+		if err := scanned.Scan(c.src); err != nil || scanned != c.want {
+			t.Errorf("Scan(%v) = %d, %v; se esperaba %d", c.src, scanned, err, c.want)
+		}
+	}
+	if value, _ := Amount(-5).Value(); value != "-0.05" {
+		t.Errorf("Value = %v", value)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	eur, _ := ParseRate("0.046512")
+	usd, _ := ParseRate("0.05")
+	cases := []struct {
+		amount   Amount
+		from, to Rate
+		decimals int
+		want     Amount
+	}{
+		{Units(100), One, usd, 2, Units(5)},
+		{Units(999), One, eur, 2, 4647}, // 46.465488
+		{Units(5), usd, One, 2, Units(100)},
+		{Units(5), usd, eur, 2, 465},
+		{12345, One, One, 0, Units(123)},
+		{12350, One, One, 0, Units(124)},
+		{-12350, One, One, 0, -Units(124)},
+	}
+	for _, c := range cases {
+		if got := Convert(c.amount, c.from, c.to, c.decimals); got != c.want {
+			t.Errorf("Convert(%s, %s, %s, %d) = %s; se esperaba %s", c.amount, c.from, c.to, c.decimals, got, c.want)
+		}
+	}
+	if eur.String() != "0.046512" || One.String() != "1" {
+		t.Errorf("Rate.String = %s, %s", eur, One)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		amount   Amount
+		symbol   string
+		locale   string
+		decimals int
+		want     string
+	}{
+		{123450, "$", "es-CL", 2, "$1.234,50"},
+		{123450, "$", "es-MX", 2, "$1,234.50"},
+		{123450, "€", "es-ES", 2, "1.234,50 €"},
+		{123450, "$", "es-AR", 0, "$ 1.235"},
+		{-99, "$", "en-US", 2, "-$0.99"},
+		{Units(1000000), "", "es-ES", 2, "1.000.000,00"},
+		{500, "$", "xx-YY", 2, "$5.00"},
+	}
+	for _, c := range cases {
+		if got := Format(c.amount, c.symbol, c.locale, c.decimals); got != c.want {
+			t.Errorf("Format(%s, %s) = %q; se esperaba %q", c.amount, c.locale, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package pagination
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/repository"
+	"website/backend/services"
+	"website/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ========================================
+// LENGUAJE DE CONSULTA DE LOS LISTADOS
+// ========================================
+//
+// Todos los listados de la API pública y del CMS aceptan:
+//   - limit: filas por página (DefaultLimit por defecto, MaxLimit como máximo)
+//   - page: página desde 1 (paginación por páginas, la predeterminada)
+//   - cursor: paginación por cursor; vacío para la primera página y después el
+//     next_cursor de la respuesta. No se combina con page
+//   - sort: columna de Resource.Sorts; con "-" delante en orden descendente
+//   - active: true/false (solo listados del CMS, ver Resource.WithActive)
+//   - category_id, category (slug), min_price, max_price, has_image: solo productos
+//     (los precios en la moneda base; la API pública los convierte desde su currency).
+//     Una categoría incluye los productos de todas sus subcategorías
+//   - tag: solo productos; slug de una etiqueta
+//   - stock_status: solo productos; estados de disponibilidad separados por comas
+//     (in_stock, low_stock, out_of_stock, backorder, made_to_order)
+//   - attr.<key>: solo productos; valores separados por comas (alguno de ellos) o, en los
+//     atributos numéricos, un rango min..max (se puede omitir un extremo)
+//
+// Los listados por relevancia (Resource.Ranked, como la búsqueda) solo admiten limit y page.
+//
+// La respuesta es {data, meta, links} con las cabeceras X-Total-Count y Link (RFC 8288).
+
+const (
+	DefaultLimit = 20  // Filas por página si no se indica limit
+	MaxLimit     = 100 // Máximo de filas por página
+)
+
+// HeaderTotalCount cabecera con el total de filas que cumplen el filtro
+const HeaderTotalCount = "X-Total-Count"
+
+// Resource listado paginable: columnas de orden y filtros admitidos
+type Resource struct {
+	Sorts   []string        // Columnas admitidas en sort
+	Default repository.Sort // Orden sin sort
+	Catalog bool            // Admite los filtros de productos
+	Active  bool            // Admite el filtro active
+	Ranked  bool            // Ordenado por relevancia: sin sort ni cursor
+}
+
+// Listados del sitio
+var (
+	Slides      = Resource{Sorts: []string{"order", "title", "created_at", "id"}, Default: repository.SortByOrder}
+	Categories  = Resource{Sorts: []string{"order", "name", "created_at", "id"}, Default: repository.SortByOrder}
+	Products    = Resource{Sorts: []string{"price", "name", "created_at", "id"}, Default: repository.SortByNewest, Catalog: true}
+	Tags        = Resource{Sorts: []string{"name", "created_at", "id"}, Default: repository.SortByName}
+	Collections = Resource{Sorts: []string{"order", "name", "created_at", "id"}, Default: repository.SortByOrder}
+	Contacts    = Resource{Sorts: []string{"order", "type", "created_at", "id"}, Default: repository.SortByOrder}
+	Currencies  = Resource{Sorts: []string{"order", "code", "name", "created_at", "id"}, Default: repository.SortByOrder}
+	Users       = Resource{Sorts: []string{"username", "email", "created_at", "id"}, Default: repository.SortByID}
+	Search      = Resource{Ranked: true}
+)
+
+// WithActive copia del listado que admite el filtro active (el CMS ve los inactivos)
+func (r Resource) WithActive() Resource {
+	r.Active = true
+	return r
+}
+
+// Query listado pedido en la query string
+type Query struct {
+	Filter repository.Filter // Filtros, orden y paginación para el repositorio
+	Page   int               // Página pedida (paginación por páginas)
+	Cursor bool              // Paginación por cursor
+
+	path   string
+	values url.Values
+}
+
+// Parse lee la query string del listado
+// Parámetros:
+//   - c: Contexto de Fiber
+//   - resource: Columnas de orden y filtros admitidos
+//
+// Retorna: Query o *utils.ValidationError con todos los parámetros inválidos
+func Parse(c *fiber.Ctx, resource Resource) (Query, error) {
+	values, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	query := Query{path: c.Path(), values: values, Page: 1}
+	filter := &query.Filter
+	var problems []string
+
+	filter.Limit = DefaultLimit
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			problems = append(problems, "limit debe estar entre 1 y "+strconv.Itoa(MaxLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	filter.Sort = resource.Default
+	if _, ok := values["sort"]; ok && resource.Ranked {
+		problems = append(problems, "sort no se admite: los resultados se ordenan por relevancia")
+	} else if value := values.Get("sort"); value != "" {
+		sort := repository.Sort{Column: strings.TrimPrefix(value, "-"), Desc: strings.HasPrefix(value, "-")}
+		if !contains(resource.Sorts, sort.Column) {
+			problems = append(problems, "sort admite "+strings.Join(resource.Sorts, ", ")+" (con - para descendente)")
+		} else {
+			filter.Sort = sort
+		}
+	}
+
+	_, query.Cursor = values["cursor"]
+	if query.Cursor && resource.Ranked {
+		problems = append(problems, "cursor no se admite: use page")
+		query.Cursor = false
+		delete(values, "cursor")
+	}
+	if value := values.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		switch {
+		case query.Cursor:
+			problems = append(problems, "page y cursor no se pueden combinar")
+		case err != nil || page < 1:
+			problems = append(problems, "page debe ser un entero mayor que 0")
+		default:
+			query.Page = page
+		}
+	}
+	if token := values.Get("cursor"); token != "" {
+		after, err := repository.DecodeCursor(token, filter.Sort)
+		if err != nil {
+			problems = append(problems, "cursor inválido para este orden")
+		}
+		filter.After = after
+	}
+	if !query.Cursor {
+		filter.Offset = (query.Page - 1) * filter.Limit
+	}
+
+	if resource.Active {
+		filter.Active = parseBool(values, "active", &problems)
+	}
+	if resource.Catalog {
+		if value := values.Get("category_id"); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				problems = append(problems, "category_id inválido")
+			}
+			filter.CategoryID = uint(id)
+		}
+		filter.CategorySlug = values.Get("category")
+		filter.TagSlug = values.Get("tag")
+		filter.Subcategories = true
+		filter.MinPrice = parsePrice(values, "min_price", &problems)
+		filter.MaxPrice = parsePrice(values, "max_price", &problems)
+		if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+			problems = append(problems, "min_price no puede ser mayor que max_price")
+		}
+		filter.HasImage = parseBool(values, "has_image", &problems)
+		filter.StockStatus = parseStockStatus(values, &problems)
+		filter.Attributes = parseAttributes(values, &problems)
+	}
+
+	if len(problems) > 0 {
+		return query, &utils.ValidationError{Message: "Parámetros de listado inválidos", Details: problems}
+	}
+	return query, nil
+}
+
+// Key ruta y query normalizada (parámetros ordenados): identifica la respuesta
+func (q Query) Key() string {
+	if len(q.values) == 0 {
+		return q.path
+	}
+	return q.path + "?" + q.values.Encode()
+}
+
+// ========================================
+// RESPUESTA
+// ========================================
+
+// Envelope respuesta común de los listados
+type Envelope[T any] struct {
+	Data   []T               `json:"data"`
+	Meta   Meta              `json:"meta"`
+	Links  map[string]string `json:"links"`
+	Facets []services.Facet  `json:"facets,omitempty"` // Solo productos de una categoría
+}
+
+// Meta datos de la paginación
+type Meta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`  // Solo paginación por páginas
+	Pages      int    `json:"pages,omitempty"` // Solo paginación por páginas
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Send responde la página con el envoltorio y las cabeceras X-Total-Count y Link
+// Parámetros:
+//   - c: Contexto de Fiber
+//   - query: Listado pedido (de Parse)
+//   - page: Filas, total y cursor siguiente del servicio
+func Send[T any](c *fiber.Ctx, query Query, page services.Page[T]) error {
+	return SendFaceted(c, query, page, nil)
+}
+
+// SendFaceted igual que Send, con las facetas del listado
+func SendFaceted[T any](c *fiber.Ctx, query Query, page services.Page[T], facets []services.Facet) error {
+	envelope := Envelope[T]{
+		Data:   page.Items,
+		Meta:   Meta{Total: page.Total, Limit: query.Filter.Limit},
+		Links:  map[string]string{},
+		Facets: facets,
+	}
+	if envelope.Data == nil {
+		envelope.Data = []T{}
+	}
+	if page.Next != nil {
+		envelope.Meta.NextCursor = page.Next.Encode(query.Filter.Sort)
+	}
+
+	if query.Cursor {
+		envelope.Links["first"] = query.link("cursor", "")
+		if page.Next != nil {
+			envelope.Links["next"] = query.link("cursor", envelope.Meta.NextCursor)
+		}
+	} else {
+		limit := int64(query.Filter.Limit)
+		pages := int((page.Total + limit - 1) / limit)
+		envelope.Meta.Page, envelope.Meta.Pages = query.Page, pages
+		envelope.Links["first"] = query.link("page", "1")
+		if query.Page > 1 {
+			envelope.Links["prev"] = query.link("page", strconv.Itoa(min(query.Page-1, max(pages, 1))))
+		}
+		if query.Page < pages {
+			envelope.Links["next"] = query.link("page", strconv.Itoa(query.Page+1))
+		}
+		envelope.Links["last"] = query.link("page", strconv.Itoa(max(pages, 1)))
+	}
+
+	c.Set(HeaderTotalCount, strconv.FormatInt(page.Total, 10))
+	var links []string
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if href, ok := envelope.Links[rel]; ok {
+			links = append(links, "<"+href+`>; rel="`+rel+`"`)
+		}
+	}
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	return c.JSON(envelope)
+}
+
+// link URL del listado con otra página o cursor (relativa a la raíz, válida tras un proxy)
+func (q Query) link(param, value string) string {
+	values := url.Values{}
+	for key, list := range q.values {
+		if key != "page" && key != "cursor" {
+			values[key] = list
+		}
+	}
+	values.Set(param, value)
+	return q.path + "?" + values.Encode()
+}
+
+// AttributePrefix prefijo de los parámetros de filtro por atributo
+const AttributePrefix = "attr."
+
+// parseAttributes filtros attr.<key>, ordenados por key
+func parseAttributes(values url.Values, problems *[]string) []repository.AttributeFilter {
+	var filters []repository.AttributeFilter
+	for name := range values {
+		key, ok := strings.CutPrefix(name, AttributePrefix)
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(values.Get(name))
+		if key == "" || utils.GenerateSlug(key) != key || value == "" {
+			*problems = append(*problems, name+" inválido")
+			continue
+		}
+
+		filter := repository.AttributeFilter{Key: key}
+		if low, high, isRange := strings.Cut(value, ".."); isRange {
+			filter.Min = parseBound(low, name, problems)
+			filter.Max = parseBound(high, name, problems)
+			if filter.Min == nil && filter.Max == nil {
+				*problems = append(*problems, name+" debe ser un rango min..max")
+			}
+		} else {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					filter.Values = append(filter.Values, item)
+				}
+			}
+		}
+		filters = append(filters, filter)
+	}
+	sort.Slice(filters, func(i, j int) bool { return filters[i].Key < filters[j].Key })
+	return filters
+}
+
+// parseStockStatus estados de disponibilidad separados por comas
+func parseStockStatus(values url.Values, problems *[]string) []string {
+	var statuses []string
+	for _, item := range strings.Split(values.Get("stock_status"), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !contains(models.StockStatuses, item) {
+			*problems = append(*problems, "stock_status admite "+strings.Join(models.StockStatuses, ", "))
+			return nil
+		}
+		statuses = append(statuses, item)
+	}
+	return statuses
+}
+
+// parseBound extremo opcional de un rango
+func parseBound(value, name string, problems *[]string) *float64 {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		*problems = append(*problems, name+" debe ser un rango de números min..max")
+		return nil
+	}
+	return &number
+}
+
+// parseBool parámetro booleano opcional
+func parseBool(values url.Values, name string, problems *[]string) *bool {
+	value := values.Get(name)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		*problems = append(*problems, name+" debe ser true o false")
+		return nil
+	}
+	return &parsed
+}
+
+// parsePrice precio opcional no negativo en la moneda base, con como máximo dos decimales
+func parsePrice(values url.Values, name string, problems *[]string) *money.Amount {
+	value := values.Get(name)
+	if value == "" {
+		return nil
+	}
+	price, err := money.ParseAmount(value)
+	if err != nil || price < 0 {
+		*problems = append(*problems, name+" debe ser un número mayor o igual que 0")
+		return nil
+	}
+	return &price
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
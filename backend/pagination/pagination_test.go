@@ -0,0 +1,181 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"website/backend/middleware"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/repository"
+	"website/backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newCatalogApp /products over 7 products (two share a price) in two categories
+func newCatalogApp(t *testing.T) *fiber.App {
+	t.Helper()
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+
+	categories := []models.Category{
+		{Name: "Fotografía", Slug: "fotografia", Path: "/1/", SlugPath: "fotografia", Active: true},
+		{Name: "Vídeo", Slug: "video", Path: "/2/", SlugPath: "video", Active: true},
+	}
+	for i := range categories {
+		if err := store.Categories().Create(ctx, &categories[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, price := range []int64{30, 10, 20, 20, 50, 40, 5} {
+		product := models.Product{
+			CategoryID: categories[i%2].ID,
+			Name:       fmt.Sprintf("P%d", i+1),
+			Slug:       fmt.Sprintf("p%d", i+1),
+			Price:      money.Units(price),
+			Active:     i != 6,
+		}
+		if i < 3 {
+			product.ImageURLs = []string{"https://example.com/p.jpg"}
+		}
+		if err := store.Products().Create(ctx, &product); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc := services.New(store)
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Get("/products", func(c *fiber.Ctx) error {
+		query, err := Parse(c, Products)
+		if err != nil {
+			return err
+		}
+		page, err := svc.Products.ActivePage(c.UserContext(), query.Filter)
+		if err != nil {
+			return err
+		}
+		return Send(c, query, page)
+	})
+	return app
+}
+
+// fetch GETs a listing and returns its status, envelope and headers
+func fetch(t *testing.T, app *fiber.App, path string) (int, Envelope[models.Product], map[string]string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+
+	var envelope Envelope[models.Product]
+	if resp.StatusCode == 200 {
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			t.Fatalf("GET %s: %v\n%s", path, err, raw)
+		}
+	}
+	return resp.StatusCode, envelope, map[string]string{
+		HeaderTotalCount: resp.Header.Get(HeaderTotalCount),
+		"Link":           resp.Header.Get("Link"),
+	}
+}
+
+// productNames names of the products of a page
+func productNames(products []models.Product) string {
+	var names []string
+	for _, product := range products {
+		names = append(names, product.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestOffsetPagination(t *testing.T) {
+	app := newCatalogApp(t)
+
+	status, page, headers := fetch(t, app, "/products?sort=price&limit=2&page=2")
+	if status != 200 || productNames(page.Data) != "P4,P1" {
+		t.Fatalf("página 2 por precio = %d %q", status, productNames(page.Data))
+	}
+	if page.Meta.Total != 6 || page.Meta.Pages != 3 || headers[HeaderTotalCount] != "6" {
+		t.Errorf("meta = %+v, X-Total-Count %q", page.Meta, headers[HeaderTotalCount])
+	}
+	wantLink := `</products?limit=2&page=1&sort=price>; rel="first", </products?limit=2&page=1&sort=price>; rel="prev", ` +
+		`</products?limit=2&page=3&sort=price>; rel="next", </products?limit=2&page=3&sort=price>; rel="last"`
+	if headers["Link"] != wantLink {
+		t.Errorf("Link = %s", headers["Link"])
+	}
+	if page.Links["next"] != "/products?limit=2&page=3&sort=price" {
+		t.Errorf("links = %v", page.Links)
+	}
+
+	// Filtros combinados
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"sort=-price", "P5,P6,P1,P4,P3,P2"},
+		{"sort=name&category=video", "P2,P4,P6"},
+		{"sort=price&category_id=1&min_price=25", "P1,P5"},
+		{"sort=price&max_price=20&has_image=true", "P2,P3"},
+		{"sort=price&has_image=false", "P4,P6,P5"},
+	}
+	for _, tt := range tests {
+		if _, page, _ := fetch(t, app, "/products?"+tt.query); productNames(page.Data) != tt.want {
+			t.Errorf("%s = %q, se esperaba %q", tt.query, productNames(page.Data), tt.want)
+		}
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	app := newCatalogApp(t)
+
+	// Recorre todo el listado siguiendo next_cursor: sin repetir ni saltar los empates de precio
+	var seen []string
+	path := "/products?sort=-price&limit=2&cursor="
+	for i := 0; path != ""; i++ {
+		if i > 5 {
+			t.Fatal("demasiadas páginas")
+		}
+		status, page, headers := fetch(t, app, path)
+		if status != 200 || page.Meta.Total != 6 || page.Meta.Page != 0 {
+			t.Fatalf("%s = %d %+v", path, status, page.Meta)
+		}
+		seen = append(seen, productNames(page.Data))
+		path = page.Links["next"]
+		if (path == "") != (page.Meta.NextCursor == "") || (path != "" && !strings.Contains(headers["Link"], `rel="next"`)) {
+			t.Fatalf("next inconsistente: %v, Link %s", page.Links, headers["Link"])
+		}
+	}
+	if got := strings.Join(seen, "|"); got != "P5,P6|P1,P4|P3,P2" {
+		t.Errorf("páginas = %s", got)
+	}
+
+	// Un cursor de otro orden se rechaza
+	_, page, _ := fetch(t, app, "/products?sort=-price&limit=2&cursor=")
+	if status, _, _ := fetch(t, app, "/products?sort=price&cursor="+page.Meta.NextCursor); status != 400 {
+		t.Errorf("cursor de otro orden = %d, se esperaba 400", status)
+	}
+}
+
+func TestInvalidListingParameters(t *testing.T) {
+	app := newCatalogApp(t)
+
+	for _, query := range []string{
+		"limit=0", "limit=101", "page=0", "page=1&cursor=", "sort=order", "sort=password",
+		"cursor=xyz", "min_price=-1", "min_price=10&max_price=5", "has_image=quizá", "category_id=abc", "stock_status=agotado",
+	} {
+		if status, _, _ := fetch(t, app, "/products?"+query); status != 400 {
+			t.Errorf("%s = %d, se esperaba 400", query, status)
+		}
+	}
+	// active solo existe en los listados del CMS
+	if _, page, _ := fetch(t, app, "/products?active=false"); page.Meta.Total != 6 {
+		t.Errorf("active=false en la API pública = %d productos, se esperaban 6", page.Meta.Total)
+	}
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+	"website/backend/money"
+)
+
+// ========================================
+// COLUMNAS DE ORDEN
+// ========================================
+
+// columnKind tipo de los valores de una columna de orden
+type columnKind int
+
+const (
+	kindInt columnKind = iota
+	kindFloat
+	kindString
+	kindTime
+	kindMoney
+)
+
+// sortColumn campo del modelo y tipo de una columna de orden
+type sortColumn struct {
+	field string
+	kind  columnKind
+}
+
+// sortColumns columnas por las que se puede ordenar (y paginar por cursor)
+var sortColumns = map[string]sortColumn{
+	"id":         {"ID", kindInt},
+	"order":      {"Order", kindInt},
+	"created_at": {"CreatedAt", kindTime},
+	"updated_at": {"UpdatedAt", kindTime},
+	"name":       {"Name", kindString},
+	"title":      {"Title", kindString},
+	"type":       {"Type", kindString},
+	"code":       {"Code", kindString},
+	"price":      {"Price", kindMoney},
+	"username":   {"Username", kindString},
+	"email":      {"Email", kindString},
+}
+
+// ========================================
+// CURSORES
+// ========================================
+
+// ErrInvalidCursor el cursor está mal formado o se creó con otro orden
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// Cursor posición de la última fila de una página: valor de la columna de orden e id
+type Cursor struct {
+	Value interface{} // int64, float64, string, time.Time o money.Amount según la columna
+	ID    uint
+}
+
+// cursorToken forma serializada del cursor; incluye el orden para rechazar
+// cursores de otro listado
+type cursorToken struct {
+	Column string          `json:"c"`
+	Desc   bool            `json:"d,omitempty"`
+	Value  json.RawMessage `json:"v"`
+	ID     uint            `json:"i"`
+}
+
+// CursorOf cursor que apunta a item según el orden
+// Parámetros:
+//   - item: Última fila de la página (struct del modelo)
+//   - sort: Orden del listado
+func CursorOf(item interface{}, sort Sort) *Cursor {
+	value := reflect.Indirect(reflect.ValueOf(item))
+	column := sortColumns[sort.column()]
+	return &Cursor{
+		Value: value.FieldByName(column.field).Interface(),
+		ID:    uint(value.FieldByName("ID").Uint()),
+	}
+}
+
+// Encode cursor opaco para la URL
+func (c *Cursor) Encode(sort Sort) string {
+	value, _ := json.Marshal(c.Value)
+	raw, _ := json.Marshal(cursorToken{Column: sort.column(), Desc: sort.Desc, Value: value, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor lee un cursor de Encode
+// Parámetros:
+//   - token: Cursor opaco
+//   - sort: Orden del listado; debe coincidir con el del cursor
+//
+// Retorna: Cursor con el valor del tipo de la columna, o ErrInvalidCursor
+func DecodeCursor(token string, sort Sort) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var parsed cursorToken
+	if err := json.Unmarshal(raw, &parsed); err != nil || parsed.Column != sort.column() || parsed.Desc != sort.Desc {
+		return nil, ErrInvalidCursor
+	}
+
+	column, ok := sortColumns[parsed.Column]
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	var value interface{}
+	switch column.kind {
+	case kindInt:
+		var v int64
+		err = json.Unmarshal(parsed.Value, &v)
+		value = v
+	case kindFloat:
+		var v float64
+		err = json.Unmarshal(parsed.Value, &v)
+		value = v
+	case kindString:
+		var v string
+		err = json.Unmarshal(parsed.Value, &v)
+		value = v
+	case kindTime:
+		var v time.Time
+		err = json.Unmarshal(parsed.Value, &v)
+		value = v
+	case kindMoney:
+		var v money.Amount
+		err = json.Unmarshal(parsed.Value, &v)
+		value = v
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{Value: value, ID: parsed.ID}, nil
+}
+
+// compareColumn compara dos filas por una columna de orden (-1, 0, 1)
+func compareColumn(a, b reflect.Value, column string) int {
+	spec, ok := sortColumns[column]
+	if !ok {
+		return 0
+	}
+	fieldA, fieldB := a.FieldByName(spec.field), b.FieldByName(spec.field)
+	if !fieldA.IsValid() || !fieldB.IsValid() {
+		return 0
+	}
+	return compareValues(fieldA.Interface(), fieldB.Interface())
+}
+
+// compareValues compara valores del mismo tipo de columna (-1, 0, 1)
+func compareValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case time.Time:
+		y := b.(time.Time)
+		switch {
+		case x.Before(y):
+			return -1
+		case x.After(y):
+			return 1
+		}
+		return 0
+	case string:
+		return compareOrdered(x, b.(string))
+	case float64:
+		return compareOrdered(x, toFloat(b))
+	default:
+		return compareOrdered(toFloat(a), toFloat(b))
+	}
+}
+
+// toFloat número de cualquier tipo entero o decimal
+func toFloat(v interface{}) float64 {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	}
+	return 0
+}
+
+func compareOrdered[V int | float64 | string](a, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
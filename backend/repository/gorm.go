@@ -0,0 +1,933 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"website/backend/migrate"
+	"website/backend/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// uniqueViolation código de PostgreSQL para claves duplicadas
+const uniqueViolation = "23505"
+
+// GormStore repositorios sobre PostgreSQL con GORM
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore crea los repositorios sobre una conexión de GORM
+// Parámetros:
+//   - db: Conexión de GORM (o transacción)
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Slides repositorio de slides
+func (s *GormStore) Slides() SlideRepository {
+	return gormTable[models.Slide]{db: s.db}
+}
+
+// Categories repositorio de categorías
+func (s *GormStore) Categories() CategoryRepository {
+	return gormCategories{gormTable[models.Category]{db: s.db}}
+}
+
+// Products repositorio de productos
+func (s *GormStore) Products() ProductRepository {
+	return gormProducts{gormTable[models.Product]{
+		db:      s.db,
+		preload: []string{"Category", "Attributes.Attribute", "Variants", "Tags"},
+		order:   map[string]string{"Variants": `"order", id`, "Tags": "name, id"},
+	}}
+}
+
+// Variants repositorio de variantes de producto
+func (s *GormStore) Variants() VariantRepository {
+	return gormVariants{gormTable[models.ProductVariant]{db: s.db}}
+}
+
+// Currencies repositorio de monedas
+func (s *GormStore) Currencies() CurrencyRepository {
+	return gormCurrencies{gormTable[models.Currency]{db: s.db}}
+}
+
+// Prices repositorio de precios por moneda
+func (s *GormStore) Prices() PriceRepository {
+	return gormPrices{db: s.db}
+}
+
+// Relations repositorio de relaciones entre productos
+func (s *GormStore) Relations() RelationRepository {
+	return gormRelations{db: s.db}
+}
+
+// Stock repositorio del historial de existencias
+func (s *GormStore) Stock() StockRepository {
+	return gormStock{db: s.db}
+}
+
+// Attributes repositorio de atributos de producto
+func (s *GormStore) Attributes() AttributeRepository {
+	return gormAttributes{gormTable[models.AttributeDefinition]{db: s.db}}
+}
+
+// Tags repositorio de etiquetas
+func (s *GormStore) Tags() TagRepository {
+	return gormTags{gormTable[models.Tag]{db: s.db}}
+}
+
+// Collections repositorio de colecciones
+func (s *GormStore) Collections() CollectionRepository {
+	return gormCollections{gormTable[models.Collection]{db: s.db}}
+}
+
+// Contacts repositorio de contactos
+func (s *GormStore) Contacts() ContactRepository {
+	return gormTable[models.ContactInfo]{db: s.db}
+}
+
+// Configs repositorio de configuración
+func (s *GormStore) Configs() ConfigRepository {
+	return gormConfigs{db: s.db}
+}
+
+// Users repositorio de usuarios
+func (s *GormStore) Users() UserRepository {
+	return gormUsers{gormTable[models.User]{db: s.db}}
+}
+
+// Audit repositorio de auditoría
+func (s *GormStore) Audit() AuditRepository {
+	return gormAudit{db: s.db}
+}
+
+// BatchStats une las consultas agregadas con UNION ALL; cada fila lleva el índice de
+// su consulta porque el orden de UNION ALL no está garantizado
+func (s *GormStore) BatchStats(ctx context.Context, queries []StatsQuery) ([]Stats, error) {
+	if len(queries) == 0 {
+		return []Stats{}, nil
+	}
+	parts := make([]string, len(queries))
+	subqueries := make([]interface{}, len(queries))
+	for i, query := range queries {
+		parts[i] = "?"
+		if query.Audit != nil {
+			subqueries[i] = auditConditions(s.db.Model(&models.AuditEvent{}), *query.Audit).
+				Select(fmt.Sprintf("%d AS query, COUNT(*) AS count, MAX(created_at) AS last_modified", i))
+			continue
+		}
+		subqueries[i] = applyFilter(s.db.Model(query.Model), query.Filter, false).
+			Select(fmt.Sprintf("%d AS query, COUNT(*) AS count, MAX(updated_at) AS last_modified", i))
+	}
+
+	var rows []struct {
+		Query        int
+		Count        int64
+		LastModified sql.NullTime
+	}
+	if err := s.db.WithContext(ctx).Raw(strings.Join(parts, " UNION ALL "), subqueries...).Scan(&rows).Error; err != nil {
+		return nil, translate(err)
+	}
+	stats := make([]Stats, len(queries))
+	for _, row := range rows {
+		stats[row.Query] = Stats{Count: row.Count, LastModified: row.LastModified.Time}
+	}
+	return stats, nil
+}
+
+// SchemaVersion última migración registrada en schema_migrations
+func (s *GormStore) SchemaVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.db.WithContext(ctx).Raw("SELECT COALESCE(MAX(version), 0) FROM " + migrate.TableName).Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("error al leer la versión del esquema: %w", err)
+	}
+	return version, nil
+}
+
+// Transaction ejecuta fn dentro de una transacción de base de datos
+func (s *GormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	})
+}
+
+// Snapshot ejecuta fn en una transacción REPEATABLE READ de solo lectura
+func (s *GormStore) Snapshot(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// translate convierte los errores de GORM y PostgreSQL en los del paquete
+func translate(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return fmt.Errorf("%w: %s", ErrDuplicate, pgErr.ConstraintName)
+	default:
+		return err
+	}
+}
+
+// ========================================
+// TABLA GENÉRICA
+// ========================================
+
+// gormTable CRUD común a todas las entidades con id
+type gormTable[T any] struct {
+	db      *gorm.DB
+	preload []string          // Relaciones cargadas en Get y List
+	order   map[string]string // Orden de las relaciones de preload que lo necesitan
+}
+
+// query consulta con el contexto y las relaciones a cargar
+func (t gormTable[T]) query(ctx context.Context) *gorm.DB {
+	query := t.db.WithContext(ctx)
+	for _, relation := range t.preload {
+		if order, ok := t.order[relation]; ok {
+			query = query.Preload(relation, func(db *gorm.DB) *gorm.DB { return db.Order(order) })
+		} else {
+			query = query.Preload(relation)
+		}
+	}
+	return query
+}
+
+// Get busca por id
+func (t gormTable[T]) Get(ctx context.Context, id uint) (*T, error) {
+	var item T
+	if err := t.query(ctx).First(&item, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &item, nil
+}
+
+// Create inserta el registro sin tocar sus relaciones
+func (t gormTable[T]) Create(ctx context.Context, item *T) error {
+	return translate(t.db.WithContext(ctx).Omit(clause.Associations).Create(item).Error)
+}
+
+// Update guarda todos los campos del registro sin tocar sus relaciones
+func (t gormTable[T]) Update(ctx context.Context, item *T) error {
+	return translate(t.db.WithContext(ctx).Omit(clause.Associations).Save(item).Error)
+}
+
+// Delete elimina por id
+func (t gormTable[T]) Delete(ctx context.Context, id uint) error {
+	result := t.db.WithContext(ctx).Delete(new(T), id)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List lista según el filtro
+func (t gormTable[T]) List(ctx context.Context, filter Filter) ([]T, error) {
+	var items []T
+	err := applyFilter(t.query(ctx), filter, true).Find(&items).Error
+	return items, translate(err)
+}
+
+// Count cuenta según el filtro (sin límite)
+func (t gormTable[T]) Count(ctx context.Context, filter Filter) (int64, error) {
+	var count int64
+	err := applyFilter(t.db.WithContext(ctx).Model(new(T)), filter, false).Count(&count).Error
+	return count, translate(err)
+}
+
+// Stats cuenta y obtiene el mayor updated_at según el filtro (sin límite)
+func (t gormTable[T]) Stats(ctx context.Context, filter Filter) (Stats, error) {
+	return aggregate(applyFilter(t.db.WithContext(ctx).Model(new(T)), filter, false))
+}
+
+// aggregate COUNT(*) y MAX(updated_at) de la consulta
+func aggregate(query *gorm.DB) (Stats, error) {
+	var row struct {
+		Count        int64
+		LastModified sql.NullTime
+	}
+	if err := query.Select("COUNT(*) AS count, MAX(updated_at) AS last_modified").Scan(&row).Error; err != nil {
+		return Stats{}, translate(err)
+	}
+	return Stats{Count: row.Count, LastModified: row.LastModified.Time}, nil
+}
+
+// slugExists verifica si otro registro usa el slug
+func (t gormTable[T]) slugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := t.db.WithContext(ctx).Model(new(T)).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	return count > 0, translate(err)
+}
+
+// applyFilter añade condiciones y, si paginate, el orden, el cursor, el salto y el límite
+func applyFilter(query *gorm.DB, filter Filter, paginate bool) *gorm.DB {
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+	if filter.IDs != nil {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.CategoryID != 0 && filter.Subcategories {
+		query = query.Where("category_id IN (SELECT d.id FROM categories c JOIN categories d ON d.path LIKE c.path || '%' WHERE c.id = ?)", filter.CategoryID)
+	} else if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.CategorySlug != "" && filter.Subcategories {
+		query = query.Where("category_id IN (SELECT d.id FROM categories c JOIN categories d ON d.path LIKE c.path || '%' WHERE c.slug = ?)", filter.CategorySlug)
+	} else if filter.CategorySlug != "" {
+		query = query.Where("category_id IN (SELECT id FROM categories WHERE slug = ?)", filter.CategorySlug)
+	}
+	if filter.TagID != 0 {
+		query = query.Where("id IN (SELECT product_id FROM product_tags WHERE tag_id = ?)", filter.TagID)
+	}
+	if filter.TagSlug != "" {
+		query = query.Where("id IN (SELECT pt.product_id FROM product_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.slug = ?)", filter.TagSlug)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.HasImage != nil {
+		if *filter.HasImage {
+			query = query.Where("cardinality(image_urls) > 0")
+		} else {
+			query = query.Where("COALESCE(cardinality(image_urls), 0) = 0")
+		}
+	}
+	for _, attribute := range filter.Attributes {
+		query = query.Where(attributeCondition(attribute))
+	}
+	if len(filter.StockStatus) > 0 {
+		query = query.Where("stock_status IN ?", filter.StockStatus)
+	}
+	// Con WithProducts la consulta es de categorías: InStock se aplica a sus productos
+	if filter.InStock && !filter.WithProducts {
+		query = query.Where("stock_status <> ?", models.StockStatusOut)
+	}
+	if !paginate {
+		return query
+	}
+
+	if filter.WithProducts {
+		query = query.Preload("Products", func(products *gorm.DB) *gorm.DB {
+			if filter.Active != nil {
+				products = products.Where("active = ?", *filter.Active)
+			}
+			if filter.InStock {
+				products = products.Where("stock_status <> ?", models.StockStatusOut)
+			}
+			return products.Order(clause.OrderByColumn{Column: clause.Column{Name: "created_at"}, Desc: true})
+		})
+		query = query.Preload("Products.Variants", func(db *gorm.DB) *gorm.DB { return db.Order(`"order", id`) })
+	}
+
+	// Orden total por la columna y el id; el cursor compara la pareja completa
+	column := filter.Sort.column()
+	if filter.After != nil {
+		operator := ">"
+		if filter.Sort.Desc {
+			operator = "<"
+		}
+		query = query.Where(clause.Expr{
+			SQL:  "(?, ?) " + operator + " (?, ?)",
+			Vars: []interface{}{clause.Column{Name: column}, clause.Column{Name: "id"}, filter.After.Value, filter.After.ID},
+		})
+	}
+	// "order" es palabra reservada: OrderByColumn la entrecomilla
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: filter.Sort.Desc})
+	if column != "id" {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: filter.Sort.Desc})
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return query
+}
+
+// attributeCondition el producto tiene el atributo con alguno de los valores y en el rango
+func attributeCondition(filter AttributeFilter) clause.Expr {
+	sql := `EXISTS (SELECT 1 FROM product_attributes pa
+JOIN attribute_definitions ad ON ad.id = pa.attribute_id
+WHERE pa.product_id = products.id AND ad.category_id = products.category_id AND ad.key = ?`
+	vars := []interface{}{filter.Key}
+	if len(filter.Values) > 0 {
+		sql += " AND pa.value IN ?"
+		vars = append(vars, filter.Values)
+	}
+	if filter.Min != nil {
+		sql += " AND pa.number_value >= ?"
+		vars = append(vars, *filter.Min)
+	}
+	if filter.Max != nil {
+		sql += " AND pa.number_value <= ?"
+		vars = append(vars, *filter.Max)
+	}
+	return clause.Expr{SQL: sql + ")", Vars: vars}
+}
+
+// ========================================
+// ENTIDADES
+// ========================================
+
+// gormCategories categorías con búsqueda por slug
+type gormCategories struct {
+	gormTable[models.Category]
+}
+
+// FindBySlug busca una categoría por slug
+func (r gormCategories) FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Category, error) {
+	filter.Limit = 0
+	var category models.Category
+	err := applyFilter(r.query(ctx).Where("slug = ?", slug), filter, true).First(&category).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &category, nil
+}
+
+// SlugExists verifica si otra categoría usa el slug
+func (r gormCategories) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(ctx, slug, excludeID)
+}
+
+// Descendants subcategorías bajo la ruta materializada, de menor a mayor profundidad
+func (r gormCategories) Descendants(ctx context.Context, path string) ([]models.Category, error) {
+	var categories []models.Category
+	if path == "" {
+		return categories, nil
+	}
+	err := r.db.WithContext(ctx).Where("path LIKE ? AND path <> ?", path+"%", path).Order("depth, path").Find(&categories).Error
+	return categories, translate(err)
+}
+
+// gormProducts productos con su categoría
+type gormProducts struct {
+	gormTable[models.Product]
+}
+
+// SlugExists verifica si otro producto usa el slug
+func (r gormProducts) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(ctx, slug, excludeID)
+}
+
+// FindBySlug busca un producto por slug
+func (r gormProducts) FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Product, error) {
+	filter.Limit = 0
+	var product models.Product
+	err := applyFilter(r.query(ctx).Where("slug = ?", slug), filter, true).First(&product).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &product, nil
+}
+
+// searchFrom productos visibles que coinciden con la búsqueda (q: consulta y término sin acentos).
+// Coincide el documento del producto, el nombre de la categoría o, con errores de escritura,
+// alguna palabra del nombre (pg_trgm)
+const searchFrom = `
+FROM products p
+JOIN categories c ON c.id = p.category_id
+CROSS JOIN (SELECT websearch_to_tsquery('es_unaccent', @query) AS query, f_unaccent(lower(@query)) AS term) q
+WHERE p.active AND c.active
+  AND (NOT @in_stock OR p.stock_status <> 'out_of_stock')
+  AND (p.search_vector @@ q.query
+       OR to_tsvector('es_unaccent', c.name) @@ q.query
+       OR q.term <% f_unaccent(lower(p.name)))`
+
+// searchHeadline opciones de ts_headline: marcas de SearchHit y fragmentos cortos
+const searchHeadline = "StartSel=" + HighlightStart + ", StopSel=" + HighlightStop + ", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// Search busca por relevancia: ts_rank_cd (con el nombre de la categoría con peso B)
+// más la similitud del nombre, que ordena los aciertos con errores de escritura
+func (r gormProducts) Search(ctx context.Context, filter SearchFilter) ([]SearchHit, int64, error) {
+	args := map[string]interface{}{"query": filter.Query, "in_stock": filter.InStock, "headline": searchHeadline, "limit": filter.Limit, "offset": filter.Offset}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw("SELECT COUNT(*)"+searchFrom, args).Scan(&total).Error; err != nil {
+		return nil, 0, translate(err)
+	}
+	if total == 0 {
+		return []SearchHit{}, 0, nil
+	}
+
+	paginate := ""
+	if filter.Limit > 0 {
+		paginate = " LIMIT @limit OFFSET @offset"
+	}
+	var rows []struct {
+		ID      uint
+		Rank    float64
+		Name    string
+		Snippet string
+	}
+	err := r.db.WithContext(ctx).Raw(`
+SELECT p.id,
+       ts_rank_cd(p.search_vector || setweight(to_tsvector('es_unaccent', c.name), 'B'), q.query)
+         + word_similarity(q.term, f_unaccent(lower(p.name))) AS rank,
+       ts_headline('es_unaccent', p.name, q.query, 'HighlightAll=true, ' || @headline) AS name,
+       ts_headline('es_unaccent', coalesce(nullif(p.description, ''), p.features, ''), q.query, @headline) AS snippet`+
+		searchFrom+`
+ORDER BY rank DESC, p.id`+paginate, args).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, translate(err)
+	}
+
+	// Los productos (con su categoría) se cargan igual que en List y se devuelven por relevancia
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var products []models.Product
+	if err := r.query(ctx).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, 0, translate(err)
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	hits := make([]SearchHit, 0, len(rows))
+	for _, row := range rows {
+		if product, ok := byID[row.ID]; ok {
+			hits = append(hits, SearchHit{Product: product, Rank: row.Rank, Name: row.Name, Snippet: row.Snippet})
+		}
+	}
+	return hits, total, nil
+}
+
+// similarScore parecido de products con el producto de referencia: misma categoría,
+// etiquetas en común y cercanía del precio entre 0 (muy distintos o uno sin precio) y 1
+const similarScore = `(CASE WHEN products.category_id = ? THEN ? ELSE 0 END)
+  + ? * (SELECT COUNT(*) FROM product_tags pt JOIN product_tags own ON own.tag_id = pt.tag_id
+         WHERE pt.product_id = products.id AND own.product_id = ?)
+  + CASE WHEN LEAST(products.price, ?::numeric) <= 0 THEN 0
+         ELSE (LEAST(products.price, ?::numeric) / GREATEST(products.price, ?::numeric))::float END`
+
+// Similar puntúa y ordena en la base de datos; solo devuelve los ids de los primeros
+func (r gormProducts) Similar(ctx context.Context, product models.Product, filter Filter, exclude []uint, limit int) ([]uint, error) {
+	query := applyFilter(r.db.WithContext(ctx).Model(&models.Product{}), filter, false).
+		Select("products.id, "+similarScore+" AS score",
+			product.CategoryID, similarCategoryScore, similarTagScore, product.ID, product.Price, product.Price, product.Price).
+		Where(`products.category_id = ? OR EXISTS (SELECT 1 FROM product_tags pt JOIN product_tags own ON own.tag_id = pt.tag_id
+  WHERE pt.product_id = products.id AND own.product_id = ?)`, product.CategoryID, product.ID).
+		Where(`NOT EXISTS (SELECT 1 FROM categories c JOIN categories a ON c.path LIKE a.path || '%'
+  WHERE c.id = products.category_id AND NOT a.active)`)
+	if len(exclude) > 0 {
+		query = query.Where("products.id NOT IN ?", exclude)
+	}
+
+	var rows []struct {
+		ID    uint
+		Score float64
+	}
+	err := query.Order("score DESC, products.created_at DESC, products.id DESC").Limit(limit).Scan(&rows).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return ids, nil
+}
+
+// gormVariants variantes con SKU único
+type gormVariants struct {
+	gormTable[models.ProductVariant]
+}
+
+// SKUExists verifica si otra variante usa el SKU
+func (r gormVariants) SKUExists(ctx context.Context, sku string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ProductVariant{}).Where("sku = ? AND id <> ?", sku, excludeID).Count(&count).Error
+	return count > 0, translate(err)
+}
+
+// gormCurrencies monedas con búsqueda por código
+type gormCurrencies struct {
+	gormTable[models.Currency]
+}
+
+// FindByCode busca una moneda por código
+func (r gormCurrencies) FindByCode(ctx context.Context, code string) (*models.Currency, error) {
+	var currency models.Currency
+	if err := r.query(ctx).Where("code = ?", code).First(&currency).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &currency, nil
+}
+
+// gormPrices precios por moneda de productos y variantes
+type gormPrices struct {
+	db *gorm.DB
+}
+
+// ForProducts precios por producto, variante (primero los del producto) y moneda
+func (r gormPrices) ForProducts(ctx context.Context, productIDs []uint, currency string) ([]models.ProductPrice, error) {
+	query := r.db.WithContext(ctx).Order("product_id, variant_id NULLS FIRST, currency_code")
+	if productIDs != nil {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+	if currency != "" {
+		query = query.Where("currency_code = ?", currency)
+	}
+	var prices []models.ProductPrice
+	return prices, translate(query.Find(&prices).Error)
+}
+
+// SetPrices borra los precios del producto e inserta los nuevos
+func (r gormPrices) SetPrices(ctx context.Context, productID uint, prices []models.ProductPrice) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("product_id = ?", productID).Delete(&models.ProductPrice{}).Error; err != nil {
+		return translate(err)
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+	for i := range prices {
+		prices[i].ID, prices[i].ProductID = 0, productID
+	}
+	return translate(db.Create(&prices).Error)
+}
+
+// Stats número de precios y última modificación
+func (r gormPrices) Stats(ctx context.Context) (Stats, error) {
+	return aggregate(r.db.WithContext(ctx).Model(&models.ProductPrice{}))
+}
+
+// gormRelations relaciones entre productos
+type gormRelations struct {
+	db *gorm.DB
+}
+
+// ForProducts relaciones por producto, tipo y posición
+func (r gormRelations) ForProducts(ctx context.Context, productIDs []uint) ([]models.ProductRelation, error) {
+	query := r.db.WithContext(ctx).Order("product_id, type, position, id")
+	if productIDs != nil {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+	var relations []models.ProductRelation
+	return relations, translate(query.Find(&relations).Error)
+}
+
+// SetRelations borra las relaciones del producto e inserta las nuevas
+func (r gormRelations) SetRelations(ctx context.Context, productID uint, relations []models.ProductRelation) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("product_id = ?", productID).Delete(&models.ProductRelation{}).Error; err != nil {
+		return translate(err)
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+	for i := range relations {
+		relations[i].ID, relations[i].ProductID = 0, productID
+	}
+	return translate(db.Create(&relations).Error)
+}
+
+// Stats número de relaciones y última modificación
+func (r gormRelations) Stats(ctx context.Context) (Stats, error) {
+	return aggregate(r.db.WithContext(ctx).Model(&models.ProductRelation{}))
+}
+
+// gormStock historial de existencias
+type gormStock struct {
+	db *gorm.DB
+}
+
+// Create inserta un movimiento
+func (r gormStock) Create(ctx context.Context, movement *models.StockMovement) error {
+	return translate(r.db.WithContext(ctx).Create(movement).Error)
+}
+
+// List lista movimientos, más recientes primero
+func (r gormStock) List(ctx context.Context, filter StockFilter) ([]models.StockMovement, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockMovement{})
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.VariantID != nil {
+		query = query.Where("variant_id = ?", *filter.VariantID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var movements []models.StockMovement
+	err := query.Order("created_at DESC, id DESC").Find(&movements).Error
+	return movements, translate(err)
+}
+
+// LockProduct SELECT ... FOR UPDATE de la fila del producto
+func (r gormStock) LockProduct(ctx context.Context, productID uint) error {
+	var id uint
+	err := r.db.WithContext(ctx).Raw("SELECT id FROM products WHERE id = ? FOR UPDATE", productID).Scan(&id).Error
+	if err != nil {
+		return translate(err)
+	}
+	if id == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// gormAttributes definiciones de atributos y valores de los productos
+type gormAttributes struct {
+	gormTable[models.AttributeDefinition]
+}
+
+// Values valores con su definición, por orden de la definición
+func (r gormAttributes) Values(ctx context.Context, productIDs []uint) ([]models.ProductAttribute, error) {
+	query := r.db.WithContext(ctx).Preload("Attribute").
+		Joins("JOIN attribute_definitions ad ON ad.id = product_attributes.attribute_id").
+		Order(`product_attributes.product_id, ad."order", ad.id`)
+	if productIDs != nil {
+		query = query.Where("product_attributes.product_id IN ?", productIDs)
+	}
+	var values []models.ProductAttribute
+	return values, translate(query.Find(&values).Error)
+}
+
+// SetValues borra los valores del producto e inserta los nuevos
+func (r gormAttributes) SetValues(ctx context.Context, productID uint, values []models.ProductAttribute) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("product_id = ?", productID).Delete(&models.ProductAttribute{}).Error; err != nil {
+		return translate(err)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	for i := range values {
+		values[i].ID, values[i].ProductID = 0, productID
+	}
+	return translate(db.Omit(clause.Associations).Create(&values).Error)
+}
+
+// FacetCounts cuenta los productos del filtro por valor, de más a menos frecuente
+func (r gormAttributes) FacetCounts(ctx context.Context, attributeID uint, filter Filter) ([]FacetValue, error) {
+	products := applyFilter(r.db.WithContext(ctx).Model(&models.Product{}).Select("id"), filter, false)
+	var values []FacetValue
+	err := r.db.WithContext(ctx).Table("product_attributes").
+		Select("value, COUNT(*) AS count").
+		Where("attribute_id = ? AND product_id IN (?)", attributeID, products).
+		Group("value").
+		Order("count DESC, value").
+		Scan(&values).Error
+	return values, translate(err)
+}
+
+// gormTags etiquetas y su asignación a los productos
+type gormTags struct {
+	gormTable[models.Tag]
+}
+
+// FindBySlug busca una etiqueta por slug
+func (r gormTags) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.query(ctx).Where("slug = ?", slug).First(&tag).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &tag, nil
+}
+
+// SlugExists verifica si otra etiqueta usa el slug
+func (r gormTags) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(ctx, slug, excludeID)
+}
+
+// ForProducts etiquetas de los productos, por producto
+func (r gormTags) ForProducts(ctx context.Context, productIDs []uint) ([]models.ProductTag, error) {
+	query := r.db.WithContext(ctx).Order("product_id, tag_id")
+	if productIDs != nil {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+	var links []models.ProductTag
+	return links, translate(query.Find(&links).Error)
+}
+
+// SetProductTags borra las etiquetas del producto e inserta las nuevas
+func (r gormTags) SetProductTags(ctx context.Context, productID uint, tagIDs []uint) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("product_id = ?", productID).Delete(&models.ProductTag{}).Error; err != nil {
+		return translate(err)
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	links := make([]models.ProductTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = models.ProductTag{ProductID: productID, TagID: tagID}
+	}
+	return translate(db.Create(&links).Error)
+}
+
+// gormCollections colecciones con sus productos elegidos a mano
+type gormCollections struct {
+	gormTable[models.Collection]
+}
+
+// FindBySlug busca una colección por slug
+func (r gormCollections) FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Collection, error) {
+	filter.Limit = 0
+	var collection models.Collection
+	err := applyFilter(r.query(ctx).Where("slug = ?", slug), filter, true).First(&collection).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &collection, nil
+}
+
+// SlugExists verifica si otra colección usa el slug
+func (r gormCollections) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(ctx, slug, excludeID)
+}
+
+// Items productos de las colecciones por colección y posición
+func (r gormCollections) Items(ctx context.Context, collectionIDs []uint) ([]models.CollectionProduct, error) {
+	query := r.db.WithContext(ctx).Order("collection_id, position, id")
+	if collectionIDs != nil {
+		query = query.Where("collection_id IN ?", collectionIDs)
+	}
+	var items []models.CollectionProduct
+	return items, translate(query.Find(&items).Error)
+}
+
+// SetItems borra los productos de la colección e inserta los nuevos
+func (r gormCollections) SetItems(ctx context.Context, collectionID uint, items []models.CollectionProduct) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("collection_id = ?", collectionID).Delete(&models.CollectionProduct{}).Error; err != nil {
+		return translate(err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	for i := range items {
+		items[i].ID, items[i].CollectionID = 0, collectionID
+	}
+	return translate(db.Create(&items).Error)
+}
+
+// gormUsers usuarios con búsqueda por username o email
+type gormUsers struct {
+	gormTable[models.User]
+}
+
+// FindByLogin busca por username o email
+func (r gormUsers) FindByLogin(ctx context.Context, login string) (*models.User, error) {
+	var user models.User
+	if err := r.query(ctx).Where("username = ? OR email = ?", login, login).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+// gormConfigs configuración clave/valor
+type gormConfigs struct {
+	db *gorm.DB
+}
+
+// List devuelve todas las claves
+func (r gormConfigs) List(ctx context.Context) ([]models.SiteConfig, error) {
+	var configs []models.SiteConfig
+	err := r.db.WithContext(ctx).Order("id").Find(&configs).Error
+	return configs, translate(err)
+}
+
+// FindByKey busca una clave
+func (r gormConfigs) FindByKey(ctx context.Context, key string) (*models.SiteConfig, error) {
+	var config models.SiteConfig
+	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&config).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &config, nil
+}
+
+// Create inserta una clave
+func (r gormConfigs) Create(ctx context.Context, item *models.SiteConfig) error {
+	return translate(r.db.WithContext(ctx).Create(item).Error)
+}
+
+// Update guarda una clave existente
+func (r gormConfigs) Update(ctx context.Context, item *models.SiteConfig) error {
+	return translate(r.db.WithContext(ctx).Save(item).Error)
+}
+
+// Stats número de claves y última modificación
+func (r gormConfigs) Stats(ctx context.Context) (Stats, error) {
+	return aggregate(r.db.WithContext(ctx).Model(&models.SiteConfig{}))
+}
+
+// gormAudit registro de auditoría
+type gormAudit struct {
+	db *gorm.DB
+}
+
+// Create inserta un evento
+func (r gormAudit) Create(ctx context.Context, event *models.AuditEvent) error {
+	return translate(r.db.WithContext(ctx).Create(event).Error)
+}
+
+// List lista eventos, más recientes primero
+func (r gormAudit) List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error) {
+	query := auditConditions(r.db.WithContext(ctx).Model(&models.AuditEvent{}), filter)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("created_at DESC, id DESC").Find(&events).Error
+	return events, translate(err)
+}
+
+// auditConditions añade las condiciones del filtro de auditoría (sin el límite)
+func auditConditions(query *gorm.DB, filter AuditFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query
+}
@@ -0,0 +1,1673 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/utils"
+)
+
+// MemoryStore repositorios en memoria para pruebas y desarrollo sin base de datos.
+// Emula las restricciones de unicidad, el borrado en cascada de productos y
+// el rollback de Transaction. Las transacciones se serializan entre sí, pero
+// una escritura fuera de transacción concurrente con un rollback se pierde.
+type MemoryStore struct {
+	mu            sync.Mutex // Protege data
+	tx            sync.Mutex // Serializa Transaction y Snapshot
+	data          *memoryData
+	schemaVersion int64
+}
+
+// memoryData filas de todas las tablas
+type memoryData struct {
+	configs     memoryRows[models.SiteConfig]
+	slides      memoryRows[models.Slide]
+	categories  memoryRows[models.Category]
+	products    memoryRows[models.Product]
+	variants    memoryRows[models.ProductVariant]
+	currencies  memoryRows[models.Currency]
+	prices      memoryRows[models.ProductPrice]
+	relations   memoryRows[models.ProductRelation]
+	movements   memoryRows[models.StockMovement]
+	attributes  memoryRows[models.AttributeDefinition]
+	values      memoryRows[models.ProductAttribute]
+	tags        memoryRows[models.Tag]
+	tagLinks    memoryRows[models.ProductTag]
+	collections memoryRows[models.Collection]
+	items       memoryRows[models.CollectionProduct]
+	contacts    memoryRows[models.ContactInfo]
+	users       memoryRows[models.User]
+	audit       memoryRows[models.AuditEvent]
+}
+
+// memoryRows filas de una tabla por id y siguiente id a asignar
+type memoryRows[T any] struct {
+	items  map[uint]T
+	nextID uint
+}
+
+// NewMemoryStore crea un almacén en memoria vacío
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: &memoryData{}}
+}
+
+// SetSchemaVersion fija la versión devuelta por SchemaVersion
+func (s *MemoryStore) SetSchemaVersion(version int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemaVersion = version
+}
+
+// Slides repositorio de slides
+func (s *MemoryStore) Slides() SlideRepository {
+	return memoryTable[models.Slide]{store: s, rows: func(d *memoryData) *memoryRows[models.Slide] { return &d.slides }}
+}
+
+// Categories repositorio de categorías
+func (s *MemoryStore) Categories() CategoryRepository {
+	return memoryCategories{memoryTable[models.Category]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.Category] { return &d.categories },
+		unique: []string{"Slug"},
+		load:   loadCategoryProducts,
+		cascade: func(d *memoryData, id uint) {
+			for productID, product := range d.products.items {
+				if product.CategoryID == id {
+					delete(d.products.items, productID)
+					deleteProductRows(d, productID)
+				}
+			}
+			for attributeID, attribute := range d.attributes.items {
+				if attribute.CategoryID == id {
+					delete(d.attributes.items, attributeID)
+				}
+			}
+		},
+	}}
+}
+
+// Products repositorio de productos
+func (s *MemoryStore) Products() ProductRepository {
+	return memoryProducts{memoryTable[models.Product]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.Product] { return &d.products },
+		unique: []string{"Slug"},
+		load: func(d *memoryData, product *models.Product, _ Filter) {
+			product.Category = d.categories.items[product.CategoryID]
+			product.Attributes = productValues(d, func(value models.ProductAttribute) bool { return value.ProductID == product.ID })
+			product.Variants = productVariants(d, product.ID)
+			product.Tags = productTags(d, product.ID)
+		},
+		cascade: func(d *memoryData, id uint) {
+			deleteProductRows(d, id)
+		},
+	}}
+}
+
+// Variants repositorio de variantes de producto
+func (s *MemoryStore) Variants() VariantRepository {
+	return memoryVariants{memoryTable[models.ProductVariant]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.ProductVariant] { return &d.variants },
+		unique: []string{"SKU"},
+		cascade: func(d *memoryData, id uint) {
+			deletePrices(d, func(price models.ProductPrice) bool { return price.VariantID != nil && *price.VariantID == id })
+			deleteMovements(d, func(movement models.StockMovement) bool {
+				return movement.VariantID != nil && *movement.VariantID == id
+			})
+		},
+	}}
+}
+
+// Currencies repositorio de monedas
+func (s *MemoryStore) Currencies() CurrencyRepository {
+	return memoryCurrencies{memoryTable[models.Currency]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.Currency] { return &d.currencies },
+		unique: []string{"Code"},
+		cascade: func(d *memoryData, id uint) {
+			// La clave externa es el código: se borra después de la moneda, así que se
+			// borran los precios cuyo código ya no tiene moneda
+			deletePrices(d, func(price models.ProductPrice) bool {
+				for _, currency := range d.currencies.items {
+					if currency.Code == price.Currency {
+						return false
+					}
+				}
+				return true
+			})
+		},
+	}}
+}
+
+// Prices repositorio de precios por moneda
+func (s *MemoryStore) Prices() PriceRepository {
+	return memoryPrices{memoryTable[models.ProductPrice]{
+		store: s,
+		rows:  func(d *memoryData) *memoryRows[models.ProductPrice] { return &d.prices },
+	}}
+}
+
+// Relations repositorio de relaciones entre productos
+func (s *MemoryStore) Relations() RelationRepository {
+	return memoryRelations{memoryTable[models.ProductRelation]{
+		store: s,
+		rows:  func(d *memoryData) *memoryRows[models.ProductRelation] { return &d.relations },
+	}}
+}
+
+// Stock repositorio del historial de existencias
+func (s *MemoryStore) Stock() StockRepository {
+	return memoryStock{memoryTable[models.StockMovement]{
+		store: s,
+		rows:  func(d *memoryData) *memoryRows[models.StockMovement] { return &d.movements },
+	}}
+}
+
+// Attributes repositorio de atributos de producto
+func (s *MemoryStore) Attributes() AttributeRepository {
+	return memoryAttributes{memoryTable[models.AttributeDefinition]{
+		store: s,
+		rows:  func(d *memoryData) *memoryRows[models.AttributeDefinition] { return &d.attributes },
+		cascade: func(d *memoryData, id uint) {
+			deleteValues(d, func(value models.ProductAttribute) bool { return value.AttributeID == id })
+		},
+	}}
+}
+
+// Tags repositorio de etiquetas
+func (s *MemoryStore) Tags() TagRepository {
+	return memoryTags{memoryTable[models.Tag]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.Tag] { return &d.tags },
+		unique: []string{"Slug"},
+		cascade: func(d *memoryData, id uint) {
+			deleteTagLinks(d, func(link models.ProductTag) bool { return link.TagID == id })
+		},
+	}}
+}
+
+// Collections repositorio de colecciones
+func (s *MemoryStore) Collections() CollectionRepository {
+	return memoryCollections{memoryTable[models.Collection]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.Collection] { return &d.collections },
+		unique: []string{"Slug"},
+		cascade: func(d *memoryData, id uint) {
+			deleteItems(d, func(item models.CollectionProduct) bool { return item.CollectionID == id })
+		},
+	}}
+}
+
+// Contacts repositorio de contactos
+func (s *MemoryStore) Contacts() ContactRepository {
+	return memoryTable[models.ContactInfo]{store: s, rows: func(d *memoryData) *memoryRows[models.ContactInfo] { return &d.contacts }}
+}
+
+// Configs repositorio de configuración
+func (s *MemoryStore) Configs() ConfigRepository {
+	return memoryConfigs{memoryTable[models.SiteConfig]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.SiteConfig] { return &d.configs },
+		unique: []string{"Key"},
+	}}
+}
+
+// Users repositorio de usuarios
+func (s *MemoryStore) Users() UserRepository {
+	return memoryUsers{memoryTable[models.User]{
+		store:  s,
+		rows:   func(d *memoryData) *memoryRows[models.User] { return &d.users },
+		unique: []string{"Username", "Email"},
+	}}
+}
+
+// Audit repositorio de auditoría
+func (s *MemoryStore) Audit() AuditRepository {
+	return memoryAudit{memoryTable[models.AuditEvent]{
+		store: s,
+		rows:  func(d *memoryData) *memoryRows[models.AuditEvent] { return &d.audit },
+	}}
+}
+
+// BatchStats resuelve cada consulta con el Stats de su repositorio
+func (s *MemoryStore) BatchStats(ctx context.Context, queries []StatsQuery) ([]Stats, error) {
+	stats := make([]Stats, len(queries))
+	for i, query := range queries {
+		var err error
+		if query.Audit != nil {
+			stats[i], err = s.auditStats(ctx, *query.Audit)
+		} else {
+			stats[i], err = s.modelStats(ctx, query.Model, query.Filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// modelStats Stats del repositorio del modelo
+func (s *MemoryStore) modelStats(ctx context.Context, model interface{}, filter Filter) (Stats, error) {
+	switch model.(type) {
+	case *models.Slide:
+		return s.Slides().Stats(ctx, filter)
+	case *models.Category:
+		return s.Categories().Stats(ctx, filter)
+	case *models.Product:
+		return s.Products().Stats(ctx, filter)
+	case *models.ProductVariant:
+		return s.Variants().Stats(ctx, filter)
+	case *models.Currency:
+		return s.Currencies().Stats(ctx, filter)
+	case *models.ProductPrice:
+		return s.Prices().Stats(ctx)
+	case *models.ProductRelation:
+		return s.Relations().Stats(ctx)
+	case *models.AttributeDefinition:
+		return s.Attributes().Stats(ctx, filter)
+	case *models.Tag:
+		return s.Tags().Stats(ctx, filter)
+	case *models.Collection:
+		return s.Collections().Stats(ctx, filter)
+	case *models.ContactInfo:
+		return s.Contacts().Stats(ctx, filter)
+	case *models.SiteConfig:
+		return s.Configs().Stats(ctx)
+	case *models.User:
+		return s.Users().Stats(ctx, filter)
+	}
+	return Stats{}, fmt.Errorf("sin agregados para %T", model)
+}
+
+// auditStats número de eventos y mayor created_at
+func (s *MemoryStore) auditStats(ctx context.Context, filter AuditFilter) (Stats, error) {
+	filter.Limit = 0
+	events, err := s.Audit().List(ctx, filter)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Count: int64(len(events))}
+	for _, event := range events {
+		if event.CreatedAt.After(stats.LastModified) {
+			stats.LastModified = event.CreatedAt
+		}
+	}
+	return stats, nil
+}
+
+// SchemaVersion versión fijada con SetSchemaVersion
+func (s *MemoryStore) SchemaVersion(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schemaVersion, nil
+}
+
+// Transaction ejecuta fn y restaura los datos anteriores si devuelve error
+func (s *MemoryStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+
+	s.mu.Lock()
+	saved := s.data.clone()
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		s.data = saved
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Snapshot ejecuta fn sin transacciones concurrentes
+func (s *MemoryStore) Snapshot(ctx context.Context, fn func(tx Store) error) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+	return fn(s)
+}
+
+// clone copia las tablas (las filas son valores y se sustituyen enteras)
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		configs:     d.configs.clone(),
+		slides:      d.slides.clone(),
+		categories:  d.categories.clone(),
+		products:    d.products.clone(),
+		variants:    d.variants.clone(),
+		currencies:  d.currencies.clone(),
+		prices:      d.prices.clone(),
+		relations:   d.relations.clone(),
+		movements:   d.movements.clone(),
+		attributes:  d.attributes.clone(),
+		values:      d.values.clone(),
+		tags:        d.tags.clone(),
+		tagLinks:    d.tagLinks.clone(),
+		collections: d.collections.clone(),
+		items:       d.items.clone(),
+		contacts:    d.contacts.clone(),
+		users:       d.users.clone(),
+		audit:       d.audit.clone(),
+	}
+}
+
+func (r memoryRows[T]) clone() memoryRows[T] {
+	items := make(map[uint]T, len(r.items))
+	for id, item := range r.items {
+		items[id] = item
+	}
+	return memoryRows[T]{items: items, nextID: r.nextID}
+}
+
+// loadCategoryProducts carga los productos de la categoría si el filtro lo pide
+func loadCategoryProducts(d *memoryData, category *models.Category, filter Filter) {
+	if !filter.WithProducts {
+		return
+	}
+	category.Products = nil
+	for _, product := range d.products.items {
+		if product.CategoryID == category.ID && (filter.Active == nil || product.Active == *filter.Active) && (!filter.InStock || product.StockStatus != models.StockStatusOut) {
+			product.Variants = productVariants(d, product.ID)
+			category.Products = append(category.Products, product)
+		}
+	}
+	sortItems(category.Products, SortByNewest)
+}
+
+// ========================================
+// TABLA GENÉRICA
+// ========================================
+
+// memoryTable CRUD genérico; accede a los campos comunes (ID, Active, Order,
+// CreatedAt, UpdatedAt) por reflexión
+type memoryTable[T any] struct {
+	store   *MemoryStore
+	rows    func(d *memoryData) *memoryRows[T]
+	unique  []string                               // Campos con restricción de unicidad
+	load    func(d *memoryData, item *T, f Filter) // Carga las relaciones al leer
+	cascade func(d *memoryData, id uint)           // Borra las filas dependientes
+}
+
+// Get busca por id
+func (t memoryTable[T]) Get(ctx context.Context, id uint) (*T, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	item, ok := t.rows(t.store.data).items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t.loadRelations(&item, Filter{})
+	return &item, nil
+}
+
+// Create inserta el registro asignando id y fechas
+func (t memoryTable[T]) Create(ctx context.Context, item *T) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	rows := t.rows(t.store.data)
+	if err := t.checkUnique(rows, item, 0); err != nil {
+		return err
+	}
+
+	rows.nextID++
+	now := time.Now()
+	value := reflect.ValueOf(item).Elem()
+	value.FieldByName("ID").SetUint(uint64(rows.nextID))
+	setTime(value, "CreatedAt", now, true)
+	setTime(value, "UpdatedAt", now, false)
+
+	if rows.items == nil {
+		rows.items = make(map[uint]T)
+	}
+	rows.items[rows.nextID] = withoutRelations(*item)
+	return nil
+}
+
+// Update sustituye el registro con el mismo id
+func (t memoryTable[T]) Update(ctx context.Context, item *T) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	rows := t.rows(t.store.data)
+	id := idOf(*item)
+	if _, ok := rows.items[id]; !ok {
+		return ErrNotFound
+	}
+	if err := t.checkUnique(rows, item, id); err != nil {
+		return err
+	}
+
+	setTime(reflect.ValueOf(item).Elem(), "UpdatedAt", time.Now(), false)
+	rows.items[id] = withoutRelations(*item)
+	return nil
+}
+
+// Delete elimina por id
+func (t memoryTable[T]) Delete(ctx context.Context, id uint) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	rows := t.rows(t.store.data)
+	if _, ok := rows.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(rows.items, id)
+	if t.cascade != nil {
+		t.cascade(t.store.data, id)
+	}
+	return nil
+}
+
+// List lista según el filtro
+func (t memoryTable[T]) List(ctx context.Context, filter Filter) ([]T, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	return t.list(filter, nil), nil
+}
+
+// Count cuenta según el filtro (sin límite)
+func (t memoryTable[T]) Count(ctx context.Context, filter Filter) (int64, error) {
+	filter.Limit, filter.Offset, filter.After = 0, 0, nil
+	items, err := t.List(ctx, filter)
+	return int64(len(items)), err
+}
+
+// Stats cuenta y obtiene el mayor updated_at según el filtro (sin límite)
+func (t memoryTable[T]) Stats(ctx context.Context, filter Filter) (Stats, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	var stats Stats
+	for _, item := range t.rows(t.store.data).items {
+		if !matches(t.store.data, item, filter) {
+			continue
+		}
+		stats.Count++
+		if updated := reflect.ValueOf(item).FieldByName("UpdatedAt").Interface().(time.Time); updated.After(stats.LastModified) {
+			stats.LastModified = updated
+		}
+	}
+	return stats, nil
+}
+
+// list filtra, ordena, aplica el cursor, salta y limita; match es una condición
+// adicional opcional. Requiere mu.
+func (t memoryTable[T]) list(filter Filter, match func(item T) bool) []T {
+	items := []T{}
+	for _, item := range t.rows(t.store.data).items {
+		if matches(t.store.data, item, filter) && (match == nil || match(item)) && afterCursor(item, filter) {
+			t.loadRelations(&item, filter)
+			items = append(items, item)
+		}
+	}
+
+	sortItems(items, filter.Sort)
+	if filter.Offset > 0 {
+		items = items[min(filter.Offset, len(items)):]
+	}
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+	return items
+}
+
+// loadRelations rellena las relaciones del registro. Requiere mu.
+func (t memoryTable[T]) loadRelations(item *T, filter Filter) {
+	if t.load != nil {
+		t.load(t.store.data, item, filter)
+	}
+}
+
+// checkUnique emula los índices únicos. Requiere mu.
+func (t memoryTable[T]) checkUnique(rows *memoryRows[T], item *T, id uint) error {
+	value := reflect.ValueOf(item).Elem()
+	for _, field := range t.unique {
+		wanted := value.FieldByName(field).String()
+		for otherID, other := range rows.items {
+			if otherID != id && reflect.ValueOf(other).FieldByName(field).String() == wanted {
+				return fmt.Errorf("%w: %s", ErrDuplicate, field)
+			}
+		}
+	}
+	return nil
+}
+
+// slugExists verifica si otro registro usa el slug
+func (t memoryTable[T]) slugExists(slug string, excludeID uint) bool {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	for id, item := range t.rows(t.store.data).items {
+		if id != excludeID && reflect.ValueOf(item).FieldByName("Slug").String() == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// matches aplica los filtros cuyos campos tiene la entidad. Requiere mu.
+func matches(d *memoryData, item interface{}, filter Filter) bool {
+	value := reflect.ValueOf(item)
+	if filter.Active != nil {
+		if field := value.FieldByName("Active"); field.IsValid() && field.Bool() != *filter.Active {
+			return false
+		}
+	}
+	if filter.IDs != nil && !containsID(filter.IDs, idOf(item)) {
+		return false
+	}
+	if filter.CategoryID != 0 {
+		if field := value.FieldByName("CategoryID"); field.IsValid() && !inCategory(d, uint(field.Uint()), filter.CategoryID, filter.Subcategories) {
+			return false
+		}
+	}
+	if filter.ProductID != 0 {
+		if field := value.FieldByName("ProductID"); field.IsValid() && uint(field.Uint()) != filter.ProductID {
+			return false
+		}
+	}
+	if filter.CategorySlug != "" {
+		if field := value.FieldByName("CategoryID"); field.IsValid() && !inCategory(d, uint(field.Uint()), categoryBySlug(d, filter.CategorySlug), filter.Subcategories) {
+			return false
+		}
+	}
+	if filter.CreatedAfter != nil {
+		if field := value.FieldByName("CreatedAt"); field.IsValid() && field.Interface().(time.Time).Before(*filter.CreatedAfter) {
+			return false
+		}
+	}
+	if field := value.FieldByName("Price"); field.IsValid() {
+		if filter.MinPrice != nil && money.Amount(field.Int()) < *filter.MinPrice {
+			return false
+		}
+		if filter.MaxPrice != nil && money.Amount(field.Int()) > *filter.MaxPrice {
+			return false
+		}
+	}
+	if filter.HasImage != nil {
+		if field := value.FieldByName("ImageURLs"); field.IsValid() && (field.Len() > 0) != *filter.HasImage {
+			return false
+		}
+	}
+	if field := value.FieldByName("StockStatus"); field.IsValid() {
+		if len(filter.StockStatus) > 0 && !containsString(filter.StockStatus, field.String()) {
+			return false
+		}
+		if filter.InStock && field.String() == models.StockStatusOut {
+			return false
+		}
+	}
+	if product, ok := item.(models.Product); ok {
+		if filter.TagID != 0 && !hasTag(d, product.ID, func(tag models.Tag) bool { return tag.ID == filter.TagID }) {
+			return false
+		}
+		if filter.TagSlug != "" && !hasTag(d, product.ID, func(tag models.Tag) bool { return tag.Slug == filter.TagSlug }) {
+			return false
+		}
+		for _, attribute := range filter.Attributes {
+			if !hasAttribute(d, product, attribute) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasAttribute el producto tiene un valor del atributo que cumple el filtro. Requiere mu.
+func hasAttribute(d *memoryData, product models.Product, filter AttributeFilter) bool {
+	for _, value := range d.values.items {
+		definition := d.attributes.items[value.AttributeID]
+		if value.ProductID != product.ID || definition.CategoryID != product.CategoryID || definition.Key != filter.Key {
+			continue
+		}
+		if len(filter.Values) > 0 && !containsString(filter.Values, value.Value) {
+			continue
+		}
+		if (filter.Min != nil || filter.Max != nil) && value.Number == nil {
+			continue
+		}
+		if (filter.Min != nil && *value.Number < *filter.Min) || (filter.Max != nil && *value.Number > *filter.Max) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// hasTag el producto tiene una etiqueta que cumple match. Requiere mu.
+func hasTag(d *memoryData, productID uint, match func(tag models.Tag) bool) bool {
+	for _, link := range d.tagLinks.items {
+		if link.ProductID == productID && match(d.tags.items[link.TagID]) {
+			return true
+		}
+	}
+	return false
+}
+
+// productTags etiquetas del producto por nombre. Requiere mu.
+func productTags(d *memoryData, productID uint) []models.Tag {
+	var tags []models.Tag
+	for _, link := range d.tagLinks.items {
+		if tag, ok := d.tags.items[link.TagID]; ok && link.ProductID == productID {
+			tags = append(tags, tag)
+		}
+	}
+	sortItems(tags, SortByName)
+	return tags
+}
+
+// productValues valores que cumplen match con su definición, por orden de la definición. Requiere mu.
+func productValues(d *memoryData, match func(value models.ProductAttribute) bool) []models.ProductAttribute {
+	var values []models.ProductAttribute
+	for _, value := range d.values.items {
+		if match(value) {
+			value.Attribute = d.attributes.items[value.AttributeID]
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if a.Attribute.Order != b.Attribute.Order {
+			return a.Attribute.Order < b.Attribute.Order
+		}
+		return a.AttributeID < b.AttributeID
+	})
+	return values
+}
+
+// productVariants variantes del producto por orden. Requiere mu.
+func productVariants(d *memoryData, productID uint) []models.ProductVariant {
+	var variants []models.ProductVariant
+	for _, variant := range d.variants.items {
+		if variant.ProductID == productID {
+			variants = append(variants, variant)
+		}
+	}
+	sortItems(variants, SortByOrder)
+	return variants
+}
+
+// deleteProductRows borra los valores de atributos, los precios, los movimientos de
+// existencias, las etiquetas, la posición en las colecciones, las relaciones (en
+// ambos sentidos) y las variantes del producto. Requiere mu.
+func deleteProductRows(d *memoryData, productID uint) {
+	deleteValues(d, func(value models.ProductAttribute) bool { return value.ProductID == productID })
+	deletePrices(d, func(price models.ProductPrice) bool { return price.ProductID == productID })
+	deleteMovements(d, func(movement models.StockMovement) bool { return movement.ProductID == productID })
+	deleteTagLinks(d, func(link models.ProductTag) bool { return link.ProductID == productID })
+	deleteItems(d, func(item models.CollectionProduct) bool { return item.ProductID == productID })
+	deleteRelations(d, func(relation models.ProductRelation) bool {
+		return relation.ProductID == productID || relation.RelatedID == productID
+	})
+	for id, variant := range d.variants.items {
+		if variant.ProductID == productID {
+			delete(d.variants.items, id)
+		}
+	}
+}
+
+// deleteValues borra los valores que cumplen match. Requiere mu.
+func deleteValues(d *memoryData, match func(value models.ProductAttribute) bool) {
+	for id, value := range d.values.items {
+		if match(value) {
+			delete(d.values.items, id)
+		}
+	}
+}
+
+// deletePrices borra los precios que cumplen match. Requiere mu.
+func deletePrices(d *memoryData, match func(price models.ProductPrice) bool) {
+	for id, price := range d.prices.items {
+		if match(price) {
+			delete(d.prices.items, id)
+		}
+	}
+}
+
+// deleteRelations borra las relaciones que cumplen match. Requiere mu.
+func deleteRelations(d *memoryData, match func(relation models.ProductRelation) bool) {
+	for id, relation := range d.relations.items {
+		if match(relation) {
+			delete(d.relations.items, id)
+		}
+	}
+}
+
+// deleteMovements borra los movimientos de existencias que cumplen match. Requiere mu.
+func deleteMovements(d *memoryData, match func(movement models.StockMovement) bool) {
+	for id, movement := range d.movements.items {
+		if match(movement) {
+			delete(d.movements.items, id)
+		}
+	}
+}
+
+// deleteTagLinks borra las etiquetas de productos que cumplen match. Requiere mu.
+func deleteTagLinks(d *memoryData, match func(link models.ProductTag) bool) {
+	for id, link := range d.tagLinks.items {
+		if match(link) {
+			delete(d.tagLinks.items, id)
+		}
+	}
+}
+
+// deleteItems borra los productos de colecciones que cumplen match. Requiere mu.
+func deleteItems(d *memoryData, match func(item models.CollectionProduct) bool) {
+	for id, item := range d.items.items {
+		if match(item) {
+			delete(d.items.items, id)
+		}
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// afterCursor indica si la fila va detrás de filter.After según filter.Sort
+func afterCursor(item interface{}, filter Filter) bool {
+	if filter.After == nil {
+		return true
+	}
+	value := reflect.ValueOf(item)
+	column := sortColumns[filter.Sort.column()]
+	result := compareValues(value.FieldByName(column.field).Interface(), filter.After.Value)
+	if result == 0 {
+		result = compareOrdered(float64(value.FieldByName("ID").Uint()), float64(filter.After.ID))
+	}
+	if filter.Sort.Desc {
+		return result < 0
+	}
+	return result > 0
+}
+
+// sortItems ordena igual que applyFilter en GormStore: por la columna y después por id,
+// ambos en el sentido de Sort
+func sortItems[T any](items []T, order Sort) {
+	column := order.column()
+	sort.Slice(items, func(i, j int) bool {
+		a, b := reflect.ValueOf(items[i]), reflect.ValueOf(items[j])
+		result := compareColumn(a, b, column)
+		if result == 0 {
+			result = compareColumn(a, b, "id")
+		}
+		if order.Desc {
+			return result > 0
+		}
+		return result < 0
+	})
+}
+
+// idOf id del registro
+func idOf(item interface{}) uint {
+	return uint(reflect.ValueOf(item).FieldByName("ID").Uint())
+}
+
+// setTime asigna un campo time.Time; si onlyZero, solo cuando está vacío
+func setTime(value reflect.Value, name string, t time.Time, onlyZero bool) {
+	field := value.FieldByName(name)
+	if !field.IsValid() {
+		return
+	}
+	if onlyZero && !field.Interface().(time.Time).IsZero() {
+		return
+	}
+	field.Set(reflect.ValueOf(t))
+}
+
+// withoutRelations quita las relaciones antes de guardar, como Omit(clause.Associations)
+func withoutRelations[T any](item T) T {
+	switch v := any(&item).(type) {
+	case *models.Product:
+		v.Category = models.Category{}
+		v.Attributes = nil
+		v.Variants = nil
+		v.Tags = nil
+	case *models.Category:
+		v.Products = nil
+	case *models.Collection:
+		v.Products = nil
+	}
+	return item
+}
+
+// inCategory la categoría id es target o, con subcategories, una de sus subcategorías. Requiere mu.
+func inCategory(d *memoryData, id, target uint, subcategories bool) bool {
+	if id == target {
+		return true
+	}
+	parent := d.categories.items[target].Path
+	return subcategories && parent != "" && strings.HasPrefix(d.categories.items[id].Path, parent)
+}
+
+// categoryBySlug id de la categoría con el slug; 0 si no existe. Requiere mu.
+func categoryBySlug(d *memoryData, slug string) uint {
+	for id, category := range d.categories.items {
+		if category.Slug == slug {
+			return id
+		}
+	}
+	return 0
+}
+
+// ========================================
+// ENTIDADES
+// ========================================
+
+// memoryCategories categorías con búsqueda por slug
+type memoryCategories struct {
+	memoryTable[models.Category]
+}
+
+// FindBySlug busca una categoría por slug
+func (r memoryCategories) FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	filter.Limit = 0
+	items := r.list(filter, func(category models.Category) bool { return category.Slug == slug })
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// SlugExists verifica si otra categoría usa el slug
+func (r memoryCategories) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(slug, excludeID), nil
+}
+
+// Descendants subcategorías bajo la ruta materializada, de menor a mayor profundidad
+func (r memoryCategories) Descendants(ctx context.Context, path string) ([]models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	categories := []models.Category{}
+	for _, category := range r.store.data.categories.items {
+		if path != "" && category.Path != path && strings.HasPrefix(category.Path, path) {
+			categories = append(categories, category)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Depth != categories[j].Depth {
+			return categories[i].Depth < categories[j].Depth
+		}
+		return categories[i].Path < categories[j].Path
+	})
+	return categories, nil
+}
+
+// memoryProducts productos con su categoría
+type memoryProducts struct {
+	memoryTable[models.Product]
+}
+
+// SlugExists verifica si otro producto usa el slug
+func (r memoryProducts) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(slug, excludeID), nil
+}
+
+// FindBySlug busca un producto por slug
+func (r memoryProducts) FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	filter.Limit = 0
+	items := r.list(filter, func(product models.Product) bool { return product.Slug == slug })
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// Search aproxima la búsqueda de PostgreSQL para pruebas: compara palabras sin acentos
+// ni mayúsculas que empiezan igual (en lugar de la raíz del stemmer) y, en el nombre,
+// admite una letra de diferencia. Cada palabra de la consulta debe aparecer en algún campo.
+func (r memoryProducts) Search(ctx context.Context, filter SearchFilter) ([]SearchHit, int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	terms := searchWords(filter.Query)
+	hits := []SearchHit{}
+	if len(terms) == 0 {
+		return hits, 0, nil
+	}
+	for _, product := range r.rows(r.store.data).items {
+		category, ok := r.store.data.categories.items[product.CategoryID]
+		if !product.Active || !ok || !category.Active || (filter.InStock && product.StockStatus == models.StockStatusOut) {
+			continue
+		}
+		fields := []struct {
+			text   string
+			weight float64
+			typos  bool
+		}{
+			{product.Name, 1, true},
+			{product.Features, 0.4, false},
+			{category.Name, 0.4, false},
+			{product.Description, 0.2, false},
+		}
+
+		rank := 0.0
+		for _, term := range terms {
+			best := 0.0
+			for _, field := range fields {
+				for _, word := range searchWords(field.text) {
+					if field.weight > best && wordMatches(term, word, field.typos) {
+						best = field.weight
+					}
+				}
+			}
+			if best == 0 {
+				rank = 0
+				break
+			}
+			rank += best
+		}
+		if rank == 0 {
+			continue
+		}
+
+		snippet := product.Description
+		if snippet == "" {
+			snippet = product.Features
+		}
+		product.Category = category
+		hits = append(hits, SearchHit{
+			Product: product,
+			Rank:    rank,
+			Name:    highlightWords(product.Name, terms, true),
+			Snippet: highlightWords(snippet, terms, false),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Product.ID < hits[j].Product.ID
+	})
+	total := int64(len(hits))
+	hits = hits[min(filter.Offset, len(hits)):]
+	if filter.Limit > 0 && len(hits) > filter.Limit {
+		hits = hits[:filter.Limit]
+	}
+	return hits, total, nil
+}
+
+// searchWords palabras del texto sin acentos ni mayúsculas
+func searchWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(text, isWordSeparator) {
+		if folded := utils.GenerateSlug(word); folded != "" {
+			words = append(words, folded)
+		}
+	}
+	return words
+}
+
+// isWordSeparator todo lo que no es letra ni dígito separa palabras
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// wordMatches una palabra es prefijo de la otra (plurales, género) o, con typos,
+// la palabra de la consulta tiene al menos 4 letras y difiere en una
+func wordMatches(term, word string, typos bool) bool {
+	shorter := min(len(term), len(word))
+	if shorter >= 3 && (strings.HasPrefix(word, term) || strings.HasPrefix(term, word)) {
+		return true
+	}
+	return term == word || (typos && len(term) >= 4 && withinOneEdit(term, word))
+}
+
+// withinOneEdit distancia de Levenshtein de 1 como máximo (textos ASCII)
+func withinOneEdit(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	if len(a) == len(b) {
+		return a[i+min(1, len(a)-i):] == b[i+min(1, len(b)-i):]
+	}
+	return a[i:] == b[i+1:]
+}
+
+// highlightWords marca con HighlightStart/HighlightStop las palabras del texto que coinciden
+func highlightWords(text string, terms []string, typos bool) string {
+	var result strings.Builder
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		end := start
+		for end < len(runes) && !isWordSeparator(runes[end]) {
+			end++
+		}
+		if end == start {
+			result.WriteRune(runes[start])
+			start++
+			continue
+		}
+		word := string(runes[start:end])
+		matched := false
+		if folded := utils.GenerateSlug(word); folded != "" {
+			for _, term := range terms {
+				if wordMatches(term, folded, typos) {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			result.WriteString(HighlightStart + word + HighlightStop)
+		} else {
+			result.WriteString(word)
+		}
+		start = end
+	}
+	return result.String()
+}
+
+// Similar puntúa como la consulta de PostgreSQL: misma categoría, etiquetas en común y
+// cercanía del precio
+func (r memoryProducts) Similar(ctx context.Context, product models.Product, filter Filter, exclude []uint, limit int) ([]uint, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.data
+	tags := map[uint]bool{}
+	for _, tag := range productTags(d, product.ID) {
+		tags[tag.ID] = true
+	}
+
+	var candidates []models.Product
+	scores := map[uint]float64{}
+	for _, candidate := range d.products.items {
+		if !matches(d, candidate, filter) || containsID(exclude, candidate.ID) || !visibleCategory(d, candidate.CategoryID) {
+			continue
+		}
+		score := priceProximity(product.Price, candidate.Price)
+		similar := candidate.CategoryID == product.CategoryID
+		if similar {
+			score += similarCategoryScore
+		}
+		for _, tag := range productTags(d, candidate.ID) {
+			if tags[tag.ID] {
+				score += similarTagScore
+				similar = true
+			}
+		}
+		if similar {
+			candidates = append(candidates, candidate)
+			scores[candidate.ID] = score
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	ids := make([]uint, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.ID
+	}
+	return ids, nil
+}
+
+// visibleCategory la categoría y todas sus superiores están activas. Requiere mu.
+func visibleCategory(d *memoryData, id uint) bool {
+	category, ok := d.categories.items[id]
+	if !ok {
+		return false
+	}
+	for _, ancestor := range category.PathIDs() {
+		if !d.categories.items[ancestor].Active {
+			return false
+		}
+	}
+	return category.Active
+}
+
+// priceProximity cercanía de los precios entre 0 (muy distintos o uno sin precio) y 1
+// (iguales)
+func priceProximity(a, b money.Amount) float64 {
+	low, high := a, b
+	if low > high {
+		low, high = high, low
+	}
+	if low <= 0 {
+		return 0
+	}
+	return float64(low) / float64(high)
+}
+
+// memoryVariants variantes con SKU único
+type memoryVariants struct {
+	memoryTable[models.ProductVariant]
+}
+
+// SKUExists verifica si otra variante usa el SKU
+func (r memoryVariants) SKUExists(ctx context.Context, sku string, excludeID uint) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, variant := range r.rows(r.store.data).items {
+		if id != excludeID && variant.SKU == sku {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memoryCurrencies monedas con búsqueda por código
+type memoryCurrencies struct {
+	memoryTable[models.Currency]
+}
+
+// FindByCode busca una moneda por código
+func (r memoryCurrencies) FindByCode(ctx context.Context, code string) (*models.Currency, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	items := r.list(Filter{Sort: SortByID}, func(currency models.Currency) bool { return currency.Code == code })
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// Update guarda la moneda y, si cambia el código, lo cambia en sus precios (ON UPDATE CASCADE)
+func (r memoryCurrencies) Update(ctx context.Context, currency *models.Currency) error {
+	before, err := r.Get(ctx, currency.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.memoryTable.Update(ctx, currency); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for id, price := range r.store.data.prices.items {
+		if price.Currency == before.Code {
+			price.Currency = currency.Code
+			r.store.data.prices.items[id] = price
+		}
+	}
+	return nil
+}
+
+// memoryPrices precios por moneda de productos y variantes
+type memoryPrices struct {
+	table memoryTable[models.ProductPrice]
+}
+
+// ForProducts precios por producto, variante (primero los del producto) y moneda
+func (r memoryPrices) ForProducts(ctx context.Context, productIDs []uint, currency string) ([]models.ProductPrice, error) {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	prices := r.table.list(Filter{Sort: SortByID}, func(price models.ProductPrice) bool {
+		return (productIDs == nil || containsID(productIDs, price.ProductID)) && (currency == "" || price.Currency == currency)
+	})
+	sort.SliceStable(prices, func(i, j int) bool {
+		a, b := prices[i], prices[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if variantA, variantB := variantOrder(a), variantOrder(b); variantA != variantB {
+			return variantA < variantB
+		}
+		return a.Currency < b.Currency
+	})
+	return prices, nil
+}
+
+// SetPrices borra los precios del producto e inserta los nuevos
+func (r memoryPrices) SetPrices(ctx context.Context, productID uint, prices []models.ProductPrice) error {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	d := r.table.store.data
+	deletePrices(d, func(price models.ProductPrice) bool { return price.ProductID == productID })
+	if d.prices.items == nil {
+		d.prices.items = make(map[uint]models.ProductPrice)
+	}
+	now := time.Now()
+	for i := range prices {
+		d.prices.nextID++
+		prices[i].ID, prices[i].ProductID = d.prices.nextID, productID
+		prices[i].CreatedAt, prices[i].UpdatedAt = now, now
+		d.prices.items[prices[i].ID] = prices[i]
+	}
+	return nil
+}
+
+// Stats número de precios y última modificación
+func (r memoryPrices) Stats(ctx context.Context) (Stats, error) {
+	return r.table.Stats(ctx, Filter{})
+}
+
+// memoryRelations relaciones entre productos
+type memoryRelations struct {
+	table memoryTable[models.ProductRelation]
+}
+
+// ForProducts relaciones por producto, tipo y posición
+func (r memoryRelations) ForProducts(ctx context.Context, productIDs []uint) ([]models.ProductRelation, error) {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	relations := r.table.list(Filter{Sort: SortByID}, func(relation models.ProductRelation) bool {
+		return productIDs == nil || containsID(productIDs, relation.ProductID)
+	})
+	sort.SliceStable(relations, func(i, j int) bool {
+		a, b := relations[i], relations[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Position < b.Position
+	})
+	return relations, nil
+}
+
+// SetRelations borra las relaciones del producto e inserta las nuevas
+func (r memoryRelations) SetRelations(ctx context.Context, productID uint, relations []models.ProductRelation) error {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	d := r.table.store.data
+	deleteRelations(d, func(relation models.ProductRelation) bool { return relation.ProductID == productID })
+	if d.relations.items == nil {
+		d.relations.items = make(map[uint]models.ProductRelation)
+	}
+	now := time.Now()
+	for i := range relations {
+		d.relations.nextID++
+		relations[i].ID, relations[i].ProductID = d.relations.nextID, productID
+		relations[i].CreatedAt, relations[i].UpdatedAt = now, now
+		d.relations.items[relations[i].ID] = relations[i]
+	}
+	return nil
+}
+
+// Stats número de relaciones y última modificación
+func (r memoryRelations) Stats(ctx context.Context) (Stats, error) {
+	return r.table.Stats(ctx, Filter{})
+}
+
+// variantOrder id de la variante del precio; 0 (primero) para el del producto
+func variantOrder(price models.ProductPrice) uint {
+	if price.VariantID == nil {
+		return 0
+	}
+	return *price.VariantID
+}
+
+func containsID(list []uint, id uint) bool {
+	for _, item := range list {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryStock historial de existencias
+type memoryStock struct {
+	table memoryTable[models.StockMovement]
+}
+
+// Create inserta un movimiento
+func (r memoryStock) Create(ctx context.Context, movement *models.StockMovement) error {
+	return r.table.Create(ctx, movement)
+}
+
+// List lista movimientos, más recientes primero
+func (r memoryStock) List(ctx context.Context, filter StockFilter) ([]models.StockMovement, error) {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	movements := r.table.list(Filter{Sort: SortByNewest, Limit: filter.Limit}, func(movement models.StockMovement) bool {
+		switch {
+		case filter.ProductID != 0 && movement.ProductID != filter.ProductID,
+			filter.VariantID != nil && (movement.VariantID == nil || *movement.VariantID != *filter.VariantID):
+			return false
+		}
+		return true
+	})
+	return movements, nil
+}
+
+// LockProduct no hace nada: las transacciones en memoria ya se serializan
+func (r memoryStock) LockProduct(ctx context.Context, productID uint) error {
+	return nil
+}
+
+// memoryAttributes definiciones de atributos y valores de los productos
+type memoryAttributes struct {
+	memoryTable[models.AttributeDefinition]
+}
+
+// Values valores con su definición, por orden de la definición
+func (r memoryAttributes) Values(ctx context.Context, productIDs []uint) ([]models.ProductAttribute, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	values := productValues(r.store.data, func(value models.ProductAttribute) bool {
+		if productIDs == nil {
+			return true
+		}
+		for _, id := range productIDs {
+			if value.ProductID == id {
+				return true
+			}
+		}
+		return false
+	})
+	if values == nil {
+		values = []models.ProductAttribute{}
+	}
+	return values, nil
+}
+
+// SetValues borra los valores del producto e inserta los nuevos
+func (r memoryAttributes) SetValues(ctx context.Context, productID uint, values []models.ProductAttribute) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.data
+	deleteValues(d, func(value models.ProductAttribute) bool { return value.ProductID == productID })
+	if d.values.items == nil {
+		d.values.items = make(map[uint]models.ProductAttribute)
+	}
+	now := time.Now()
+	for i := range values {
+		d.values.nextID++
+		values[i].ID, values[i].ProductID = d.values.nextID, productID
+		values[i].CreatedAt, values[i].UpdatedAt = now, now
+		stored := values[i]
+		stored.Attribute = models.AttributeDefinition{}
+		d.values.items[stored.ID] = stored
+	}
+	return nil
+}
+
+// FacetCounts cuenta los productos del filtro por valor, de más a menos frecuente
+func (r memoryAttributes) FacetCounts(ctx context.Context, attributeID uint, filter Filter) ([]FacetValue, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.data
+	counts := map[string]int64{}
+	for _, value := range d.values.items {
+		if product, ok := d.products.items[value.ProductID]; ok && value.AttributeID == attributeID && matches(d, product, filter) {
+			counts[value.Value]++
+		}
+	}
+	values := []FacetValue{}
+	for value, count := range counts {
+		values = append(values, FacetValue{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	return values, nil
+}
+
+// memoryTags etiquetas y su asignación a los productos
+type memoryTags struct {
+	memoryTable[models.Tag]
+}
+
+// FindBySlug busca una etiqueta por slug
+func (r memoryTags) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	items := r.list(Filter{Sort: SortByID}, func(tag models.Tag) bool { return tag.Slug == slug })
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// SlugExists verifica si otra etiqueta usa el slug
+func (r memoryTags) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(slug, excludeID), nil
+}
+
+// ForProducts etiquetas de los productos, por producto
+func (r memoryTags) ForProducts(ctx context.Context, productIDs []uint) ([]models.ProductTag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	links := []models.ProductTag{}
+	for _, link := range r.store.data.tagLinks.items {
+		if productIDs == nil || containsID(productIDs, link.ProductID) {
+			links = append(links, link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].ProductID != links[j].ProductID {
+			return links[i].ProductID < links[j].ProductID
+		}
+		return links[i].TagID < links[j].TagID
+	})
+	return links, nil
+}
+
+// SetProductTags borra las etiquetas del producto e inserta las nuevas
+func (r memoryTags) SetProductTags(ctx context.Context, productID uint, tagIDs []uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.data
+	deleteTagLinks(d, func(link models.ProductTag) bool { return link.ProductID == productID })
+	if d.tagLinks.items == nil {
+		d.tagLinks.items = make(map[uint]models.ProductTag)
+	}
+	now := time.Now()
+	for _, tagID := range tagIDs {
+		d.tagLinks.nextID++
+		d.tagLinks.items[d.tagLinks.nextID] = models.ProductTag{
+			ID: d.tagLinks.nextID, ProductID: productID, TagID: tagID, CreatedAt: now, UpdatedAt: now,
+		}
+	}
+	return nil
+}
+
+// memoryCollections colecciones con sus productos elegidos a mano
+type memoryCollections struct {
+	memoryTable[models.Collection]
+}
+
+// FindBySlug busca una colección por slug
+func (r memoryCollections) FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Collection, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	filter.Limit = 0
+	items := r.list(filter, func(collection models.Collection) bool { return collection.Slug == slug })
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// SlugExists verifica si otra colección usa el slug
+func (r memoryCollections) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	return r.slugExists(slug, excludeID), nil
+}
+
+// Items productos de las colecciones por colección y posición
+func (r memoryCollections) Items(ctx context.Context, collectionIDs []uint) ([]models.CollectionProduct, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	items := []models.CollectionProduct{}
+	for _, item := range r.store.data.items.items {
+		if collectionIDs == nil || containsID(collectionIDs, item.CollectionID) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.CollectionID != b.CollectionID {
+			return a.CollectionID < b.CollectionID
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	})
+	return items, nil
+}
+
+// SetItems borra los productos de la colección e inserta los nuevos
+func (r memoryCollections) SetItems(ctx context.Context, collectionID uint, items []models.CollectionProduct) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.data
+	deleteItems(d, func(item models.CollectionProduct) bool { return item.CollectionID == collectionID })
+	if d.items.items == nil {
+		d.items.items = make(map[uint]models.CollectionProduct)
+	}
+	now := time.Now()
+	for i := range items {
+		d.items.nextID++
+		items[i].ID, items[i].CollectionID = d.items.nextID, collectionID
+		items[i].CreatedAt, items[i].UpdatedAt = now, now
+		d.items.items[items[i].ID] = items[i]
+	}
+	return nil
+}
+
+// memoryUsers usuarios con búsqueda por username o email
+type memoryUsers struct {
+	memoryTable[models.User]
+}
+
+// FindByLogin busca por username o email
+func (r memoryUsers) FindByLogin(ctx context.Context, login string) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	items := r.list(Filter{Sort: SortByID}, func(user models.User) bool {
+		return user.Username == login || user.Email == login
+	})
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// memoryConfigs configuración clave/valor
+type memoryConfigs struct {
+	table memoryTable[models.SiteConfig]
+}
+
+// List devuelve todas las claves
+func (r memoryConfigs) List(ctx context.Context) ([]models.SiteConfig, error) {
+	return r.table.List(ctx, Filter{Sort: SortByID})
+}
+
+// FindByKey busca una clave
+func (r memoryConfigs) FindByKey(ctx context.Context, key string) (*models.SiteConfig, error) {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	items := r.table.list(Filter{Sort: SortByID}, func(config models.SiteConfig) bool { return config.Key == key })
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// Create inserta una clave
+func (r memoryConfigs) Create(ctx context.Context, item *models.SiteConfig) error {
+	return r.table.Create(ctx, item)
+}
+
+// Update guarda una clave existente
+func (r memoryConfigs) Update(ctx context.Context, item *models.SiteConfig) error {
+	return r.table.Update(ctx, item)
+}
+
+// Stats número de claves y última modificación
+func (r memoryConfigs) Stats(ctx context.Context) (Stats, error) {
+	return r.table.Stats(ctx, Filter{})
+}
+
+// memoryAudit registro de auditoría
+type memoryAudit struct {
+	table memoryTable[models.AuditEvent]
+}
+
+// Create inserta un evento
+func (r memoryAudit) Create(ctx context.Context, event *models.AuditEvent) error {
+	return r.table.Create(ctx, event)
+}
+
+// List lista eventos, más recientes primero
+func (r memoryAudit) List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error) {
+	r.table.store.mu.Lock()
+	defer r.table.store.mu.Unlock()
+
+	events := r.table.list(Filter{Sort: SortByNewest}, func(event models.AuditEvent) bool {
+		switch {
+		case filter.UserID != nil && (event.UserID == nil || *event.UserID != *filter.UserID),
+			filter.Username != "" && event.Username != filter.Username,
+			filter.EntityType != "" && event.EntityType != filter.EntityType,
+			filter.EntityID != "" && event.EntityID != filter.EntityID,
+			filter.Action != "" && event.Action != filter.Action,
+			!filter.From.IsZero() && event.CreatedAt.Before(filter.From),
+			!filter.To.IsZero() && !event.CreatedAt.Before(filter.To):
+			return false
+		}
+		return true
+	})
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"website/backend/models"
+	"website/backend/money"
+)
+
+// ========================================
+// ERRORES
+// ========================================
+
+// ErrNotFound el registro no existe
+var ErrNotFound = errors.New("registro no encontrado")
+
+// ErrDuplicate viola una restricción de unicidad (slug, username, email, key)
+var ErrDuplicate = errors.New("registro duplicado")
+
+// ========================================
+// CONSULTAS
+// ========================================
+
+// Sort orden de los listados; el valor cero ordena por "order" ascendente.
+// Los empates se deshacen por id en el mismo sentido, así el orden es total
+// y la paginación por cursor no repite ni salta filas.
+type Sort struct {
+	Column string // Columna de SortColumns ("" = "order")
+	Desc   bool
+}
+
+var (
+	SortByOrder  = Sort{Column: "order"}                  // Slides, categorías, contactos
+	SortByNewest = Sort{Column: "created_at", Desc: true} // Productos
+	SortByID     = Sort{Column: "id"}                     // Usuarios, copias de seguridad
+	SortByName   = Sort{Column: "name"}                   // Etiquetas
+)
+
+// column columna efectiva del orden
+func (s Sort) column() string {
+	if s.Column == "" {
+		return "order"
+	}
+	return s.Column
+}
+
+// Filter criterios de un listado; el valor cero lista todo ordenado por "order"
+type Filter struct {
+	Active        *bool             // nil = activos e inactivos
+	IDs           []uint            // Solo estos ids (nil = todos)
+	CategoryID    uint              // Solo productos: 0 = todas las categorías
+	CategorySlug  string            // Solo productos: categoría por slug ("" = todas)
+	Subcategories bool              // Solo productos: CategoryID y CategorySlug incluyen sus subcategorías
+	MinPrice      *money.Amount     // Solo productos: precio mínimo en la moneda base (inclusivo)
+	MaxPrice      *money.Amount     // Solo productos: precio máximo en la moneda base (inclusivo)
+	HasImage      *bool             // Solo productos: con o sin imágenes
+	Attributes    []AttributeFilter // Solo productos: todos los atributos deben cumplirse
+	TagID         uint              // Solo productos: con la etiqueta (0 = todas)
+	TagSlug       string            // Solo productos: con la etiqueta por slug ("" = todas)
+	CreatedAfter  *time.Time        // Solo productos: creados desde esta fecha (inclusivo)
+	StockStatus   []string          // Solo productos: alguno de estos estados de disponibilidad
+	InStock       bool              // Solo productos (y los de WithProducts): sin los agotados
+	ProductID     uint              // Solo variantes: 0 = todos los productos
+	WithProducts  bool              // Solo categorías: cargar sus productos (con el mismo filtro Active)
+	Sort          Sort
+	Limit         int     // 0 = sin límite
+	Offset        int     // Filas a saltar (paginación por páginas)
+	After         *Cursor // Filas posteriores al cursor según Sort (paginación por cursor)
+}
+
+// ActiveOnly filtro de elementos activos ordenados por "order"
+func ActiveOnly() Filter {
+	active := true
+	return Filter{Active: &active}
+}
+
+// Stats agregados de un listado para calcular sus validadores HTTP (ETag, Last-Modified)
+type Stats struct {
+	Count        int64     // Filas que cumplen el filtro
+	LastModified time.Time // Mayor updated_at; cero si no hay filas
+}
+
+// StatsQuery agregados pedidos a Store.BatchStats: los de Model (puntero a un modelo,
+// p. ej. new(models.Product)) que cumplen Filter o, con Audit, el número de eventos de
+// auditoría que cumplen el filtro y el mayor created_at
+type StatsQuery struct {
+	Model  interface{}
+	Filter Filter       // Se ignoran el orden y la paginación; sin efecto en precios, relaciones y configuración
+	Audit  *AuditFilter // Si no es nil se ignoran Model y Filter
+}
+
+// SearchFilter búsqueda de texto en los productos visibles, por relevancia
+type SearchFilter struct {
+	Query   string // Texto del usuario (admite "frase exacta", OR y -excluir)
+	InStock bool   // Sin los productos agotados
+	Limit   int    // 0 = sin límite
+	Offset  int
+}
+
+// Marcas de las coincidencias en SearchHit.Name y SearchHit.Snippet; son caracteres
+// de uso privado para que la capa de presentación escape el texto antes de resaltarlo
+const (
+	HighlightStart = "\ue000"
+	HighlightStop  = "\ue001"
+)
+
+// SearchHit producto encontrado con su relevancia y los textos con las coincidencias marcadas
+type SearchHit struct {
+	Product models.Product // Con su categoría
+	Rank    float64
+	Name    string // Nombre con las coincidencias marcadas
+	Snippet string // Fragmento de la descripción (o de las características) marcado
+}
+
+// AttributeFilter condición sobre un atributo de producto, identificado por su key
+type AttributeFilter struct {
+	Key    string
+	Values []string // Alguno de estos valores canónicos (vacío = cualquiera)
+	Min    *float64 // Solo atributos number: mínimo (inclusivo)
+	Max    *float64 // Solo atributos number: máximo (inclusivo)
+}
+
+// FacetValue valor de un atributo y número de productos que lo tienen
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// AuditFilter criterios del listado de auditoría (más recientes primero)
+type AuditFilter struct {
+	UserID     *uint
+	Username   string
+	EntityType string
+	EntityID   string
+	Action     string
+	From       time.Time // Inclusivo; cero = sin límite
+	To         time.Time // Exclusivo; cero = sin límite
+	Limit      int
+}
+
+// StockFilter criterios del historial de existencias (más recientes primero)
+type StockFilter struct {
+	ProductID uint  // 0 = todos los productos
+	VariantID *uint // nil = el producto y todas sus variantes
+	Limit     int   // 0 = sin límite
+}
+
+// ========================================
+// REPOSITORIOS
+// ========================================
+
+// CRUD operaciones por id comunes a todas las entidades
+type CRUD[T any] interface {
+	Get(ctx context.Context, id uint) (*T, error)
+	Create(ctx context.Context, item *T) error
+	Update(ctx context.Context, item *T) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter Filter) ([]T, error)
+	Count(ctx context.Context, filter Filter) (int64, error)
+	// Stats cuenta y obtiene el mayor updated_at con una sola consulta agregada
+	Stats(ctx context.Context, filter Filter) (Stats, error)
+}
+
+// SlideRepository slides del carrusel de inicio
+type SlideRepository interface {
+	CRUD[models.Slide]
+}
+
+// CategoryRepository categorías del catálogo
+type CategoryRepository interface {
+	CRUD[models.Category]
+	// FindBySlug busca por slug respetando filter.Active y filter.WithProducts
+	FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Category, error)
+	SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	// Descendants subcategorías de todos los niveles de la categoría con esa ruta
+	// materializada (Path), sin ella misma, de menor a mayor profundidad
+	Descendants(ctx context.Context, path string) ([]models.Category, error)
+}
+
+// ProductRepository productos; los listados cargan su categoría, sus atributos, sus variantes
+// y sus etiquetas
+type ProductRepository interface {
+	CRUD[models.Product]
+	SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	// FindBySlug busca por slug respetando filter.Active; incluye la categoría
+	FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Product, error)
+	// Search busca en el nombre, las características, la descripción y el nombre de la
+	// categoría de los productos activos de categorías activas
+	// Retorna: Página de resultados y total de coincidencias
+	Search(ctx context.Context, filter SearchFilter) ([]SearchHit, int64, error)
+	// Similar ids de los productos del filtro (sin paginación) más parecidos a product:
+	// de su misma categoría o con alguna etiqueta en común, de categorías visibles (activas,
+	// como todas sus superiores) y sin los de exclude. Ordena por la misma categoría, las
+	// etiquetas en común y la cercanía del precio y, a igualdad, los más recientes
+	// Retorna: Hasta limit ids, de más a menos parecidos
+	Similar(ctx context.Context, product models.Product, filter Filter, exclude []uint, limit int) ([]uint, error)
+}
+
+// Puntuación de Similar: la misma categoría pesa más que cada etiqueta en común y la
+// cercanía del precio (entre 0 y 1) solo desempata
+const (
+	similarCategoryScore = 4
+	similarTagScore      = 1
+)
+
+// VariantRepository variantes de los productos
+type VariantRepository interface {
+	CRUD[models.ProductVariant]
+	// SKUExists verifica si otra variante de cualquier producto usa el SKU
+	SKUExists(ctx context.Context, sku string, excludeID uint) (bool, error)
+}
+
+// CurrencyRepository monedas en las que se muestran los precios
+type CurrencyRepository interface {
+	CRUD[models.Currency]
+	// FindByCode busca por código ISO 4217 (en mayúsculas)
+	FindByCode(ctx context.Context, code string) (*models.Currency, error)
+}
+
+// PriceRepository precios fijados a mano por moneda para productos y variantes
+type PriceRepository interface {
+	// ForProducts precios de los productos (productIDs nil = todos) en la moneda
+	// ("" = todas), por producto, variante y moneda
+	ForProducts(ctx context.Context, productIDs []uint, currency string) ([]models.ProductPrice, error)
+	// SetPrices sustituye todos los precios del producto y de sus variantes
+	SetPrices(ctx context.Context, productID uint, prices []models.ProductPrice) error
+	// Stats número de precios y última modificación
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// RelationRepository relaciones entre productos elegidas en el CMS
+type RelationRepository interface {
+	// ForProducts relaciones de los productos (productIDs nil = todas), por producto,
+	// tipo y posición
+	ForProducts(ctx context.Context, productIDs []uint) ([]models.ProductRelation, error)
+	// SetRelations sustituye todas las relaciones del producto
+	SetRelations(ctx context.Context, productID uint, relations []models.ProductRelation) error
+	// Stats número de relaciones y última modificación
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// StockRepository historial de movimientos de existencias
+type StockRepository interface {
+	Create(ctx context.Context, movement *models.StockMovement) error
+	List(ctx context.Context, filter StockFilter) ([]models.StockMovement, error)
+	// LockProduct bloquea la fila del producto hasta el final de la transacción para
+	// que dos ajustes simultáneos no partan de las mismas existencias
+	LockProduct(ctx context.Context, productID uint) error
+}
+
+// AttributeRepository definiciones de atributos por categoría y sus valores en los productos
+type AttributeRepository interface {
+	CRUD[models.AttributeDefinition]
+	// Values valores de los productos con su definición, por orden de la definición
+	// (productIDs nil = todos los productos)
+	Values(ctx context.Context, productIDs []uint) ([]models.ProductAttribute, error)
+	// SetValues sustituye todos los valores del producto
+	SetValues(ctx context.Context, productID uint, values []models.ProductAttribute) error
+	// FacetCounts número de productos que cumplen filter por cada valor del atributo
+	FacetCounts(ctx context.Context, attributeID uint, filter Filter) ([]FacetValue, error)
+}
+
+// TagRepository etiquetas de los productos
+type TagRepository interface {
+	CRUD[models.Tag]
+	FindBySlug(ctx context.Context, slug string) (*models.Tag, error)
+	SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	// ForProducts etiquetas de los productos (productIDs nil = todos), por producto
+	ForProducts(ctx context.Context, productIDs []uint) ([]models.ProductTag, error)
+	// SetProductTags sustituye las etiquetas del producto
+	SetProductTags(ctx context.Context, productID uint, tagIDs []uint) error
+}
+
+// CollectionRepository colecciones de productos
+type CollectionRepository interface {
+	CRUD[models.Collection]
+	// FindBySlug busca por slug respetando filter.Active
+	FindBySlug(ctx context.Context, slug string, filter Filter) (*models.Collection, error)
+	SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	// Items productos de las colecciones manuales (collectionIDs nil = todas), por
+	// colección y posición
+	Items(ctx context.Context, collectionIDs []uint) ([]models.CollectionProduct, error)
+	// SetItems sustituye los productos de la colección
+	SetItems(ctx context.Context, collectionID uint, items []models.CollectionProduct) error
+}
+
+// ContactRepository canales de contacto
+type ContactRepository interface {
+	CRUD[models.ContactInfo]
+}
+
+// ConfigRepository configuración del sitio (clave/valor)
+type ConfigRepository interface {
+	List(ctx context.Context) ([]models.SiteConfig, error)
+	FindByKey(ctx context.Context, key string) (*models.SiteConfig, error)
+	Create(ctx context.Context, item *models.SiteConfig) error
+	Update(ctx context.Context, item *models.SiteConfig) error
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// UserRepository usuarios del CMS
+type UserRepository interface {
+	CRUD[models.User]
+	// FindByLogin busca por username o email
+	FindByLogin(ctx context.Context, login string) (*models.User, error)
+}
+
+// AuditRepository registro de auditoría
+type AuditRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error)
+}
+
+// Store agrupa los repositorios y permite usarlos dentro de una transacción
+type Store interface {
+	Slides() SlideRepository
+	Categories() CategoryRepository
+	Products() ProductRepository
+	Variants() VariantRepository
+	Currencies() CurrencyRepository
+	Prices() PriceRepository
+	Relations() RelationRepository
+	Stock() StockRepository
+	Attributes() AttributeRepository
+	Tags() TagRepository
+	Collections() CollectionRepository
+	Contacts() ContactRepository
+	Configs() ConfigRepository
+	Users() UserRepository
+	Audit() AuditRepository
+
+	// BatchStats agregados de varias consultas en una sola ida y vuelta, en su orden
+	BatchStats(ctx context.Context, queries []StatsQuery) ([]Stats, error)
+
+	// SchemaVersion última migración aplicada
+	SchemaVersion(ctx context.Context) (int64, error)
+
+	// Transaction ejecuta fn con repositorios transaccionales; si fn devuelve
+	// error no se aplica ningún cambio
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+
+	// Snapshot ejecuta fn con una vista de solo lectura coherente de todos los datos
+	Snapshot(ctx context.Context, fn func(tx Store) error) error
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	"website/backend/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultShutdownTimeout tiempo máximo por defecto para drenar peticiones en curso
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Options configuración del ciclo de vida del servidor
+type Options struct {
+	ShutdownTimeout time.Duration    // Tiempo máximo para drenar peticiones en curso
+	DrainDelay      time.Duration    // Espera tras marcar /readyz como fallido antes de cerrar
+	Health          *health.Registry // Registro de readiness a marcar como "shutting_down"
+	Cleanup         []func() error   // Tareas de limpieza tras drenar (en orden)
+}
+
+// Server aplicación Fiber con su listener y su readiness
+type Server struct {
+	Name   string           // Nombre para los logs (public, cms)
+	App    *fiber.App       // Aplicación Fiber
+	Listen func() error     // Función que bloquea sirviendo (app.Listen o app.Listener)
+	Health *health.Registry // Registro de readiness a marcar como "shutting_down" (puede ser nil)
+}
+
+// Serve arranca el servidor y lo apaga ordenadamente al recibir SIGINT o SIGTERM
+// Parámetros:
+//   - app: Aplicación Fiber
+//   - listen: Función que bloquea sirviendo (app.Listen o app.Listener)
+//   - options: Tiempos de apagado, readiness y tareas de limpieza
+//
+// Retorna: Error del listener o de alguna tarea de apagado
+func Serve(app *fiber.App, listen func() error, options Options) error {
+	return ServeAll([]Server{{App: app, Listen: listen, Health: options.Health}}, options)
+}
+
+// ServeAll arranca varios servidores en el mismo proceso y los apaga juntos al recibir
+// SIGINT o SIGTERM, o cuando alguno de ellos termina con error
+// Parámetros:
+//   - servers: Aplicaciones con su listener y su readiness
+//   - options: Tiempos de apagado y tareas de limpieza (options.Health se ignora)
+//
+// Retorna: Errores combinados de los listeners y del apagado
+func ServeAll(servers []Server, options Options) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	type result struct {
+		index int
+		err   error
+	}
+	finished := make(chan result, len(servers))
+	for i, srv := range servers {
+		go func(i int, listen func() error) {
+			finished <- result{index: i, err: listen()}
+		}(i, srv.Listen)
+	}
+
+	// Canal por servidor con el resultado de su listener (nil si ya terminó)
+	listenErrs := make([]<-chan error, len(servers))
+	forward := make([]chan error, len(servers))
+	for i := range servers {
+		forward[i] = make(chan error, 1)
+		listenErrs[i] = forward[i]
+	}
+
+	var failed error
+	select {
+	case first := <-finished:
+		// Un listener terminó sin señal: error al arrancar; se apagan los demás
+		srv := servers[first.index]
+		failed = first.err
+		if failed == nil {
+			failed = errors.New("el servidor terminó inesperadamente")
+		}
+		failed = withName(srv, failed)
+		listenErrs[first.index] = nil
+		// Sin señal no hay balanceador que avisar: se cierra sin esperar
+		options.DrainDelay = 0
+	case sig := <-signals:
+		slog.Info("señal recibida, iniciando apagado ordenado", "signal", sig.String(), "timeout", options.ShutdownTimeout.String())
+	}
+	go func() {
+		for range servers {
+			r := <-finished
+			forward[r.index] <- r.err
+		}
+	}()
+
+	if failed != nil && len(servers) == 1 {
+		return errors.Join(failed, runCleanup(options.Cleanup))
+	}
+	return errors.Join(failed, shutdownAll(servers, listenErrs, options))
+}
+
+// Shutdown marca la readiness como fallida, deja de aceptar conexiones,
+// drena las peticiones en curso y ejecuta la limpieza
+// Parámetros:
+//   - app: Aplicación Fiber
+//   - listenErr: Canal por el que el listener devuelve su resultado (puede ser nil)
+//   - options: Tiempos de apagado, readiness y tareas de limpieza
+//
+// Retorna: Errores combinados del apagado y la limpieza
+func Shutdown(app *fiber.App, listenErr <-chan error, options Options) error {
+	return shutdownAll([]Server{{App: app, Health: options.Health}}, []<-chan error{listenErr}, options)
+}
+
+// shutdownAll apaga los servidores en paralelo y después ejecuta la limpieza
+func shutdownAll(servers []Server, listenErrs []<-chan error, options Options) error {
+	for _, srv := range servers {
+		if srv.Health != nil {
+			srv.Health.SetShuttingDown()
+		}
+	}
+
+	// Dar tiempo al balanceador para ver /readyz en 503 antes de cerrar
+	if options.DrainDelay > 0 {
+		time.Sleep(options.DrainDelay)
+	}
+
+	timeout := options.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for i, srv := range servers {
+		wg.Add(1)
+		go func(srv Server, listenErr <-chan error) {
+			defer wg.Done()
+			var serverErrs []error
+			if err := srv.App.ShutdownWithTimeout(timeout); err != nil {
+				serverErrs = append(serverErrs, err)
+			}
+			if listenErr != nil {
+				if err := <-listenErr; err != nil {
+					serverErrs = append(serverErrs, err)
+				}
+			}
+			if err := errors.Join(serverErrs...); err != nil {
+				mu.Lock()
+				errs = append(errs, withName(srv, err))
+				mu.Unlock()
+			}
+		}(srv, listenErrs[i])
+	}
+	wg.Wait()
+	errs = append(errs, runCleanup(options.Cleanup))
+
+	err := errors.Join(errs...)
+	if err != nil {
+		slog.Error("apagado con errores", "error", err)
+	} else {
+		slog.Info("apagado completado")
+	}
+	return err
+}
+
+// withName antepone el nombre del servidor al error (si tiene nombre)
+func withName(srv Server, err error) error {
+	if srv.Name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", srv.Name, err)
+}
+
+// runCleanup ejecuta todas las tareas de limpieza aunque alguna falle
+func runCleanup(tasks []func() error) error {
+	var errs []error
+	for _, task := range tasks {
+		if err := task(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
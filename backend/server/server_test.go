@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"website/backend/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	started := make(chan struct{})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.SendString("terminado")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- app.Listener(ln) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	registry := health.NewRegistry()
+	registry.SetReady()
+	cleaned := false
+	err = Shutdown(app, listenErr, Options{
+		ShutdownTimeout: 5 * time.Second,
+		Health:          registry,
+		Cleanup:         []func() error{func() error { cleaned = true; return nil }},
+	})
+	if err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if got := <-response; got.err != nil || got.body != "terminado" {
+		t.Fatalf("la petición en curso no se drenó: %+v", got)
+	}
+	if registry.State() != health.StateShuttingDown {
+		t.Error("la readiness no se marcó como shutting_down")
+	}
+	if !cleaned {
+		t.Error("no se ejecutó la limpieza")
+	}
+	if _, err := net.DialTimeout("tcp", ln.Addr().String(), 100*time.Millisecond); err == nil {
+		t.Error("el servidor sigue aceptando conexiones")
+	}
+}
+
+func TestServeAllStopsEveryServerWhenOneFails(t *testing.T) {
+	running := fiber.New(fiber.Config{DisableStartupMessage: true})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+
+	// El segundo servidor falla al arrancar cuando el primero ya sirve
+	started := make(chan struct{})
+	running.Hooks().OnListen(func(fiber.ListenData) error {
+		close(started)
+		return nil
+	})
+	failing := errors.New("puerto ocupado")
+
+	cleaned := false
+	err = ServeAll([]Server{
+		{Name: "public", App: running, Listen: func() error { return running.Listener(ln) }},
+		{Name: "cms", App: fiber.New(), Listen: func() error {
+			<-started
+			return failing
+		}},
+	}, Options{
+		ShutdownTimeout: 5 * time.Second,
+		DrainDelay:      time.Hour,
+		Cleanup:         []func() error{func() error { cleaned = true; return nil }},
+	})
+
+	if !errors.Is(err, failing) || !strings.Contains(err.Error(), "cms: puerto ocupado") {
+		t.Fatalf("ServeAll = %v, se esperaba el error del CMS", err)
+	}
+	if !cleaned {
+		t.Error("no se ejecutó la limpieza")
+	}
+	if _, err := net.DialTimeout("tcp", ln.Addr().String(), 100*time.Millisecond); err == nil {
+		t.Error("el servidor público sigue aceptando conexiones")
+	}
+}
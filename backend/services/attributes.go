@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"website/backend/cache"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// ATRIBUTOS DE PRODUCTO
+// ========================================
+
+// attributeTextLength longitud máxima de un valor de texto
+const attributeTextLength = 200
+
+// AttributeService atributos tipados de los productos de cada categoría; la key se
+// genera a partir del nombre y es única dentro de la categoría
+type AttributeService struct {
+	crud[models.AttributeDefinition]
+}
+
+func newAttributeService(store repository.Store, changes *changes) *AttributeService {
+	service := &AttributeService{crud: newCRUD(store, changes, "attribute", func(s repository.Store) repository.CRUD[models.AttributeDefinition] {
+		return s.Attributes()
+	})}
+	// Los productos y sus facetas incluyen los atributos
+	service.tags = func(_ context.Context, attribute *models.AttributeDefinition) []string {
+		return []string{cache.TagProducts, cache.TagCategories, cache.CategoryTag(attribute.CategoryID)}
+	}
+	service.prepare = prepareDefinition
+	return service
+}
+
+// ForCategory definiciones de la categoría por orden
+func (s *AttributeService) ForCategory(ctx context.Context, categoryID uint) ([]models.AttributeDefinition, error) {
+	return s.store.Attributes().List(ctx, repository.Filter{CategoryID: categoryID, Sort: repository.SortByOrder})
+}
+
+// prepareDefinition completa la key, descarta la unidad y las opciones que no aplican al
+// tipo y no permite cambios que invaliden los valores ya guardados
+func prepareDefinition(ctx context.Context, tx repository.Store, attribute *models.AttributeDefinition) error {
+	if attribute.CategoryID != 0 {
+		_, err := tx.Categories().Get(ctx, attribute.CategoryID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return utils.NewValidationError("CategoryID no corresponde a ninguna categoría")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if attribute.Key == "" {
+		attribute.Key = utils.GenerateSlug(attribute.Name)
+	}
+	if attribute.Type != models.AttributeNumber {
+		attribute.Unit = ""
+	}
+	if attribute.Type != models.AttributeEnum {
+		attribute.Options = nil
+	}
+
+	var problems []string
+	if attribute.Type == models.AttributeEnum {
+		if len(attribute.Options) == 0 {
+			problems = append(problems, "Options es requerido en los atributos enum")
+		}
+		seen := map[string]bool{}
+		for _, option := range attribute.Options {
+			if seen[strings.ToLower(option)] {
+				problems = append(problems, "La opción "+option+" está repetida")
+			}
+			seen[strings.ToLower(option)] = true
+		}
+	}
+
+	if attribute.CategoryID != 0 {
+		siblings, err := tx.Attributes().List(ctx, repository.Filter{CategoryID: attribute.CategoryID})
+		if err != nil {
+			return err
+		}
+		for _, sibling := range siblings {
+			if sibling.ID != attribute.ID && sibling.Key == attribute.Key {
+				problems = append(problems, "Key "+attribute.Key+" ya existe en la categoría")
+			}
+		}
+	}
+
+	if attribute.ID != 0 {
+		inUse, err := definitionInUse(ctx, tx, attribute)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, inUse...)
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	return nil
+}
+
+// definitionInUse cambios de una definición que dejarían inválidos valores guardados
+func definitionInUse(ctx context.Context, tx repository.Store, attribute *models.AttributeDefinition) ([]string, error) {
+	before, err := tx.Attributes().Get(ctx, attribute.ID)
+	if err != nil {
+		return nil, err
+	}
+	used, err := tx.Attributes().FacetCounts(ctx, attribute.ID, repository.Filter{})
+	if err != nil || len(used) == 0 {
+		return nil, err
+	}
+
+	var problems []string
+	if before.Type != attribute.Type {
+		problems = append(problems, "Type no se puede cambiar: hay productos con valores de este atributo")
+	}
+	if before.CategoryID != attribute.CategoryID {
+		problems = append(problems, "CategoryID no se puede cambiar: hay productos con valores de este atributo")
+	}
+	if attribute.Type == models.AttributeEnum {
+		for _, value := range used {
+			if !containsFold(attribute.Options, value.Value) {
+				problems = append(problems, fmt.Sprintf("La opción %s está en uso en %d producto(s)", value.Value, value.Count))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// prepareAttributes valida los valores del producto con las definiciones de su categoría
+// y los deja en forma canónica, por orden de la definición. Sin Attributes (nil) al
+// actualizar se conservan los valores guardados que sigan siendo de la categoría.
+func prepareAttributes(ctx context.Context, tx repository.Store, product *models.Product) error {
+	if product.CategoryID == 0 {
+		return nil
+	}
+	definitions, err := tx.Attributes().List(ctx, repository.Filter{CategoryID: product.CategoryID, Sort: repository.SortByOrder})
+	if err != nil {
+		return err
+	}
+	keep := product.Attributes == nil && product.ID != 0
+	if keep {
+		if product.Attributes, err = tx.Attributes().Values(ctx, []uint{product.ID}); err != nil {
+			return err
+		}
+	}
+
+	byID := make(map[uint]models.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byID[definition.ID] = definition
+	}
+	var problems []string
+	given := map[uint]string{}
+	for _, value := range product.Attributes {
+		definition, ok := byID[value.AttributeID]
+		switch {
+		case !ok && !keep:
+			problems = append(problems, fmt.Sprintf("El atributo %d no pertenece a la categoría del producto", value.AttributeID))
+		case !ok:
+			// Valor de la categoría anterior: se descarta
+		default:
+			if _, repeated := given[value.AttributeID]; repeated {
+				problems = append(problems, "El atributo "+definition.Name+" está repetido")
+			}
+			given[value.AttributeID] = value.Value
+		}
+	}
+
+	values := []models.ProductAttribute{}
+	for _, definition := range definitions {
+		raw := strings.TrimSpace(given[definition.ID])
+		if raw == "" {
+			if definition.Required {
+				problems = append(problems, definition.Name+" es requerido")
+			}
+			continue
+		}
+		value, number, problem := normalizeAttribute(definition, raw)
+		if problem != "" {
+			problems = append(problems, problem)
+			continue
+		}
+		values = append(values, models.ProductAttribute{AttributeID: definition.ID, Value: value, Number: number, Attribute: definition})
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	product.Attributes = values
+	return nil
+}
+
+// normalizeAttribute forma canónica de un valor según el tipo del atributo
+// Retorna: Valor, número (solo number) y el problema si el valor no es válido
+func normalizeAttribute(definition models.AttributeDefinition, raw string) (string, *float64, string) {
+	switch definition.Type {
+	case models.AttributeNumber:
+		number, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", nil, definition.Name + " debe ser un número"
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), &number, ""
+	case models.AttributeEnum:
+		for _, option := range definition.Options {
+			if strings.EqualFold(option, raw) {
+				return option, nil, ""
+			}
+		}
+		return "", nil, definition.Name + " admite: " + strings.Join(definition.Options, ", ")
+	case models.AttributeBoolean:
+		switch strings.ToLower(raw) {
+		case "true", "1", "si", "sí":
+			return "true", nil, ""
+		case "false", "0", "no":
+			return "false", nil, ""
+		}
+		return "", nil, definition.Name + " debe ser sí o no"
+	default:
+		if utf8.RuneCountInString(raw) > attributeTextLength {
+			return "", nil, fmt.Sprintf("%s admite como máximo %d caracteres", definition.Name, attributeTextLength)
+		}
+		return raw, nil, ""
+	}
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ========================================
+// FACETAS
+// ========================================
+
+// Facet atributo filtrable con el número de productos por valor
+type Facet struct {
+	Key    string                  `json:"key"`
+	Name   string                  `json:"name"`
+	Type   string                  `json:"type"`
+	Unit   string                  `json:"unit,omitempty"`
+	Values []repository.FacetValue `json:"values"`
+	Min    *float64                `json:"min,omitempty"` // Solo number
+	Max    *float64                `json:"max,omitempty"` // Solo number
+}
+
+// Facets facetas de los atributos filtrables de la categoría del listado
+// Cada faceta cuenta los productos visibles con el resto de filtros aplicados, sin el
+// suyo propio, para que se puedan elegir varios valores del mismo atributo.
+// Parámetros:
+//   - filter: Filtros del listado; sin categoría (CategoryID o CategorySlug) no hay facetas
+func (s *ProductService) Facets(ctx context.Context, filter repository.Filter) ([]Facet, error) {
+	categoryID := filter.CategoryID
+	if categoryID == 0 && filter.CategorySlug != "" {
+		category, err := s.store.Categories().FindBySlug(ctx, filter.CategorySlug, repository.Filter{})
+		if errors.Is(err, repository.ErrNotFound) {
+			return []Facet{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		categoryID = category.ID
+	}
+	if categoryID == 0 {
+		return nil, nil
+	}
+	definitions, err := s.store.Attributes().List(ctx, repository.Filter{CategoryID: categoryID, Sort: repository.SortByOrder})
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := catalogFilter(ctx, s.store, activeOnly(filter))
+	if err != nil {
+		return nil, err
+	}
+	base.Sort, base.Limit, base.Offset, base.After = repository.Sort{}, 0, 0, nil
+	facets := []Facet{}
+	for _, definition := range definitions {
+		if !definition.Filterable {
+			continue
+		}
+		others := base
+		others.Attributes = nil
+		for _, attribute := range base.Attributes {
+			if attribute.Key != definition.Key {
+				others.Attributes = append(others.Attributes, attribute)
+			}
+		}
+		values, err := s.store.Attributes().FacetCounts(ctx, definition.ID, others)
+		if err != nil {
+			return nil, err
+		}
+
+		facet := Facet{Key: definition.Key, Name: definition.Name, Type: definition.Type, Unit: definition.Unit, Values: values}
+		if definition.Type == models.AttributeNumber && len(values) > 0 {
+			number := func(i int) float64 {
+				n, _ := strconv.ParseFloat(values[i].Value, 64)
+				return n
+			}
+			sort.Slice(values, func(i, j int) bool { return number(i) < number(j) })
+			low, high := number(0), number(len(values)-1)
+			facet.Min, facet.Max = &low, &high
+		}
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
+
+// ========================================
+// TABLA DE ESPECIFICACIONES
+// ========================================
+
+// SpecTable atributos de los productos de una categoría en columnas
+type SpecTable struct {
+	Columns []models.AttributeDefinition
+	Rows    []SpecRow
+}
+
+// SpecRow fila de un producto
+type SpecRow struct {
+	Product models.Product
+	Values  []string // Valor para mostrar de cada columna ("" si no lo tiene)
+}
+
+// SpecTable tabla de los productos cargados en la categoría que son de ella (los de sus
+// subcategorías tienen sus propios atributos); nil si la categoría no tiene atributos
+// o ningún producto tiene valores
+func (s *AttributeService) SpecTable(ctx context.Context, category *models.Category) (*SpecTable, error) {
+	var products []models.Product
+	for _, product := range category.Products {
+		if product.CategoryID == category.ID {
+			products = append(products, product)
+		}
+	}
+	definitions, err := s.ForCategory(ctx, category.ID)
+	if err != nil || len(definitions) == 0 || len(products) == 0 {
+		return nil, err
+	}
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	values, err := s.store.Attributes().Values(ctx, ids)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	display := map[[2]uint]string{}
+	used := map[uint]bool{}
+	for _, value := range values {
+		display[[2]uint{value.ProductID, value.AttributeID}] = value.Display()
+		used[value.AttributeID] = true
+	}
+	table := &SpecTable{}
+	for _, definition := range definitions {
+		if used[definition.ID] {
+			table.Columns = append(table.Columns, definition)
+		}
+	}
+	for _, product := range products {
+		row := SpecRow{Product: product}
+		for _, column := range table.Columns {
+			row.Values = append(row.Values, display[[2]uint{product.ID, column.ID}])
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
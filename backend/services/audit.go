@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"website/backend/models"
+	"website/backend/repository"
+)
+
+// Acciones de auditoría
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Límites del listado de auditoría
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditIgnoredFields no se registran como cambios (cambian en cada guardado)
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// auditAssociations claves JSON de las relaciones precargadas de los modelos (y de los
+// datos calculados), que no son columnas de la entidad auditada
+var auditAssociations = map[string]bool{
+	"category":   true,
+	"attribute":  true,
+	"products":   true,
+	"variants":   true,
+	"tags":       true,
+	"attributes": true,
+	"children":   true,
+	"pricing":    true,
+}
+
+// fieldChange un campo modificado
+type fieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// auditDiff JSON guardado en audit_events.diff
+type auditDiff struct {
+	Before  map[string]interface{} `json:"before,omitempty"`
+	After   map[string]interface{} `json:"after,omitempty"`
+	Changes map[string]fieldChange `json:"changes,omitempty"`
+}
+
+// AuditService consulta del registro de auditoría
+type AuditService struct {
+	store repository.Store
+}
+
+// List eventos más recientes primero; el límite por defecto es 100 y el máximo 1000
+func (s *AuditService) List(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	return s.store.Audit().List(ctx, filter)
+}
+
+// recordAudit escribe un evento de auditoría en la misma transacción que el cambio.
+// before es nil en las altas y after es nil en las bajas.
+func recordAudit(ctx context.Context, tx repository.Store, actor Actor, action, entityType string, entityID interface{}, before, after interface{}) error {
+	diff, err := buildAuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	event := models.AuditEvent{
+		UserID:     actor.UserID,
+		Username:   actor.Username,
+		Role:       actor.Role,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+		Diff:       diff,
+	}
+
+	return tx.Audit().Create(ctx, &event)
+}
+
+// buildAuditDiff serializa ambos estados y calcula los campos modificados
+func buildAuditDiff(before, after interface{}) (json.RawMessage, error) {
+	var diff auditDiff
+	var err error
+
+	if diff.Before, err = toAuditMap(before); err != nil {
+		return nil, err
+	}
+	if diff.After, err = toAuditMap(after); err != nil {
+		return nil, err
+	}
+
+	if diff.Before != nil && diff.After != nil {
+		diff.Changes = make(map[string]fieldChange)
+		for key, to := range diff.After {
+			if auditIgnoredFields[key] {
+				continue
+			}
+			if from := diff.Before[key]; !reflect.DeepEqual(from, to) {
+				diff.Changes[key] = fieldChange{From: from, To: to}
+			}
+		}
+		for key, from := range diff.Before {
+			if _, ok := diff.After[key]; !ok && !auditIgnoredFields[key] {
+				diff.Changes[key] = fieldChange{From: from, To: nil}
+			}
+		}
+	}
+
+	return json.Marshal(diff)
+}
+
+// toAuditMap convierte un modelo en un mapa genérico usando sus etiquetas JSON,
+// así los campos json:"-" (como las contraseñas) nunca se guardan
+func toAuditMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	// Las relaciones se auditan en su propia entidad; las columnas jsonb (opciones de
+	// una variante, reglas de una colección...) sí se comparan
+	for key := range auditAssociations {
+		delete(result, key)
+	}
+
+	return result, nil
+}
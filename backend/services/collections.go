@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"website/backend/cache"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// ETIQUETAS
+// ========================================
+
+// TagService etiquetas libres de los productos; el slug se genera a partir del nombre
+// y los productos también crean las suyas al guardarse (prepareTags)
+type TagService struct {
+	crud[models.Tag]
+}
+
+func newTagService(store repository.Store, changes *changes) *TagService {
+	service := &TagService{crud: newCRUD(store, changes, "tag", func(s repository.Store) repository.CRUD[models.Tag] {
+		return s.Tags()
+	})}
+	// Los productos de cualquier listado incluyen sus etiquetas
+	service.tags = func(ctx context.Context, _ *models.Tag) []string {
+		tags := []string{cache.TagProducts, cache.TagCollections}
+		categories, err := store.Categories().List(ctx, repository.Filter{})
+		if err != nil {
+			return append(tags, cache.TagCategories)
+		}
+		for _, category := range categories {
+			tags = append(tags, cache.CategoryTag(category.ID))
+		}
+		return tags
+	}
+	service.prepare = func(ctx context.Context, tx repository.Store, tag *models.Tag) error {
+		tag.Name = strings.TrimSpace(tag.Name)
+		if tag.Slug != "" {
+			return nil
+		}
+		slug, err := uniqueSlug(ctx, tag.Name, tag.ID, tx.Tags().SlugExists)
+		tag.Slug = slug
+		return err
+	}
+	service.deleted = func(ctx context.Context, tx repository.Store, tag *models.Tag) error {
+		return collectionsUsing(ctx, tx, "La etiqueta", func(rules models.CollectionRules) bool { return rules.TagID == tag.ID })
+	}
+	return service
+}
+
+// List todas las etiquetas por nombre
+func (s *TagService) List(ctx context.Context) ([]models.Tag, error) {
+	return s.store.Tags().List(ctx, repository.Filter{Sort: repository.SortByName})
+}
+
+// prepareTags resuelve las etiquetas del producto: por id o, sin id, por nombre (sin
+// distinguir mayúsculas ni acentos, como el slug), creando las que no existen. Quedan
+// sin repetir y ordenadas por nombre
+func prepareTags(ctx context.Context, tx repository.Store, product *models.Product) error {
+	tags := make([]models.Tag, 0, len(product.Tags))
+	seen := map[uint]bool{}
+	var problems []string
+	for _, given := range product.Tags {
+		var tag *models.Tag
+		var err error
+		if given.ID != 0 {
+			tag, err = tx.Tags().Get(ctx, given.ID)
+			if errors.Is(err, repository.ErrNotFound) {
+				problems = append(problems, fmt.Sprintf("La etiqueta %d no existe", given.ID))
+				continue
+			}
+		} else {
+			var problem string
+			tag, problem, err = findOrCreateTag(ctx, tx, given.Name)
+			if problem != "" {
+				problems = append(problems, problem)
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, *tag)
+		}
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	product.Tags = tags
+	return nil
+}
+
+// findOrCreateTag etiqueta con el slug del nombre; si no existe la crea
+// Retorna: Etiqueta o el problema que impide crearla
+func findOrCreateTag(ctx context.Context, tx repository.Store, name string) (*models.Tag, string, error) {
+	name = strings.TrimSpace(name)
+	slug := utils.GenerateSlug(name)
+	if slug == "" {
+		return nil, "Etiqueta inválida: " + strconv.Quote(name), nil
+	}
+	tag, err := tx.Tags().FindBySlug(ctx, slug)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return tag, "", err
+	}
+
+	tag = &models.Tag{Name: name, Slug: slug}
+	if problems := utils.ValidateStruct(tag); len(problems) > 0 {
+		return nil, "Etiqueta " + name + ": " + strings.Join(problems, ", "), nil
+	}
+	return tag, "", tx.Tags().Create(ctx, tag)
+}
+
+// tagIDs ids de las etiquetas
+func tagIDs(tags []models.Tag) []uint {
+	ids := make([]uint, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+// ========================================
+// COLECCIONES
+// ========================================
+
+// CollectionService colecciones de productos de cualquier categoría: las manuales con
+// los productos elegidos y ordenados en el CMS (SetProducts) y las smart con los que
+// cumplen sus reglas en cada momento
+type CollectionService struct {
+	crud[models.Collection]
+}
+
+func newCollectionService(store repository.Store, changes *changes) *CollectionService {
+	service := &CollectionService{crud: newCRUD(store, changes, "collection", func(s repository.Store) repository.CRUD[models.Collection] {
+		return s.Collections()
+	})}
+	service.tags = func(context.Context, *models.Collection) []string {
+		return []string{cache.TagCollections}
+	}
+	service.prepare = prepareCollection
+	// Una colección que pasa a smart deja de usar los productos elegidos a mano
+	service.saved = func(ctx context.Context, tx repository.Store, collection *models.Collection) error {
+		if collection.Type != models.CollectionSmart {
+			return nil
+		}
+		return tx.Collections().SetItems(ctx, collection.ID, nil)
+	}
+	return service
+}
+
+// List todas las colecciones por orden
+func (s *CollectionService) List(ctx context.Context) ([]models.Collection, error) {
+	return s.store.Collections().List(ctx, repository.Filter{})
+}
+
+// Active colecciones visibles por orden, sin productos
+func (s *CollectionService) Active(ctx context.Context) ([]models.Collection, error) {
+	return s.store.Collections().List(ctx, repository.ActiveOnly())
+}
+
+// Detail colección visible con sus productos visibles
+// Parámetros:
+//   - slug: Slug de la colección
+//
+// Retorna: Colección con Products (en su orden y como mucho MaxProducts) o repository.ErrNotFound
+func (s *CollectionService) Detail(ctx context.Context, slug string) (*models.Collection, error) {
+	collection, err := s.store.Collections().FindBySlug(ctx, slug, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	filter, err := catalogFilter(ctx, s.store, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	if collection.Products, err = collectionProducts(ctx, s.store, *collection, filter); err != nil {
+		return nil, err
+	}
+	visibleVariants(collection.Products)
+	return collection, nil
+}
+
+// Products productos de la colección, activos o no, en su orden; los de una smart son
+// los que cumplen ahora sus reglas
+// Parámetros:
+//   - id: Colección (repository.ErrNotFound si no existe)
+func (s *CollectionService) Products(ctx context.Context, id uint) ([]models.Product, error) {
+	collection, err := s.store.Collections().Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return collectionProducts(ctx, s.store, *collection, repository.Filter{})
+}
+
+// SetProducts sustituye los productos de una colección manual, en el orden dado
+// Parámetros:
+//   - id: Colección (repository.ErrNotFound si no existe)
+//   - productIDs: Productos existentes, sin repetir (vacío = ninguno)
+//
+// Retorna: *utils.ValidationError si la colección es smart o algún producto no es válido
+func (s *CollectionService) SetProducts(ctx context.Context, actor Actor, id uint, productIDs []uint) error {
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		collection, err := tx.Collections().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if collection.Type != models.CollectionManual {
+			return utils.NewValidationError("Los productos de una colección smart salen de sus reglas")
+		}
+
+		var problems []string
+		seen := map[uint]bool{}
+		items := make([]models.CollectionProduct, 0, len(productIDs))
+		for i, productID := range productIDs {
+			_, err := tx.Products().Get(ctx, productID)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				problems = append(problems, fmt.Sprintf("El producto %d no existe", productID))
+			case err != nil:
+				return err
+			case seen[productID]:
+				problems = append(problems, fmt.Sprintf("El producto %d está repetido", productID))
+			}
+			seen[productID] = true
+			items = append(items, models.CollectionProduct{ProductID: productID, Position: i})
+		}
+		if len(problems) > 0 {
+			return utils.NewValidationError(problems...)
+		}
+
+		before, err := tx.Collections().Items(ctx, []uint{id})
+		if err != nil {
+			return err
+		}
+		if err := tx.Collections().SetItems(ctx, id, items); err != nil {
+			return err
+		}
+		// updated_at de la colección: la versión de su página
+		if err := tx.Collections().Update(ctx, collection); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditUpdate, "collection_products", id, collectionItems{itemProducts(before)}, collectionItems{productIDs})
+	})
+	if err == nil {
+		s.changes.publish(ctx, cache.TagCollections)
+	}
+	return err
+}
+
+// collectionItems productos de una colección en el registro de auditoría (un objeto, no una lista)
+type collectionItems struct {
+	ProductIDs []uint `json:"product_ids"`
+}
+
+// itemProducts ids de los productos por posición
+func itemProducts(items []models.CollectionProduct) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	return ids
+}
+
+// collectionProducts productos de la colección que cumplen filter: los elegidos a mano
+// por posición o los que cumplen las reglas en el orden de la colección, como mucho
+// MaxProducts
+func collectionProducts(ctx context.Context, store repository.Store, collection models.Collection, filter repository.Filter) ([]models.Product, error) {
+	if collection.Type == models.CollectionSmart {
+		filter = withRules(filter, collection, time.Now())
+		return store.Products().List(ctx, filter)
+	}
+
+	items, err := store.Collections().Items(ctx, []uint{collection.ID})
+	if err != nil {
+		return nil, err
+	}
+	position := make(map[uint]int, len(items))
+	for _, item := range items {
+		position[item.ProductID] = item.Position
+	}
+	filter.IDs = itemProducts(items)
+	products, err := store.Products().List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(products, func(i, j int) bool {
+		return position[products[i].ID] < position[products[j].ID]
+	})
+	if collection.MaxProducts > 0 && len(products) > collection.MaxProducts {
+		products = products[:collection.MaxProducts]
+	}
+	return products, nil
+}
+
+// withRules añade al filtro las reglas de una colección smart, su orden y su límite;
+// now fija el inicio de NewerThanDays
+func withRules(filter repository.Filter, collection models.Collection, now time.Time) repository.Filter {
+	filter.Sort = repository.SortByNewest
+	if collection.Sort != "" {
+		filter.Sort = repository.Sort{Column: strings.TrimPrefix(collection.Sort, "-"), Desc: strings.HasPrefix(collection.Sort, "-")}
+	}
+	filter.Limit = collection.MaxProducts
+	rules := collection.Rules
+	if rules == nil {
+		return filter
+	}
+	filter.CategoryID, filter.Subcategories = rules.CategoryID, true
+	filter.TagID = rules.TagID
+	filter.MinPrice, filter.MaxPrice = rules.MinPrice, rules.MaxPrice
+	if rules.NewerThanDays > 0 {
+		after := now.AddDate(0, 0, -rules.NewerThanDays)
+		filter.CreatedAfter = &after
+	}
+	// Sin los agotados si lo pide la regla o la configuración del catálogo
+	filter.InStock = filter.InStock || rules.InStock
+	return filter
+}
+
+// prepareCollection completa el tipo y el slug y comprueba que las reglas sean las de
+// una colección smart y se refieran a una categoría y una etiqueta existentes
+func prepareCollection(ctx context.Context, tx repository.Store, collection *models.Collection) error {
+	collection.Products = nil
+	collection.Name = strings.TrimSpace(collection.Name)
+	if collection.Type == "" {
+		collection.Type = models.CollectionManual
+	}
+	if collection.Slug == "" {
+		slug, err := uniqueSlug(ctx, collection.Name, collection.ID, tx.Collections().SlugExists)
+		if err != nil {
+			return err
+		}
+		collection.Slug = slug
+	}
+
+	var problems []string
+	rules := collection.Rules
+	switch {
+	case collection.Type == models.CollectionManual && rules != nil:
+		problems = append(problems, "Solo las colecciones smart tienen reglas: los productos de una manual se eligen a mano")
+	case collection.Type == models.CollectionSmart && (rules == nil || *rules == models.CollectionRules{}):
+		problems = append(problems, "Una colección smart necesita al menos una regla")
+	}
+	if rules != nil {
+		if rules.CategoryID != 0 {
+			_, err := tx.Categories().Get(ctx, rules.CategoryID)
+			if errors.Is(err, repository.ErrNotFound) {
+				problems = append(problems, "rules.category_id no corresponde a ninguna categoría")
+			} else if err != nil {
+				return err
+			}
+		}
+		if rules.TagID != 0 {
+			_, err := tx.Tags().Get(ctx, rules.TagID)
+			if errors.Is(err, repository.ErrNotFound) {
+				problems = append(problems, "rules.tag_id no corresponde a ninguna etiqueta")
+			} else if err != nil {
+				return err
+			}
+		}
+		if rules.MinPrice != nil && rules.MaxPrice != nil && *rules.MinPrice > *rules.MaxPrice {
+			problems = append(problems, "rules.min_price no puede ser mayor que rules.max_price")
+		}
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	return nil
+}
+
+// collectionsUsing impide borrar lo que usan las reglas de alguna colección smart
+// Parámetros:
+//   - subject: Lo que se borra, para el mensaje ("La categoría")
+//   - uses: Indica si unas reglas lo usan
+func collectionsUsing(ctx context.Context, tx repository.Store, subject string, uses func(rules models.CollectionRules) bool) error {
+	collections, err := tx.Collections().List(ctx, repository.Filter{})
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, collection := range collections {
+		if collection.Rules != nil && uses(*collection.Rules) {
+			names = append(names, collection.Name)
+		}
+	}
+	if len(names) > 0 {
+		return utils.NewValidationError(subject + " se usa en las reglas de: " + strings.Join(names, ", ") + ". Cambia esas reglas antes")
+	}
+	return nil
+}
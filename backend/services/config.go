@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"website/backend/cache"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ConfigService configuración del sitio (clave/valor)
+type ConfigService struct {
+	store   repository.Store
+	changes *changes
+}
+
+// All todas las claves
+func (s *ConfigService) All(ctx context.Context) ([]models.SiteConfig, error) {
+	return s.store.Configs().List(ctx)
+}
+
+// Map claves y valores para el frontend
+func (s *ConfigService) Map(ctx context.Context) (map[string]string, error) {
+	configs, err := s.store.Configs().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(configs))
+	for _, config := range configs {
+		values[config.Key] = config.Value
+	}
+	return values, nil
+}
+
+// Value valor de key, o fallback si no existe o está vacío
+func (s *ConfigService) Value(ctx context.Context, key, fallback string) (string, error) {
+	config, err := s.store.Configs().FindByKey(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return fallback, err
+	}
+	if config.Value == "" {
+		return fallback, nil
+	}
+	return config.Value, nil
+}
+
+// List valor de key separado por comas, sin elementos vacíos
+func (s *ConfigService) List(ctx context.Context, key string) ([]string, error) {
+	value, err := s.Value(ctx, key, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Set crea o actualiza la clave config.Key
+func (s *ConfigService) Set(ctx context.Context, actor Actor, config *models.SiteConfig) error {
+	if err := utils.Validate(config); err != nil {
+		return err
+	}
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := tx.Configs().FindByKey(ctx, config.Key)
+		if errors.Is(err, repository.ErrNotFound) {
+			config.ID = 0
+			if err := tx.Configs().Create(ctx, config); err != nil {
+				return err
+			}
+			return recordAudit(ctx, tx, actor, AuditCreate, "site_config", config.Key, nil, config)
+		}
+		if err != nil {
+			return err
+		}
+
+		config.ID = before.ID
+		config.CreatedAt = before.CreatedAt
+		if err := tx.Configs().Update(ctx, config); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditUpdate, "site_config", config.Key, before, config)
+	})
+	if err == nil {
+		s.changes.publish(ctx, cache.TagConfig)
+	}
+	return err
+}
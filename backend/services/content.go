@@ -0,0 +1,542 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"website/backend/cache"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// homeCategoryLimit categorías mostradas en la página de inicio
+const homeCategoryLimit = 6
+
+// ========================================
+// SLIDES
+// ========================================
+
+// SlideService slides del carrusel de inicio
+type SlideService struct {
+	crud[models.Slide]
+}
+
+func newSlideService(store repository.Store, changes *changes) *SlideService {
+	service := &SlideService{crud: newCRUD(store, changes, "slide", func(s repository.Store) repository.CRUD[models.Slide] {
+		return s.Slides()
+	})}
+	service.tags = func(context.Context, *models.Slide) []string {
+		return []string{cache.TagSlides}
+	}
+	return service
+}
+
+// List todos los slides por orden
+func (s *SlideService) List(ctx context.Context) ([]models.Slide, error) {
+	return s.store.Slides().List(ctx, repository.Filter{})
+}
+
+// Active slides visibles por orden
+func (s *SlideService) Active(ctx context.Context) ([]models.Slide, error) {
+	return s.store.Slides().List(ctx, repository.ActiveOnly())
+}
+
+// ========================================
+// CATEGORÍAS
+// ========================================
+
+// CategoryService categorías del catálogo en árbol; el slug se genera a partir del
+// nombre y la ruta materializada (Path, SlugPath, Depth) se calcula al guardar
+type CategoryService struct {
+	crud[models.Category]
+}
+
+func newCategoryService(store repository.Store, changes *changes) *CategoryService {
+	service := &CategoryService{crud: newCRUD(store, changes, "category", func(s repository.Store) repository.CRUD[models.Category] {
+		return s.Categories()
+	})}
+	// Los productos incluyen su categoría y se borran con ella; los listados de las
+	// categorías superiores incluyen los de esta
+	service.tags = func(_ context.Context, category *models.Category) []string {
+		return append([]string{cache.TagCategories, cache.TagProducts}, categoryTags(*category)...)
+	}
+	service.prepare = func(ctx context.Context, tx repository.Store, category *models.Category) error {
+		category.Products, category.Children = nil, nil
+		if category.Slug == "" {
+			slug, err := uniqueSlug(ctx, category.Name, category.ID, tx.Categories().SlugExists)
+			if err != nil {
+				return err
+			}
+			category.Slug = slug
+		}
+		return placeCategory(ctx, tx, category)
+	}
+	// Path termina con el propio id, que en un alta no se conoce hasta insertarla
+	service.saved = func(ctx context.Context, tx repository.Store, category *models.Category) error {
+		own := strconv.FormatUint(uint64(category.ID), 10) + "/"
+		if strings.HasSuffix(category.Path, "/"+own) {
+			return nil
+		}
+		category.Path += own
+		return tx.Categories().Update(ctx, category)
+	}
+	service.deleted = func(ctx context.Context, tx repository.Store, category *models.Category) error {
+		descendants, err := tx.Categories().Descendants(ctx, category.Path)
+		if err != nil {
+			return err
+		}
+		if len(descendants) > 0 {
+			return utils.NewValidationError("La categoría tiene subcategorías: muévelas a otra categoría o elimínalas antes")
+		}
+		return collectionsUsing(ctx, tx, "La categoría", func(rules models.CollectionRules) bool { return rules.CategoryID == category.ID })
+	}
+	return service
+}
+
+// placeCategory coloca la categoría bajo ParentID, que debe existir y no puede ser la
+// propia categoría ni una de sus subcategorías (formaría un ciclo). Calcula Path (en
+// un alta, sin el propio id), SlugPath y Depth y, si cambian, reescribe los de todo el
+// subárbol
+func placeCategory(ctx context.Context, tx repository.Store, category *models.Category) error {
+	path, slugPath, depth := "/", category.Slug, 0
+	if category.ParentID != nil {
+		parent, err := tx.Categories().Get(ctx, *category.ParentID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return utils.NewValidationError("ParentID no corresponde a ninguna categoría")
+		}
+		if err != nil {
+			return err
+		}
+		if category.ID != 0 && (parent.ID == category.ID || slices.Contains(parent.PathIDs(), category.ID)) {
+			return utils.NewValidationError("ParentID no puede ser la propia categoría ni una de sus subcategorías")
+		}
+		path, slugPath, depth = parent.Path, parent.SlugPath+"/"+category.Slug, parent.Depth+1
+	}
+	category.Path, category.SlugPath, category.Depth = path, slugPath, depth
+	if category.ID == 0 {
+		return nil
+	}
+	category.Path += strconv.FormatUint(uint64(category.ID), 10) + "/"
+
+	stored, err := tx.Categories().Get(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	if stored.Path == category.Path && stored.SlugPath == category.SlugPath {
+		return nil
+	}
+	descendants, err := tx.Categories().Descendants(ctx, stored.Path)
+	if err != nil {
+		return err
+	}
+	for _, descendant := range descendants {
+		descendant.Path = category.Path + strings.TrimPrefix(descendant.Path, stored.Path)
+		descendant.SlugPath = category.SlugPath + strings.TrimPrefix(descendant.SlugPath, stored.SlugPath)
+		descendant.Depth += category.Depth - stored.Depth
+		if err := tx.Categories().Update(ctx, &descendant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryTags etiquetas de cache de la categoría y de todas sus superiores
+func categoryTags(category models.Category) []string {
+	ids := category.PathIDs()
+	if len(ids) == 0 {
+		ids = []uint{category.ID}
+	}
+	tags := make([]string, len(ids))
+	for i, id := range ids {
+		tags[i] = cache.CategoryTag(id)
+	}
+	return tags
+}
+
+// productTags etiquetas de cache que invalida un cambio en un producto de la categoría:
+// los listados de productos y los de la categoría y sus superiores
+func productTags(ctx context.Context, store repository.Store, categoryID uint) []string {
+	category, err := store.Categories().Get(ctx, categoryID)
+	if err != nil {
+		return []string{cache.TagProducts, cache.CategoryTag(categoryID)}
+	}
+	return append([]string{cache.TagProducts}, categoryTags(*category)...)
+}
+
+// List todas las categorías por orden
+func (s *CategoryService) List(ctx context.Context) ([]models.Category, error) {
+	return s.store.Categories().List(ctx, repository.Filter{})
+}
+
+// Active categorías visibles por orden, sin productos
+func (s *CategoryService) Active(ctx context.Context) ([]models.Category, error) {
+	return s.store.Categories().List(ctx, repository.ActiveOnly())
+}
+
+// Tree todas las categorías en árbol (Children), cada nivel por orden
+func (s *CategoryService) Tree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.List(ctx)
+	return buildTree(categories), err
+}
+
+// ActiveTree árbol de las categorías visibles: una categoría inactiva oculta también
+// sus subcategorías
+func (s *CategoryService) ActiveTree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.Active(ctx)
+	return buildTree(categories), err
+}
+
+// buildTree anida las categorías bajo su padre conservando el orden de la lista; las que
+// tienen el padre fuera de la lista se quedan fuera del árbol
+func buildTree(categories []models.Category) []models.Category {
+	children := map[uint][]models.Category{}
+	for _, category := range categories {
+		var parent uint
+		if category.ParentID != nil {
+			parent = *category.ParentID
+		}
+		children[parent] = append(children[parent], category)
+	}
+	var attach func(parent uint) []models.Category
+	attach = func(parent uint) []models.Category {
+		nodes := children[parent]
+		for i := range nodes {
+			nodes[i].Children = attach(nodes[i].ID)
+		}
+		return nodes
+	}
+	if tree := attach(0); tree != nil {
+		return tree
+	}
+	return []models.Category{}
+}
+
+// WithProducts categorías visibles con sus productos visibles (limit 0 = todas)
+func (s *CategoryService) WithProducts(ctx context.Context, limit int) ([]models.Category, error) {
+	filter, err := catalogFilter(ctx, s.store, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	filter.WithProducts = true
+	filter.Limit = limit
+	return s.store.Categories().List(ctx, filter)
+}
+
+// Home categorías de la página de inicio
+func (s *CategoryService) Home(ctx context.Context) ([]models.Category, error) {
+	return s.WithProducts(ctx, homeCategoryLimit)
+}
+
+// Breadcrumb enlace de la ruta de navegación de una página
+type Breadcrumb struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// CategoryDetail página pública de una categoría: Products son los productos visibles
+// de ella y de sus subcategorías y Children sus subcategorías visibles
+type CategoryDetail struct {
+	models.Category
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs"` // Categorías superiores desde la raíz
+}
+
+// BySlug categoría visible (activa, como todas sus superiores) con sus subcategorías
+// visibles y los productos visibles de ella y de sus subcategorías
+// Parámetros:
+//   - slug: Slug de la categoría (único en todo el árbol)
+//
+// Retorna: Página de la categoría o repository.ErrNotFound
+func (s *CategoryService) BySlug(ctx context.Context, slug string) (*CategoryDetail, error) {
+	category, err := s.store.Categories().FindBySlug(ctx, slug, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	trail, visible, err := breadcrumbs(ctx, s.store, *category)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, repository.ErrNotFound
+	}
+
+	descendants, err := s.store.Categories().Descendants(ctx, category.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, descendant := range descendants {
+		if descendant.Active && descendant.ParentID != nil && *descendant.ParentID == category.ID {
+			category.Children = append(category.Children, descendant)
+		}
+	}
+	sort.SliceStable(category.Children, func(i, j int) bool {
+		return category.Children[i].Order < category.Children[j].Order
+	})
+
+	filter, err := catalogFilter(ctx, s.store, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	filter.CategoryID = category.ID
+	filter.Subcategories = true
+	filter.Sort = repository.SortByNewest
+	category.Products, err = s.store.Products().List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	visibleVariants(category.Products)
+
+	if len(trail) > 0 {
+		trail = trail[:len(trail)-1]
+	}
+	return &CategoryDetail{Category: *category, Breadcrumbs: trail}, nil
+}
+
+// breadcrumbs ruta de navegación de la raíz a la categoría (incluida); visible es false
+// si la categoría o alguna de sus superiores está inactiva
+func breadcrumbs(ctx context.Context, store repository.Store, category models.Category) (trail []Breadcrumb, visible bool, err error) {
+	trail, visible = []Breadcrumb{}, category.Active
+	for _, id := range category.PathIDs() {
+		ancestor := &category
+		if id != category.ID {
+			if ancestor, err = store.Categories().Get(ctx, id); err != nil {
+				return nil, false, err
+			}
+		}
+		visible = visible && ancestor.Active
+		trail = append(trail, Breadcrumb{Name: ancestor.Name, URL: ancestor.URL()})
+	}
+	return trail, visible, nil
+}
+
+// ========================================
+// PRODUCTOS
+// ========================================
+
+// ProductService productos; el slug se genera a partir del nombre, la
+// categoría debe existir y las etiquetas se crean si no existen
+type ProductService struct {
+	crud[models.Product]
+}
+
+func newProductService(store repository.Store, changes *changes) *ProductService {
+	service := &ProductService{crud: newCRUD(store, changes, "product", func(s repository.Store) repository.CRUD[models.Product] {
+		return s.Products()
+	})}
+	service.tags = func(ctx context.Context, product *models.Product) []string {
+		return productTags(ctx, store, product.CategoryID)
+	}
+	service.prepare = func(ctx context.Context, tx repository.Store, product *models.Product) error {
+		product.Category = models.Category{}
+		if product.CategoryID != 0 {
+			_, err := tx.Categories().Get(ctx, product.CategoryID)
+			if errors.Is(err, repository.ErrNotFound) {
+				return utils.NewValidationError("CategoryID no corresponde a ninguna categoría")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := prepareAttributes(ctx, tx, product); err != nil {
+			return err
+		}
+		stored := 0
+		if product.ID != 0 {
+			before, err := tx.Products().Get(ctx, product.ID)
+			if err != nil {
+				return err
+			}
+			stored = before.Stock
+			if product.Tags == nil {
+				product.Tags = before.Tags
+			}
+			// Con variantes activas el precio es el "desde" y la disponibilidad la de ellas
+			product.Variants = before.Variants
+		}
+		if err := prepareTags(ctx, tx, product); err != nil {
+			return err
+		}
+		prepareStock(&product.StockPolicy, &product.Stock, &product.StockStatus, product.LowStockThreshold, stored)
+		applyVariants(product)
+		if product.Slug != "" {
+			return nil
+		}
+		slug, err := uniqueSlug(ctx, product.Name, product.ID, tx.Products().SlugExists)
+		product.Slug = slug
+		return err
+	}
+	service.saved = func(ctx context.Context, tx repository.Store, product *models.Product) error {
+		if err := tx.Attributes().SetValues(ctx, product.ID, product.Attributes); err != nil {
+			return err
+		}
+		return tx.Tags().SetProductTags(ctx, product.ID, tagIDs(product.Tags))
+	}
+	return service
+}
+
+// List todos los productos, más recientes primero (categoryID 0 = todas)
+func (s *ProductService) List(ctx context.Context, categoryID uint) ([]models.Product, error) {
+	return s.store.Products().List(ctx, repository.Filter{CategoryID: categoryID, Sort: repository.SortByNewest})
+}
+
+// Active productos visibles, más recientes primero (categoryID 0 = todas)
+func (s *ProductService) Active(ctx context.Context, categoryID uint) ([]models.Product, error) {
+	filter, err := catalogFilter(ctx, s.store, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	filter.CategoryID = categoryID
+	filter.Sort = repository.SortByNewest
+	products, err := s.store.Products().List(ctx, filter)
+	visibleVariants(products)
+	return products, err
+}
+
+// ActivePage página de productos visibles con sus variantes activas
+func (s *ProductService) ActivePage(ctx context.Context, filter repository.Filter) (Page[models.Product], error) {
+	filter, err := catalogFilter(ctx, s.store, filter)
+	if err != nil {
+		return Page[models.Product]{}, err
+	}
+	page, err := s.crud.ActivePage(ctx, filter)
+	visibleVariants(page.Items)
+	return page, err
+}
+
+// RelatedLimit productos relacionados de la ficha de un producto
+const RelatedLimit = 4
+
+// ProductDetail ficha pública de un producto
+type ProductDetail struct {
+	models.Product
+	FeatureList  []string         `json:"feature_list"` // Features separadas en elementos
+	URL          string           `json:"url"`          // Ruta canónica /productos/<categorías>/:product
+	Breadcrumbs  []Breadcrumb     `json:"breadcrumbs"`  // Su categoría y las superiores desde la raíz
+	Related      []models.Product `json:"related"`      // Elegidos en el CMS y, hasta RelatedLimit, los más parecidos
+	Accessories  []models.Product `json:"accessories"`  // Accesorios elegidos en el CMS
+	Alternatives []models.Product `json:"alternatives"` // Alternativas elegidas en el CMS
+	Upgrades     []models.Product `json:"upgrades"`     // Versiones superiores elegidas en el CMS
+}
+
+// Detail ficha de un producto visible (activo y de una categoría activa, como todas sus
+// superiores) con sus variantes activas
+// Parámetros:
+//   - slug: Slug del producto (único en todas las categorías)
+//
+// Retorna: Ficha con la categoría y los productos relacionados visibles, o repository.ErrNotFound
+func (s *ProductService) Detail(ctx context.Context, slug string) (*ProductDetail, error) {
+	product, err := s.store.Products().FindBySlug(ctx, slug, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	trail, visible, err := breadcrumbs(ctx, s.store, product.Category)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, repository.ErrNotFound
+	}
+
+	related, err := relatedProducts(ctx, s.store, *product)
+	if err != nil {
+		return nil, err
+	}
+	product.Variants = activeVariants(product.Variants)
+
+	return &ProductDetail{
+		Product:      *product,
+		FeatureList:  ParseFeatures(product.Features),
+		URL:          ProductPath(*product),
+		Breadcrumbs:  trail,
+		Related:      nonNil(related[models.RelationRelated]),
+		Accessories:  nonNil(related[models.RelationAccessory]),
+		Alternatives: nonNil(related[models.RelationAlternative]),
+		Upgrades:     nonNil(related[models.RelationUpgrade]),
+	}, nil
+}
+
+// nonNil lista vacía en lugar de nil (JSON [] en vez de null)
+func nonNil(products []models.Product) []models.Product {
+	if products == nil {
+		return []models.Product{}
+	}
+	return products
+}
+
+// ProductPath ruta pública de un producto bajo la de su categoría; requiere la
+// categoría cargada
+func ProductPath(product models.Product) string {
+	return product.Category.URL() + "/" + product.Slug
+}
+
+// ParseFeatures separa las características: una por línea o, si es una sola línea,
+// separadas por comas o punto y coma. Quita las viñetas (-, *, •) del principio.
+func ParseFeatures(text string) []string {
+	parts := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(parts) == 1 {
+		parts = strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' })
+	}
+
+	features := []string{}
+	for _, part := range parts {
+		part = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(part), "-*•"))
+		if part != "" {
+			features = append(features, part)
+		}
+	}
+	return features
+}
+
+// ========================================
+// CONTACTOS
+// ========================================
+
+// ContactService canales de contacto
+type ContactService struct {
+	crud[models.ContactInfo]
+}
+
+func newContactService(store repository.Store, changes *changes) *ContactService {
+	service := &ContactService{crud: newCRUD(store, changes, "contact", func(s repository.Store) repository.CRUD[models.ContactInfo] {
+		return s.Contacts()
+	})}
+	service.tags = func(context.Context, *models.ContactInfo) []string {
+		return []string{cache.TagContacts}
+	}
+	return service
+}
+
+// List todos los contactos por orden
+func (s *ContactService) List(ctx context.Context) ([]models.ContactInfo, error) {
+	return s.store.Contacts().List(ctx, repository.Filter{})
+}
+
+// Active contactos visibles por orden
+func (s *ContactService) Active(ctx context.Context) ([]models.ContactInfo, error) {
+	return s.store.Contacts().List(ctx, repository.ActiveOnly())
+}
+
+// ========================================
+// SLUGS
+// ========================================
+
+// uniqueSlug genera el slug de name y añade -2, -3... si ya está en uso.
+// Si name no produce ningún carácter válido devuelve "" y la validación lo rechaza.
+func uniqueSlug(ctx context.Context, name string, id uint, exists func(ctx context.Context, slug string, excludeID uint) (bool, error)) (string, error) {
+	base := utils.GenerateSlug(name)
+	if base == "" {
+		return "", nil
+	}
+
+	slug := base
+	for n := 2; ; n++ {
+		taken, err := exists(ctx, slug, id)
+		if err != nil || !taken {
+			return slug, err
+		}
+		slug = base + "-" + strconv.Itoa(n)
+	}
+}
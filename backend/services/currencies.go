@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"website/backend/cache"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// MONEDAS
+// ========================================
+
+// DefaultCurrency moneda base mientras no haya ninguna en la tabla (la migración la crea)
+var DefaultCurrency = models.Currency{
+	Code:     "MXN",
+	Name:     "Peso mexicano",
+	Symbol:   "$",
+	Locale:   money.DefaultLocale,
+	Decimals: 2,
+	Rate:     money.One,
+	Base:     true,
+	Active:   true,
+}
+
+// CurrencyService monedas en las que se muestran los precios. Los precios se guardan en
+// la moneda base y se convierten con el tipo de cambio de cada moneda, salvo los fijados
+// a mano con SetPrices
+type CurrencyService struct {
+	crud[models.Currency]
+}
+
+func newCurrencyService(store repository.Store, changes *changes) *CurrencyService {
+	service := &CurrencyService{crud: newCRUD(store, changes, "currency", func(s repository.Store) repository.CRUD[models.Currency] {
+		return s.Currencies()
+	})}
+	service.tags = func(context.Context, *models.Currency) []string {
+		return []string{cache.TagCurrencies}
+	}
+	service.prepare = prepareCurrency
+	// Los precios de los productos están en la moneda base
+	service.deleted = func(_ context.Context, _ repository.Store, currency *models.Currency) error {
+		if currency.Base {
+			return utils.NewValidationError("La moneda base no se puede eliminar")
+		}
+		return nil
+	}
+	return service
+}
+
+// List todas las monedas por orden
+func (s *CurrencyService) List(ctx context.Context) ([]models.Currency, error) {
+	return s.store.Currencies().List(ctx, repository.Filter{})
+}
+
+// Active monedas visibles por orden
+func (s *CurrencyService) Active(ctx context.Context) ([]models.Currency, error) {
+	return s.store.Currencies().List(ctx, repository.ActiveOnly())
+}
+
+// Base moneda de los precios guardados; DefaultCurrency si no hay ninguna marcada
+func (s *CurrencyService) Base(ctx context.Context) (models.Currency, error) {
+	currencies, err := s.List(ctx)
+	if err != nil {
+		return models.Currency{}, err
+	}
+	for _, currency := range currencies {
+		if currency.Base {
+			return currency, nil
+		}
+	}
+	return DefaultCurrency, nil
+}
+
+// Resolve moneda pedida por el visitante
+// Parámetros:
+//   - code: Código ISO 4217 sin distinguir mayúsculas; vacío = moneda base
+//
+// Retorna: Moneda activa o *utils.ValidationError si no existe o no está activa
+func (s *CurrencyService) Resolve(ctx context.Context, code string) (models.Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	base, err := s.Base(ctx)
+	if err != nil || code == "" || code == base.Code {
+		return base, err
+	}
+	currency, err := s.store.Currencies().FindByCode(ctx, code)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && !currency.Active {
+		return models.Currency{}, utils.NewValidationError("Moneda no disponible: " + code)
+	}
+	if err != nil {
+		return models.Currency{}, err
+	}
+	return *currency, nil
+}
+
+// ToBase convierte un importe de la moneda a la moneda base (filtros de precio)
+func (s *CurrencyService) ToBase(currency models.Currency, amount money.Amount) money.Amount {
+	return money.Convert(amount, currency.Rate, money.One, 2)
+}
+
+// prepareCurrency normaliza el código y el locale y mantiene una única moneda base,
+// que siempre está activa con tipo de cambio 1
+func prepareCurrency(ctx context.Context, tx repository.Store, currency *models.Currency) error {
+	currency.Code = strings.ToUpper(strings.TrimSpace(currency.Code))
+	currency.Symbol = strings.TrimSpace(currency.Symbol)
+	currency.Locale = strings.TrimSpace(currency.Locale)
+	if currency.Base {
+		currency.Rate, currency.Active = money.One, true
+	}
+
+	var problems []string
+	if currency.Locale != "" && !money.ValidLocale(currency.Locale) {
+		problems = append(problems, "Locale admite "+strings.Join(money.Locales(), ", "))
+	}
+	currencies, err := tx.Currencies().List(ctx, repository.Filter{})
+	if err != nil {
+		return err
+	}
+	// Los precios de productos y variantes no llevan código: están en la base guardada
+	for _, other := range currencies {
+		switch {
+		case other.ID == currency.ID && other.Base && !currency.Base:
+			problems = append(problems, "La moneda base no se puede cambiar: los precios de los productos están en "+other.Code)
+		case other.ID == currency.ID && other.Base && (currency.Code != other.Code || currency.Decimals != other.Decimals):
+			problems = append(problems, "El código y los decimales de la moneda base no se pueden cambiar: los precios de los productos están en "+other.Code)
+		case other.ID != currency.ID && other.Base && currency.Base:
+			problems = append(problems, "Ya hay una moneda base: "+other.Code)
+		}
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	return nil
+}
+
+// ========================================
+// PRECIOS POR MONEDA
+// ========================================
+
+// priceKey producto y variante (0 = el producto) de un precio fijado
+type priceKey struct {
+	product uint
+	variant uint
+}
+
+// Price rellena Pricing de los productos y de sus variantes en la moneda: el precio fijado
+// a mano si lo hay y si no la conversión con el tipo de cambio. Con variantes activas el
+// precio del producto es el menor de ellas (From si no todas cuestan lo mismo).
+// Parámetros:
+//   - currency: Moneda de Resolve
+//   - products: Productos a completar (con sus variantes cargadas)
+func (s *CurrencyService) Price(ctx context.Context, currency models.Currency, products ...*models.Product) error {
+	overrides := map[priceKey]models.ProductPrice{}
+	if !currency.Base && len(products) > 0 {
+		ids := make([]uint, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+		prices, err := s.store.Prices().ForProducts(ctx, ids, currency.Code)
+		if err != nil {
+			return err
+		}
+		for _, price := range prices {
+			overrides[priceKey{price.ProductID, variantOf(price)}] = price
+		}
+	}
+
+	price := func(key priceKey, amount, compareAt money.Amount) *models.Pricing {
+		if override, ok := overrides[key]; ok {
+			return newPricing(currency, override.Price, override.CompareAtPrice)
+		}
+		convert := func(amount money.Amount) money.Amount {
+			return money.Convert(amount, money.One, currency.Rate, currency.Decimals)
+		}
+		return newPricing(currency, convert(amount), convert(compareAt))
+	}
+
+	for _, product := range products {
+		var from *models.Pricing
+		varies := false
+		for i := range product.Variants {
+			variant := &product.Variants[i]
+			variant.Pricing = price(priceKey{product.ID, variant.ID}, variant.Price, variant.CompareAtPrice)
+			if !variant.Active {
+				continue
+			}
+			if from != nil && variant.Pricing.Amount != from.Amount {
+				varies = true
+			}
+			if from == nil || variant.Pricing.Amount < from.Amount {
+				from = variant.Pricing
+			}
+		}
+		if from == nil {
+			product.Pricing = price(priceKey{product.ID, 0}, product.Price, 0)
+			continue
+		}
+		product.Pricing = newPricing(currency, from.Amount, 0)
+		product.Pricing.From = varies
+	}
+	return nil
+}
+
+// PriceList Price de una lista de productos
+func (s *CurrencyService) PriceList(ctx context.Context, currency models.Currency, products []models.Product) error {
+	pointers := make([]*models.Product, len(products))
+	for i := range products {
+		pointers[i] = &products[i]
+	}
+	return s.Price(ctx, currency, pointers...)
+}
+
+// PriceCategories Price de los productos de las categorías
+func (s *CurrencyService) PriceCategories(ctx context.Context, currency models.Currency, categories []models.Category) error {
+	var products []*models.Product
+	for i := range categories {
+		for j := range categories[i].Products {
+			products = append(products, &categories[i].Products[j])
+		}
+	}
+	return s.Price(ctx, currency, products...)
+}
+
+// PriceDetail Price del producto de la ficha y de todos sus relacionados
+func (s *CurrencyService) PriceDetail(ctx context.Context, currency models.Currency, detail *ProductDetail) error {
+	products := []*models.Product{&detail.Product}
+	for _, list := range [][]models.Product{detail.Related, detail.Accessories, detail.Alternatives, detail.Upgrades} {
+		for i := range list {
+			products = append(products, &list[i])
+		}
+	}
+	return s.Price(ctx, currency, products...)
+}
+
+// PriceResults Price de los resultados de una búsqueda
+func (s *CurrencyService) PriceResults(ctx context.Context, currency models.Currency, results []SearchResult) error {
+	products := make([]*models.Product, len(results))
+	for i := range results {
+		products[i] = &results[i].Product
+	}
+	return s.Price(ctx, currency, products...)
+}
+
+// newPricing importes en la moneda con su texto formateado
+func newPricing(currency models.Currency, amount, compareAt money.Amount) *models.Pricing {
+	pricing := &models.Pricing{Currency: currency.Code, Amount: amount, Formatted: currency.Format(amount)}
+	if compareAt > amount {
+		pricing.CompareAt, pricing.CompareAtFormatted = compareAt, currency.Format(compareAt)
+	}
+	return pricing
+}
+
+// variantOf id de la variante del precio; 0 si es el del producto
+func variantOf(price models.ProductPrice) uint {
+	if price.VariantID == nil {
+		return 0
+	}
+	return *price.VariantID
+}
+
+// Prices precios fijados a mano del producto y de sus variantes
+func (s *CurrencyService) Prices(ctx context.Context, productID uint) ([]models.ProductPrice, error) {
+	if _, err := s.store.Products().Get(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.store.Prices().ForProducts(ctx, []uint{productID}, "")
+}
+
+// SetPrices sustituye los precios fijados a mano del producto y de sus variantes
+// Parámetros:
+//   - productID: Producto (repository.ErrNotFound si no existe)
+//   - prices: Precios por moneda (no la base) y variante (nil = el producto), sin repetir
+//
+// Retorna: *utils.ValidationError con todos los precios inválidos
+func (s *CurrencyService) SetPrices(ctx context.Context, actor Actor, productID uint, prices []models.ProductPrice) error {
+	var product *models.Product
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		product, err = tx.Products().Get(ctx, productID)
+		if err != nil {
+			return err
+		}
+		before, err := tx.Prices().ForProducts(ctx, []uint{productID}, "")
+		if err != nil {
+			return err
+		}
+		if err := preparePrices(ctx, tx, product, prices); err != nil {
+			return err
+		}
+		if err := tx.Prices().SetPrices(ctx, productID, prices); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditUpdate, "price", productID, productPrices{before}, productPrices{prices})
+	})
+	if err == nil {
+		s.changes.publish(ctx, productTags(ctx, s.store, product.CategoryID)...)
+	}
+	return err
+}
+
+// productPrices precios de un producto en el registro de auditoría (un objeto, no una lista)
+type productPrices struct {
+	Prices []models.ProductPrice `json:"prices"`
+}
+
+// preparePrices normaliza los códigos y comprueba monedas, variantes, repetidos y
+// precios anteriores
+func preparePrices(ctx context.Context, tx repository.Store, product *models.Product, prices []models.ProductPrice) error {
+	variants := map[uint]bool{}
+	for _, variant := range product.Variants {
+		variants[variant.ID] = true
+	}
+
+	var problems []string
+	seen := map[string]bool{}
+	for i := range prices {
+		price := &prices[i]
+		price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+		label := price.Currency
+		if price.VariantID != nil {
+			label += " (variante " + strconv.FormatUint(uint64(*price.VariantID), 10) + ")"
+		}
+		for _, problem := range utils.ValidateStruct(price) {
+			problems = append(problems, label+": "+problem)
+		}
+
+		currency, err := tx.Currencies().FindByCode(ctx, price.Currency)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			problems = append(problems, label+": moneda desconocida")
+		case err != nil:
+			return err
+		case currency.Base:
+			problems = append(problems, label+": los precios en la moneda base son los del producto y sus variantes")
+		}
+		if price.VariantID != nil && !variants[*price.VariantID] {
+			problems = append(problems, label+": la variante no es de este producto")
+		}
+		if seen[label] {
+			problems = append(problems, label+": precio repetido")
+		}
+		seen[label] = true
+		if price.CompareAtPrice > 0 && price.CompareAtPrice <= price.Price {
+			problems = append(problems, label+": CompareAtPrice debe ser mayor que Price")
+		}
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"website/backend/models"
+	"website/backend/repository"
+)
+
+// ========================================
+// LISTADOS PAGINADOS
+// ========================================
+
+// Page página de un listado
+type Page[T any] struct {
+	Items []T
+	Total int64              // Filas que cumplen el filtro (sin paginar)
+	Next  *repository.Cursor // Cursor tras la última fila; nil en la última página
+}
+
+// listPage lee una página según filter.Limit con Offset o After
+// Parámetros:
+//   - repo: Repositorio de la entidad
+//   - filter: Filtro, orden y paginación (Limit 0 = todas las filas)
+//
+// Retorna: Filas, total y cursor de la página siguiente
+func listPage[T any](ctx context.Context, repo repository.CRUD[T], filter repository.Filter) (Page[T], error) {
+	total, err := repo.Count(ctx, filter)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	// Una fila de más indica si hay página siguiente
+	limit := filter.Limit
+	if limit > 0 {
+		filter.Limit++
+	}
+	items, err := repo.List(ctx, filter)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	page := Page[T]{Items: items, Total: total}
+	if limit > 0 && len(items) > limit {
+		page.Items = items[:limit]
+		page.Next = repository.CursorOf(page.Items[limit-1], filter.Sort)
+	}
+	return page, nil
+}
+
+// Page página de registros, activos o no
+func (s crud[T]) Page(ctx context.Context, filter repository.Filter) (Page[T], error) {
+	return listPage(ctx, s.repo(s.store), filter)
+}
+
+// ActivePage página de registros visibles
+func (s crud[T]) ActivePage(ctx context.Context, filter repository.Filter) (Page[T], error) {
+	return s.Page(ctx, activeOnly(filter))
+}
+
+// Page página de usuarios
+func (s *UserService) Page(ctx context.Context, filter repository.Filter) (Page[models.User], error) {
+	return listPage[models.User](ctx, s.store.Users(), filter)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// RELACIONES ENTRE PRODUCTOS
+// ========================================
+
+// RelationService relaciones entre productos elegidas en el CMS: relacionados,
+// accesorios, alternativas y versiones superiores
+type RelationService struct {
+	store   repository.Store
+	changes *changes
+}
+
+// ForProduct relaciones del producto por tipo y posición
+// Parámetros:
+//   - productID: Producto (repository.ErrNotFound si no existe)
+func (s *RelationService) ForProduct(ctx context.Context, productID uint) ([]models.ProductRelation, error) {
+	if _, err := s.store.Products().Get(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.store.Relations().ForProducts(ctx, []uint{productID})
+}
+
+// SetRelations sustituye las relaciones del producto; cada una queda en su orden
+// dentro de las de su tipo
+// Parámetros:
+//   - productID: Producto (repository.ErrNotFound si no existe)
+//   - relations: Productos activos distintos del propio, cada uno una sola vez
+//
+// Retorna: *utils.ValidationError con todas las relaciones inválidas
+func (s *RelationService) SetRelations(ctx context.Context, actor Actor, productID uint, relations []models.ProductRelation) error {
+	var product *models.Product
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		product, err = tx.Products().Get(ctx, productID)
+		if err != nil {
+			return err
+		}
+		before, err := tx.Relations().ForProducts(ctx, []uint{productID})
+		if err != nil {
+			return err
+		}
+		if err := prepareRelations(ctx, tx, productID, relations); err != nil {
+			return err
+		}
+		if err := tx.Relations().SetRelations(ctx, productID, relations); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditUpdate, "relation", productID, productRelations{before}, productRelations{relations})
+	})
+	if err == nil {
+		s.changes.publish(ctx, productTags(ctx, s.store, product.CategoryID)...)
+	}
+	return err
+}
+
+// productRelations relaciones de un producto en el registro de auditoría (un objeto, no una lista)
+type productRelations struct {
+	Relations []models.ProductRelation `json:"relations"`
+}
+
+// prepareRelations comprueba tipos, productos y repetidos y numera las posiciones
+func prepareRelations(ctx context.Context, tx repository.Store, productID uint, relations []models.ProductRelation) error {
+	var problems []string
+	seen := map[uint]bool{}
+	positions := map[string]int{}
+	for i := range relations {
+		relation := &relations[i]
+		label := fmt.Sprintf("Producto %d", relation.RelatedID)
+		for _, problem := range utils.ValidateStruct(relation) {
+			problems = append(problems, label+": "+problem)
+		}
+
+		if relation.RelatedID == productID {
+			problems = append(problems, label+": un producto no puede relacionarse consigo mismo")
+		} else if relation.RelatedID != 0 {
+			related, err := tx.Products().Get(ctx, relation.RelatedID)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				problems = append(problems, label+": no existe")
+			case err != nil:
+				return err
+			case !related.Active:
+				problems = append(problems, label+": está inactivo")
+			}
+		}
+		if seen[relation.RelatedID] {
+			problems = append(problems, label+": está repetido")
+		}
+		seen[relation.RelatedID] = true
+
+		relation.Position = positions[relation.Type]
+		positions[relation.Type]++
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	return nil
+}
+
+// ========================================
+// PRODUCTOS RELACIONADOS DE LA FICHA
+// ========================================
+
+// relatedProducts productos visibles relacionados con product por tipo; los de
+// RelationRelated se completan hasta RelatedLimit con los más parecidos (similarProducts)
+func relatedProducts(ctx context.Context, store repository.Store, product models.Product) (map[string][]models.Product, error) {
+	relations, err := store.Relations().ForProducts(ctx, []uint{product.ID})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(relations))
+	for i, relation := range relations {
+		ids[i] = relation.RelatedID
+	}
+	visible, err := visibleProducts(ctx, store, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(visible))
+	for _, candidate := range visible {
+		byID[candidate.ID] = candidate
+	}
+
+	related := map[string][]models.Product{}
+	for _, relation := range relations {
+		if candidate, ok := byID[relation.RelatedID]; ok {
+			related[relation.Type] = append(related[relation.Type], candidate)
+		}
+	}
+	if len(related[models.RelationRelated]) > RelatedLimit {
+		related[models.RelationRelated] = related[models.RelationRelated][:RelatedLimit]
+	}
+	if missing := RelatedLimit - len(related[models.RelationRelated]); missing > 0 {
+		exclude := append(ids, product.ID)
+		similar, err := similarProducts(ctx, store, product, exclude, missing)
+		if err != nil {
+			return nil, err
+		}
+		related[models.RelationRelated] = append(related[models.RelationRelated], similar...)
+	}
+	return related, nil
+}
+
+// similarProducts hasta limit productos visibles parecidos a product, sin los de
+// exclude, de más a menos parecidos; la base de datos puntúa y ordena los candidatos y
+// solo se cargan los elegidos
+func similarProducts(ctx context.Context, store repository.Store, product models.Product, exclude []uint, limit int) ([]models.Product, error) {
+	filter, err := catalogFilter(ctx, store, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	ids, err := store.Products().Similar(ctx, product, filter, exclude, limit)
+	if err != nil {
+		return nil, err
+	}
+	return visibleProducts(ctx, store, ids)
+}
+
+// visibleProducts productos de ids que se muestran en el catálogo, en el orden de ids:
+// activos, de una categoría visible (activa, como todas sus superiores) y, con
+// hide_out_of_stock, no agotados
+func visibleProducts(ctx context.Context, store repository.Store, ids []uint) ([]models.Product, error) {
+	if len(ids) == 0 {
+		return []models.Product{}, nil
+	}
+	filter, err := catalogFilter(ctx, store, repository.ActiveOnly())
+	if err != nil {
+		return nil, err
+	}
+	filter.IDs = ids
+	products, err := store.Products().List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := store.Categories().List(ctx, repository.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		active[category.ID] = category.Active
+	}
+
+	position := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	visible := []models.Product{}
+	for _, product := range products {
+		shown := active[product.CategoryID]
+		for _, id := range product.Category.PathIDs() {
+			shown = shown && active[id]
+		}
+		if shown {
+			visible = append(visible, product)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return position[visible[i].ID] < position[visible[j].ID]
+	})
+	visibleVariants(visible)
+	return visible, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"html"
+	"html/template"
+	"strings"
+	"unicode/utf8"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// BÚSQUEDA DE PRODUCTOS
+// ========================================
+
+// Longitud admitida de la consulta de búsqueda (en caracteres)
+const (
+	SearchMinLength = 2
+	SearchMaxLength = 100
+)
+
+// SearchResult producto encontrado con los textos resaltados en HTML seguro
+type SearchResult struct {
+	models.Product
+	Rank     float64       `json:"rank"`
+	NameHTML template.HTML `json:"name_html"` // Nombre con <mark> en las coincidencias
+	Snippet  template.HTML `json:"snippet"`   // Fragmento de la descripción con <mark>
+}
+
+// Search busca en los productos visibles por relevancia
+// Parámetros:
+//   - query: Texto del usuario; admite "frase exacta", OR y -excluir
+//   - filter: Paginación (Limit y Offset); el orden es siempre por relevancia
+//
+// Retorna: Página de resultados o *utils.ValidationError si la consulta es muy corta o larga
+func (s *ProductService) Search(ctx context.Context, query string, filter repository.Filter) (Page[SearchResult], error) {
+	query = strings.TrimSpace(query)
+	if length := utf8.RuneCountInString(query); length < SearchMinLength || length > SearchMaxLength {
+		return Page[SearchResult]{}, utils.NewValidationError("La búsqueda debe tener entre 2 y 100 caracteres")
+	}
+
+	hide, err := hideOutOfStock(ctx, s.store)
+	if err != nil {
+		return Page[SearchResult]{}, err
+	}
+	hits, total, err := s.store.Products().Search(ctx, repository.SearchFilter{
+		Query:   query,
+		InStock: hide,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	})
+	if err != nil {
+		return Page[SearchResult]{}, err
+	}
+
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		hit.Product.Variants = activeVariants(hit.Product.Variants)
+		results[i] = SearchResult{
+			Product:  hit.Product,
+			Rank:     hit.Rank,
+			NameHTML: highlight(hit.Name),
+			Snippet:  highlight(hit.Snippet),
+		}
+	}
+	return Page[SearchResult]{Items: results, Total: total}, nil
+}
+
+// highlight escapa el texto y convierte las marcas del repositorio en <mark>
+func highlight(text string) template.HTML {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, repository.HighlightStart, "<mark>")
+	text = strings.ReplaceAll(text, repository.HighlightStop, "</mark>")
+	return template.HTML(text)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// Services reglas de negocio del sitio sobre un repository.Store.
+// Los handlers HTTP y websitectl solo traducen entradas y errores.
+type Services struct {
+	Config      *ConfigService
+	Slides      *SlideService
+	Categories  *CategoryService
+	Products    *ProductService
+	Tags        *TagService
+	Collections *CollectionService
+	Variants    *VariantService
+	Currencies  *CurrencyService
+	Relations   *RelationService
+	Stock       *StockService
+	Attributes  *AttributeService
+	Contacts    *ContactService
+	Users       *UserService
+	Audit       *AuditService
+	System      *SystemService
+
+	changes *changes
+}
+
+// New crea los servicios sobre el almacén indicado
+// Parámetros:
+//   - store: Repositorios (GORM en producción, memoria en pruebas)
+func New(store repository.Store) *Services {
+	changes := &changes{}
+	return &Services{
+		Config:      &ConfigService{store: store, changes: changes},
+		Slides:      newSlideService(store, changes),
+		Categories:  newCategoryService(store, changes),
+		Products:    newProductService(store, changes),
+		Tags:        newTagService(store, changes),
+		Collections: newCollectionService(store, changes),
+		Variants:    newVariantService(store, changes),
+		Currencies:  newCurrencyService(store, changes),
+		Relations:   &RelationService{store: store, changes: changes},
+		Stock:       &StockService{store: store, changes: changes},
+		Attributes:  newAttributeService(store, changes),
+		Contacts:    newContactService(store, changes),
+		Users:       newUserService(store),
+		Audit:       &AuditService{store: store},
+		System:      &SystemService{store: store},
+		changes:     changes,
+	}
+}
+
+// OnChange registra la función que recibe las etiquetas de cache (cache.Tag*) afectadas
+// por cada cambio de contenido confirmado; el CMS las publica con cache.Publish
+func (s *Services) OnChange(fn func(ctx context.Context, tags []string)) {
+	s.changes.notify = fn
+}
+
+// changes avisos de cambios de contenido compartidos por los servicios
+type changes struct {
+	notify func(ctx context.Context, tags []string)
+}
+
+// publish avisa de las etiquetas afectadas (sin repetir) tras confirmar un cambio
+func (c *changes) publish(ctx context.Context, tags ...string) {
+	if c == nil || c.notify == nil || len(tags) == 0 {
+		return
+	}
+	seen := make(map[string]bool, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	c.notify(ctx, unique)
+}
+
+// Actor identifica a quien hace un cambio en el registro de auditoría
+type Actor struct {
+	UserID    *uint
+	Username  string
+	Role      string
+	IP        string
+	RequestID string
+}
+
+// ========================================
+// CRUD AUDITADO
+// ========================================
+
+// repoOf elige el repositorio de la entidad dentro de un Store (o una transacción)
+type repoOf[T any] func(repository.Store) repository.CRUD[T]
+
+// crud altas, cambios y bajas validados y auditados en la misma transacción
+type crud[T any] struct {
+	store      repository.Store
+	repo       repoOf[T]
+	entityType string
+	// prepare completa el registro antes de validarlo (slug, valores por defecto)
+	prepare func(ctx context.Context, tx repository.Store, item *T) error
+	// saved guarda lo que depende del registro ya guardado (tablas relacionadas)
+	saved func(ctx context.Context, tx repository.Store, item *T) error
+	// deleted actualiza lo que dependía del registro borrado
+	deleted func(ctx context.Context, tx repository.Store, before *T) error
+	// tags etiquetas de cache que invalida un cambio del registro (tras confirmarlo)
+	tags    func(ctx context.Context, item *T) []string
+	changes *changes
+}
+
+func newCRUD[T any](store repository.Store, changes *changes, entityType string, repo repoOf[T]) crud[T] {
+	return crud[T]{store: store, changes: changes, repo: repo, entityType: entityType}
+}
+
+// Get busca por id
+func (s crud[T]) Get(ctx context.Context, id uint) (*T, error) {
+	return s.repo(s.store).Get(ctx, id)
+}
+
+// Create valida e inserta el registro
+func (s crud[T]) Create(ctx context.Context, actor Actor, item *T) error {
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := s.validate(ctx, tx, item); err != nil {
+			return err
+		}
+		if err := s.repo(tx).Create(ctx, item); err != nil {
+			return err
+		}
+		if err := s.afterSave(ctx, tx, item); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditCreate, s.entityType, entityID(item), nil, item)
+	})
+	if err == nil {
+		s.changes.publish(ctx, s.tagsOf(ctx, item)...)
+	}
+	return err
+}
+
+// Update sustituye el registro id conservando su fecha de creación
+func (s crud[T]) Update(ctx context.Context, actor Actor, id uint, item *T) error {
+	var before *T
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		before, err = s.repo(tx).Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		keepIdentity(item, before, id)
+		if err := s.validate(ctx, tx, item); err != nil {
+			return err
+		}
+		if err := s.repo(tx).Update(ctx, item); err != nil {
+			return err
+		}
+		if err := s.afterSave(ctx, tx, item); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditUpdate, s.entityType, id, before, item)
+	})
+	if err == nil {
+		// Antes y después: un producto que cambia de categoría afecta a las dos
+		s.changes.publish(ctx, append(s.tagsOf(ctx, before), s.tagsOf(ctx, item)...)...)
+	}
+	return err
+}
+
+// Delete elimina el registro id
+func (s crud[T]) Delete(ctx context.Context, actor Actor, id uint) error {
+	var before *T
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		before, err = s.repo(tx).Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo(tx).Delete(ctx, id); err != nil {
+			return err
+		}
+		if s.deleted != nil {
+			if err := s.deleted(ctx, tx, before); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, tx, actor, AuditDelete, s.entityType, id, before, nil)
+	})
+	if err == nil {
+		s.changes.publish(ctx, s.tagsOf(ctx, before)...)
+	}
+	return err
+}
+
+// afterSave aplica saved si la entidad lo define
+func (s crud[T]) afterSave(ctx context.Context, tx repository.Store, item *T) error {
+	if s.saved == nil {
+		return nil
+	}
+	return s.saved(ctx, tx, item)
+}
+
+// tagsOf etiquetas de cache del registro
+func (s crud[T]) tagsOf(ctx context.Context, item *T) []string {
+	if s.tags == nil || item == nil {
+		return nil
+	}
+	return s.tags(ctx, item)
+}
+
+// validate aplica prepare y las etiquetas validate del modelo
+func (s crud[T]) validate(ctx context.Context, tx repository.Store, item *T) error {
+	if s.prepare != nil {
+		if err := s.prepare(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return utils.Validate(item)
+}
+
+// entityID campo ID de un modelo
+func entityID(item interface{}) uint {
+	return uint(reflect.ValueOf(item).Elem().FieldByName("ID").Uint())
+}
+
+// keepIdentity copia ID y CreatedAt del registro guardado: el cuerpo de la
+// petición no puede cambiarlos
+func keepIdentity(item, before interface{}, id uint) {
+	value := reflect.ValueOf(item).Elem()
+	value.FieldByName("ID").SetUint(uint64(id))
+	if createdAt := value.FieldByName("CreatedAt"); createdAt.IsValid() {
+		createdAt.Set(reflect.ValueOf(before).Elem().FieldByName("CreatedAt"))
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// EXISTENCIAS
+// ========================================
+
+// HideOutOfStockKey clave de configuración: "true" oculta los productos agotados del catálogo
+// público (listados, búsqueda y relacionados); su ficha sigue accesible
+const HideOutOfStockKey = "hide_out_of_stock"
+
+// StockService existencias de productos y variantes; solo cambian con Adjust, que
+// deja cada cambio en el historial con su motivo y quién lo hizo
+type StockService struct {
+	store   repository.Store
+	changes *changes
+}
+
+// StockAdjustment ajuste de existencias pedido en el CMS: una entrada o salida (Delta)
+// o un recuento (Quantity), nunca los dos
+type StockAdjustment struct {
+	Delta    *int   `json:"delta"`
+	Quantity *int   `json:"quantity" validate:"omitempty,gte=0"`
+	Reason   string `json:"reason" validate:"required,oneof=restock sale return damage correction count"`
+	Note     string `json:"note" validate:"max=500"`
+}
+
+// Adjust cambia las existencias del producto o de una de sus variantes
+// Parámetros:
+//   - productID: Producto (repository.ErrNotFound si no existe)
+//   - variantID: Variante del producto (nil = el producto, si no tiene variantes)
+//   - adjustment: Delta o Quantity, motivo y nota
+//
+// Retorna: Movimiento registrado o *utils.ValidationError si el inventario no lleva
+// control de existencias o quedarían negativas sin venta bajo pedido
+func (s *StockService) Adjust(ctx context.Context, actor Actor, productID uint, variantID *uint, adjustment StockAdjustment) (*models.StockMovement, error) {
+	if err := utils.Validate(&adjustment); err != nil {
+		return nil, err
+	}
+	switch {
+	case (adjustment.Delta == nil) == (adjustment.Quantity == nil):
+		return nil, utils.NewValidationError("Indica delta (entrada o salida) o quantity (recuento), solo uno de los dos")
+	case adjustment.Delta != nil && *adjustment.Delta == 0:
+		return nil, utils.NewValidationError("delta no puede ser 0")
+	}
+
+	var movement *models.StockMovement
+	var product *models.Product
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Stock().LockProduct(ctx, productID); err != nil {
+			return err
+		}
+		var err error
+		product, err = tx.Products().Get(ctx, productID)
+		if err != nil {
+			return err
+		}
+
+		var variant *models.ProductVariant
+		policy, current := product.StockPolicy, product.Stock
+		if variantID != nil {
+			for i := range product.Variants {
+				if product.Variants[i].ID == *variantID {
+					variant = &product.Variants[i]
+				}
+			}
+			if variant == nil {
+				return repository.ErrNotFound
+			}
+			policy, current = variant.StockPolicy, variant.Stock
+		} else if len(product.Variants) > 0 {
+			return utils.NewValidationError("Las existencias de un producto con variantes se ajustan en cada variante")
+		}
+
+		quantity := current
+		if adjustment.Delta != nil {
+			quantity += *adjustment.Delta
+		} else {
+			quantity = *adjustment.Quantity
+		}
+		switch {
+		case policy != models.StockTracked && policy != models.StockBackorder:
+			return utils.NewValidationError("No lleva control de existencias: stock_policy debe ser tracked o backorder")
+		case quantity < 0 && policy != models.StockBackorder:
+			return utils.NewValidationError("Existencias insuficientes: quedan " + strconv.Itoa(current))
+		}
+
+		if variant != nil {
+			variant.Stock = quantity
+			variant.StockStatus = models.StockStatusOf(policy, quantity, variant.LowStockThreshold)
+			if err := tx.Variants().Update(ctx, variant); err != nil {
+				return err
+			}
+			if err := updateFromVariants(ctx, tx, productID); err != nil {
+				return err
+			}
+		} else {
+			product.Stock = quantity
+			product.StockStatus = models.StockStatusOf(policy, quantity, product.LowStockThreshold)
+			if err := tx.Products().Update(ctx, product); err != nil {
+				return err
+			}
+		}
+
+		movement = &models.StockMovement{
+			ProductID: productID,
+			VariantID: variantID,
+			Delta:     quantity - current,
+			Quantity:  quantity,
+			Reason:    adjustment.Reason,
+			Note:      adjustment.Note,
+			UserID:    actor.UserID,
+			Username:  actor.Username,
+		}
+		if err := tx.Stock().Create(ctx, movement); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditCreate, "stock_movement", movement.ID, nil, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.changes.publish(ctx, productTags(ctx, s.store, product.CategoryID)...)
+	return movement, nil
+}
+
+// Movements historial de existencias del producto y de sus variantes, más recientes primero
+// Parámetros:
+//   - productID: Producto (repository.ErrNotFound si no existe)
+//   - variantID: Solo esta variante (nil = todo el producto)
+//   - limit: Movimientos como máximo (0 = todos)
+func (s *StockService) Movements(ctx context.Context, productID uint, variantID *uint, limit int) ([]models.StockMovement, error) {
+	if _, err := s.store.Products().Get(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.store.Stock().List(ctx, repository.StockFilter{ProductID: productID, VariantID: variantID, Limit: limit})
+}
+
+// prepareStock normaliza la política y calcula la disponibilidad; las existencias son
+// las guardadas (stored; 0 al crear), porque solo cambian con Adjust
+func prepareStock(policy *string, stock *int, status *string, threshold, stored int) {
+	if *policy == "" {
+		*policy = models.StockUntracked
+	}
+	*stock = stored
+	*status = models.StockStatusOf(*policy, stored, threshold)
+}
+
+// variantStatus mejor disponibilidad de las variantes activas; false si no hay ninguna
+func variantStatus(variants []models.ProductVariant) (string, bool) {
+	best, found := len(models.StockStatuses), false
+	for _, variant := range variants {
+		if !variant.Active {
+			continue
+		}
+		status := models.StockStatusOf(variant.StockPolicy, variant.Stock, variant.LowStockThreshold)
+		for i, candidate := range models.StockStatuses {
+			if candidate == status && i < best {
+				best = i
+			}
+		}
+		found = true
+	}
+	if !found {
+		return "", false
+	}
+	return models.StockStatuses[best], true
+}
+
+// catalogFilter filtro de los listados públicos de productos: sin los agotados si la
+// configuración HideOutOfStockKey lo pide
+func catalogFilter(ctx context.Context, store repository.Store, filter repository.Filter) (repository.Filter, error) {
+	hide, err := hideOutOfStock(ctx, store)
+	filter.InStock = hide
+	return filter, err
+}
+
+// hideOutOfStock valor de HideOutOfStockKey; false si no existe o no es un booleano
+func hideOutOfStock(ctx context.Context, store repository.Store) (bool, error) {
+	config, err := store.Configs().FindByKey(ctx, HideOutOfStockKey)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	hide, _ := strconv.ParseBool(config.Value)
+	return hide, nil
+}
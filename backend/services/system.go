@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"website/backend/backup"
+	"website/backend/repository"
+)
+
+// SystemStatus número de registros totales y activos por entidad
+type SystemStatus struct {
+	TotalUsers       int64 `json:"total_users"`
+	ActiveUsers      int64 `json:"active_users"`
+	TotalSlides      int64 `json:"total_slides"`
+	ActiveSlides     int64 `json:"active_slides"`
+	TotalCategories  int64 `json:"total_categories"`
+	ActiveCategories int64 `json:"active_categories"`
+	TotalProducts    int64 `json:"total_products"`
+	ActiveProducts   int64 `json:"active_products"`
+	TotalContacts    int64 `json:"total_contacts"`
+	ActiveContacts   int64 `json:"active_contacts"`
+}
+
+// SystemService estado y copias de seguridad
+type SystemService struct {
+	store repository.Store
+}
+
+// counter cuenta registros de un repositorio
+type counter interface {
+	Count(ctx context.Context, filter repository.Filter) (int64, error)
+}
+
+// Status cuenta los registros de cada entidad
+func (s *SystemService) Status(ctx context.Context) (*SystemStatus, error) {
+	var status SystemStatus
+	counts := []struct {
+		repo          counter
+		total, active *int64
+	}{
+		{s.store.Users(), &status.TotalUsers, &status.ActiveUsers},
+		{s.store.Slides(), &status.TotalSlides, &status.ActiveSlides},
+		{s.store.Categories(), &status.TotalCategories, &status.ActiveCategories},
+		{s.store.Products(), &status.TotalProducts, &status.ActiveProducts},
+		{s.store.Contacts(), &status.TotalContacts, &status.ActiveContacts},
+	}
+
+	for _, count := range counts {
+		var err error
+		if *count.total, err = count.repo.Count(ctx, repository.Filter{}); err != nil {
+			return nil, err
+		}
+		if *count.active, err = count.repo.Count(ctx, repository.ActiveOnly()); err != nil {
+			return nil, err
+		}
+	}
+	return &status, nil
+}
+
+// Backup copia coherente del contenido
+func (s *SystemService) Backup(ctx context.Context) (*backup.Backup, error) {
+	return backup.Create(ctx, s.store)
+}
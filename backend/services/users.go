@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"website/backend/middleware"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// Errores de autenticación
+var (
+	ErrInvalidCredentials = errors.New("credenciales inválidas")
+	ErrInactiveUser       = errors.New("usuario inactivo")
+)
+
+// DefaultRole rol de los usuarios creados sin rol y de los registros públicos
+const DefaultRole = "viewer"
+
+// minPasswordLength longitud mínima de contraseña (igual que models.User)
+const minPasswordLength = 6
+
+// UserInput cuerpo de alta y edición de usuarios. models.User no lee la
+// contraseña del JSON (json:"-") para no devolverla nunca; aquí sí se acepta.
+type UserInput struct {
+	models.User
+	Password string `json:"password"`
+}
+
+// UserService usuarios del CMS; las contraseñas se guardan con HashPassword
+type UserService struct {
+	store repository.Store
+	// HashPassword hashea las contraseñas (bcrypt por defecto; las pruebas usan uno barato)
+	HashPassword func(password string) (string, error)
+	// CheckPassword compara una contraseña con su hash
+	CheckPassword func(password, hash string) bool
+}
+
+func newUserService(store repository.Store) *UserService {
+	return &UserService{
+		store:         store,
+		HashPassword:  middleware.HashPassword,
+		CheckPassword: middleware.CheckPassword,
+	}
+}
+
+// List todos los usuarios por id
+func (s *UserService) List(ctx context.Context) ([]models.User, error) {
+	return s.store.Users().List(ctx, repository.Filter{Sort: repository.SortByID})
+}
+
+// Get busca por id
+func (s *UserService) Get(ctx context.Context, id uint) (*models.User, error) {
+	return s.store.Users().Get(ctx, id)
+}
+
+// FindByLogin busca por username o email
+func (s *UserService) FindByLogin(ctx context.Context, login string) (*models.User, error) {
+	return s.store.Users().FindByLogin(ctx, login)
+}
+
+// Authenticate comprueba usuario (o email) y contraseña
+// Retorna: ErrInvalidCredentials o ErrInactiveUser si no puede iniciar sesión
+func (s *UserService) Authenticate(ctx context.Context, login, password string) (*models.User, error) {
+	user, err := s.store.Users().FindByLogin(ctx, login)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.CheckPassword(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.Active {
+		return nil, ErrInactiveUser
+	}
+	return user, nil
+}
+
+// Create da de alta un usuario; la contraseña es obligatoria y el rol por defecto es viewer
+func (s *UserService) Create(ctx context.Context, actor Actor, input *UserInput) (*models.User, error) {
+	user := input.User
+	user.ID = 0
+	if user.Role == "" {
+		user.Role = DefaultRole
+	}
+	if err := s.setPassword(&user, input.Password); err != nil {
+		return nil, err
+	}
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Create(ctx, &user); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditCreate, "user", user.ID, nil, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Register alta pública: siempre con el rol viewer y activo
+func (s *UserService) Register(ctx context.Context, actor Actor, input *UserInput) (*models.User, error) {
+	registration := *input
+	registration.Role = DefaultRole
+	registration.Active = true
+	return s.Create(ctx, actor, &registration)
+}
+
+// Update sustituye los datos del usuario id; sin contraseña se conserva la actual
+func (s *UserService) Update(ctx context.Context, actor Actor, id uint, input *UserInput) (*models.User, error) {
+	user := input.User
+	var updated *models.User
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := tx.Users().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		keepIdentity(&user, before, id)
+
+		if input.Password == "" {
+			user.Password = before.Password
+			if err := utils.Validate(&user); err != nil {
+				return err
+			}
+		} else if err := s.setPassword(&user, input.Password); err != nil {
+			return err
+		}
+
+		if err := tx.Users().Update(ctx, &user); err != nil {
+			return err
+		}
+		updated = &user
+		return recordAudit(ctx, tx, actor, AuditUpdate, "user", id, before, user)
+	})
+	return updated, err
+}
+
+// Modify aplica change al usuario login (username o email), lo valida y lo audita
+func (s *UserService) Modify(ctx context.Context, actor Actor, login string, change func(user *models.User)) (*models.User, error) {
+	var updated *models.User
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := tx.Users().FindByLogin(ctx, login)
+		if err != nil {
+			return err
+		}
+		user := *before
+		change(&user)
+		if err := utils.Validate(&user); err != nil {
+			return err
+		}
+		if err := tx.Users().Update(ctx, &user); err != nil {
+			return err
+		}
+		updated = &user
+		return recordAudit(ctx, tx, actor, AuditUpdate, "user", user.ID, before, user)
+	})
+	return updated, err
+}
+
+// ResetPassword cambia la contraseña del usuario login
+func (s *UserService) ResetPassword(ctx context.Context, actor Actor, login, password string) (*models.User, error) {
+	if len(password) < minPasswordLength {
+		return nil, utils.NewValidationError("Password debe tener al menos 6 caracteres")
+	}
+	hash, err := s.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return s.Modify(ctx, actor, login, func(user *models.User) {
+		user.Password = hash
+	})
+}
+
+// Delete elimina el usuario id
+func (s *UserService) Delete(ctx context.Context, actor Actor, id uint) error {
+	return s.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := tx.Users().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Users().Delete(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, actor, AuditDelete, "user", id, before, nil)
+	})
+}
+
+// setPassword valida el usuario con la contraseña en claro y la sustituye por su hash
+func (s *UserService) setPassword(user *models.User, password string) error {
+	user.Password = password
+	if err := utils.Validate(user); err != nil {
+		return err
+	}
+
+	hash, err := s.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	return nil
+}
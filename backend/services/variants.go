@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"website/backend/cache"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/repository"
+	"website/backend/utils"
+)
+
+// ========================================
+// VARIANTES DE PRODUCTO
+// ========================================
+
+// VariantService variantes de los productos; el SKU es único en todo el catálogo y
+// cada cambio recalcula el precio "desde" y la disponibilidad del producto en la misma
+// transacción
+type VariantService struct {
+	crud[models.ProductVariant]
+}
+
+func newVariantService(store repository.Store, changes *changes) *VariantService {
+	service := &VariantService{crud: newCRUD(store, changes, "variant", func(s repository.Store) repository.CRUD[models.ProductVariant] {
+		return s.Variants()
+	})}
+	// El precio del producto aparece en los listados de su categoría
+	service.tags = func(ctx context.Context, variant *models.ProductVariant) []string {
+		product, err := store.Products().Get(ctx, variant.ProductID)
+		if err != nil {
+			return []string{cache.TagProducts}
+		}
+		return productTags(ctx, store, product.CategoryID)
+	}
+	service.prepare = prepareVariant
+	service.saved = func(ctx context.Context, tx repository.Store, variant *models.ProductVariant) error {
+		return updateFromVariants(ctx, tx, variant.ProductID)
+	}
+	service.deleted = service.saved
+	return service
+}
+
+// ForProduct variantes del producto por orden, activas o no
+func (s *VariantService) ForProduct(ctx context.Context, productID uint) ([]models.ProductVariant, error) {
+	return s.store.Variants().List(ctx, repository.Filter{ProductID: productID, Sort: repository.SortByOrder})
+}
+
+// prepareVariant normaliza el SKU, las opciones y el inventario y comprueba el producto,
+// la unicidad del SKU y de la combinación de opciones dentro del producto y el precio anterior
+func prepareVariant(ctx context.Context, tx repository.Store, variant *models.ProductVariant) error {
+	stored := 0
+	if variant.ID != 0 {
+		before, err := tx.Variants().Get(ctx, variant.ID)
+		if err != nil {
+			return err
+		}
+		stored = before.Stock
+	}
+	prepareStock(&variant.StockPolicy, &variant.Stock, &variant.StockStatus, variant.LowStockThreshold, stored)
+
+	if variant.ProductID != 0 {
+		_, err := tx.Products().Get(ctx, variant.ProductID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return utils.NewValidationError("ProductID no corresponde a ningún producto")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	variant.SKU = strings.TrimSpace(variant.SKU)
+	options := make(map[string]string, len(variant.Options))
+	for name, value := range variant.Options {
+		options[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	variant.Options = options
+
+	var problems []string
+	if variant.CompareAtPrice > 0 && variant.CompareAtPrice <= variant.Price {
+		problems = append(problems, "CompareAtPrice debe ser mayor que Price")
+	}
+	if variant.SKU != "" {
+		taken, err := tx.Variants().SKUExists(ctx, variant.SKU, variant.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			problems = append(problems, "SKU "+variant.SKU+" ya existe en el catálogo")
+		}
+	}
+	if variant.ProductID != 0 {
+		siblings, err := tx.Variants().List(ctx, repository.Filter{ProductID: variant.ProductID})
+		if err != nil {
+			return err
+		}
+		for _, sibling := range siblings {
+			if sibling.ID != variant.ID && optionsKey(sibling) == optionsKey(*variant) {
+				problems = append(problems, "Ya existe una variante con las opciones "+variant.Label())
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return utils.NewValidationError(problems...)
+	}
+	return nil
+}
+
+// updateFromVariants guarda en el producto el precio "desde" y la disponibilidad que
+// resultan de sus variantes (applyVariants)
+func updateFromVariants(ctx context.Context, tx repository.Store, productID uint) error {
+	product, err := tx.Products().Get(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	price, status := product.Price, product.StockStatus
+	applyVariants(product)
+	if price == product.Price && status == product.StockStatus {
+		return nil
+	}
+	return tx.Products().Update(ctx, product)
+}
+
+// applyVariants con variantes activas el precio del producto es el menor de ellas y su
+// disponibilidad la mejor de ellas; sin ellas conserva su precio y la disponibilidad
+// sale de su propio inventario
+func applyVariants(product *models.Product) {
+	product.StockStatus = models.StockStatusOf(product.StockPolicy, product.Stock, product.LowStockThreshold)
+	if price, ok := fromPrice(product.Variants); ok {
+		product.Price = price
+	}
+	if status, ok := variantStatus(product.Variants); ok {
+		product.StockStatus = status
+	}
+}
+
+// fromPrice menor precio de las variantes activas; false si no hay ninguna
+func fromPrice(variants []models.ProductVariant) (money.Amount, bool) {
+	price, found := money.Amount(0), false
+	for _, variant := range variants {
+		if variant.Active && (!found || variant.Price < price) {
+			price, found = variant.Price, true
+		}
+	}
+	return price, found
+}
+
+// visibleVariants deja solo las variantes activas de los productos públicos
+func visibleVariants(products []models.Product) {
+	for i := range products {
+		products[i].Variants = activeVariants(products[i].Variants)
+	}
+}
+
+// activeVariants variantes activas, en el mismo orden
+func activeVariants(variants []models.ProductVariant) []models.ProductVariant {
+	var active []models.ProductVariant
+	for _, variant := range variants {
+		if variant.Active {
+			active = append(active, variant)
+		}
+	}
+	return active
+}
+
+// optionsKey combinación de opciones sin distinguir mayúsculas ni el orden
+func optionsKey(variant models.ProductVariant) string {
+	return strings.ToLower(variant.Label())
+}
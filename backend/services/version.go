@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"time"
+	"website/backend/models"
+	"website/backend/repository"
+)
+
+// ========================================
+// VERSIONES DE LOS LISTADOS PÚBLICOS
+// ========================================
+
+// Version validadores HTTP de un listado público, calculados con consultas agregadas
+// (número de filas y mayor updated_at) sin cargar las filas
+type Version struct {
+	ETag         string    // ETag fuerte entrecomillado
+	LastModified time.Time // Última modificación de las tablas enteras (incluye borrados); cero si no hay datos
+}
+
+// versionBuilder acumula las consultas agregadas de las tablas de las que depende un
+// listado; version las resuelve todas con una sola ida y vuelta a la base de datos
+type versionBuilder struct {
+	hash         []byte
+	lastModified time.Time
+	queries      []repository.StatsQuery
+	counted      []bool // Por consulta: el número de filas forma parte del ETag
+}
+
+// newVersion versión identificada por scope (listado y filtro), que forma parte del ETag
+func newVersion(scope string) *versionBuilder {
+	return &versionBuilder{hash: []byte(scope)}
+}
+
+// count añade el número de filas y el mayor updated_at de una tabla entera
+func (v *versionBuilder) count(model interface{}) {
+	v.queue(repository.StatsQuery{Model: model}, true)
+}
+
+// table añade los agregados de un listado y la fecha del último borrado de la entidad
+// según la auditoría: los borrados no dejan updated_at
+// Last-Modified usa el mayor updated_at de toda la tabla: un registro que sale del
+// listado (desactivado, agotado, movido de categoría) no está entre los filtrados, pero
+// su cambio debe adelantar la fecha o If-Modified-Since daría un 304 obsoleto
+// Parámetros:
+//   - model: Puntero al modelo de la tabla, p. ej. new(models.Product)
+//   - entityType: Entidad en la auditoría
+func (v *versionBuilder) table(model interface{}, entityType string, filter repository.Filter) {
+	v.queue(repository.StatsQuery{Model: model, Filter: filter}, true)
+	if !reflect.ValueOf(filter).IsZero() {
+		v.queue(repository.StatsQuery{Model: model}, false)
+	}
+	v.queue(repository.StatsQuery{Audit: &repository.AuditFilter{EntityType: entityType, Action: AuditDelete}}, false)
+}
+
+// queue añade una consulta; si no counted solo cuenta su fecha
+func (v *versionBuilder) queue(query repository.StatsQuery, counted bool) {
+	v.queries = append(v.queries, query)
+	v.counted = append(v.counted, counted)
+}
+
+// version resuelve las consultas acumuladas y devuelve el ETag y el Last-Modified
+func (v *versionBuilder) version(ctx context.Context, store repository.Store) (Version, error) {
+	stats, err := store.BatchStats(ctx, v.queries)
+	if err != nil {
+		return Version{}, err
+	}
+	for i, stat := range stats {
+		if v.counted[i] {
+			v.hash = strconv.AppendInt(append(v.hash, '|'), stat.Count, 10)
+		}
+		v.hash = strconv.AppendInt(append(v.hash, '@'), stat.LastModified.UnixNano(), 10)
+		if stat.LastModified.After(v.lastModified) {
+			v.lastModified = stat.LastModified
+		}
+	}
+
+	sum := sha256.Sum256(v.hash)
+	return Version{
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: v.lastModified,
+	}, nil
+}
+
+// ActiveVersion versión de un listado de registros visibles
+// Parámetros:
+//   - filter: Filtros del listado (se ignoran el orden y la paginación)
+//   - scope: Listado y query normalizada; forma parte del ETag
+func (s crud[T]) ActiveVersion(ctx context.Context, filter repository.Filter, scope string) (Version, error) {
+	version := newVersion(scope)
+	version.table(new(T), s.entityType, activeOnly(filter))
+	return version.version(ctx, s.store)
+}
+
+// ActiveVersion versión de un listado de productos visibles (ver catalogVersion)
+func (s *ProductService) ActiveVersion(ctx context.Context, filter repository.Filter, scope string) (Version, error) {
+	version, err := s.activeVersion(ctx, filter, scope)
+	if err != nil {
+		return Version{}, err
+	}
+	return version.version(ctx, s.store)
+}
+
+// DetailVersion versión de la ficha de un producto: la de cualquier producto visible,
+// porque sus relacionados pueden ser cualquiera, y la de las relaciones del CMS
+// Parámetros:
+//   - scope: Ruta y query normalizada; forma parte del ETag
+func (s *ProductService) DetailVersion(ctx context.Context, scope string) (Version, error) {
+	version, err := s.activeVersion(ctx, repository.Filter{}, scope)
+	if err != nil {
+		return Version{}, err
+	}
+	// SetRelations sustituye las filas: cambian el número o el mayor updated_at
+	version.count(&models.ProductRelation{})
+	return version.version(ctx, s.store)
+}
+
+// activeVersion consultas de los productos visibles y de lo que incluyen
+func (s *ProductService) activeVersion(ctx context.Context, filter repository.Filter, scope string) (*versionBuilder, error) {
+	version := newVersion(scope)
+	filter, err := catalogFilter(ctx, s.store, activeOnly(filter))
+	if err != nil {
+		return nil, err
+	}
+	version.table(new(models.Product), s.entityType, filter)
+	catalogVersion(version)
+	return version, nil
+}
+
+// ActiveVersion versión de la página de una colección: sus productos pueden ser
+// cualquiera del catálogo y las reglas de antigüedad cambian cada día, así que depende
+// de las colecciones visibles, de todos los productos (ver catalogVersion) y de la fecha
+func (s *CollectionService) ActiveVersion(ctx context.Context, filter repository.Filter, scope string) (Version, error) {
+	version := newVersion(scope + "|" + time.Now().Format(time.DateOnly))
+	version.table(new(models.Collection), s.entityType, activeOnly(filter))
+	version.table(new(models.Product), "product", repository.Filter{})
+	catalogVersion(version)
+	return version.version(ctx, s.store)
+}
+
+// catalogVersion añade lo que incluyen los productos de un listado: su categoría, sus
+// etiquetas, sus atributos, sus variantes y sus precios por moneda, así que depende de
+// categorías, etiquetas, variantes, definiciones, monedas, precios fijados y de la
+// configuración que oculta los agotados
+func catalogVersion(version *versionBuilder) {
+	version.table(new(models.Category), "category", repository.Filter{})
+	version.table(new(models.Tag), "tag", repository.Filter{})
+	version.table(new(models.ProductVariant), "variant", repository.Filter{})
+	// Las facetas dependen de las definiciones de atributos
+	version.table(new(models.AttributeDefinition), "attribute", repository.Filter{})
+	version.table(new(models.Currency), "currency", repository.Filter{})
+	// SetPrices sustituye las filas: cambian el número o el mayor updated_at
+	version.count(&models.ProductPrice{})
+	version.count(&models.SiteConfig{})
+}
+
+// activeOnly filtro restringido a registros visibles
+func activeOnly(filter repository.Filter) repository.Filter {
+	active := true
+	filter.Active = &active
+	return filter
+}
+
+// Version versión de la configuración del sitio
+func (s *ConfigService) Version(ctx context.Context) (Version, error) {
+	version := newVersion("config")
+	version.count(&models.SiteConfig{})
+	return version.version(ctx, s.store)
+}
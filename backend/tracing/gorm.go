@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey clave de la instancia de GORM donde se guarda el span de la consulta
+const gormSpanKey = "tracing:span"
+
+// GormPlugin plugin de GORM que crea un span hijo por cada consulta
+// El span padre se toma del contexto de la sesión (db.WithContext)
+type GormPlugin struct{}
+
+// Name nombre del plugin para GORM
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registra callbacks antes y después de cada tipo de operación
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []func() error{
+		func() error {
+			return callback.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create"))
+		},
+		func() error { return callback.Create().After("gorm:create").Register("tracing:after_create", endSpan) },
+		func() error {
+			return callback.Query().Before("gorm:query").Register("tracing:before_query", startSpan("select"))
+		},
+		func() error { return callback.Query().After("gorm:query").Register("tracing:after_query", endSpan) },
+		func() error {
+			return callback.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update"))
+		},
+		func() error { return callback.Update().After("gorm:update").Register("tracing:after_update", endSpan) },
+		func() error {
+			return callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete"))
+		},
+		func() error { return callback.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan) },
+		func() error {
+			return callback.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row"))
+		},
+		func() error { return callback.Row().After("gorm:row").Register("tracing:after_row", endSpan) },
+		func() error {
+			return callback.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw"))
+		},
+		func() error { return callback.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan) },
+	}
+
+	for _, register := range registrations {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startSpan abre el span de la consulta como hijo del contexto de la sesión
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			return
+		}
+
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+
+		_, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// endSpan completa el span con la consulta (sin valores) y el resultado
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// Solo el SQL con placeholders: los valores pueden contener datos personales
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"website/backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName nombre del tracer usado por la aplicación
+const TracerName = "website"
+
+// Config configuración del exportador de trazas
+type Config struct {
+	ServiceName string  // Nombre del servicio en las trazas
+	Exporter    string  // none, stdout u otlp
+	Endpoint    string  // URL del colector OTLP/HTTP (vacío = OTEL_EXPORTER_OTLP_ENDPOINT o localhost:4318)
+	Insecure    bool    // Usar HTTP sin TLS con el colector
+	SampleRatio float64 // Fracción de trazas raíz muestreadas (0-1)
+}
+
+// NewConfig construye la configuración de trazas a partir de la de la aplicación
+// Parámetros:
+//   - settings: Sección tracing de la configuración (OTEL_*)
+//   - serviceName: Nombre por defecto si OTEL_SERVICE_NAME no está definido
+func NewConfig(settings config.TracingConfig, serviceName string) Config {
+	if settings.ServiceName != "" {
+		serviceName = settings.ServiceName
+	}
+	return Config{
+		ServiceName: serviceName,
+		Exporter:    settings.Exporter,
+		Endpoint:    settings.Endpoint,
+		Insecure:    settings.Insecure,
+		SampleRatio: settings.SampleRatio,
+	}
+}
+
+// Setup instala el propagador W3C (traceparent, baggage) y el proveedor de trazas global
+// Con el exportador "none" solo se propaga el contexto, sin registrar spans
+// Parámetros:
+//   - ctx: Contexto para crear el exportador
+//   - config: Configuración del exportador
+//
+// Retorna: Función que vacía y cierra el exportador, y error si la configuración es inválida
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("exportador de trazas desconocido: %q (use none, stdout u otlp)", config.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(config, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider crea un proveedor de trazas con el recurso del servicio
+// Parámetros:
+//   - config: Nombre del servicio y fracción de muestreo
+//   - options: Opciones adicionales (por ejemplo el procesador del exportador)
+func NewProvider(config Config, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(config.ServiceName),
+	))
+	if err != nil {
+		res = resource.Default()
+	}
+
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	}, options...)
+
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// Tracer devuelve el tracer de la aplicación del proveedor global
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Render renderiza una plantilla dentro de un span hijo del span de la petición
+// Parámetros:
+//   - c: Contexto de Fiber
+//   - name: Nombre de la plantilla
+//   - bind: Datos de la plantilla
+//   - layouts: Layouts opcionales
+func Render(c *fiber.Ctx, name string, bind interface{}, layouts ...string) error {
+	_, span := Tracer().Start(c.UserContext(), "template.render "+name,
+		trace.WithAttributes(attribute.String("template.name", name)),
+	)
+	defer span.End()
+
+	err := c.Render(name, bind, layouts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// useInMemoryExporter instala un proveedor que guarda los spans en memoria
+func useInMemoryExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(Config{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return exporter
+}
+
+// dryRunDB crea una conexión de GORM que genera SQL sin ejecutarlo
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatalf("db.Use: %v", err)
+	}
+	return db
+}
+
+type product struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPluginCreatesChildSpans(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	db := dryRunDB(t)
+
+	ctx, parent := Tracer().Start(context.Background(), "GET /productos")
+	var products []product
+	db.WithContext(ctx).Where("name = ?", "secreto@example.com").Find(&products)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("se esperaban 2 spans, hay %d", len(spans))
+	}
+
+	query := spans[0]
+	if query.Name != "gorm.select products" {
+		t.Errorf("nombre del span = %q", query.Name)
+	}
+	if query.Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Error("el span de la consulta no es hijo del span de la petición")
+	}
+
+	attrs := make(map[string]string)
+	for _, attr := range query.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["db.collection.name"] != "products" || attrs["db.system"] != "postgresql" {
+		t.Errorf("atributos inesperados: %v", attrs)
+	}
+	if text := attrs["db.query.text"]; text != `SELECT * FROM "products" WHERE name = $1` {
+		t.Errorf("db.query.text = %q (no debe incluir valores)", text)
+	}
+}
+
+type fakeViews struct{}
+
+func (fakeViews) Load() error { return nil }
+
+func (fakeViews) Render(w io.Writer, name string, _ interface{}, _ ...string) error {
+	_, err := io.WriteString(w, name)
+	return err
+}
+
+func TestRenderCreatesTemplateSpan(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+
+	app := fiber.New(fiber.Config{Views: fakeViews{}})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Render(c, "pages/index", fiber.Map{})
+	})
+
+	if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "template.render pages/index" {
+		t.Fatalf("spans = %v", spans)
+	}
+}
+
+func TestOTLPExporterSendsToCollector(t *testing.T) {
+	// Colector local que acepta OTLP/HTTP
+	received := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		select {
+		case received <- r.URL.Path:
+		default:
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	shutdown, err := Setup(context.Background(), Config{
+		ServiceName: "test",
+		Exporter:    "otlp",
+		Endpoint:    collector.URL,
+		Insecure:    true,
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	_, span := Tracer().Start(context.Background(), "prueba")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	select {
+	case path := <-received:
+		if path != "/v1/traces" {
+			t.Errorf("ruta OTLP = %q", path)
+		}
+	default:
+		t.Fatal("el colector no recibió spans")
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Fatal("se esperaba error con un exportador desconocido")
+	}
+}
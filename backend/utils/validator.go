@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -14,6 +15,34 @@ func init() {
 
 	// Register custom validators
 	validate.RegisterValidation("alphanum", validateAlphanum)
+	validate.RegisterValidation("slug", validateSlug)
+}
+
+// slugPattern lowercase words separated by single hyphens, as produced by GenerateSlug
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// ValidationError is returned when a request body does not pass validation.
+// The error handler answers it with 400 and the list of problems.
+type ValidationError struct {
+	Message string
+	Details []string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message + ": " + strings.Join(e.Details, "; ")
+}
+
+// NewValidationError builds a ValidationError from ValidateStruct messages
+func NewValidationError(details ...string) *ValidationError {
+	return &ValidationError{Message: "Errores de validación", Details: details}
+}
+
+// Validate validates a struct and returns a *ValidationError if it is not valid
+func Validate(s interface{}) error {
+	if errors := ValidateStruct(s); len(errors) > 0 {
+		return NewValidationError(errors...)
+	}
+	return nil
 }
 
 // ValidateStruct validates a struct using validator tags
@@ -45,6 +74,8 @@ func ValidateStruct(s interface{}) []string {
 				message = field + " debe ser uno de: " + param
 			case "alphanum":
 				message = field + " debe contener solo letras y números"
+			case "slug":
+				message = field + " debe contener solo minúsculas, números y guiones"
 			default:
 				message = field + " no es válido"
 			}
@@ -67,29 +98,43 @@ func validateAlphanum(fl validator.FieldLevel) bool {
 	return true
 }
 
-// ParseAndValidate parses JSON body and validates struct
-func ParseAndValidate(c *fiber.Ctx, s interface{}) error {
+// validateSlug custom validator for URL slugs
+func validateSlug(fl validator.FieldLevel) bool {
+	return slugPattern.MatchString(fl.Field().String())
+}
+
+// ParseBody parses the JSON body, returning a 400 error if it is malformed
+func ParseBody(c *fiber.Ctx, s interface{}) error {
 	if err := c.BodyParser(s); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Error al parsear el JSON: " + err.Error(),
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Error al parsear el JSON: "+err.Error())
 	}
+	return nil
+}
 
-	errors := ValidateStruct(s)
-	if len(errors) > 0 {
-		return c.Status(400).JSON(fiber.Map{
-			"error":   "Errores de validación",
-			"details": errors,
-		})
+// ParseAndValidate parses JSON body and validates struct.
+// It does not write the response: handlers must return the error as is so
+// the error handler answers 400 and the handler stops.
+func ParseAndValidate(c *fiber.Ctx, s interface{}) error {
+	if err := ParseBody(c, s); err != nil {
+		return err
 	}
-
-	return nil
+	return Validate(s)
 }
 
+// slugReplacer transliterates accented letters before dropping non-ASCII characters
+var slugReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
 // GenerateSlug generates a URL-friendly slug from a string
 func GenerateSlug(s string) string {
-	// Convert to lowercase
-	s = strings.ToLower(s)
+	// Convert to lowercase and transliterate accents ("Señal Básica" -> "senal-basica")
+	s = slugReplacer.Replace(strings.ToLower(s))
 
 	// Replace spaces with hyphens
 	s = strings.ReplaceAll(s, " ", "-")
@@ -104,7 +149,9 @@ func GenerateSlug(s string) string {
 
 	// Remove multiple consecutive hyphens
 	slug := result.String()
-	slug = strings.ReplaceAll(slug, "--", "-")
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
 	slug = strings.Trim(slug, "-")
 
 	return slug
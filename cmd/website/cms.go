@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"website/backend/assets"
+	"website/backend/cache"
+	"website/backend/config"
+	"website/backend/health"
+	"website/backend/middleware"
+	"website/backend/migrate"
+	"website/backend/repository"
+	"website/backend/server"
+	"website/backend/services"
+	"website/cms/admin"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// cmsServer admin API and admin panel; it owns the schema migrations
+func (rt *runtime) cmsServer(addr string) (server.Server, error) {
+	cfg := rt.cfg
+	db, sqlDB, err := rt.open(cfg.CMSDatabase)
+	if err != nil {
+		return server.Server{}, err
+	}
+
+	// Business rules over the GORM repositories
+	svc := services.New(repository.NewGormStore(db))
+
+	// Content changes invalidate the public response cache, which listens on the backend database
+	_, backendDB, err := rt.open(cfg.Database)
+	if err != nil {
+		return server.Server{}, err
+	}
+	svc.OnChange(func(ctx context.Context, tags []string) {
+		if err := cache.Publish(context.WithoutCancel(ctx), backendDB, cache.JoinTags(tags)); err != nil {
+			slog.Warn("cache invalidation not published", "tags", tags, "error", err)
+		}
+	})
+
+	// Apply pending migrations (the advisory lock serializes concurrent starts)
+	// and refuse to start if the schema drifted from the embedded migrations
+	migrator, err := migrator(sqlDB)
+	if err != nil {
+		return server.Server{}, err
+	}
+	if cfg.Migrate.OnStart {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			return server.Server{}, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		return server.Server{}, fmt.Errorf("database schema check failed: %w", err)
+	}
+
+	// Admin panel embedded in the binary (from disk in development)
+	ui, err := assets.Open(admin.UI, ".", filepath.Join(cfg.Assets.Dir, "cms", "admin"), cfg.Assets.Dev)
+	if err != nil {
+		return server.Server{}, fmt.Errorf("failed to open admin panel: %w", err)
+	}
+
+	// Readiness checks
+	registry := health.NewRegistry()
+	registry.Register("database", health.DatabaseCheck(sqlDB))
+	registry.Register("migrations", migrator.Check)
+
+	app := rt.newApp(fiber.Config{BodyLimit: int(cfg.Upload.MaxFileSize)}, registry)
+
+	// Rate limiting estricto para CMS
+	app.Use(middleware.RateLimitStrict())
+
+	// Admin panel, then the admin routes (which require a token)
+	if err := admin.SetupUI(app, ui, cfg.Assets.Dev); err != nil {
+		return server.Server{}, err
+	}
+	admin.SetupRoutes(app, svc)
+
+	listen, err := rt.listen(partCMS, app, addr, registry)
+	if err != nil {
+		return server.Server{}, err
+	}
+	return server.Server{Name: partCMS, App: app, Listen: listen, Health: registry}, nil
+}
+
+// runMigrate "migrate up|down [n]|status" over the CMS database
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	rt := newRuntime(cfg)
+	defer rt.close()
+
+	_, sqlDB, err := rt.open(cfg.CMSDatabase)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrator(sqlDB)
+	if err != nil {
+		return err
+	}
+	return migrate.Run(ctx, migrator, args, os.Stdout)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"website/backend/config"
+	"website/backend/middleware"
+)
+
+const usage = `website: sitio público y CMS en un solo binario
+
+uso: website [-config archivo.yaml] [-dev] [-print-config] <comando> [argumentos]
+
+comandos:
+  serve public|cms|all [-public-addr host:puerto] [-cms-addr host:puerto]
+  migrate up | down [n] | status
+
+-dev sirve plantillas, assets y panel del CMS desde el disco (ASSETS_DIR)
+y recarga las plantillas en cada petición.
+`
+
+func main() {
+	flags := flag.NewFlagSet("website", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configFile := flags.String("config", "", "Archivo YAML de configuración (por defecto CONFIG_FILE)")
+	printConfig := flags.Bool("print-config", false, "Imprimir la configuración efectiva (secretos ocultos) y salir")
+	dev := flags.Bool("dev", false, "Leer plantillas y assets del disco con recarga (ASSETS_DEV)")
+	flags.Parse(os.Args[1:])
+
+	// Load configuration: defaults, optional YAML, .env and environment
+	cfg, err := config.Load(config.LoadOptions{ConfigFile: *configFile})
+	if err != nil {
+		log.Fatal("Failed to load configuration:\n", err)
+	}
+	if *dev {
+		cfg.Assets.Dev = true
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout, "yaml"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+	if *printConfig {
+		return
+	}
+	middleware.Configure(cfg)
+
+	if err := run(cfg, flags.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run dispatches the command
+func run(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("falta el comando\n" + usage)
+	}
+
+	switch args[0] {
+	case "serve":
+		return runServe(cfg, args[1:])
+	case "migrate":
+		return runMigrate(context.Background(), cfg, args[1:])
+	default:
+		return fmt.Errorf("comando desconocido %q\n%s", args[0], usage)
+	}
+}
+
+// newLogger structured logger (JSON in production) set as the default one
+func newLogger() (*slog.Logger, middleware.LogConfig) {
+	logConfig := middleware.DefaultLogConfig()
+	logger := middleware.NewLogger(logConfig)
+	slog.SetDefault(logger)
+	return logger, logConfig
+}
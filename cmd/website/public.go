@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+	"website/backend/assets"
+	"website/backend/cache"
+	"website/backend/controllers"
+	"website/backend/health"
+	"website/backend/metrics"
+	"website/backend/middleware"
+	"website/backend/repository"
+	"website/backend/server"
+	"website/backend/services"
+	"website/frontend"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// publicServer public website: pages, public API and static assets
+func (rt *runtime) publicServer(addr string) (server.Server, error) {
+	cfg := rt.cfg
+	db, sqlDB, err := rt.open(cfg.Database)
+	if err != nil {
+		return server.Server{}, err
+	}
+
+	// Business rules over the GORM repositories
+	svc := services.New(repository.NewGormStore(db))
+
+	// The CMS owns the schema: refuse to start if it drifted from the embedded migrations
+	migrator, err := migrator(sqlDB)
+	if err != nil {
+		return server.Server{}, err
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		return server.Server{}, fmt.Errorf("database schema check failed: %w", err)
+	}
+
+	// Templates and assets embedded in the binary (from disk in development)
+	diskDir := filepath.Join(cfg.Assets.Dir, "frontend")
+	templates, err := assets.Open(frontend.FS, "templates", diskDir, cfg.Assets.Dev)
+	if err != nil {
+		return server.Server{}, fmt.Errorf("failed to open templates: %w", err)
+	}
+	static, err := assets.Open(frontend.FS, "assets", diskDir, cfg.Assets.Dev)
+	if err != nil {
+		return server.Server{}, fmt.Errorf("failed to open assets: %w", err)
+	}
+	engine := assets.Templates(templates, cfg.Assets.Dev)
+	if err := engine.Load(); err != nil {
+		// Reported by /readyz through the templates check
+		slog.Warn("failed to load templates", "error", err)
+	}
+
+	// Readiness checks
+	registry := health.NewRegistry()
+	registry.Register("database", health.DatabaseCheck(sqlDB))
+	registry.Register("migrations", migrator.Check)
+	registry.Register("templates", health.TemplatesCheck(engine))
+
+	app := rt.newApp(fiber.Config{Views: metrics.InstrumentViews(engine)}, registry)
+
+	// Static assets (sin rate limiting ni cache de respuestas; el navegador los cachea)
+	app.Use("/assets", assets.Static(static, cfg.Assets.Dev))
+
+	// Rate limiting middleware
+	app.Use(middleware.RateLimitModerate())
+
+	// Response cache for public routes, keyed by path, query and Vary (CORS varies by Origin),
+	// bounded to cache.DefaultMaxEntries (least recently used first).
+	// CMS changes invalidate their tags through LISTEN/NOTIFY; "websitectl cache purge" empties it
+	cacheStore := cache.NewStore()
+	stopCache := make(chan struct{})
+	listenCtx, stopListen := context.WithCancel(context.Background())
+	go cacheStore.Cleanup(time.Minute, stopCache)
+	go cache.Listen(listenCtx, cfg.Database.DSN(), cacheStore.Purge)
+	rt.cleanup = append(rt.cleanup, func() error {
+		stopListen()
+		close(stopCache)
+		return nil
+	})
+	app.Use(cache.New(cache.Config{
+		// In development pages change with the templates: no response cache
+		Next:       func(*fiber.Ctx) bool { return cfg.Assets.Dev },
+		Expiration: cache.DefaultExpiration,
+		Store:      cacheStore,
+	}))
+
+	// Setup routes
+	controllers.SetupRoutes(app, svc, cfg.Site.URL)
+
+	listen, err := rt.listen(partPublic, app, addr, registry)
+	if err != nil {
+		return server.Server{}, err
+	}
+	return server.Server{Name: partPublic, App: app, Listen: listen, Health: registry}, nil
+}
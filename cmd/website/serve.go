@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+	"website/backend/config"
+	"website/backend/health"
+	"website/backend/metrics"
+	"website/backend/middleware"
+	"website/backend/migrate"
+	"website/backend/server"
+	"website/backend/tracing"
+	"website/cms/migrations"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Parts served by each mode of "serve"
+const (
+	partPublic = "public"
+	partCMS    = "cms"
+	partAll    = "all"
+)
+
+// serviceNames tracing service name of each mode (OTEL_SERVICE_NAME wins)
+var serviceNames = map[string]string{
+	partPublic: "website-backend",
+	partCMS:    "website-cms",
+	partAll:    "website",
+}
+
+// runtime resources shared by the parts served in this process
+type runtime struct {
+	cfg       *config.Config
+	logger    *slog.Logger
+	logConfig middleware.LogConfig
+	pools     map[string]*gorm.DB // One pool per DSN: "all" with a single database shares it
+	cleanup   []func() error      // Background tasks to stop on shutdown (cache listener)
+}
+
+// newRuntime sets up the default logger for cfg
+func newRuntime(cfg *config.Config) *runtime {
+	logger, logConfig := newLogger()
+	return &runtime{cfg: cfg, logger: logger, logConfig: logConfig, pools: map[string]*gorm.DB{}}
+}
+
+func runServe(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	publicAddr := flags.String("public-addr", cfg.Server.BackendAddr(), "Dirección del sitio público (BACKEND_HOST y BACKEND_PORT)")
+	cmsAddr := flags.String("cms-addr", cfg.Server.CMSAddr(), "Dirección del CMS (CMS_HOST y CMS_PORT)")
+	if len(args) == 0 {
+		return errors.New("uso: serve public|cms|all [-public-addr host:puerto] [-cms-addr host:puerto]")
+	}
+	mode := args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	serviceName, ok := serviceNames[mode]
+	if !ok {
+		return fmt.Errorf("modo desconocido %q (use public, cms o all)", mode)
+	}
+
+	rt := newRuntime(cfg)
+
+	// Reopen log files on SIGHUP (external logrotate)
+	stopReopen := middleware.ReopenLogFilesOnSIGHUP()
+	defer stopReopen()
+
+	// Tracing: W3C propagation plus the configured exporter (OTEL_TRACES_EXPORTER)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.NewConfig(cfg.Tracing, serviceName))
+	if err != nil {
+		return fmt.Errorf("failed to configure tracing: %w", err)
+	}
+
+	servers, err := rt.servers(mode, *publicAddr, *cmsAddr)
+	if err != nil {
+		rt.close()
+		shutdownTracing(context.Background())
+		return err
+	}
+	if cfg.Assets.Dev {
+		slog.Warn("modo desarrollo: plantillas y assets desde el disco", "dir", cfg.Assets.Dir)
+	}
+
+	// Serve until SIGINT/SIGTERM, then drain requests and release resources
+	return server.ServeAll(servers, server.Options{
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		DrainDelay:      cfg.Server.DrainDelay,
+		Cleanup: append(rt.cleanup,
+			func() error {
+				middleware.StopRateLimiters()
+				return nil
+			},
+			rt.close,
+			func() error {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				return shutdownTracing(ctx)
+			},
+		),
+	})
+}
+
+// servers builds the apps of the mode. The CMS goes first because it applies the
+// pending migrations that the public site then checks.
+func (rt *runtime) servers(mode, publicAddr, cmsAddr string) ([]server.Server, error) {
+	var servers []server.Server
+	if mode == partCMS || mode == partAll {
+		srv, err := rt.cmsServer(cmsAddr)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, srv)
+	}
+	if mode == partPublic || mode == partAll {
+		srv, err := rt.publicServer(publicAddr)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, srv)
+	}
+	return servers, nil
+}
+
+// ========================================
+// DATABASE
+// ========================================
+
+// open connects to the database (once per DSN) with logging, tracing and metrics
+func (rt *runtime) open(settings config.DatabaseConfig) (*gorm.DB, *sql.DB, error) {
+	dsn := settings.DSN()
+	if db, ok := rt.pools[dsn]; ok {
+		sqlDB, err := db.DB()
+		return db, sqlDB, err
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: middleware.NewGormLogger(rt.logger, gormlogger.Info),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Get underlying sql.DB object to configure connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+	rt.pools[dsn] = db
+
+	// Configure connection pool
+	sqlDB.SetMaxIdleConns(10)
+	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
+	// Database metrics: query durations and pool stats
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to register metrics plugin: %w", err)
+	}
+	if err := metrics.RegisterDB(sqlDB, settings.Name); err != nil {
+		// Two different servers with a database of the same name: only the first one is reported
+		slog.Warn("pool stats not registered", "db_name", settings.Name, "error", err)
+	}
+	return db, sqlDB, nil
+}
+
+// migrator runner over the migrations embedded in the binary
+func migrator(sqlDB *sql.DB) (*migrate.Runner, error) {
+	embedded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrate.New(sqlDB, embedded), nil
+}
+
+// close releases the database pools
+func (rt *runtime) close() error {
+	var errs []error
+	for _, db := range rt.pools {
+		if sqlDB, err := db.DB(); err == nil {
+			errs = append(errs, sqlDB.Close())
+		}
+	}
+	rt.pools = map[string]*gorm.DB{}
+	return errors.Join(errs...)
+}
+
+// ========================================
+// HTTP
+// ========================================
+
+// newApp Fiber app with the middleware shared by both parts, in order:
+// request ID, metrics, health endpoints, tracing, security headers, CORS, logs and /metrics
+func (rt *runtime) newApp(fiberConfig fiber.Config, registry *health.Registry) *fiber.App {
+	fiberConfig.ErrorHandler = middleware.ErrorHandler
+	app := fiber.New(fiberConfig)
+
+	// Request ID middleware (debe ir primero)
+	app.Use(middleware.RequestID())
+
+	// Metrics middleware
+	app.Use(middleware.Metrics())
+
+	// Health endpoints (sin logs ni rate limiting)
+	health.Mount(app, registry)
+
+	// Tracing middleware (span por petición, antes del logger para incluir trace_id)
+	app.Use(middleware.Tracing())
+
+	// Security middleware
+	app.Use(middleware.SecurityHeadersMiddleware())
+
+	// CORS middleware
+	app.Use(cors.New(cors.Config{
+		AllowOrigins:  "*",
+		AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset",
+		ExposeHeaders: "X-Request-ID",
+	}))
+
+	// Logger middleware
+	app.Use(middleware.LogWithLogger(rt.logger, rt.logConfig))
+
+	// Prometheus metrics (antes del rate limiting y la cache)
+	app.Get("/metrics", middleware.MetricsHandler(middleware.DefaultMetricsConfig()))
+
+	return app
+}
+
+// listen returns the blocking serve function for addr, over TLS when enabled
+func (rt *runtime) listen(name string, app *fiber.App, addr string, registry *health.Registry) (func() error, error) {
+	if !rt.cfg.Server.EnableHTTPS {
+		slog.Info("HTTP server starting", "server", name, "addr", addr)
+		return func() error { return app.Listen(addr) }, nil
+	}
+
+	// Configure TLS
+	certFile := rt.cfg.Server.CertFile
+	keyFile := rt.cfg.Server.KeyFile
+	tlsConfig := middleware.TLSServerConfig()
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+	registry.Register("tls_certificate", health.TLSCertificateCheck(certFile, keyFile))
+
+	// Create custom listener with TLS
+	ln, err := tls.Listen("tcp", addr, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TLS listener: %w", err)
+	}
+
+	slog.Info("HTTPS server starting", "server", name, "addr", addr)
+	return func() error { return app.Listener(ln) }, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+	"website/backend/backup"
+)
+
+// backupResult summary of a backup file
+type backupResult struct {
+	File          string         `json:"file"`
+	Timestamp     time.Time      `json:"timestamp"`
+	SchemaVersion int64          `json:"schema_version"`
+	Rows          map[string]int `json:"rows"`
+}
+
+func runBackup(app *cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("uso: backup create|restore")
+	}
+
+	switch args[0] {
+	case "create":
+		return backupCreate(app, args[1:])
+	case "restore":
+		return backupRestore(app, args[1:])
+	default:
+		return fmt.Errorf("subcomando de backup desconocido %q", args[0])
+	}
+}
+
+func backupCreate(app *cli, args []string) error {
+	flags := flag.NewFlagSet("backup create", flag.ContinueOnError)
+	file := flags.String("file", "", "Archivo de destino (por defecto backup-<fecha>.json)")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	svc, err := app.services()
+	if err != nil {
+		return err
+	}
+	snapshot, err := svc.System.Backup(app.ctx)
+	if err != nil {
+		return err
+	}
+
+	path := *file
+	if path == "" {
+		path = "backup-" + snapshot.Timestamp.Format("20060102-150405") + ".json"
+	}
+	// 0600: the backup contains every site setting
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("error al crear %s: %w", path, err)
+	}
+	if err := backup.Write(out, snapshot); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return app.renderBackup(path, snapshot)
+}
+
+func backupRestore(app *cli, args []string) error {
+	flags := flag.NewFlagSet("backup restore", flag.ContinueOnError)
+	file := flags.String("file", "", "Archivo creado con backup create")
+	confirmed := flags.Bool("yes", false, "Confirmar que se sustituye todo el contenido actual")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("uso: backup restore -file backup.json -yes")
+	}
+	if !*confirmed {
+		return errors.New("restore sustituye todo el contenido actual; repita con -yes para confirmar")
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	snapshot, err := backup.Read(in)
+	if err != nil {
+		return err
+	}
+
+	db, err := app.db()
+	if err != nil {
+		return err
+	}
+	if err := backup.Restore(app.ctx, db, snapshot); err != nil {
+		return err
+	}
+	app.purgeCache()
+
+	return app.renderBackup(*file, snapshot)
+}
+
+// renderBackup prints the rows per table of a backup
+func (app *cli) renderBackup(path string, snapshot *backup.Backup) error {
+	counts := snapshot.Data.Counts()
+	tables := make([]string, 0, len(counts))
+	for table := range counts {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	rows := make([][]string, len(tables))
+	for i, table := range tables {
+		rows[i] = []string{path, strconv.FormatInt(snapshot.SchemaVersion, 10), table, strconv.Itoa(counts[table])}
+	}
+
+	result := backupResult{
+		File:          path,
+		Timestamp:     snapshot.Timestamp,
+		SchemaVersion: snapshot.SchemaVersion,
+		Rows:          counts,
+	}
+	return app.render(result, []string{"FILE", "SCHEMA", "TABLE", "ROWS"}, rows)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"website/backend/cache"
+)
+
+// cachePurgeResult output of cache purge
+type cachePurgeResult struct {
+	Channel string `json:"channel"`
+	Payload string `json:"payload"`
+}
+
+func runCache(app *cli, args []string) error {
+	if len(args) == 0 || args[0] != "purge" {
+		return errors.New("uso: cache purge [etiqueta...]")
+	}
+
+	// Without tags the whole cache is emptied
+	payload := cache.PurgeAll
+	if tags := cache.ParseTags(cache.JoinTags(args[1:])); len(tags) > 0 {
+		payload = cache.JoinTags(tags)
+	}
+
+	db, err := app.backendDB()
+	if err != nil {
+		return err
+	}
+	if err := cache.Publish(app.ctx, db, payload); err != nil {
+		return err
+	}
+
+	result := cachePurgeResult{Channel: cache.PurgeChannel, Payload: payload}
+	return app.render(result, []string{"CHANNEL", "PAYLOAD"}, [][]string{{result.Channel, result.Payload}})
+}
+
+// purgeCache empties the public response cache after writing content directly
+// through GORM (seed, restore); a failure only leaves stale pages until they expire
+func (app *cli) purgeCache() {
+	db, err := app.backendDB()
+	if err == nil {
+		err = cache.Publish(app.ctx, db, cache.PurgeAll)
+	}
+	if err != nil {
+		slog.Warn("no se pudo purgar la cache del sitio", "error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// configCheckResult output of config check
+type configCheckResult struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func runConfig(app *cli, args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return errors.New("uso: config check [-print] [-format yaml|env]")
+	}
+
+	flags := flag.NewFlagSet("config check", flag.ContinueOnError)
+	printConfig := flags.Bool("print", false, "Imprimir la configuración efectiva (secretos ocultos)")
+	format := flags.String("format", "yaml", "Formato de -print: yaml o env")
+	if _, err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	if *printConfig {
+		if err := app.cfg.Print(app.stdout, *format); err != nil {
+			return err
+		}
+	}
+
+	result := configCheckResult{Valid: app.cfgErr == nil}
+	if app.cfgErr != nil {
+		result.Errors = strings.Split(app.cfgErr.Error(), "\n")
+	}
+
+	if app.output == "json" {
+		if err := app.render(result, nil, nil); err != nil {
+			return err
+		}
+	} else if result.Valid {
+		fmt.Fprintln(app.stdout, "configuración válida")
+	} else {
+		rows := make([][]string, len(result.Errors))
+		for i, problem := range result.Errors {
+			rows[i] = []string{problem}
+		}
+		if err := app.render(result, []string{"ERROR"}, rows); err != nil {
+			return err
+		}
+	}
+
+	if !result.Valid {
+		return errors.New("configuración inválida")
+	}
+	return nil
+}
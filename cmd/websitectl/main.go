@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"website/backend/config"
+	"website/backend/middleware"
+	"website/backend/repository"
+	"website/backend/services"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const usage = `websitectl: tareas de administración del sitio
+
+uso: websitectl [-config archivo.yaml] [-o table|json] <comando> [argumentos]
+
+comandos:
+  user list
+  user create -username <u> -email <e> [-role viewer] [-password-stdin]
+  user disable <username>
+  user reset-password <username> [-password-stdin]
+  user set-role <username> <super_admin|admin|editor|viewer>
+  migrate up | down [n] | status
+  seed [-demo]
+  backup create [-file backup.json]
+  backup restore -file backup.json -yes
+  config check [-print] [-format yaml|env]
+  cache purge [etiqueta...]
+  token issue <username> [-ttl 1h]
+`
+
+// command runs a subcommand with the arguments that follow its name
+type command func(app *cli, args []string) error
+
+// commands top-level command groups
+var commands = map[string]command{
+	"user":    runUser,
+	"migrate": runMigrate,
+	"seed":    runSeed,
+	"backup":  runBackup,
+	"config":  runConfig,
+	"cache":   runCache,
+	"token":   runToken,
+}
+
+// cli shared state of a websitectl invocation
+type cli struct {
+	ctx     context.Context
+	cfg     *config.Config
+	cfgErr  error // Validation error, reported by "config check" and fatal elsewhere
+	output  string
+	stdin   io.Reader
+	stdout  io.Writer
+	cmsDB   *gorm.DB
+	backend *sql.DB
+}
+
+func main() {
+	flags := flag.NewFlagSet("websitectl", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configFile := flags.String("config", "", "Archivo YAML de configuración (por defecto CONFIG_FILE)")
+	output := flags.String("o", "table", "Formato de salida: table o json")
+	flags.Parse(os.Args[1:])
+
+	// Progress logs go to stderr so stdout stays parseable with -o json
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	app := &cli{
+		ctx:    context.Background(),
+		output: *output,
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+	}
+	err := app.run(*configFile, flags.Args())
+	app.close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the configuration and dispatches the command
+func (app *cli) run(configFile string, args []string) error {
+	if app.output != "table" && app.output != "json" {
+		return fmt.Errorf("formato de salida inválido %q (use table o json)", app.output)
+	}
+	if len(args) == 0 {
+		return errors.New("falta el comando\n" + usage)
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("comando desconocido %q\n%s", args[0], usage)
+	}
+
+	cfg, err := config.Load(config.LoadOptions{ConfigFile: configFile})
+	if err != nil {
+		return err
+	}
+	app.cfg = cfg
+	app.cfgErr = cfg.Validate()
+	if app.cfgErr != nil && args[0] != "config" {
+		return fmt.Errorf("configuración inválida (ver \"websitectl config check\"):\n%w", app.cfgErr)
+	}
+	middleware.Configure(cfg)
+
+	return run(app, args[1:])
+}
+
+// ========================================
+// DATABASE
+// ========================================
+
+// db opens the CMS database, which owns users and content
+func (app *cli) db() (*gorm.DB, error) {
+	if app.cmsDB != nil {
+		return app.cmsDB, nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, err := gorm.Open(postgres.Open(app.cfg.CMSDatabase.DSN()), &gorm.Config{
+		Logger: middleware.NewGormLogger(logger, gormlogger.Warn),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar con la base de datos: %w", err)
+	}
+	app.cmsDB = db
+	return db, nil
+}
+
+// sqlDB pool underlying the CMS connection
+func (app *cli) sqlDB() (*sql.DB, error) {
+	db, err := app.db()
+	if err != nil {
+		return nil, err
+	}
+	return db.DB()
+}
+
+// backendDB opens the public backend database (cache notifications are per database)
+func (app *cli) backendDB() (*sql.DB, error) {
+	if app.backend != nil {
+		return app.backend, nil
+	}
+
+	db, err := gorm.Open(postgres.Open(app.cfg.Database.DSN()), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar con la base de datos: %w", err)
+	}
+	app.backend, err = db.DB()
+	return app.backend, err
+}
+
+// close releases the open connections
+func (app *cli) close() {
+	if app.cmsDB != nil {
+		if sqlDB, err := app.cmsDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+	if app.backend != nil {
+		app.backend.Close()
+	}
+}
+
+// services business rules over the CMS database
+func (app *cli) services() (*services.Services, error) {
+	db, err := app.db()
+	if err != nil {
+		return nil, err
+	}
+	return services.New(repository.NewGormStore(db)), nil
+}
+
+// actor identifies websitectl changes in the audit log
+func (app *cli) actor() services.Actor {
+	name := "websitectl"
+	if current, err := user.Current(); err == nil {
+		name += ":" + current.Username
+	}
+	return services.Actor{Username: name, Role: "cli"}
+}
+
+// ========================================
+// OUTPUT
+// ========================================
+
+// render writes value as JSON or the rows as an aligned table
+func (app *cli) render(value interface{}, headers []string, rows [][]string) error {
+	if app.output == "json" {
+		encoder := json.NewEncoder(app.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	table := tabwriter.NewWriter(app.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	return table.Flush()
+}
+
+// parseFlags parses subcommand flags; positional arguments may come before or after them
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", flags.Name(), err)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
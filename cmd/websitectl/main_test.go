@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestConfigCheckJSON(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("BACKEND_PORT", "0")
+
+	var out bytes.Buffer
+	app := &cli{ctx: context.Background(), output: "json", stdout: &out}
+	if err := app.run("", []string{"config", "check"}); err == nil {
+		t.Fatal("se esperaba error por configuración inválida")
+	}
+
+	var result configCheckResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("salida no es JSON: %v\n%s", err, out.String())
+	}
+	if result.Valid || len(result.Errors) != 2 {
+		t.Errorf("resultado = %+v", result)
+	}
+}
+
+func TestCommandsRequireValidConfig(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+
+	app := &cli{ctx: context.Background(), output: "table", stdout: &bytes.Buffer{}}
+	if err := app.run("", []string{"user", "list"}); err == nil {
+		t.Error("se esperaba error antes de conectar con la base de datos")
+	}
+}
+
+func TestParseFlagsInterleaved(t *testing.T) {
+	flags := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	ttl := flags.String("ttl", "", "")
+	positional, err := parseFlags(flags, []string{"admin", "-ttl", "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *ttl != "1h" || !reflect.DeepEqual(positional, []string{"admin"}) {
+		t.Errorf("ttl = %q, positional = %v", *ttl, positional)
+	}
+}
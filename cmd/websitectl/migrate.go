@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"website/backend/migrate"
+	"website/cms/migrations"
+)
+
+func runMigrate(app *cli, args []string) error {
+	sqlDB, err := app.sqlDB()
+	if err != nil {
+		return err
+	}
+	embedded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+	runner := migrate.New(sqlDB, embedded)
+
+	// Table output reuses the subcommands shared with "website migrate"
+	if app.output == "table" || len(args) == 0 {
+		return migrate.Run(app.ctx, runner, args, app.stdout)
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := runner.Status(app.ctx)
+		if err != nil {
+			return err
+		}
+		return app.render(statuses, nil, nil)
+	case "up":
+		applied, err := runner.Up(app.ctx)
+		if err != nil {
+			return err
+		}
+		return app.render(migrationNames(applied), nil, nil)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("número de migraciones inválido: %q", args[1])
+			}
+		}
+		reverted, err := runner.Down(app.ctx, steps)
+		if err != nil {
+			return err
+		}
+		return app.render(migrationNames(reverted), nil, nil)
+	default:
+		return fmt.Errorf("comando desconocido %q\n%s", args[0], migrate.Usage)
+	}
+}
+
+// migrationNames versions and names without the SQL scripts
+func migrationNames(list []migrate.Migration) []migrate.MigrationStatus {
+	result := make([]migrate.MigrationStatus, len(list))
+	for i, migration := range list {
+		result[i] = migrate.MigrationStatus{Version: migration.Version, Name: migration.Name}
+	}
+	return result
+}
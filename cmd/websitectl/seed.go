@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"website/backend/models"
+	"website/backend/money"
+
+	"gorm.io/gorm"
+)
+
+// defaultSiteConfigs site settings every installation needs
+var defaultSiteConfigs = []models.SiteConfig{
+	{Key: "site_name", Value: "Mi Sitio Web"},
+	{Key: "site_description", Value: "Descripción del sitio web"},
+	{Key: "home_videos", Value: ""},
+	{Key: "locations", Value: ""},
+	{Key: "contact_email", Value: "contacto@example.com"},
+	{Key: "contact_phone", Value: "+1234567890"},
+	{Key: "hide_out_of_stock", Value: "false"},
+}
+
+// demoCategories sample catalog for local development (-demo)
+var demoCategories = []models.Category{
+	{
+		Name: "Fotografía", Slug: "fotografia", SlugPath: "fotografia", Description: "Sesiones y reportajes fotográficos", Order: 1, Active: true,
+		Products: []models.Product{
+			{Name: "Sesión de retrato", Slug: "sesion-retrato", Description: "Sesión de una hora en estudio", Price: money.Units(120), Features: "1 hora,20 fotos editadas", Active: true},
+			{Name: "Reportaje de boda", Slug: "reportaje-boda", Description: "Cobertura completa del evento", Price: money.Units(950), Features: "8 horas,álbum impreso", Active: true},
+		},
+	},
+	{
+		Name: "Vídeo", Slug: "video", SlugPath: "video", Description: "Producción audiovisual", Order: 2, Active: true,
+		Products: []models.Product{
+			{Name: "Vídeo corporativo", Slug: "video-corporativo", Description: "Vídeo de presentación de empresa", Price: money.Units(700), Features: "Guion,edición,música", Active: true},
+		},
+	},
+}
+
+// demoSlides sample home slides (-demo)
+var demoSlides = []models.Slide{
+	{ImageURL: "https://picsum.photos/seed/slide1/1600/600", Title: "Bienvenido", Subtitle: "Contenido de ejemplo", Order: 1, Active: true},
+	{ImageURL: "https://picsum.photos/seed/slide2/1600/600", Title: "Nuestros servicios", Subtitle: "Fotografía y vídeo", Order: 2, Active: true},
+}
+
+// demoCollections sample smart collection of the products added in the last 30 days (-demo)
+var demoCollections = []models.Collection{
+	{Name: "Novedades", Slug: "novedades", Description: "Lo último del catálogo", Type: models.CollectionSmart, Rules: &models.CollectionRules{NewerThanDays: 30}, Order: 1, Active: true},
+}
+
+// demoContacts sample contact channels (-demo)
+var demoContacts = []models.ContactInfo{
+	{Type: "email", Value: "contacto@example.com", Icon: "email", Order: 1, Active: true},
+	{Type: "whatsapp", Value: "+1234567890", Icon: "whatsapp", Order: 2, Active: true},
+}
+
+// seedResult rows created per table (existing rows are left untouched)
+type seedResult struct {
+	Table   string `json:"table"`
+	Created int    `json:"created"`
+}
+
+func runSeed(app *cli, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	demo := flags.Bool("demo", false, "Crear también contenido de ejemplo")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	db, err := app.db()
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range defaultSiteConfigs {
+			if err := firstOrCreate(tx, counts, "site_configs", &item, "key = ?", item.Key); err != nil {
+				return err
+			}
+		}
+		if !*demo {
+			return nil
+		}
+
+		for _, category := range demoCategories {
+			products := category.Products
+			category.Products = nil
+			if err := firstOrCreate(tx, counts, "categories", &category, "slug = ?", category.Slug); err != nil {
+				return err
+			}
+			// Demo categories are roots: their path is just their own id
+			if category.Path == "" {
+				if err := tx.Model(&category).Update("path", "/"+strconv.FormatUint(uint64(category.ID), 10)+"/").Error; err != nil {
+					return err
+				}
+			}
+			for _, product := range products {
+				product.CategoryID = category.ID
+				if err := firstOrCreate(tx, counts, "products", &product, "slug = ?", product.Slug); err != nil {
+					return err
+				}
+			}
+		}
+		for _, collection := range demoCollections {
+			if err := firstOrCreate(tx, counts, "collections", &collection, "slug = ?", collection.Slug); err != nil {
+				return err
+			}
+		}
+		for _, slide := range demoSlides {
+			if err := firstOrCreate(tx, counts, "slides", &slide, "title = ?", slide.Title); err != nil {
+				return err
+			}
+		}
+		for _, contact := range demoContacts {
+			if err := firstOrCreate(tx, counts, "contact_infos", &contact, "type = ? AND value = ?", contact.Type, contact.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	app.purgeCache()
+
+	tables := []string{"site_configs"}
+	if *demo {
+		tables = append(tables, "categories", "products", "collections", "slides", "contact_infos")
+	}
+	results := make([]seedResult, len(tables))
+	rows := make([][]string, len(tables))
+	for i, table := range tables {
+		results[i] = seedResult{Table: table, Created: counts[table]}
+		rows[i] = []string{table, strconv.Itoa(counts[table])}
+	}
+	return app.render(results, []string{"TABLE", "CREATED"}, rows)
+}
+
+// firstOrCreate inserts record unless a row matches the query (then record is loaded
+// from it), counting insertions per table
+func firstOrCreate(tx *gorm.DB, counts map[string]int, table string, record interface{}, query string, args ...interface{}) error {
+	var existing int64
+	if err := tx.Model(record).Where(query, args...).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return tx.Where(query, args...).First(record).Error
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return err
+	}
+	counts[table]++
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+	"website/backend/middleware"
+	"website/backend/repository"
+)
+
+// tokenResult output of token issue
+type tokenResult struct {
+	Token     string    `json:"token"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func runToken(app *cli, args []string) error {
+	if len(args) == 0 || args[0] != "issue" {
+		return errors.New("uso: token issue <username> [-ttl 1h]")
+	}
+
+	flags := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	ttl := flags.Duration("ttl", app.cfg.JWT.Expiration, "Validez del token")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("uso: token issue <username> [-ttl 1h]")
+	}
+	if *ttl <= 0 {
+		return errors.New("-ttl debe ser positivo")
+	}
+
+	svc, err := app.services()
+	if err != nil {
+		return err
+	}
+	user, err := svc.Users.FindByLogin(app.ctx, positional[0])
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("usuario %q no encontrado", positional[0])
+	}
+	if err != nil {
+		return err
+	}
+	if !user.Active {
+		return fmt.Errorf("el usuario %q está desactivado", user.Username)
+	}
+
+	// GenerateToken reads the expiration from the configuration applied with Configure
+	app.cfg.JWT.Expiration = *ttl
+	issuedAt := time.Now()
+	token, err := middleware.GenerateToken(*user)
+	if err != nil {
+		return err
+	}
+
+	result := tokenResult{
+		Token:     token,
+		Username:  user.Username,
+		Role:      user.Role,
+		ExpiresAt: issuedAt.Add(*ttl).UTC().Truncate(time.Second),
+	}
+	return app.render(result,
+		[]string{"USERNAME", "ROLE", "EXPIRES AT", "TOKEN"},
+		[][]string{{result.Username, result.Role, result.ExpiresAt.Format(time.RFC3339), result.Token}},
+	)
+}
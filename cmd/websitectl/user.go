@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/services"
+)
+
+// roles valid values of users.role
+var roles = []string{"super_admin", "admin", "editor", "viewer"}
+
+// generatedPasswordLength length of the passwords created when none is given
+const generatedPasswordLength = 20
+
+// userResult output of the user commands
+type userResult struct {
+	User     models.User `json:"user"`
+	Password string      `json:"password,omitempty"` // Only when websitectl generated it
+}
+
+func runUser(app *cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("uso: user list|create|disable|reset-password|set-role")
+	}
+
+	switch args[0] {
+	case "list":
+		return userList(app)
+	case "create":
+		return userCreate(app, args[1:])
+	case "disable":
+		return userDisable(app, args[1:])
+	case "reset-password":
+		return userResetPassword(app, args[1:])
+	case "set-role":
+		return userSetRole(app, args[1:])
+	default:
+		return fmt.Errorf("subcomando de user desconocido %q", args[0])
+	}
+}
+
+func userList(app *cli) error {
+	svc, err := app.services()
+	if err != nil {
+		return err
+	}
+	users, err := svc.Users.List(app.ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(users))
+	for i, u := range users {
+		rows[i] = []string{strconv.Itoa(int(u.ID)), u.Username, u.Email, u.Role, strconv.FormatBool(u.Active)}
+	}
+	return app.render(users, []string{"ID", "USERNAME", "EMAIL", "ROLE", "ACTIVE"}, rows)
+}
+
+func userCreate(app *cli, args []string) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	username := flags.String("username", "", "Nombre de usuario")
+	email := flags.String("email", "", "Email")
+	role := flags.String("role", "viewer", "Rol: "+strings.Join(roles, ", "))
+	passwordStdin := flags.Bool("password-stdin", false, "Leer la contraseña de la entrada estándar")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	password, generated, err := app.password(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	svc, err := app.services()
+	if err != nil {
+		return err
+	}
+	input := services.UserInput{
+		User: models.User{
+			Username: *username,
+			Email:    *email,
+			Role:     *role,
+			Active:   true,
+		},
+		Password: password,
+	}
+	user, err := svc.Users.Create(app.ctx, app.actor(), &input)
+	if err != nil {
+		return fmt.Errorf("error al crear el usuario: %w", err)
+	}
+
+	result := userResult{User: *user}
+	if generated {
+		result.Password = password
+	}
+	return app.renderUser(result)
+}
+
+func userDisable(app *cli, args []string) error {
+	positional, err := parseFlags(flag.NewFlagSet("user disable", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("uso: user disable <username>")
+	}
+
+	user, err := app.updateUser(positional[0], func(user *models.User) {
+		user.Active = false
+	})
+	if err != nil {
+		return err
+	}
+	return app.renderUser(userResult{User: *user})
+}
+
+func userResetPassword(app *cli, args []string) error {
+	flags := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	passwordStdin := flags.Bool("password-stdin", false, "Leer la contraseña de la entrada estándar")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("uso: user reset-password <username> [-password-stdin]")
+	}
+
+	password, generated, err := app.password(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	svc, err := app.services()
+	if err != nil {
+		return err
+	}
+	user, err := svc.Users.ResetPassword(app.ctx, app.actor(), positional[0], password)
+	if err != nil {
+		return userError(positional[0], err)
+	}
+
+	result := userResult{User: *user}
+	if generated {
+		result.Password = password
+	}
+	return app.renderUser(result)
+}
+
+func userSetRole(app *cli, args []string) error {
+	positional, err := parseFlags(flag.NewFlagSet("user set-role", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("uso: user set-role <username> <rol>")
+	}
+	role := positional[1]
+	if !validRole(role) {
+		return fmt.Errorf("rol inválido %q (use %s)", role, strings.Join(roles, ", "))
+	}
+
+	user, err := app.updateUser(positional[0], func(user *models.User) {
+		user.Role = role
+	})
+	if err != nil {
+		return err
+	}
+	return app.renderUser(userResult{User: *user})
+}
+
+// updateUser loads a user by username or email, applies change and audits it in one transaction
+func (app *cli) updateUser(username string, change func(user *models.User)) (*models.User, error) {
+	svc, err := app.services()
+	if err != nil {
+		return nil, err
+	}
+	user, err := svc.Users.Modify(app.ctx, app.actor(), username, change)
+	if err != nil {
+		return nil, userError(username, err)
+	}
+	return user, nil
+}
+
+// userError describes a failed change of a user
+func userError(username string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("usuario %q no encontrado", username)
+	}
+	return fmt.Errorf("error al actualizar el usuario: %w", err)
+}
+
+// renderUser prints a user and, if it was generated, its new password
+func (app *cli) renderUser(result userResult) error {
+	user := result.User
+	rows := [][]string{{strconv.Itoa(int(user.ID)), user.Username, user.Email, user.Role, strconv.FormatBool(user.Active)}}
+	headers := []string{"ID", "USERNAME", "EMAIL", "ROLE", "ACTIVE"}
+	if result.Password != "" {
+		headers = append(headers, "PASSWORD")
+		rows[0] = append(rows[0], result.Password)
+	}
+	return app.render(result, headers, rows)
+}
+
+// password reads the password from stdin or generates a random one
+// Passwords are never taken from flags so they do not end up in the shell history
+func (app *cli) password(fromStdin bool) (password string, generated bool, err error) {
+	if fromStdin {
+		line, err := bufio.NewReader(app.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", false, errors.New("no se pudo leer la contraseña de la entrada estándar")
+		}
+		return strings.TrimRight(line, "\r\n"), false, nil
+	}
+
+	const alphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	buf := make([]byte, generatedPasswordLength)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", false, err
+		}
+		buf[i] = alphabet[n.Int64()]
+	}
+	return string(buf), true, nil
+}
+
+// validRole checks a role against the allowed values
+func validRole(role string) bool {
+	for _, candidate := range roles {
+		if role == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"encoding/csv"
+	"strconv"
+	"time"
+	"website/backend/models"
+	"website/backend/repository"
+	"website/backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Audit Handlers
+func getAuditEvents(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var filter repository.AuditFilter
+
+		if userID := c.Query("user_id"); userID != "" {
+			id, err := strconv.ParseUint(userID, 10, 32)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "user_id inválido")
+			}
+			uid := uint(id)
+			filter.UserID = &uid
+		}
+		filter.Username = c.Query("username")
+		filter.EntityType = c.Query("entity_type")
+		filter.EntityID = c.Query("entity_id")
+		filter.Action = c.Query("action")
+		if from := c.Query("from"); from != "" {
+			t, err := parseAuditDate(from, false)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "from inválido, use YYYY-MM-DD o RFC3339")
+			}
+			filter.From = t
+		}
+		if to := c.Query("to"); to != "" {
+			t, err := parseAuditDate(to, true)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "to inválido, use YYYY-MM-DD o RFC3339")
+			}
+			filter.To = t
+		}
+		// The service applies the default (100) and maximum (1000) limits
+		filter.Limit = c.QueryInt("limit", 0)
+
+		events, err := svc.Audit.List(c.UserContext(), filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener auditoría")
+		}
+
+		if c.Query("format") == "csv" {
+			return writeAuditCSV(c, events)
+		}
+		return c.JSON(events)
+	}
+}
+
+// parseAuditDate accepts dates (YYYY-MM-DD) or RFC3339 timestamps.
+// For inclusive upper bounds a plain date is moved to the next day.
+func parseAuditDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// writeAuditCSV streams the events as a CSV attachment
+func writeAuditCSV(c *fiber.Ctx, events []models.AuditEvent) error {
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="audit_events.csv"`)
+
+	writer := csv.NewWriter(c.Response().BodyWriter())
+	writer.Write([]string{"id", "created_at", "user_id", "username", "role", "ip", "request_id", "action", "entity_type", "entity_id", "diff"})
+
+	for _, event := range events {
+		userID := ""
+		if event.UserID != nil {
+			userID = strconv.FormatUint(uint64(*event.UserID), 10)
+		}
+		writer.Write([]string{
+			strconv.FormatUint(uint64(event.ID), 10),
+			event.CreatedAt.UTC().Format(time.RFC3339),
+			userID,
+			event.Username,
+			event.Role,
+			event.IP,
+			event.RequestID,
+			event.Action,
+			event.EntityType,
+			event.EntityID,
+			string(event.Diff),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -260,11 +260,12 @@ class CMSAdmin {
     
     async loadDashboard() {
         try {
+            // Only the totals are needed: one row per listing
             const [slides, categories, products, contacts] = await Promise.all([
-                this.apiCall('/admin/slides'),
-                this.apiCall('/admin/categories'),
-                this.apiCall('/admin/products'),
-                this.apiCall('/admin/contacts')
+                this.apiCall('/admin/slides?limit=1'),
+                this.apiCall('/admin/categories?limit=1'),
+                this.apiCall('/admin/products?limit=1'),
+                this.apiCall('/admin/contacts?limit=1')
             ]);
             
             const dashboardContent = document.getElementById('dashboardContent');
@@ -274,7 +275,7 @@ class CMSAdmin {
                         <div class="card text-white bg-primary">
                             <div class="card-body">
                                 <h5 class="card-title">Slides</h5>
-                                <p class="card-text display-6">${slides.length}</p>
+                                <p class="card-text display-6">${slides.meta.total}</p>
                             </div>
                         </div>
                     </div>
@@ -282,7 +283,7 @@ class CMSAdmin {
                         <div class="card text-white bg-success">
                             <div class="card-body">
                                 <h5 class="card-title">Categorías</h5>
-                                <p class="card-text display-6">${categories.length}</p>
+                                <p class="card-text display-6">${categories.meta.total}</p>
                             </div>
                         </div>
                     </div>
@@ -290,7 +291,7 @@ class CMSAdmin {
                         <div class="card text-white bg-warning">
                             <div class="card-body">
                                 <h5 class="card-title">Productos</h5>
-                                <p class="card-text display-6">${products.length}</p>
+                                <p class="card-text display-6">${products.meta.total}</p>
                             </div>
                         </div>
                     </div>
@@ -298,7 +299,7 @@ class CMSAdmin {
                         <div class="card text-white bg-info">
                             <div class="card-body">
                                 <h5 class="card-title">Contactos</h5>
-                                <p class="card-text display-6">${contacts.length}</p>
+                                <p class="card-text display-6">${contacts.meta.total}</p>
                             </div>
                         </div>
                     </div>
@@ -311,7 +312,7 @@ class CMSAdmin {
     
     async loadSlides() {
         try {
-            const slides = await this.apiCall('/admin/slides');
+            const slides = await this.apiList('/admin/slides');
             this.renderSlidesTable(slides);
         } catch (error) {
             this.showNotification('Error al cargar slides', 'error');
@@ -486,7 +487,7 @@ class CMSAdmin {
     // Similar methods for other entities...
     async loadCategories() {
         try {
-            const categories = await this.apiCall('/admin/categories');
+            const categories = await this.apiList('/admin/categories');
             this.renderCategoriesTable(categories);
         } catch (error) {
             this.showNotification('Error al cargar categorías', 'error');
@@ -495,7 +496,7 @@ class CMSAdmin {
     
     async loadProducts() {
         try {
-            const products = await this.apiCall('/admin/products');
+            const products = await this.apiList('/admin/products');
             this.renderProductsTable(products);
         } catch (error) {
             this.showNotification('Error al cargar productos', 'error');
@@ -504,7 +505,7 @@ class CMSAdmin {
     
     async loadContacts() {
         try {
-            const contacts = await this.apiCall('/admin/contacts');
+            const contacts = await this.apiList('/admin/contacts');
             this.renderContactsTable(contacts);
         } catch (error) {
             this.showNotification('Error al cargar contactos', 'error');
@@ -513,7 +514,7 @@ class CMSAdmin {
     
     async loadUsers() {
         try {
-            const users = await this.apiCall('/admin/users');
+            const users = await this.apiList('/admin/users');
             this.renderUsersTable(users);
         } catch (error) {
             this.showNotification('Error al cargar usuarios', 'error');
@@ -529,6 +530,21 @@ class CMSAdmin {
         }
     }
     
+    // Listings are paginated: follow links.next and return every row
+    async apiList(endpoint) {
+        const items = [];
+        let url = `${endpoint}?limit=100`;
+        while (url) {
+            const page = await this.apiCall(url);
+            if (!page) {
+                return items;
+            }
+            items.push(...page.data);
+            url = page.links.next;
+        }
+        return items;
+    }
+    
     // API helper
     async apiCall(endpoint, method = 'GET', data = null) {
         const options = {
@@ -1,68 +1,167 @@
 package admin
 
 import (
+	"context"
+	"errors"
 	"strconv"
 	"website/backend/middleware"
 	"website/backend/models"
+	"website/backend/pagination"
+	"website/backend/repository"
+	"website/backend/services"
 	"website/backend/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-func SetupRoutes(app *fiber.App, db *gorm.DB) {
+func SetupRoutes(app *fiber.App, svc *services.Services) {
 	admin := app.Group("/admin")
 
 	// Auth routes with strict rate limiting
 	authGroup := admin.Group("/auth")
 	authGroup.Use(middleware.RateLimitAuth())
-	authGroup.Post("/login", loginHandler(db))
-	authGroup.Post("/register", registerHandler(db))
+	authGroup.Post("/login", loginHandler(svc))
+	authGroup.Post("/register", registerHandler(svc))
 
 	// Protected routes
-	protected := admin.Use(middleware.AuthMiddleware(db))
+	protected := admin.Use(middleware.AuthMiddleware(svc.Users))
 
 	// Site Config - Solo admin y super_admin
-	protected.Get("/config", middleware.IsEditor(), getSiteConfig(db))
-	protected.Put("/config", middleware.IsAdmin(), updateSiteConfig(db))
+	protected.Get("/config", middleware.IsEditor(), getSiteConfig(svc))
+	protected.Put("/config", middleware.IsAdmin(), updateSiteConfig(svc))
 
 	// Slides Management - Editor y superior
-	protected.Get("/slides", middleware.IsEditor(), getSlides(db))
-	protected.Post("/slides", middleware.IsEditor(), createSlide(db))
-	protected.Put("/slides/:id", middleware.IsEditor(), updateSlide(db))
-	protected.Delete("/slides/:id", middleware.IsAdmin(), deleteSlide(db))
+	protected.Get("/slides", middleware.IsEditor(), getSlides(svc))
+	protected.Post("/slides", middleware.IsEditor(), createEntity[models.Slide](svc.Slides, slideMessages))
+	protected.Put("/slides/:id", middleware.IsEditor(), updateEntity[models.Slide](svc.Slides, slideMessages))
+	protected.Delete("/slides/:id", middleware.IsAdmin(), deleteEntity[models.Slide](svc.Slides, slideMessages))
 
 	// Categories Management - Editor y superior
-	protected.Get("/categories", middleware.IsEditor(), getCategories(db))
-	protected.Post("/categories", middleware.IsEditor(), createCategory(db))
-	protected.Put("/categories/:id", middleware.IsEditor(), updateCategory(db))
-	protected.Delete("/categories/:id", middleware.IsAdmin(), deleteCategory(db))
+	protected.Get("/categories", middleware.IsEditor(), getCategories(svc))
+	protected.Get("/categories/tree", middleware.IsEditor(), getCategoryTree(svc))
+	protected.Post("/categories", middleware.IsEditor(), createEntity[models.Category](svc.Categories, categoryMessages))
+	protected.Put("/categories/:id", middleware.IsEditor(), updateEntity[models.Category](svc.Categories, categoryMessages))
+	protected.Delete("/categories/:id", middleware.IsAdmin(), deleteEntity[models.Category](svc.Categories, categoryMessages))
 
 	// Products Management - Editor y superior
-	protected.Get("/products", middleware.IsEditor(), getProducts(db))
-	protected.Post("/products", middleware.IsEditor(), createProduct(db))
-	protected.Put("/products/:id", middleware.IsEditor(), updateProduct(db))
-	protected.Delete("/products/:id", middleware.IsAdmin(), deleteProduct(db))
+	protected.Get("/products", middleware.IsEditor(), getProducts(svc))
+	protected.Post("/products", middleware.IsEditor(), createEntity[models.Product](svc.Products, productMessages))
+	protected.Put("/products/:id", middleware.IsEditor(), updateEntity[models.Product](svc.Products, productMessages))
+	protected.Delete("/products/:id", middleware.IsAdmin(), deleteEntity[models.Product](svc.Products, productMessages))
+
+	// Product Variants (nested under their product) - Editor y superior
+	protected.Get("/products/:id/variants", middleware.IsEditor(), getVariants(svc))
+	protected.Post("/products/:id/variants", middleware.IsEditor(), createVariant(svc))
+	protected.Put("/products/:id/variants/:variant", middleware.IsEditor(), updateVariant(svc))
+	protected.Delete("/products/:id/variants/:variant", middleware.IsAdmin(), deleteVariant(svc))
+
+	// Per-currency price overrides of a product and its variants - Editor y superior
+	protected.Get("/products/:id/prices", middleware.IsEditor(), getPrices(svc))
+	protected.Put("/products/:id/prices", middleware.IsEditor(), setPrices(svc))
+
+	// Related products, accessories, alternatives and upgrades - Editor y superior
+	protected.Get("/products/:id/relations", middleware.IsEditor(), getRelations(svc))
+	protected.Put("/products/:id/relations", middleware.IsEditor(), setRelations(svc))
+
+	// Stock adjustments and their ledger - Editor y superior
+	protected.Get("/products/:id/stock", middleware.IsEditor(), getStockMovements(svc))
+	protected.Post("/products/:id/stock", middleware.IsEditor(), adjustStock(svc))
+	protected.Post("/products/:id/variants/:variant/stock", middleware.IsEditor(), adjustVariantStock(svc))
+
+	// Product Tags - Editor y superior (products also create them by name)
+	protected.Get("/tags", middleware.IsEditor(), getTags(svc))
+	protected.Post("/tags", middleware.IsEditor(), createEntity[models.Tag](svc.Tags, tagMessages))
+	protected.Put("/tags/:id", middleware.IsEditor(), updateEntity[models.Tag](svc.Tags, tagMessages))
+	protected.Delete("/tags/:id", middleware.IsAdmin(), deleteEntity[models.Tag](svc.Tags, tagMessages))
+
+	// Collections (manual or rule-based) and the products of manual ones - Editor y superior
+	protected.Get("/collections", middleware.IsEditor(), getCollections(svc))
+	protected.Post("/collections", middleware.IsEditor(), createEntity[models.Collection](svc.Collections, collectionMessages))
+	protected.Put("/collections/:id", middleware.IsEditor(), updateEntity[models.Collection](svc.Collections, collectionMessages))
+	protected.Delete("/collections/:id", middleware.IsAdmin(), deleteEntity[models.Collection](svc.Collections, collectionMessages))
+	protected.Get("/collections/:id/products", middleware.IsEditor(), getCollectionProducts(svc))
+	protected.Put("/collections/:id/products", middleware.IsEditor(), setCollectionProducts(svc))
+
+	// Currencies and exchange rates - Editor y superior
+	protected.Get("/currencies", middleware.IsEditor(), getCurrencies(svc))
+	protected.Post("/currencies", middleware.IsEditor(), createEntity[models.Currency](svc.Currencies, currencyMessages))
+	protected.Put("/currencies/:id", middleware.IsEditor(), updateEntity[models.Currency](svc.Currencies, currencyMessages))
+	protected.Delete("/currencies/:id", middleware.IsAdmin(), deleteEntity[models.Currency](svc.Currencies, currencyMessages))
+
+	// Product Attributes (definitions per category) - Editor y superior
+	protected.Get("/categories/:id/attributes", middleware.IsEditor(), getAttributes(svc))
+	protected.Post("/categories/:id/attributes", middleware.IsEditor(), createAttribute(svc))
+	protected.Put("/attributes/:id", middleware.IsEditor(), updateEntity[models.AttributeDefinition](svc.Attributes, attributeMessages))
+	protected.Delete("/attributes/:id", middleware.IsAdmin(), deleteEntity[models.AttributeDefinition](svc.Attributes, attributeMessages))
 
 	// Contact Info - Editor y superior
-	protected.Get("/contacts", middleware.IsEditor(), getContacts(db))
-	protected.Post("/contacts", middleware.IsEditor(), createContact(db))
-	protected.Put("/contacts/:id", middleware.IsEditor(), updateContact(db))
-	protected.Delete("/contacts/:id", middleware.IsAdmin(), deleteContact(db))
+	protected.Get("/contacts", middleware.IsEditor(), getContacts(svc))
+	protected.Post("/contacts", middleware.IsEditor(), createEntity[models.ContactInfo](svc.Contacts, contactMessages))
+	protected.Put("/contacts/:id", middleware.IsEditor(), updateEntity[models.ContactInfo](svc.Contacts, contactMessages))
+	protected.Delete("/contacts/:id", middleware.IsAdmin(), deleteEntity[models.ContactInfo](svc.Contacts, contactMessages))
 
 	// Users Management - Solo admin y super_admin
-	protected.Get("/users", middleware.IsAdmin(), getUsers(db))
-	protected.Post("/users", middleware.IsAdmin(), createUser(db))
-	protected.Put("/users/:id", middleware.IsAdmin(), updateUser(db))
-	protected.Delete("/users/:id", middleware.IsSuperAdmin(), deleteUser(db))
+	protected.Get("/users", middleware.IsAdmin(), getUsers(svc))
+	protected.Post("/users", middleware.IsAdmin(), createUser(svc))
+	protected.Put("/users/:id", middleware.IsAdmin(), updateUser(svc))
+	protected.Delete("/users/:id", middleware.IsSuperAdmin(), deleteUser(svc))
 
 	// System Management - Solo super_admin
-	protected.Get("/system/status", middleware.IsSuperAdmin(), getSystemStatus(db))
-	protected.Post("/system/backup", middleware.IsSuperAdmin(), createBackup(db))
+	protected.Get("/system/status", middleware.IsSuperAdmin(), getSystemStatus(svc))
+	protected.Post("/system/backup", middleware.IsSuperAdmin(), createBackup(svc))
+
+	// Audit Log - Solo super_admin
+	protected.Get("/audit", middleware.IsSuperAdmin(), getAuditEvents(svc))
+}
+
+// actor identifies the current user and request in the audit log
+func actor(c *fiber.Ctx) services.Actor {
+	actor := services.Actor{
+		IP:        c.IP(),
+		RequestID: middleware.GetRequestIDFromContext(c),
+	}
+	if user := middleware.GetCurrentUser(c); user != nil {
+		actor.UserID = &user.ID
+		actor.Username = user.Username
+		actor.Role = user.Role
+	}
+	return actor
+}
+
+// parseID reads the :id route parameter
+func parseID(c *fiber.Ctx) (uint, error) {
+	return parseParam(c, "id")
+}
+
+// parseParam reads a numeric route parameter
+func parseParam(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(name), 10, 32)
+	if err != nil || id == 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+	return uint(id), nil
+}
+
+// respondError maps service errors to HTTP responses.
+// Validation and HTTP errors are returned as is; every error is answered by the error
+// handler, which adds the request ID.
+func respondError(err error, notFound, failure string) error {
+	var validationErr *utils.ValidationError
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &validationErr), errors.As(err, &fiberErr):
+		return err
+	case errors.Is(err, repository.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, notFound)
+	case errors.Is(err, repository.ErrDuplicate):
+		return fiber.NewError(fiber.StatusConflict, "Ya existe un registro con ese valor (slug, usuario, email o clave)")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, failure)
+	}
 }
 
 // Auth Handlers
-func loginHandler(db *gorm.DB) fiber.Handler {
+func loginHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var loginData struct {
 			Username string `json:"username" validate:"required"`
@@ -73,22 +172,20 @@ func loginHandler(db *gorm.DB) fiber.Handler {
 			return err
 		}
 
-		var user models.User
-		if err := db.Where("username = ? OR email = ?", loginData.Username, loginData.Username).First(&user).Error; err != nil {
-			return c.Status(401).JSON(fiber.Map{"error": "Credenciales inválidas"})
+		user, err := svc.Users.Authenticate(c.UserContext(), loginData.Username, loginData.Password)
+		if errors.Is(err, services.ErrInactiveUser) {
+			return fiber.NewError(fiber.StatusUnauthorized, "Usuario inactivo")
 		}
-
-		if !user.Active {
-			return c.Status(401).JSON(fiber.Map{"error": "Usuario inactivo"})
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			return fiber.NewError(fiber.StatusUnauthorized, "Credenciales inválidas")
 		}
-
-		if !middleware.CheckPassword(loginData.Password, user.Password) {
-			return c.Status(401).JSON(fiber.Map{"error": "Credenciales inválidas"})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al iniciar sesión")
 		}
 
-		token, err := middleware.GenerateToken(user)
+		token, err := middleware.GenerateToken(*user)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al generar token"})
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al generar token")
 		}
 
 		return c.JSON(fiber.Map{
@@ -98,27 +195,22 @@ func loginHandler(db *gorm.DB) fiber.Handler {
 	}
 }
 
-func registerHandler(db *gorm.DB) fiber.Handler {
+func registerHandler(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var user models.User
-		if err := utils.ParseAndValidate(c, &user); err != nil {
+		var input services.UserInput
+		if err := utils.ParseBody(c, &input); err != nil {
 			return err
 		}
 
-		// Hash password
-		hashedPassword, err := middleware.HashPassword(user.Password)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al procesar contraseña"})
+		// Generate username from the email if needed
+		if input.Username == "" {
+			input.Username = utils.GenerateSlug(input.Email)
 		}
-		user.Password = hashedPassword
 
-		// Generate slug for username if needed
-		if user.Username == "" {
-			user.Username = utils.GenerateSlug(user.Email)
-		}
-
-		if err := db.Create(&user).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al crear usuario"})
+		// Public registrations always get the viewer role
+		user, err := svc.Users.Register(c.UserContext(), actor(c), &input)
+		if err != nil {
+			return respondError(err, "Usuario no encontrado", "Error al crear usuario")
 		}
 
 		return c.JSON(fiber.Map{"message": "Usuario creado exitosamente", "user": user})
@@ -126,368 +218,622 @@ func registerHandler(db *gorm.DB) fiber.Handler {
 }
 
 // Site Config Handlers
-func getSiteConfig(db *gorm.DB) fiber.Handler {
+func getSiteConfig(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var configs []models.SiteConfig
-		if err := db.Find(&configs).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener configuración"})
+		configs, err := svc.Config.All(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener configuración")
 		}
 		return c.JSON(configs)
 	}
 }
 
-func updateSiteConfig(db *gorm.DB) fiber.Handler {
+func updateSiteConfig(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Config entries are identified by key
 		var config models.SiteConfig
-		if err := utils.ParseAndValidate(c, &config); err != nil {
+		if err := utils.ParseBody(c, &config); err != nil {
 			return err
 		}
 
-		if err := db.Save(&config).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al actualizar configuración"})
+		if err := svc.Config.Set(c.UserContext(), actor(c), &config); err != nil {
+			return respondError(err, "Configuración no encontrada", "Error al actualizar configuración")
 		}
 
 		return c.JSON(config)
 	}
 }
 
-// Slides Handlers
-func getSlides(db *gorm.DB) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		var slides []models.Slide
-		if err := db.Order("order ASC").Find(&slides).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener slides"})
-		}
-		return c.JSON(slides)
-	}
+// Content Handlers
+
+// entityService audited CRUD shared by the content services
+type entityService[T any] interface {
+	Create(ctx context.Context, actor services.Actor, item *T) error
+	Update(ctx context.Context, actor services.Actor, id uint, item *T) error
+	Delete(ctx context.Context, actor services.Actor, id uint) error
 }
 
-func createSlide(db *gorm.DB) fiber.Handler {
+// entityMessages user-facing messages of an entity
+type entityMessages struct {
+	notFound string
+	create   string
+	update   string
+	delete   string
+	deleted  string
+}
+
+var (
+	slideMessages = entityMessages{
+		notFound: "Slide no encontrado",
+		create:   "Error al crear slide",
+		update:   "Error al actualizar slide",
+		delete:   "Error al eliminar slide",
+		deleted:  "Slide eliminado exitosamente",
+	}
+	categoryMessages = entityMessages{
+		notFound: "Categoría no encontrada",
+		create:   "Error al crear categoría",
+		update:   "Error al actualizar categoría",
+		delete:   "Error al eliminar categoría",
+		deleted:  "Categoría eliminada exitosamente",
+	}
+	productMessages = entityMessages{
+		notFound: "Producto no encontrado",
+		create:   "Error al crear producto",
+		update:   "Error al actualizar producto",
+		delete:   "Error al eliminar producto",
+		deleted:  "Producto eliminado exitosamente",
+	}
+	variantMessages = entityMessages{
+		notFound: "Variante no encontrada",
+		create:   "Error al crear variante",
+		update:   "Error al actualizar variante",
+		delete:   "Error al eliminar variante",
+		deleted:  "Variante eliminada exitosamente",
+	}
+	currencyMessages = entityMessages{
+		notFound: "Moneda no encontrada",
+		create:   "Error al crear moneda",
+		update:   "Error al actualizar moneda",
+		delete:   "Error al eliminar moneda",
+		deleted:  "Moneda eliminada exitosamente",
+	}
+	attributeMessages = entityMessages{
+		notFound: "Atributo no encontrado",
+		create:   "Error al crear atributo",
+		update:   "Error al actualizar atributo",
+		delete:   "Error al eliminar atributo",
+		deleted:  "Atributo eliminado exitosamente",
+	}
+	tagMessages = entityMessages{
+		notFound: "Etiqueta no encontrada",
+		create:   "Error al crear etiqueta",
+		update:   "Error al actualizar etiqueta",
+		delete:   "Error al eliminar etiqueta",
+		deleted:  "Etiqueta eliminada exitosamente",
+	}
+	collectionMessages = entityMessages{
+		notFound: "Colección no encontrada",
+		create:   "Error al crear colección",
+		update:   "Error al actualizar colección",
+		delete:   "Error al eliminar colección",
+		deleted:  "Colección eliminada exitosamente",
+	}
+	contactMessages = entityMessages{
+		notFound: "Contacto no encontrado",
+		create:   "Error al crear contacto",
+		update:   "Error al actualizar contacto",
+		delete:   "Error al eliminar contacto",
+		deleted:  "Contacto eliminado exitosamente",
+	}
+)
+
+func createEntity[T any](service entityService[T], messages entityMessages) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var slide models.Slide
-		if err := utils.ParseAndValidate(c, &slide); err != nil {
+		var item T
+		if err := utils.ParseBody(c, &item); err != nil {
 			return err
 		}
 
-		if err := db.Create(&slide).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al crear slide"})
+		if err := service.Create(c.UserContext(), actor(c), &item); err != nil {
+			return respondError(err, messages.notFound, messages.create)
 		}
 
-		return c.JSON(slide)
+		return c.JSON(item)
 	}
 }
 
-func updateSlide(db *gorm.DB) fiber.Handler {
+func updateEntity[T any](service entityService[T], messages entityMessages) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return err
 		}
 
-		var slide models.Slide
-		if err := utils.ParseAndValidate(c, &slide); err != nil {
+		var item T
+		if err := utils.ParseBody(c, &item); err != nil {
 			return err
 		}
 
-		slide.ID = uint(id)
-		if err := db.Save(&slide).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al actualizar slide"})
+		if err := service.Update(c.UserContext(), actor(c), id, &item); err != nil {
+			return respondError(err, messages.notFound, messages.update)
 		}
 
-		return c.JSON(slide)
+		return c.JSON(item)
 	}
 }
 
-func deleteSlide(db *gorm.DB) fiber.Handler {
+func deleteEntity[T any](service entityService[T], messages entityMessages) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return err
 		}
 
-		if err := db.Delete(&models.Slide{}, id).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al eliminar slide"})
+		if err := service.Delete(c.UserContext(), actor(c), id); err != nil {
+			return respondError(err, messages.notFound, messages.delete)
 		}
 
-		return c.JSON(fiber.Map{"message": "Slide eliminado exitosamente"})
+		return c.JSON(fiber.Map{"message": messages.deleted})
 	}
 }
 
-// Categories Handlers
-func getCategories(db *gorm.DB) fiber.Handler {
+func getSlides(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Slides.Page, pagination.Slides, "Error al obtener slides")
+}
+
+func getCategories(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Categories.Page, pagination.Categories, "Error al obtener categorías")
+}
+
+// getCategoryTree every category, active or not, nested under its parent (children)
+func getCategoryTree(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var categories []models.Category
-		if err := db.Order("order ASC").Find(&categories).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener categorías"})
+		tree, err := svc.Categories.Tree(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener categorías")
 		}
-		return c.JSON(categories)
+		return c.JSON(tree)
 	}
 }
 
-func createCategory(db *gorm.DB) fiber.Handler {
+// getProducts accepts the catalog filters (category_id, category, min_price, max_price, has_image,
+// stock_status), e.g. ?stock_status=low_stock,out_of_stock for restocking
+func getProducts(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Products.Page, pagination.Products, "Error al obtener productos")
+}
+
+// getVariants variants of the :id product by order, active or not
+func getVariants(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var category models.Category
-		if err := utils.ParseAndValidate(c, &category); err != nil {
+		id, err := parseID(c)
+		if err != nil {
 			return err
 		}
+		if _, err := svc.Products.Get(c.UserContext(), id); err != nil {
+			return respondError(err, productMessages.notFound, "Error al obtener variantes")
+		}
 
-		// Generate slug if not provided
-		if category.Slug == "" {
-			category.Slug = utils.GenerateSlug(category.Name)
+		variants, err := svc.Variants.ForProduct(c.UserContext(), id)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener variantes")
 		}
+		return c.JSON(variants)
+	}
+}
 
-		if err := db.Create(&category).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al crear categoría"})
+// createVariant adds a variant to the :id product
+func createVariant(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := parseID(c)
+		if err != nil {
+			return err
+		}
+		if _, err := svc.Products.Get(c.UserContext(), id); err != nil {
+			return respondError(err, productMessages.notFound, variantMessages.create)
+		}
+
+		var variant models.ProductVariant
+		if err := utils.ParseBody(c, &variant); err != nil {
+			return err
 		}
+		variant.ProductID = id
 
-		return c.JSON(category)
+		if err := svc.Variants.Create(c.UserContext(), actor(c), &variant); err != nil {
+			return respondError(err, variantMessages.notFound, variantMessages.create)
+		}
+		return c.JSON(variant)
 	}
 }
 
-func updateCategory(db *gorm.DB) fiber.Handler {
+// updateVariant replaces a variant of the :id product; it cannot move to another product
+func updateVariant(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		productID, variantID, err := variantParams(c, svc)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return respondError(err, variantMessages.notFound, variantMessages.update)
 		}
 
-		var category models.Category
-		if err := utils.ParseAndValidate(c, &category); err != nil {
+		var variant models.ProductVariant
+		if err := utils.ParseBody(c, &variant); err != nil {
 			return err
 		}
+		variant.ProductID = productID
 
-		category.ID = uint(id)
-		if err := db.Save(&category).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al actualizar categoría"})
+		if err := svc.Variants.Update(c.UserContext(), actor(c), variantID, &variant); err != nil {
+			return respondError(err, variantMessages.notFound, variantMessages.update)
 		}
-
-		return c.JSON(category)
+		return c.JSON(variant)
 	}
 }
 
-func deleteCategory(db *gorm.DB) fiber.Handler {
+// deleteVariant removes a variant of the :id product
+func deleteVariant(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		_, variantID, err := variantParams(c, svc)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return respondError(err, variantMessages.notFound, variantMessages.delete)
 		}
 
-		if err := db.Delete(&models.Category{}, id).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al eliminar categoría"})
+		if err := svc.Variants.Delete(c.UserContext(), actor(c), variantID); err != nil {
+			return respondError(err, variantMessages.notFound, variantMessages.delete)
+		}
+		return c.JSON(fiber.Map{"message": variantMessages.deleted})
+	}
+}
+
+// variantParams reads :id and :variant; a variant of another product is not found
+func variantParams(c *fiber.Ctx, svc *services.Services) (uint, uint, error) {
+	productID, err := parseID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	variantID, err := parseParam(c, "variant")
+	if err != nil {
+		return 0, 0, err
+	}
+	variant, err := svc.Variants.Get(c.UserContext(), variantID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if variant.ProductID != productID {
+		return 0, 0, repository.ErrNotFound
+	}
+	return productID, variantID, nil
+}
+
+// getPrices price overrides of the :id product and its variants, by variant and currency
+func getPrices(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := parseID(c)
+		if err != nil {
+			return err
 		}
 
-		return c.JSON(fiber.Map{"message": "Categoría eliminada exitosamente"})
+		prices, err := svc.Currencies.Prices(c.UserContext(), id)
+		if err != nil {
+			return respondError(err, productMessages.notFound, "Error al obtener precios")
+		}
+		return c.JSON(prices)
 	}
 }
 
-// Products Handlers
-func getProducts(db *gorm.DB) fiber.Handler {
+// setPrices replaces all the price overrides of the :id product; an empty list removes them
+func setPrices(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var products []models.Product
-		query := db.Preload("Category")
+		id, err := parseID(c)
+		if err != nil {
+			return err
+		}
 
-		if categoryID := c.Query("category_id"); categoryID != "" {
-			query = query.Where("category_id = ?", categoryID)
+		var prices []models.ProductPrice
+		if err := utils.ParseBody(c, &prices); err != nil {
+			return err
+		}
+		if prices == nil {
+			prices = []models.ProductPrice{}
 		}
 
-		if err := query.Order("created_at DESC").Find(&products).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener productos"})
+		if err := svc.Currencies.SetPrices(c.UserContext(), actor(c), id, prices); err != nil {
+			return respondError(err, productMessages.notFound, "Error al actualizar precios")
 		}
-		return c.JSON(products)
+		return c.JSON(prices)
 	}
 }
 
-func createProduct(db *gorm.DB) fiber.Handler {
+// getTags lists the tags by name; they have no active flag
+func getTags(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var product models.Product
-		if err := utils.ParseAndValidate(c, &product); err != nil {
+		query, err := pagination.Parse(c, pagination.Tags)
+		if err != nil {
 			return err
 		}
 
-		// Generate slug if not provided
-		if product.Slug == "" {
-			product.Slug = utils.GenerateSlug(product.Name)
+		page, err := svc.Tags.Page(c.UserContext(), query.Filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener etiquetas")
 		}
+		return pagination.Send(c, query, page)
+	}
+}
+
+func getCollections(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Collections.Page, pagination.Collections, "Error al obtener colecciones")
+}
 
-		if err := db.Create(&product).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al crear producto"})
+// getCollectionProducts products of the :id collection in order, active or not; for a
+// smart one, the products matching its rules right now
+func getCollectionProducts(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := parseID(c)
+		if err != nil {
+			return err
 		}
 
-		return c.JSON(product)
+		products, err := svc.Collections.Products(c.UserContext(), id)
+		if err != nil {
+			return respondError(err, collectionMessages.notFound, "Error al obtener productos de la colección")
+		}
+		return c.JSON(products)
 	}
 }
 
-func updateProduct(db *gorm.DB) fiber.Handler {
+// setCollectionProducts replaces the products of the :id manual collection with the
+// given product ids, in order; an empty list removes them
+func setCollectionProducts(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return err
 		}
 
-		var product models.Product
-		if err := utils.ParseAndValidate(c, &product); err != nil {
+		var productIDs []uint
+		if err := utils.ParseBody(c, &productIDs); err != nil {
 			return err
 		}
 
-		product.ID = uint(id)
-		if err := db.Save(&product).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al actualizar producto"})
+		if err := svc.Collections.SetProducts(c.UserContext(), actor(c), id, productIDs); err != nil {
+			return respondError(err, collectionMessages.notFound, "Error al actualizar productos de la colección")
+		}
+		products, err := svc.Collections.Products(c.UserContext(), id)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener productos de la colección")
+		}
+		return c.JSON(products)
+	}
+}
+
+// getRelations relations of the :id product by type and position
+func getRelations(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := parseID(c)
+		if err != nil {
+			return err
 		}
 
-		return c.JSON(product)
+		relations, err := svc.Relations.ForProduct(c.UserContext(), id)
+		if err != nil {
+			return respondError(err, productMessages.notFound, "Error al obtener relaciones")
+		}
+		return c.JSON(relations)
 	}
 }
 
-func deleteProduct(db *gorm.DB) fiber.Handler {
+// setRelations replaces all the relations of the :id product; each one keeps its place
+// among those of its type and an empty list removes them
+func setRelations(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return err
 		}
 
-		if err := db.Delete(&models.Product{}, id).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al eliminar producto"})
+		var relations []models.ProductRelation
+		if err := utils.ParseBody(c, &relations); err != nil {
+			return err
+		}
+		if relations == nil {
+			relations = []models.ProductRelation{}
 		}
 
-		return c.JSON(fiber.Map{"message": "Producto eliminado exitosamente"})
+		if err := svc.Relations.SetRelations(c.UserContext(), actor(c), id, relations); err != nil {
+			return respondError(err, productMessages.notFound, "Error al actualizar relaciones")
+		}
+		return c.JSON(relations)
 	}
 }
 
-// Contacts Handlers
-func getContacts(db *gorm.DB) fiber.Handler {
+// stockMovementLimit movements returned by getStockMovements without ?limit
+const stockMovementLimit = 50
+
+// getStockMovements stock ledger of the :id product, newest first; ?variant= narrows it
+// to one variant and ?limit= (1-MaxLimit) sets how many movements are returned
+func getStockMovements(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var contacts []models.ContactInfo
-		if err := db.Order("order ASC").Find(&contacts).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener contactos"})
+		id, err := parseID(c)
+		if err != nil {
+			return err
+		}
+		limit := c.QueryInt("limit", stockMovementLimit)
+		if limit < 1 || limit > pagination.MaxLimit {
+			return utils.NewValidationError("limit debe estar entre 1 y " + strconv.Itoa(pagination.MaxLimit))
+		}
+		var variantID *uint
+		if c.Query("variant") != "" {
+			variant, err := strconv.ParseUint(c.Query("variant"), 10, 32)
+			if err != nil || variant == 0 {
+				return utils.NewValidationError("variant inválido")
+			}
+			id := uint(variant)
+			variantID = &id
 		}
-		return c.JSON(contacts)
+
+		movements, err := svc.Stock.Movements(c.UserContext(), id, variantID, limit)
+		if err != nil {
+			return respondError(err, productMessages.notFound, "Error al obtener existencias")
+		}
+		return c.JSON(movements)
 	}
 }
 
-func createContact(db *gorm.DB) fiber.Handler {
+// adjustStock changes the stock of the :id product, which must have no variants
+func adjustStock(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var contact models.ContactInfo
-		if err := utils.ParseAndValidate(c, &contact); err != nil {
+		id, err := parseID(c)
+		if err != nil {
 			return err
 		}
 
-		if err := db.Create(&contact).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al crear contacto"})
+		var adjustment services.StockAdjustment
+		if err := utils.ParseBody(c, &adjustment); err != nil {
+			return err
 		}
 
-		return c.JSON(contact)
+		movement, err := svc.Stock.Adjust(c.UserContext(), actor(c), id, nil, adjustment)
+		if err != nil {
+			return respondError(err, productMessages.notFound, "Error al ajustar existencias")
+		}
+		return c.JSON(movement)
 	}
 }
 
-func updateContact(db *gorm.DB) fiber.Handler {
+// adjustVariantStock changes the stock of a variant of the :id product
+func adjustVariantStock(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		productID, variantID, err := variantParams(c, svc)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return respondError(err, variantMessages.notFound, "Error al ajustar existencias")
 		}
 
-		var contact models.ContactInfo
-		if err := utils.ParseAndValidate(c, &contact); err != nil {
+		var adjustment services.StockAdjustment
+		if err := utils.ParseBody(c, &adjustment); err != nil {
 			return err
 		}
 
-		contact.ID = uint(id)
-		if err := db.Save(&contact).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al actualizar contacto"})
+		movement, err := svc.Stock.Adjust(c.UserContext(), actor(c), productID, &variantID, adjustment)
+		if err != nil {
+			return respondError(err, variantMessages.notFound, "Error al ajustar existencias")
 		}
-
-		return c.JSON(contact)
+		return c.JSON(movement)
 	}
 }
 
-func deleteContact(db *gorm.DB) fiber.Handler {
+func getCurrencies(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Currencies.Page, pagination.Currencies, "Error al obtener monedas")
+}
+
+// getAttributes attribute definitions of the :id category, by order
+func getAttributes(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return err
 		}
-
-		if err := db.Delete(&models.ContactInfo{}, id).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al eliminar contacto"})
+		if _, err := svc.Categories.Get(c.UserContext(), id); err != nil {
+			return respondError(err, categoryMessages.notFound, "Error al obtener atributos")
 		}
 
-		return c.JSON(fiber.Map{"message": "Contacto eliminado exitosamente"})
+		attributes, err := svc.Attributes.ForCategory(c.UserContext(), id)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener atributos")
+		}
+		return c.JSON(attributes)
 	}
 }
 
-// Users Handlers
-func getUsers(db *gorm.DB) fiber.Handler {
+// createAttribute adds an attribute definition to the :id category
+func createAttribute(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var users []models.User
-		if err := db.Find(&users).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al obtener usuarios"})
+		id, err := parseID(c)
+		if err != nil {
+			return err
+		}
+
+		var attribute models.AttributeDefinition
+		if err := utils.ParseBody(c, &attribute); err != nil {
+			return err
 		}
-		return c.JSON(users)
+		attribute.CategoryID = id
+
+		if err := svc.Attributes.Create(c.UserContext(), actor(c), &attribute); err != nil {
+			return respondError(err, attributeMessages.notFound, attributeMessages.create)
+		}
+		return c.JSON(attribute)
 	}
 }
 
-func createUser(db *gorm.DB) fiber.Handler {
+func getContacts(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Contacts.Page, pagination.Contacts, "Error al obtener contactos")
+}
+
+// listEntities paginated listing with the shared query language; the CMS also filters by active
+func listEntities[T any](page func(ctx context.Context, filter repository.Filter) (services.Page[T], error), resource pagination.Resource, failure string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var user models.User
-		if err := utils.ParseAndValidate(c, &user); err != nil {
+		query, err := pagination.Parse(c, resource.WithActive())
+		if err != nil {
 			return err
 		}
 
-		// Hash password
-		hashedPassword, err := middleware.HashPassword(user.Password)
+		result, err := page(c.UserContext(), query.Filter)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al procesar contraseña"})
+			return fiber.NewError(fiber.StatusInternalServerError, failure)
+		}
+		return pagination.Send(c, query, result)
+	}
+}
+
+// Users Handlers
+func getUsers(svc *services.Services) fiber.Handler {
+	return listEntities(svc.Users.Page, pagination.Users, "Error al obtener usuarios")
+}
+
+func createUser(svc *services.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input services.UserInput
+		if err := utils.ParseBody(c, &input); err != nil {
+			return err
 		}
-		user.Password = hashedPassword
 
-		if err := db.Create(&user).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al crear usuario"})
+		user, err := svc.Users.Create(c.UserContext(), actor(c), &input)
+		if err != nil {
+			return respondError(err, "Usuario no encontrado", "Error al crear usuario")
 		}
 
 		return c.JSON(user)
 	}
 }
 
-func updateUser(db *gorm.DB) fiber.Handler {
+func updateUser(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
-		}
-
-		var user models.User
-		if err := utils.ParseAndValidate(c, &user); err != nil {
 			return err
 		}
 
-		user.ID = uint(id)
-
-		// Hash password if provided
-		if user.Password != "" {
-			hashedPassword, err := middleware.HashPassword(user.Password)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{"error": "Error al procesar contraseña"})
-			}
-			user.Password = hashedPassword
+		// The password is only changed when provided
+		var input services.UserInput
+		if err := utils.ParseBody(c, &input); err != nil {
+			return err
 		}
 
-		if err := db.Save(&user).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al actualizar usuario"})
+		user, err := svc.Users.Update(c.UserContext(), actor(c), id, &input)
+		if err != nil {
+			return respondError(err, "Usuario no encontrado", "Error al actualizar usuario")
 		}
 
 		return c.JSON(user)
 	}
 }
 
-func deleteUser(db *gorm.DB) fiber.Handler {
+func deleteUser(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		id, err := parseID(c)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+			return err
 		}
 
-		if err := db.Delete(&models.User{}, id).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Error al eliminar usuario"})
+		if err := svc.Users.Delete(c.UserContext(), actor(c), id); err != nil {
+			return respondError(err, "Usuario no encontrado", "Error al eliminar usuario")
 		}
 
 		return c.JSON(fiber.Map{"message": "Usuario eliminado exitosamente"})
@@ -495,80 +841,27 @@ func deleteUser(db *gorm.DB) fiber.Handler {
 }
 
 // System Management Handlers
-func getSystemStatus(db *gorm.DB) fiber.Handler {
+func getSystemStatus(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var stats struct {
-			TotalUsers       int64 `json:"total_users"`
-			ActiveUsers      int64 `json:"active_users"`
-			TotalSlides      int64 `json:"total_slides"`
-			ActiveSlides     int64 `json:"active_slides"`
-			TotalCategories  int64 `json:"total_categories"`
-			ActiveCategories int64 `json:"active_categories"`
-			TotalProducts    int64 `json:"total_products"`
-			ActiveProducts   int64 `json:"active_products"`
-			TotalContacts    int64 `json:"total_contacts"`
-			ActiveContacts   int64 `json:"active_contacts"`
-		}
-
-		// Get user statistics
-		db.Model(&models.User{}).Count(&stats.TotalUsers)
-		db.Model(&models.User{}).Where("active = ?", true).Count(&stats.ActiveUsers)
-
-		// Get slide statistics
-		db.Model(&models.Slide{}).Count(&stats.TotalSlides)
-		db.Model(&models.Slide{}).Where("active = ?", true).Count(&stats.ActiveSlides)
-
-		// Get category statistics
-		db.Model(&models.Category{}).Count(&stats.TotalCategories)
-		db.Model(&models.Category{}).Where("active = ?", true).Count(&stats.ActiveCategories)
-
-		// Get product statistics
-		db.Model(&models.Product{}).Count(&stats.TotalProducts)
-		db.Model(&models.Product{}).Where("active = ?", true).Count(&stats.ActiveProducts)
-
-		// Get contact statistics
-		db.Model(&models.ContactInfo{}).Count(&stats.TotalContacts)
-		db.Model(&models.ContactInfo{}).Where("active = ?", true).Count(&stats.ActiveContacts)
-
+		stats, err := svc.System.Status(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al obtener el estado del sistema")
+		}
 		return c.JSON(stats)
 	}
 }
 
-func createBackup(db *gorm.DB) fiber.Handler {
+func createBackup(svc *services.Services) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Esta es una implementación básica de backup
-		// En producción, deberías usar herramientas como pg_dump
-
-		var backup struct {
-			Timestamp string                 `json:"timestamp"`
-			Data      map[string]interface{} `json:"data"`
+		// Consistent snapshot of the content; websitectl backup restore can load it back
+		snapshot, err := svc.System.Backup(c.UserContext())
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Error al crear backup")
 		}
 
-		backup.Timestamp = "2024-01-01T00:00:00Z"
-		backup.Data = make(map[string]interface{})
-
-		// Exportar datos principales
-		var slides []models.Slide
-		var categories []models.Category
-		var products []models.Product
-		var contacts []models.ContactInfo
-		var configs []models.SiteConfig
-
-		db.Find(&slides)
-		db.Find(&categories)
-		db.Find(&products)
-		db.Find(&contacts)
-		db.Find(&configs)
-
-		backup.Data["slides"] = slides
-		backup.Data["categories"] = categories
-		backup.Data["products"] = products
-		backup.Data["contacts"] = contacts
-		backup.Data["configs"] = configs
-
 		return c.JSON(fiber.Map{
 			"message": "Backup creado exitosamente",
-			"backup":  backup,
+			"backup":  snapshot,
 		})
 	}
 }
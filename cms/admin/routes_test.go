@@ -0,0 +1,928 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"website/backend/config"
+	"website/backend/middleware"
+	"website/backend/models"
+	"website/backend/money"
+	"website/backend/repository"
+	"website/backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testEnv CMS routes over an in-memory store with one user per role
+type testEnv struct {
+	t      *testing.T
+	app    *fiber.App
+	store  *repository.MemoryStore
+	svc    *services.Services
+	tokens map[string]string // Role -> token
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.JWT.Secret = "test-secret"
+	middleware.Configure(cfg)
+
+	store := repository.NewMemoryStore()
+	svc := services.New(store)
+	// bcrypt is deliberately slow; tests only need a reversible marker
+	svc.Users.HashPassword = func(password string) (string, error) { return "hash:" + password, nil }
+	svc.Users.CheckPassword = func(password, hash string) bool { return hash == "hash:"+password }
+
+	env := &testEnv{t: t, store: store, svc: svc, tokens: map[string]string{}}
+	for _, role := range []string{"super_admin", "admin", "editor", "viewer"} {
+		user := models.User{
+			Username: strings.ReplaceAll(role, "_", ""),
+			Email:    strings.ReplaceAll(role, "_", "") + "@example.com",
+			Password: "hash:secreto",
+			Role:     role,
+			Active:   true,
+		}
+		if err := store.Users().Create(context.Background(), &user); err != nil {
+			t.Fatalf("usuario de prueba: %v", err)
+		}
+		token, err := middleware.GenerateToken(user)
+		if err != nil {
+			t.Fatalf("GenerateToken: %v", err)
+		}
+		env.tokens[role] = token
+	}
+	inactive := models.User{Username: "inactivo", Email: "inactivo@example.com", Password: "hash:secreto", Role: "admin"}
+	if err := store.Users().Create(context.Background(), &inactive); err != nil {
+		t.Fatalf("usuario de prueba: %v", err)
+	}
+	token, _ := middleware.GenerateToken(inactive)
+	env.tokens["inactive"] = token
+
+	env.app = fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	env.app.Use(middleware.RequestID())
+	if err := SetupUI(env.app, UI, false); err != nil {
+		t.Fatalf("SetupUI: %v", err)
+	}
+	SetupRoutes(env.app, svc)
+	return env
+}
+
+// do sends a JSON request as role ("" = anonymous) and decodes the JSON response into out
+func (env *testEnv) do(method, path, role string, body interface{}, out interface{}) int {
+	env.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		raw, _ := json.Marshal(body)
+		reader = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if role != "" {
+		req.Header.Set("Authorization", "Bearer "+env.tokens[role])
+	}
+
+	resp, err := env.app.Test(req)
+	if err != nil {
+		env.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		raw, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(raw, out); err != nil {
+			env.t.Fatalf("%s %s = %d, respuesta no es JSON: %s", method, path, resp.StatusCode, raw)
+		}
+	}
+	return resp.StatusCode
+}
+
+// list GETs a paginated listing as role and decodes its "data" into out
+func (env *testEnv) list(path, role string, out interface{}) {
+	env.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	env.expect(200, "GET", path, role, nil, &envelope)
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		env.t.Fatalf("GET %s: data no es un array: %s", path, envelope.Data)
+	}
+}
+
+// lastChanges fields changed by the newest audit event of entityType
+func (env *testEnv) lastChanges(entityType string) map[string]json.RawMessage {
+	env.t.Helper()
+	var events []models.AuditEvent
+	env.expect(200, "GET", "/admin/audit?limit=1&entity_type="+entityType, "super_admin", nil, &events)
+	if len(events) != 1 {
+		env.t.Fatalf("auditoría de %s = %+v", entityType, events)
+	}
+	var diff struct {
+		Changes map[string]json.RawMessage `json:"changes"`
+	}
+	if err := json.Unmarshal(events[0].Diff, &diff); err != nil {
+		env.t.Fatalf("diff de %s: %v", entityType, err)
+	}
+	return diff.Changes
+}
+
+// expect fails the test when a request does not return the wanted status
+func (env *testEnv) expect(want int, method, path, role string, body interface{}, out interface{}) {
+	env.t.Helper()
+	if got := env.do(method, path, role, body, out); got != want {
+		env.t.Fatalf("%s %s como %q = %d, se esperaba %d", method, path, role, got, want)
+	}
+}
+
+func TestAuthRoutes(t *testing.T) {
+	env := newTestEnv(t)
+
+	var login struct {
+		Token string      `json:"token"`
+		User  models.User `json:"user"`
+	}
+	env.expect(200, "POST", "/admin/auth/login", "", fiber.Map{"username": "editor@example.com", "password": "secreto"}, &login)
+	if login.Token == "" || login.User.Role != "editor" {
+		t.Fatalf("login = %+v", login)
+	}
+	env.expect(401, "POST", "/admin/auth/login", "", fiber.Map{"username": "editor", "password": "otra"}, nil)
+	env.expect(401, "POST", "/admin/auth/login", "", fiber.Map{"username": "inactivo", "password": "secreto"}, nil)
+	env.expect(400, "POST", "/admin/auth/login", "", fiber.Map{"username": "editor"}, nil)
+
+	// Public registration reads the password and ignores the requested role
+	var registered struct {
+		User models.User `json:"user"`
+	}
+	env.expect(200, "POST", "/admin/auth/register", "",
+		fiber.Map{"username": "nuevo", "email": "nuevo@example.com", "password": "secreto", "role": "super_admin"}, &registered)
+	if registered.User.Role != "viewer" || !registered.User.Active {
+		t.Fatalf("registro = %+v, se esperaba viewer activo", registered.User)
+	}
+}
+
+func TestAdminPanelIsPublic(t *testing.T) {
+	env := newTestEnv(t)
+
+	for path, want := range map[string]string{
+		"/admin":             `src="/admin/js/admin.js"`,
+		"/admin/js/admin.js": "fetch(",
+	} {
+		resp, err := env.app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 || !strings.Contains(string(body), want) {
+			t.Errorf("GET %s = %d, se esperaba 200 con %q", path, resp.StatusCode, want)
+		}
+	}
+
+	// The API under /admin still requires a token
+	env.expect(401, "GET", "/admin/slides", "", nil, nil)
+}
+
+func TestProtectedRoutesRequireActiveUserAndRole(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.expect(401, "GET", "/admin/slides", "", nil, nil)
+	env.expect(401, "GET", "/admin/slides", "inactive", nil, nil)
+	env.expect(403, "GET", "/admin/slides", "viewer", nil, nil)
+	env.expect(403, "PUT", "/admin/config", "editor", fiber.Map{"key": "a", "value": "b"}, nil)
+	env.expect(403, "DELETE", "/admin/slides/1", "editor", nil, nil)
+	env.expect(403, "GET", "/admin/users", "editor", nil, nil)
+	env.expect(403, "DELETE", "/admin/users/1", "admin", nil, nil)
+	env.expect(403, "GET", "/admin/system/status", "admin", nil, nil)
+	env.expect(403, "POST", "/admin/system/backup", "admin", nil, nil)
+	env.expect(403, "GET", "/admin/audit", "admin", nil, nil)
+}
+
+func TestConfigRoutes(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.expect(200, "PUT", "/admin/config", "admin", fiber.Map{"key": "site_name", "value": "Uno"}, nil)
+	env.expect(200, "PUT", "/admin/config", "admin", fiber.Map{"key": "site_name", "value": "Dos"}, nil)
+	env.expect(400, "PUT", "/admin/config", "admin", fiber.Map{"key": "site_name"}, nil)
+
+	var configs []models.SiteConfig
+	env.expect(200, "GET", "/admin/config", "editor", nil, &configs)
+	if len(configs) != 1 || configs[0].Value != "Dos" {
+		t.Fatalf("config = %+v, se esperaba una clave actualizada", configs)
+	}
+}
+
+func TestContentRoutes(t *testing.T) {
+	env := newTestEnv(t)
+
+	// Slides: invalid bodies are rejected and nothing is stored
+	var problem struct {
+		Error   string   `json:"error"`
+		Details []string `json:"details"`
+	}
+	env.expect(400, "POST", "/admin/slides", "editor", fiber.Map{"title": "Sin imagen"}, &problem)
+	if len(problem.Details) == 0 {
+		t.Fatalf("validación sin detalles: %+v", problem)
+	}
+	env.expect(400, "POST", "/admin/slides", "editor", "no es un objeto", nil)
+
+	var slide models.Slide
+	env.expect(200, "POST", "/admin/slides", "editor", fiber.Map{"title": "Hola", "image_url": "https://example.com/a.jpg", "active": true}, &slide)
+	env.expect(200, "PUT", "/admin/slides/1", "editor", fiber.Map{"title": "Adiós", "image_url": "https://example.com/a.jpg"}, &slide)
+	if slide.ID != 1 || slide.Title != "Adiós" || slide.CreatedAt.IsZero() {
+		t.Fatalf("slide actualizado = %+v", slide)
+	}
+	var slides []models.Slide
+	env.list("/admin/slides", "editor", &slides)
+	if len(slides) != 1 {
+		t.Fatalf("slides = %d, se esperaba 1 (el cuerpo inválido no debe guardarse)", len(slides))
+	}
+	env.expect(404, "PUT", "/admin/slides/99", "editor", fiber.Map{"title": "X", "image_url": "https://example.com/a.jpg"}, nil)
+	env.expect(400, "PUT", "/admin/slides/abc", "editor", fiber.Map{}, nil)
+	env.expect(200, "DELETE", "/admin/slides/1", "admin", nil, nil)
+	env.expect(404, "DELETE", "/admin/slides/1", "admin", nil, nil)
+
+	// Categories: generated, transliterated and de-duplicated slugs
+	var category models.Category
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Fotografía Única", "active": true}, &category)
+	if category.Slug != "fotografia-unica" {
+		t.Fatalf("slug = %q", category.Slug)
+	}
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Fotografía única"}, &category)
+	if category.Slug != "fotografia-unica-2" {
+		t.Fatalf("slug repetido = %q", category.Slug)
+	}
+	env.expect(409, "POST", "/admin/categories", "editor", fiber.Map{"name": "Otra", "slug": "fotografia-unica"}, nil)
+	env.expect(400, "POST", "/admin/categories", "editor", fiber.Map{"name": "Otra", "slug": "Con Espacios"}, nil)
+	env.expect(200, "PUT", "/admin/categories/2", "editor", fiber.Map{"name": "Vídeo", "slug": "video"}, &category)
+	var categories []models.Category
+	env.list("/admin/categories", "editor", &categories)
+	if len(categories) != 2 {
+		t.Fatalf("categorías = %d, se esperaban 2", len(categories))
+	}
+
+	// Products: the category must exist
+	env.expect(400, "POST", "/admin/products", "editor", fiber.Map{"name": "Retrato", "category_id": 99, "price": 10}, nil)
+	var product models.Product
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Retrato", "category_id": 1, "price": 10}, &product)
+	if product.Slug != "retrato" {
+		t.Fatalf("slug de producto = %q", product.Slug)
+	}
+	env.expect(200, "PUT", "/admin/products/1", "editor", fiber.Map{"name": "Retrato", "slug": "retrato", "category_id": 2, "price": 12}, nil)
+	var products []models.Product
+	env.list("/admin/products?category_id=2", "editor", &products)
+	if len(products) != 1 || products[0].Category.Name != "Vídeo" {
+		t.Fatalf("productos de la categoría 2 = %+v", products)
+	}
+	env.expect(400, "GET", "/admin/products?category_id=x", "editor", nil, nil)
+	env.expect(200, "DELETE", "/admin/products/1", "admin", nil, nil)
+
+	// Deleting a category removes its products (ON DELETE CASCADE)
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Boda", "category_id": 1, "price": 10}, nil)
+	env.expect(200, "DELETE", "/admin/categories/1", "admin", nil, nil)
+	env.list("/admin/products", "editor", &products)
+	if len(products) != 0 {
+		t.Fatalf("productos tras borrar la categoría = %d", len(products))
+	}
+
+	// Contacts
+	env.expect(400, "POST", "/admin/contacts", "editor", fiber.Map{"type": "fax", "value": "1"}, nil)
+	env.expect(200, "POST", "/admin/contacts", "editor", fiber.Map{"type": "email", "value": "a@example.com"}, nil)
+	env.expect(200, "PUT", "/admin/contacts/1", "editor", fiber.Map{"type": "phone", "value": "123"}, nil)
+	var contacts []models.ContactInfo
+	env.list("/admin/contacts", "editor", &contacts)
+	if len(contacts) != 1 || contacts[0].Type != "phone" {
+		t.Fatalf("contactos = %+v", contacts)
+	}
+	env.expect(200, "DELETE", "/admin/contacts/1", "admin", nil, nil)
+}
+
+func TestCategoryTreeRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	var cameras, mirrorless, lenses models.Category
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Cámaras", "active": true}, &cameras)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Mirrorless", "parent_id": cameras.ID, "active": true}, &mirrorless)
+	if mirrorless.Path != "/1/2/" || mirrorless.SlugPath != "camaras/mirrorless" || mirrorless.Depth != 1 {
+		t.Fatalf("subcategoría = %+v", mirrorless)
+	}
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Objetivos", "parent_id": mirrorless.ID}, &lenses)
+	env.expect(400, "POST", "/admin/categories", "editor", fiber.Map{"name": "Huérfana", "parent_id": 99}, nil)
+
+	// A category cannot hang from itself or from one of its subcategories
+	env.expect(400, "PUT", "/admin/categories/1", "editor", fiber.Map{"name": "Cámaras", "slug": "camaras", "parent_id": cameras.ID}, nil)
+	env.expect(400, "PUT", "/admin/categories/1", "editor", fiber.Map{"name": "Cámaras", "slug": "camaras", "parent_id": lenses.ID}, nil)
+
+	// A new slug rewrites the paths of the whole subtree
+	env.expect(200, "PUT", "/admin/categories/1", "editor", fiber.Map{"name": "Cámaras", "slug": "camaras-digitales", "active": true}, nil)
+	var tree []models.Category
+	env.expect(200, "GET", "/admin/categories/tree", "editor", nil, &tree)
+	if len(tree) != 1 || len(tree[0].Children) != 1 || len(tree[0].Children[0].Children) != 1 ||
+		tree[0].Children[0].Children[0].SlugPath != "camaras-digitales/mirrorless/objetivos" {
+		t.Fatalf("árbol = %+v", tree)
+	}
+
+	// Categories with subcategories cannot be deleted
+	env.expect(400, "DELETE", "/admin/categories/2", "admin", nil, nil)
+	env.expect(200, "DELETE", "/admin/categories/3", "admin", nil, nil)
+	env.expect(200, "DELETE", "/admin/categories/2", "admin", nil, nil)
+}
+
+func TestAttributeRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Cámaras", "active": true}, nil)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Trípodes", "active": true}, nil)
+
+	// Definitions: generated key, unique per category, options required for enums
+	var weight, mount models.AttributeDefinition
+	env.expect(200, "POST", "/admin/categories/1/attributes", "editor",
+		fiber.Map{"name": "Peso", "type": "number", "unit": "g", "filterable": true, "options": []string{"x"}}, &weight)
+	if weight.Key != "peso" || weight.CategoryID != 1 || weight.Options != nil {
+		t.Fatalf("atributo = %+v", weight)
+	}
+	env.expect(200, "POST", "/admin/categories/1/attributes", "editor",
+		fiber.Map{"name": "Montura", "type": "enum", "options": []string{"EF", "RF"}, "required": true, "order": 1}, &mount)
+	env.expect(400, "POST", "/admin/categories/1/attributes", "editor", fiber.Map{"name": "Peso", "type": "text"}, nil)
+	env.expect(400, "POST", "/admin/categories/1/attributes", "editor", fiber.Map{"name": "Sensor", "type": "enum"}, nil)
+	env.expect(400, "POST", "/admin/categories/1/attributes", "editor", fiber.Map{"name": "Color", "type": "rgb"}, nil)
+	env.expect(200, "POST", "/admin/categories/2/attributes", "editor", fiber.Map{"name": "Peso", "type": "number"}, nil)
+	env.expect(404, "GET", "/admin/categories/9/attributes", "editor", nil, nil)
+	var definitions []models.AttributeDefinition
+	env.expect(200, "GET", "/admin/categories/1/attributes", "editor", nil, &definitions)
+	if len(definitions) != 2 || definitions[0].Key != "peso" {
+		t.Fatalf("atributos de la categoría 1 = %+v", definitions)
+	}
+
+	// Values: validated against the category definitions and stored in canonical form
+	var problem struct {
+		Details []string `json:"details"`
+	}
+	env.expect(400, "POST", "/admin/products", "editor", fiber.Map{"name": "R5", "category_id": 1, "price": 10,
+		"attributes": []fiber.Map{{"attribute_id": 1, "value": "mucho"}, {"attribute_id": 3, "value": "1"}}}, &problem)
+	if strings.Join(problem.Details, "|") != "El atributo 3 no pertenece a la categoría del producto|Peso debe ser un número|Montura es requerido" {
+		t.Fatalf("errores = %v", problem.Details)
+	}
+	var product models.Product
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "R5", "category_id": 1, "price": 10,
+		"attributes": []fiber.Map{{"attribute_id": 2, "value": "rf"}, {"attribute_id": 1, "value": "738,0"}}}, &product)
+	if len(product.Attributes) != 2 || product.Attributes[0].Value != "738" || product.Attributes[1].Value != "RF" ||
+		product.Attributes[0].Display() != "738 g" {
+		t.Fatalf("valores = %+v", product.Attributes)
+	}
+
+	// Updates without attributes keep them; an option in use cannot be removed
+	env.expect(200, "PUT", "/admin/products/1", "editor", fiber.Map{"name": "R5 II", "slug": "r5", "category_id": 1, "price": 12}, &product)
+	if len(product.Attributes) != 2 {
+		t.Fatalf("valores tras actualizar = %+v", product.Attributes)
+	}
+	env.expect(400, "PUT", "/admin/attributes/2", "editor", fiber.Map{"name": "Montura", "category_id": 1, "type": "enum", "options": []string{"EF"}}, nil)
+	env.expect(400, "PUT", "/admin/attributes/1", "editor", fiber.Map{"name": "Peso", "category_id": 1, "type": "text"}, nil)
+	env.expect(200, "PUT", "/admin/attributes/2", "editor", fiber.Map{"name": "Montura", "category_id": 1, "type": "enum", "options": []string{"EF", "RF", "Z"}}, nil)
+
+	// Deleting a definition removes its values
+	env.expect(403, "DELETE", "/admin/attributes/1", "editor", nil, nil)
+	env.expect(200, "DELETE", "/admin/attributes/1", "admin", nil, nil)
+	var products []models.Product
+	env.list("/admin/products", "editor", &products)
+	if len(products) != 1 || len(products[0].Attributes) != 1 || products[0].Attributes[0].Attribute.Key != "montura" {
+		t.Fatalf("productos = %+v", products)
+	}
+}
+
+func TestTagAndCollectionRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Cámaras", "active": true}, nil)
+	var product models.Product
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "R5", "category_id": 1, "price": 10, "active": true,
+		"tags": []fiber.Map{{"name": "Oferta"}, {"name": "OFERTA"}}}, &product)
+	if len(product.Tags) != 1 || product.Tags[0].Slug != "oferta" {
+		t.Fatalf("etiquetas = %+v", product.Tags)
+	}
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "R6", "category_id": 1, "price": 20, "active": true}, nil)
+
+	// Tags: listed by name, slug unique
+	env.expect(200, "POST", "/admin/tags", "editor", fiber.Map{"name": "Nuevo"}, nil)
+	env.expect(409, "POST", "/admin/tags", "editor", fiber.Map{"name": "Nuevo", "slug": "oferta"}, nil)
+	var tags []models.Tag
+	env.list("/admin/tags", "editor", &tags)
+	if len(tags) != 2 || tags[0].Name != "Nuevo" {
+		t.Fatalf("etiquetas = %+v", tags)
+	}
+
+	// Manual collections: products in the given order
+	var collection models.Collection
+	env.expect(200, "POST", "/admin/collections", "editor", fiber.Map{"name": "Ofertas", "active": true}, &collection)
+	env.expect(400, "POST", "/admin/collections", "editor", fiber.Map{"name": "Rara", "type": "auto"}, nil)
+	var products []models.Product
+	env.expect(200, "PUT", "/admin/collections/1/products", "editor", []uint{2, 1}, &products)
+	if len(products) != 2 || products[0].Name != "R6" {
+		t.Fatalf("productos = %+v", products)
+	}
+	env.expect(400, "PUT", "/admin/collections/1/products", "editor", []uint{2, 9}, nil)
+	env.expect(404, "PUT", "/admin/collections/9/products", "editor", []uint{1}, nil)
+	env.expect(404, "GET", "/admin/collections/9/products", "editor", nil, nil)
+
+	// Smart collections: the CMS previews the products matching the rules
+	env.expect(200, "POST", "/admin/collections", "editor", fiber.Map{"name": "Etiquetados", "type": "smart",
+		"rules": fiber.Map{"tag_id": 1}}, &collection)
+	env.expect(200, "GET", "/admin/collections/2/products", "editor", nil, &products)
+	if len(products) != 1 || products[0].Name != "R5" {
+		t.Fatalf("vista previa = %+v", products)
+	}
+	env.expect(200, "PUT", "/admin/collections/2", "editor", fiber.Map{"name": "Etiquetados", "type": "smart",
+		"rules": fiber.Map{"tag_id": 1, "in_stock": true}}, nil)
+	if changes := env.lastChanges("collection"); string(changes["rules"]) != `{"from":{"tag_id":1},"to":{"in_stock":true,"tag_id":1}}` {
+		t.Errorf("cambios de la colección = %s", changes)
+	}
+	env.expect(400, "PUT", "/admin/collections/2/products", "editor", []uint{1}, nil)
+	env.expect(400, "DELETE", "/admin/tags/1", "admin", nil, nil)
+	var collections []models.Collection
+	env.list("/admin/collections?active=false", "editor", &collections)
+	if len(collections) != 1 || collections[0].Name != "Etiquetados" {
+		t.Fatalf("colecciones inactivas = %+v", collections)
+	}
+
+	env.expect(403, "DELETE", "/admin/collections/1", "editor", nil, nil)
+	env.expect(200, "DELETE", "/admin/collections/1", "admin", nil, nil)
+	env.expect(200, "DELETE", "/admin/collections/2", "admin", nil, nil)
+	env.expect(200, "DELETE", "/admin/tags/1", "admin", nil, nil)
+	products = nil
+	env.list("/admin/products?sort=id", "editor", &products)
+	if len(products) != 2 || len(products[0].Tags) != 0 {
+		t.Errorf("productos tras borrar la etiqueta = %+v", products)
+	}
+}
+
+func TestVariantRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Ropa", "active": true}, nil)
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Camiseta", "category_id": 1, "price": 30, "active": true}, nil)
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Gorra", "category_id": 1, "price": 15, "active": true}, nil)
+
+	price := func(id uint) money.Amount {
+		t.Helper()
+		product, err := env.store.Products().Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return product.Price
+	}
+
+	var variant models.ProductVariant
+	env.expect(200, "POST", "/admin/products/1/variants", "editor", fiber.Map{
+		"sku": " CAM-L-N ", "options": fiber.Map{"talla": "L", "color": "Negro"}, "price": 25, "compare_at_price": 35, "active": true,
+	}, &variant)
+	if variant.SKU != "CAM-L-N" || variant.ProductID != 1 || variant.Label() != "color: Negro, talla: L" {
+		t.Fatalf("variante = %+v", variant)
+	}
+	env.expect(200, "POST", "/admin/products/1/variants", "editor", fiber.Map{
+		"sku": "CAM-S-N", "options": fiber.Map{"talla": "S", "color": "Negro"}, "price": 20, "order": 1,
+	}, nil)
+	if got := price(1); got != money.Units(25) {
+		t.Fatalf("precio desde = %v, se esperaba 25 (la variante de 20 está inactiva)", got)
+	}
+
+	// SKU único en todo el catálogo, opciones únicas en el producto y precio anterior mayor
+	var problem struct {
+		Details []string `json:"details"`
+	}
+	env.expect(400, "POST", "/admin/products/2/variants", "editor", fiber.Map{"sku": "CAM-L-N", "price": 10}, &problem)
+	if strings.Join(problem.Details, "|") != "SKU CAM-L-N ya existe en el catálogo" {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(400, "POST", "/admin/products/1/variants", "editor", fiber.Map{
+		"sku": "CAM-L-N-2", "options": fiber.Map{"Color": "negro", "talla": "l"}, "price": 22, "compare_at_price": 22,
+	}, &problem)
+	if strings.Join(problem.Details, "|") != "CompareAtPrice debe ser mayor que Price|Ya existe una variante con las opciones Color: negro, talla: l" {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(400, "POST", "/admin/products/1/variants", "editor", fiber.Map{"options": fiber.Map{"talla": "M"}, "price": 22}, nil)
+	env.expect(404, "POST", "/admin/products/9/variants", "editor", fiber.Map{"sku": "X", "price": 1}, nil)
+
+	// Activar la más barata baja el precio desde; el producto no puede fijar otro
+	env.expect(200, "PUT", "/admin/products/1/variants/2", "editor", fiber.Map{
+		"sku": "CAM-S-N", "options": fiber.Map{"talla": "S", "color": "Negro"}, "price": 20, "active": true,
+	}, nil)
+	if got := price(1); got != money.Units(20) {
+		t.Errorf("precio desde = %v, se esperaba 20", got)
+	}
+
+	// Options are a jsonb column, so changing them shows in the audit diff
+	env.expect(200, "PUT", "/admin/products/1/variants/2", "editor", fiber.Map{
+		"sku": "CAM-S-N", "options": fiber.Map{"talla": "S", "color": "Blanco"}, "price": 20, "active": true,
+	}, nil)
+	if changes := env.lastChanges("variant"); string(changes["options"]) != `{"from":{"color":"Negro","talla":"S"},"to":{"color":"Blanco","talla":"S"}}` {
+		t.Errorf("cambios de la variante = %s", changes)
+	}
+	var product models.Product
+	env.expect(200, "PUT", "/admin/products/1", "editor", fiber.Map{"name": "Camiseta", "category_id": 1, "price": 99, "active": true}, &product)
+	if product.Price != money.Units(20) || len(product.Variants) != 2 {
+		t.Errorf("producto = %v %d variantes", product.Price, len(product.Variants))
+	}
+
+	// Las variantes se gestionan bajo su producto
+	env.expect(404, "PUT", "/admin/products/2/variants/1", "editor", fiber.Map{"sku": "CAM-L-N", "price": 25}, nil)
+	env.expect(404, "DELETE", "/admin/products/2/variants/1", "admin", nil, nil)
+	env.expect(400, "DELETE", "/admin/products/1/variants/x", "admin", nil, nil)
+	env.expect(403, "DELETE", "/admin/products/1/variants/2", "editor", nil, nil)
+	env.expect(200, "DELETE", "/admin/products/1/variants/2", "admin", nil, nil)
+	if got := price(1); got != money.Units(25) {
+		t.Errorf("precio tras borrar = %v, se esperaba 25", got)
+	}
+	var variants []models.ProductVariant
+	env.expect(200, "GET", "/admin/products/1/variants", "editor", nil, &variants)
+	if len(variants) != 1 || variants[0].SKU != "CAM-L-N" {
+		t.Errorf("variantes = %+v", variants)
+	}
+	env.expect(404, "GET", "/admin/products/9/variants", "editor", nil, nil)
+
+	// Al borrar el producto se borran sus variantes y su SKU queda libre
+	env.expect(200, "DELETE", "/admin/products/1", "admin", nil, nil)
+	env.expect(200, "POST", "/admin/products/2/variants", "editor", fiber.Map{"sku": "CAM-L-N", "price": 12, "active": true}, nil)
+	if got := price(2); got != money.Units(12) {
+		t.Errorf("precio de la gorra = %v, se esperaba 12", got)
+	}
+}
+
+func TestRelationRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Cámaras", "active": true}, nil)
+	for _, name := range []string{"R5", "Objetivo", "Trípode", "Descatalogado"} {
+		env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": name, "category_id": 1, "price": 10, "active": name != "Descatalogado"}, nil)
+	}
+
+	// Positions follow the order within each type
+	var relations []models.ProductRelation
+	env.expect(200, "PUT", "/admin/products/1/relations", "editor", []fiber.Map{
+		{"related_id": 3, "type": "accessory"}, {"related_id": 2, "type": "accessory"},
+	}, &relations)
+	env.expect(403, "GET", "/admin/products/1/relations", "viewer", nil, nil)
+	relations = nil
+	env.expect(200, "GET", "/admin/products/1/relations", "editor", nil, &relations)
+	if len(relations) != 2 || relations[0].RelatedID != 3 || relations[1].Position != 1 {
+		t.Fatalf("relaciones = %+v", relations)
+	}
+
+	var problem struct {
+		Details []string `json:"details"`
+	}
+	env.expect(400, "PUT", "/admin/products/1/relations", "editor", []fiber.Map{
+		{"related_id": 4, "type": "related"}, {"related_id": 1, "type": "upgrade"},
+	}, &problem)
+	if strings.Join(problem.Details, "|") != "Producto 4: está inactivo|Producto 1: un producto no puede relacionarse consigo mismo" {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(404, "PUT", "/admin/products/9/relations", "editor", []fiber.Map{}, nil)
+	env.expect(404, "GET", "/admin/products/9/relations", "editor", nil, nil)
+
+	// Deleting a product removes the relations pointing to it
+	env.expect(200, "DELETE", "/admin/products/3", "admin", nil, nil)
+	relations = nil
+	env.expect(200, "GET", "/admin/products/1/relations", "editor", nil, &relations)
+	if len(relations) != 1 || relations[0].RelatedID != 2 {
+		t.Errorf("relaciones tras borrar = %+v", relations)
+	}
+	env.expect(200, "PUT", "/admin/products/1/relations", "editor", []fiber.Map{}, &relations)
+	if len(relations) != 0 {
+		t.Errorf("relaciones tras vaciar = %+v", relations)
+	}
+}
+
+func TestCurrencyRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Ropa", "active": true}, nil)
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Camiseta", "category_id": 1, "price": "19.99", "active": true}, nil)
+	env.expect(200, "POST", "/admin/products/1/variants", "editor", fiber.Map{"sku": "CAM-L", "price": 25, "active": true}, nil)
+
+	// La moneda base siempre tiene tipo 1 y solo puede haber una
+	var currency models.Currency
+	env.expect(200, "POST", "/admin/currencies", "editor", fiber.Map{
+		"code": "mxn", "name": "Peso mexicano", "symbol": "$", "locale": "es-MX", "decimals": 2, "rate": 3, "base": true,
+	}, &currency)
+	if currency.Code != "MXN" || currency.Rate != money.One || !currency.Active {
+		t.Fatalf("moneda = %+v", currency)
+	}
+	var problem struct {
+		Details []string `json:"details"`
+	}
+	env.expect(400, "POST", "/admin/currencies", "editor", fiber.Map{
+		"code": "USD", "name": "Dólar", "symbol": "$", "locale": "xx-YY", "rate": "0.058", "base": true,
+	}, &problem)
+	if len(problem.Details) != 2 || !strings.HasPrefix(problem.Details[0], "Locale admite") || problem.Details[1] != "Ya hay una moneda base: MXN" {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(400, "POST", "/admin/currencies", "editor", fiber.Map{"code": "EUR", "name": "Euro", "symbol": "€", "locale": "es-ES", "rate": "0.0465125"}, nil)
+	env.expect(200, "POST", "/admin/currencies", "editor", fiber.Map{
+		"code": "EUR", "name": "Euro", "symbol": "€", "locale": "es-ES", "decimals": 2, "rate": "0.046512", "active": true,
+	}, &currency)
+	if currency.Rate.String() != "0.046512" {
+		t.Errorf("tipo de cambio = %s", currency.Rate)
+	}
+	env.expect(400, "PUT", "/admin/currencies/1", "editor", fiber.Map{"code": "MXN", "name": "Peso", "symbol": "$", "locale": "es-MX", "rate": 1}, nil)
+	// Los precios guardados están en la base: su código y sus decimales no cambian
+	env.expect(400, "PUT", "/admin/currencies/1", "editor", fiber.Map{
+		"code": "USD", "name": "Dólar", "symbol": "$", "locale": "es-MX", "decimals": 2, "rate": 1, "base": true,
+	}, &problem)
+	if strings.Join(problem.Details, "|") != "El código y los decimales de la moneda base no se pueden cambiar: los precios de los productos están en MXN" {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(400, "PUT", "/admin/currencies/1", "editor", fiber.Map{
+		"code": "MXN", "name": "Peso", "symbol": "$", "locale": "es-MX", "decimals": 0, "rate": 1, "base": true,
+	}, nil)
+	env.expect(200, "PUT", "/admin/currencies/1", "editor", fiber.Map{
+		"code": "mxn", "name": "Peso", "symbol": "$", "locale": "es-MX", "decimals": 2, "rate": 1, "base": true,
+	}, &currency)
+	if currency.Code != "MXN" || currency.Name != "Peso" {
+		t.Errorf("moneda base = %+v", currency)
+	}
+	env.expect(400, "DELETE", "/admin/currencies/1", "admin", nil, nil)
+
+	// Precios fijados por moneda para el producto y sus variantes
+	env.expect(400, "PUT", "/admin/products/1/prices", "editor", []fiber.Map{
+		{"currency": "MXN", "price": 20},
+		{"currency": "GBP", "price": 20},
+		{"currency": "EUR", "variant_id": 9, "price": 2},
+		{"currency": "EUR", "price": 1, "compare_at_price": 1},
+		{"currency": "eur", "price": 2},
+	}, &problem)
+	want := "MXN: los precios en la moneda base son los del producto y sus variantes|GBP: moneda desconocida|" +
+		"EUR (variante 9): la variante no es de este producto|EUR: CompareAtPrice debe ser mayor que Price|EUR: precio repetido"
+	if strings.Join(problem.Details, "|") != want {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(200, "PUT", "/admin/products/1/prices", "editor", []fiber.Map{
+		{"currency": "eur", "variant_id": 1, "price": "1.15"},
+		{"currency": "EUR", "price": 1},
+	}, nil)
+	var prices []models.ProductPrice
+	env.expect(200, "GET", "/admin/products/1/prices", "editor", nil, &prices)
+	if len(prices) != 2 || prices[0].VariantID != nil || prices[1].Price != 115 || prices[1].Currency != "EUR" {
+		t.Errorf("precios = %+v", prices)
+	}
+	env.expect(404, "GET", "/admin/products/9/prices", "editor", nil, nil)
+	env.expect(404, "PUT", "/admin/products/9/prices", "editor", []fiber.Map{}, nil)
+
+	// Al borrar la moneda se borran sus precios
+	env.expect(403, "DELETE", "/admin/currencies/2", "editor", nil, nil)
+	env.expect(200, "DELETE", "/admin/currencies/2", "admin", nil, nil)
+	env.expect(200, "GET", "/admin/products/1/prices", "editor", nil, &prices)
+	if len(prices) != 0 {
+		t.Errorf("precios tras borrar la moneda = %+v", prices)
+	}
+	var currencies []models.Currency
+	env.list("/admin/currencies?sort=code", "editor", &currencies)
+	if len(currencies) != 1 || currencies[0].Code != "MXN" {
+		t.Errorf("monedas = %+v", currencies)
+	}
+}
+
+func TestStockRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Ropa", "active": true}, nil)
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{
+		"name": "Gorra", "category_id": 1, "price": 15, "active": true, "stock_policy": "tracked", "stock": 40, "low_stock_threshold": 3,
+	}, nil)
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Camiseta", "category_id": 1, "price": 30, "active": true}, nil)
+	env.expect(200, "POST", "/admin/products/2/variants", "editor", fiber.Map{"sku": "CAM-L", "price": 30, "active": true, "stock_policy": "backorder"}, nil)
+
+	product := func(id uint) *models.Product {
+		t.Helper()
+		product, err := env.store.Products().Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return product
+	}
+	// Las existencias solo cambian con ajustes: el alta empieza en 0
+	if gorra := product(1); gorra.Stock != 0 || gorra.StockStatus != models.StockStatusOut {
+		t.Fatalf("gorra = %d %s", gorra.Stock, gorra.StockStatus)
+	}
+
+	var movement models.StockMovement
+	env.expect(200, "POST", "/admin/products/1/stock", "editor", fiber.Map{"delta": 5, "reason": "restock", "note": "Pedido 12"}, &movement)
+	if movement.Delta != 5 || movement.Quantity != 5 || movement.Username != "editor" || movement.UserID == nil {
+		t.Errorf("movimiento = %+v", movement)
+	}
+	env.expect(200, "POST", "/admin/products/1/stock", "editor", fiber.Map{"delta": -2, "reason": "sale"}, nil)
+	if gorra := product(1); gorra.Stock != 3 || gorra.StockStatus != models.StockStatusLow {
+		t.Errorf("gorra = %d %s", gorra.Stock, gorra.StockStatus)
+	}
+
+	// Editar el producto no cambia sus existencias
+	env.expect(200, "PUT", "/admin/products/1", "editor", fiber.Map{
+		"name": "Gorra", "category_id": 1, "price": 15, "active": true, "stock_policy": "tracked", "stock": 100, "low_stock_threshold": 1,
+	}, nil)
+	if gorra := product(1); gorra.Stock != 3 || gorra.StockStatus != models.StockStatusInStock {
+		t.Errorf("gorra tras editar = %d %s", gorra.Stock, gorra.StockStatus)
+	}
+
+	var problem struct {
+		Details []string `json:"details"`
+	}
+	env.expect(400, "POST", "/admin/products/1/stock", "editor", fiber.Map{"delta": -4, "reason": "sale"}, &problem)
+	if strings.Join(problem.Details, "|") != "Existencias insuficientes: quedan 3" {
+		t.Errorf("errores = %v", problem.Details)
+	}
+	env.expect(400, "POST", "/admin/products/1/stock", "editor", fiber.Map{"delta": 1, "quantity": 4, "reason": "count"}, nil)
+	env.expect(400, "POST", "/admin/products/1/stock", "editor", fiber.Map{"delta": 1, "reason": "regalo"}, nil)
+	env.expect(400, "POST", "/admin/products/2/stock", "editor", fiber.Map{"delta": 1, "reason": "restock"}, nil)
+	env.expect(404, "POST", "/admin/products/9/stock", "editor", fiber.Map{"delta": 1, "reason": "restock"}, nil)
+	env.expect(403, "POST", "/admin/products/1/stock", "viewer", fiber.Map{"delta": 1, "reason": "restock"}, nil)
+
+	// Un recuento fija las existencias; el movimiento guarda la diferencia
+	env.expect(200, "POST", "/admin/products/1/stock", "admin", fiber.Map{"quantity": 0, "reason": "count"}, &movement)
+	if movement.Delta != -3 || product(1).StockStatus != models.StockStatusOut {
+		t.Errorf("recuento = %+v, estado %s", movement, product(1).StockStatus)
+	}
+
+	// Variantes con venta bajo pedido: pueden quedar en negativo y el producto toma su estado
+	env.expect(200, "POST", "/admin/products/2/variants/1/stock", "editor", fiber.Map{"delta": -2, "reason": "sale"}, &movement)
+	if movement.VariantID == nil || *movement.VariantID != 1 || movement.Quantity != -2 {
+		t.Errorf("movimiento de la variante = %+v", movement)
+	}
+	if camiseta := product(2); camiseta.StockStatus != models.StockStatusBackorder || camiseta.Variants[0].Stock != -2 {
+		t.Errorf("camiseta = %s, variante %d", camiseta.StockStatus, camiseta.Variants[0].Stock)
+	}
+	env.expect(404, "POST", "/admin/products/1/variants/1/stock", "editor", fiber.Map{"delta": 1, "reason": "restock"}, nil)
+
+	var movements []models.StockMovement
+	env.expect(200, "GET", "/admin/products/1/stock", "editor", nil, &movements)
+	if len(movements) != 3 || movements[0].Reason != "count" || movements[2].Note != "Pedido 12" {
+		t.Errorf("historial = %+v", movements)
+	}
+	env.expect(200, "GET", "/admin/products/2/stock?variant=1&limit=1", "editor", nil, &movements)
+	if len(movements) != 1 || movements[0].Delta != -2 {
+		t.Errorf("historial de la variante = %+v", movements)
+	}
+	env.expect(400, "GET", "/admin/products/1/stock?limit=0", "editor", nil, nil)
+	env.expect(404, "GET", "/admin/products/9/stock", "editor", nil, nil)
+
+	// Listado para reponer
+	var products []models.Product
+	env.list("/admin/products?stock_status=out_of_stock,low_stock", "editor", &products)
+	if len(products) != 1 || products[0].Name != "Gorra" {
+		t.Errorf("agotados = %+v", products)
+	}
+}
+
+func TestContentChangesInvalidateCacheTags(t *testing.T) {
+	env := newTestEnv(t)
+	var published [][]string
+	env.svc.OnChange(func(ctx context.Context, tags []string) {
+		published = append(published, tags)
+	})
+	expectTags := func(want ...string) {
+		t.Helper()
+		if len(published) != 1 || strings.Join(published[0], ",") != strings.Join(want, ",") {
+			t.Errorf("etiquetas publicadas = %v, se esperaba %v", published, want)
+		}
+		published = nil
+	}
+
+	var first, second models.Category
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Fotos"}, &first)
+	expectTags("categories", "products", "category:1")
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Vídeo"}, &second)
+	published = nil
+
+	var product models.Product
+	env.expect(200, "POST", "/admin/products", "editor", fiber.Map{"name": "Retrato", "category_id": first.ID, "price": 10}, &product)
+	expectTags("products", "category:1")
+
+	// Moving the product affects the old and the new category
+	env.expect(200, "PUT", "/admin/products/1", "editor", fiber.Map{"name": "Retrato", "category_id": second.ID, "price": 10}, nil)
+	expectTags("products", "category:1", "category:2")
+
+	// Products of a subcategory also appear in the listings of its parents
+	var child models.Category
+	env.expect(200, "POST", "/admin/categories", "editor", fiber.Map{"name": "Bodas", "parent_id": first.ID}, &child)
+	expectTags("categories", "products", "category:1", "category:3")
+	env.expect(200, "PUT", "/admin/products/1", "editor", fiber.Map{"name": "Retrato", "category_id": child.ID, "price": 10}, nil)
+	expectTags("products", "category:2", "category:1", "category:3")
+
+	env.expect(200, "PUT", "/admin/config", "admin", fiber.Map{"key": "site_name", "value": "Estudio"}, nil)
+	expectTags("config")
+
+	// Failed writes publish nothing
+	env.expect(400, "POST", "/admin/products", "editor", fiber.Map{"name": "Sin categoría", "category_id": 99, "price": 10}, nil)
+	env.expect(404, "DELETE", "/admin/slides/99", "admin", nil, nil)
+	if len(published) != 0 {
+		t.Errorf("cambios fallidos publicaron %v", published)
+	}
+}
+
+func TestUserRoutes(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.expect(400, "POST", "/admin/users", "admin", fiber.Map{"username": "sinclave", "email": "sinclave@example.com"}, nil)
+	var user models.User
+	env.expect(200, "POST", "/admin/users", "admin", fiber.Map{"username": "ana", "email": "ana@example.com", "password": "secreto"}, &user)
+	if user.Role != "viewer" {
+		t.Fatalf("rol por defecto = %q", user.Role)
+	}
+	env.expect(409, "POST", "/admin/users", "admin", fiber.Map{"username": "ana", "email": "otra@example.com", "password": "secreto"}, nil)
+
+	// Without password the current one is kept
+	env.expect(200, "PUT", "/admin/users/6", "admin", fiber.Map{"username": "ana", "email": "ana@example.com", "role": "editor", "active": true}, &user)
+	stored, err := env.store.Users().Get(context.Background(), 6)
+	if err != nil || stored.Password != "hash:secreto" || stored.Role != "editor" {
+		t.Fatalf("usuario guardado = %+v, %v", stored, err)
+	}
+	env.expect(200, "PUT", "/admin/users/6", "admin", fiber.Map{"username": "ana", "email": "ana@example.com", "role": "editor", "active": true, "password": "nueva-clave"}, nil)
+	if stored, _ := env.store.Users().Get(context.Background(), 6); stored.Password != "hash:nueva-clave" {
+		t.Fatalf("contraseña no actualizada: %q", stored.Password)
+	}
+
+	var users []map[string]interface{}
+	env.list("/admin/users", "admin", &users)
+	if len(users) != 6 {
+		t.Fatalf("usuarios = %d, se esperaban 6", len(users))
+	}
+	for _, u := range users {
+		if _, ok := u["password"]; ok {
+			t.Fatalf("la respuesta incluye la contraseña: %v", u)
+		}
+	}
+
+	env.expect(404, "PUT", "/admin/users/99", "admin", fiber.Map{"username": "x", "email": "x@example.com", "role": "viewer"}, nil)
+	env.expect(200, "DELETE", "/admin/users/6", "super_admin", nil, nil)
+	env.expect(404, "DELETE", "/admin/users/6", "super_admin", nil, nil)
+}
+
+func TestSystemAndAuditRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	env.store.SetSchemaVersion(3)
+
+	env.expect(200, "POST", "/admin/slides", "editor", fiber.Map{"title": "Hola", "image_url": "https://example.com/a.jpg", "active": true}, nil)
+	env.expect(200, "POST", "/admin/slides", "editor", fiber.Map{"title": "Oculto", "image_url": "https://example.com/b.jpg"}, nil)
+
+	var status services.SystemStatus
+	env.expect(200, "GET", "/admin/system/status", "super_admin", nil, &status)
+	if status.TotalSlides != 2 || status.ActiveSlides != 1 || status.TotalUsers != 5 || status.ActiveUsers != 4 {
+		t.Fatalf("estado = %+v", status)
+	}
+
+	var backup struct {
+		Backup struct {
+			SchemaVersion int64 `json:"schema_version"`
+			Data          struct {
+				Slides []models.Slide `json:"slides"`
+			} `json:"data"`
+		} `json:"backup"`
+	}
+	env.expect(200, "POST", "/admin/system/backup", "super_admin", nil, &backup)
+	if backup.Backup.SchemaVersion != 3 || len(backup.Backup.Data.Slides) != 2 {
+		t.Fatalf("backup = %+v", backup.Backup)
+	}
+
+	var events []models.AuditEvent
+	env.expect(200, "GET", "/admin/audit?entity_type=slide&username=editor", "super_admin", nil, &events)
+	if len(events) != 2 || events[0].EntityID != "2" || events[0].Action != services.AuditCreate || events[0].Role != "editor" {
+		t.Fatalf("auditoría = %+v", events)
+	}
+	env.expect(200, "GET", "/admin/audit?limit=1", "super_admin", nil, &events)
+	if len(events) != 1 {
+		t.Fatalf("auditoría con limit=1 = %d eventos", len(events))
+	}
+	env.expect(400, "GET", "/admin/audit?from=ayer", "super_admin", nil, nil)
+
+	resp, err := env.app.Test(authorized(httptest.NewRequest("GET", "/admin/audit?format=csv", nil), env.tokens["super_admin"]))
+	if err != nil || resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") {
+		t.Fatalf("auditoría CSV = %v %v", resp, err)
+	}
+}
+
+func TestErrorsIncludeRequestID(t *testing.T) {
+	env := newTestEnv(t)
+	var body struct {
+		Error     string `json:"error"`
+		RequestID string `json:"request_id"`
+	}
+	env.expect(200, "POST", "/admin/tags", "editor", fiber.Map{"name": "Doble"}, nil)
+	for _, tt := range []struct {
+		status int
+		method string
+		path   string
+		body   interface{}
+	}{
+		{404, "DELETE", "/admin/products/9", nil},
+		{400, "GET", "/admin/audit?from=ayer", nil},
+		{401, "POST", "/admin/auth/login", fiber.Map{"username": "editor", "password": "otra"}},
+		{409, "POST", "/admin/tags", fiber.Map{"name": "Otra", "slug": "doble"}},
+	} {
+		body.Error, body.RequestID = "", ""
+		env.expect(tt.status, tt.method, tt.path, "super_admin", tt.body, &body)
+		if body.Error == "" || body.RequestID == "" {
+			t.Errorf("%s %s = %+v", tt.method, tt.path, body)
+		}
+	}
+}
+
+// authorized adds a bearer token to a request
+func authorized(req *http.Request, token string) *http.Request {
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
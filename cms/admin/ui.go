@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"embed"
+	"io/fs"
+	"website/backend/assets"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UI admin panel (index.html and js/) embedded in the binary
+//
+//go:embed index.html js
+var UI embed.FS
+
+// SetupUI serves the admin panel from ui (UI or its directory on disk in development).
+// It must be called before SetupRoutes: the /admin routes registered later require a token.
+func SetupUI(app *fiber.App, ui fs.FS, dev bool) error {
+	index := func(c *fiber.Ctx) error {
+		page, err := fs.ReadFile(ui, "index.html")
+		if err != nil {
+			return err
+		}
+		c.Type("html", "utf-8")
+		return c.Send(page)
+	}
+	app.Get("/admin", index)
+	app.Get("/admin/", index)
+
+	js, err := fs.Sub(ui, "js")
+	if err != nil {
+		return err
+	}
+	app.Use("/admin/js", assets.Static(js, dev))
+	return nil
+}
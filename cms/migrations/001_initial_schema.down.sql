@@ -0,0 +1,9 @@
+-- Migration: 001_initial_schema.down.sql
+-- Description: Drop the initial schema (deletes all content)
+
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS contact_infos;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS slides;
+DROP TABLE IF EXISTS site_configs;
@@ -1,4 +1,4 @@
--- Migration: 001_initial_schema.sql
+-- Migration: 001_initial_schema.up.sql
 -- Description: Initial database schema creation
 
 -- Create site_configs table
@@ -0,0 +1,4 @@
+-- Migration: 002_audit_events.down.sql
+-- Description: Drop the audit log
+
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,8 @@
+-- Migration: 003_user_roles.down.sql
+-- Description: Restore the two-role CHECK; super_admin becomes admin and viewer becomes editor
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+UPDATE users SET role = 'admin' WHERE role = 'super_admin';
+UPDATE users SET role = 'editor' WHERE role = 'viewer';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'editor'));
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'admin';
@@ -0,0 +1,7 @@
+-- Migration: 003_user_roles.up.sql
+-- Description: Align users.role with the User model (super_admin, admin, editor, viewer; default viewer)
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('super_admin', 'admin', 'editor', 'viewer'));
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'viewer';
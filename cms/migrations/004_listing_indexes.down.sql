@@ -0,0 +1,6 @@
+-- Migration: 004_listing_indexes.down.sql
+-- Description: Drop the product listing indexes
+
+DROP INDEX IF EXISTS idx_products_active_name;
+DROP INDEX IF EXISTS idx_products_active_price;
+DROP INDEX IF EXISTS idx_products_active_created;
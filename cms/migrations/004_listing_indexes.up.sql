@@ -0,0 +1,6 @@
+-- Migration: 004_listing_indexes.up.sql
+-- Description: Indexes for the paginated product listings (sort column + id, as in keyset pagination)
+
+CREATE INDEX IF NOT EXISTS idx_products_active_created ON products(active, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_products_active_price ON products(active, price, id);
+CREATE INDEX IF NOT EXISTS idx_products_active_name ON products(active, name, id);
@@ -0,0 +1,9 @@
+-- Migration: 005_product_search.down.sql
+-- Description: Drop the product search column, indexes and configuration (the extensions are kept)
+
+DROP INDEX IF EXISTS idx_products_name_trgm;
+DROP INDEX IF EXISTS idx_categories_search_name;
+DROP INDEX IF EXISTS idx_products_search_vector;
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS f_unaccent(text);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS es_unaccent;
@@ -0,0 +1,36 @@
+-- Migration: 005_product_search.up.sql
+-- Description: Spanish full-text product search with accent folding (unaccent) and typo tolerance (pg_trgm).
+-- The extensions need a role allowed to create them (the database owner on PostgreSQL 13+).
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- "spanish" plus unaccent before stemming: "cámaras" and "camara" share the lexeme "camar"
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'es_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION es_unaccent (COPY = spanish);
+        ALTER TEXT SEARCH CONFIGURATION es_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+    END IF;
+END
+$$;
+
+-- unaccent() is only STABLE; indexes need an IMMUTABLE wrapper with a fixed dictionary
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+-- Weighted document: name (A), features (B), description (C)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('es_unaccent'::regconfig, coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('es_unaccent'::regconfig, coalesce(features, '')), 'B') ||
+        setweight(to_tsvector('es_unaccent'::regconfig, coalesce(description, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+-- Category names are matched through an expression index (a generated column cannot read other tables)
+CREATE INDEX IF NOT EXISTS idx_categories_search_name ON categories USING GIN (to_tsvector('es_unaccent'::regconfig, name));
+-- Typo tolerance on product names (word_similarity, operator <%)
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (f_unaccent(lower(name)) gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- Migration: 006_product_attributes.down.sql
+-- Description: Drop the product attribute tables
+
+DROP TABLE IF EXISTS product_attributes;
+DROP TABLE IF EXISTS attribute_definitions;
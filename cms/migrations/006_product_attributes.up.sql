@@ -0,0 +1,34 @@
+-- Migration: 006_product_attributes.up.sql
+-- Description: Typed attribute definitions per category and attribute values per product
+
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('text', 'number', 'enum', 'boolean')),
+    unit VARCHAR(20),
+    options TEXT[],
+    required BOOLEAN NOT NULL DEFAULT false,
+    filterable BOOLEAN NOT NULL DEFAULT false,
+    "order" INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (category_id, key)
+);
+
+-- Canonical text in value; numbers are also kept in number_value for range filters
+CREATE TABLE IF NOT EXISTS product_attributes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions(id) ON DELETE CASCADE,
+    value VARCHAR(200) NOT NULL,
+    number_value NUMERIC,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, attribute_id)
+);
+
+-- Facet counts and attribute filters look up the values of one attribute
+CREATE INDEX IF NOT EXISTS idx_product_attributes_attribute_value ON product_attributes(attribute_id, value);
+CREATE INDEX IF NOT EXISTS idx_product_attributes_attribute_number ON product_attributes(attribute_id, number_value);
@@ -0,0 +1,4 @@
+-- Migration: 007_product_variants.down.sql
+-- Description: Drop the product variants table
+
+DROP TABLE IF EXISTS product_variants;
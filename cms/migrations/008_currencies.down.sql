@@ -0,0 +1,5 @@
+-- Migration: 008_currencies.down.sql
+-- Description: Drop the price overrides and currencies tables
+
+DROP TABLE IF EXISTS product_prices;
+DROP TABLE IF EXISTS currencies;
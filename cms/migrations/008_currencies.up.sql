@@ -0,0 +1,37 @@
+-- Migration: 008_currencies.up.sql
+-- Description: Display currencies with manual exchange rates and per-currency price overrides
+
+CREATE TABLE IF NOT EXISTS currencies (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(3) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    decimals INTEGER NOT NULL DEFAULT 2 CHECK (decimals BETWEEN 0 AND 2),
+    rate NUMERIC(18,6) NOT NULL CHECK (rate > 0),
+    base BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN DEFAULT true,
+    "order" INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Product prices are stored in the base currency: exactly one row may be the base
+CREATE UNIQUE INDEX IF NOT EXISTS idx_currencies_base ON currencies(base) WHERE base;
+
+INSERT INTO currencies (code, name, symbol, locale, decimals, rate, base, active)
+VALUES ('MXN', 'Peso mexicano', '$', 'es-MX', 2, 1, true, true)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS product_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    currency_code VARCHAR(3) NOT NULL REFERENCES currencies(code) ON DELETE CASCADE ON UPDATE CASCADE,
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    compare_at_price DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (compare_at_price >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_prices_unique ON product_prices(product_id, COALESCE(variant_id, 0), currency_code);
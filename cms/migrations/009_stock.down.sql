@@ -0,0 +1,20 @@
+-- Migration: 009_stock.down.sql
+-- Description: Drop the stock ledger and the stock columns
+
+DELETE FROM site_configs WHERE key = 'hide_out_of_stock';
+
+DROP TABLE IF EXISTS stock_movements;
+
+DROP INDEX IF EXISTS idx_products_stock_status;
+
+ALTER TABLE product_variants
+    DROP COLUMN IF EXISTS stock_status,
+    DROP COLUMN IF EXISTS low_stock_threshold,
+    DROP COLUMN IF EXISTS stock,
+    DROP COLUMN IF EXISTS stock_policy;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS stock_status,
+    DROP COLUMN IF EXISTS low_stock_threshold,
+    DROP COLUMN IF EXISTS stock,
+    DROP COLUMN IF EXISTS stock_policy;
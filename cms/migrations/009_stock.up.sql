@@ -0,0 +1,44 @@
+-- Migration: 009_stock.up.sql
+-- Description: Stock quantity, low-stock threshold and availability of products and variants, with a movement ledger
+
+-- stock_policy: untracked (always available), tracked (sold out at 0), backorder
+-- (sold on backorder at 0) or made_to_order. stock_status is derived from the policy,
+-- the quantity and the threshold; a product with active variants takes theirs.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS stock_policy VARCHAR(20) NOT NULL DEFAULT 'untracked'
+        CHECK (stock_policy IN ('untracked', 'tracked', 'backorder', 'made_to_order')),
+    ADD COLUMN IF NOT EXISTS stock INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS low_stock_threshold INTEGER NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0),
+    ADD COLUMN IF NOT EXISTS stock_status VARCHAR(20) NOT NULL DEFAULT 'in_stock'
+        CHECK (stock_status IN ('in_stock', 'low_stock', 'out_of_stock', 'backorder', 'made_to_order'));
+
+ALTER TABLE product_variants
+    ADD COLUMN IF NOT EXISTS stock_policy VARCHAR(20) NOT NULL DEFAULT 'untracked'
+        CHECK (stock_policy IN ('untracked', 'tracked', 'backorder', 'made_to_order')),
+    ADD COLUMN IF NOT EXISTS stock INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS low_stock_threshold INTEGER NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0),
+    ADD COLUMN IF NOT EXISTS stock_status VARCHAR(20) NOT NULL DEFAULT 'in_stock'
+        CHECK (stock_status IN ('in_stock', 'low_stock', 'out_of_stock', 'backorder', 'made_to_order'));
+
+CREATE INDEX IF NOT EXISTS idx_products_stock_status ON products(stock_status);
+
+-- Every stock change made in the CMS, with the resulting quantity and who made it
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    username VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id, created_at DESC, id DESC);
+
+-- Sold-out products stay in the catalog until this is set to true
+INSERT INTO site_configs (key, value) VALUES ('hide_out_of_stock', 'false')
+ON CONFLICT (key) DO NOTHING;
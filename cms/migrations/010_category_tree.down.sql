@@ -0,0 +1,11 @@
+-- Migration: 010_category_tree.down.sql
+-- Description: Drop the category hierarchy; every category becomes a root again
+
+DROP INDEX IF EXISTS idx_categories_path;
+DROP INDEX IF EXISTS idx_categories_parent;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS slug_path,
+    DROP COLUMN IF EXISTS path,
+    DROP COLUMN IF EXISTS parent_id;
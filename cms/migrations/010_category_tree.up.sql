@@ -0,0 +1,19 @@
+-- Migration: 010_category_tree.up.sql
+-- Description: Nested categories with a parent and a materialized path for subtree queries
+
+-- path lists the ids from the root down to the category itself ("/1/4/"), so a subtree
+-- is path LIKE '/1/%'; slug_path holds the slugs of the same chain ("camaras/mirrorless")
+-- used in the public URLs. Both are rewritten for the whole subtree when a category moves
+-- or changes its slug. The parent check is deferred so a restore can insert the rows in
+-- any order and the CMS can refuse to delete a category with children itself.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id) DEFERRABLE INITIALLY DEFERRED,
+    ADD COLUMN IF NOT EXISTS path TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS slug_path TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0 CHECK (depth >= 0);
+
+-- Existing categories become roots
+UPDATE categories SET path = '/' || id || '/', slug_path = slug WHERE path = '';
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_path ON categories(path text_pattern_ops);
@@ -0,0 +1,7 @@
+-- Migration: 011_tags_collections.down.sql
+-- Description: Drop the tag and collection tables
+
+DROP TABLE IF EXISTS collection_products;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS product_tags;
+DROP TABLE IF EXISTS tags;
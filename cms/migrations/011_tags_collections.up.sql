@@ -0,0 +1,54 @@
+-- Migration: 011_tags_collections.up.sql
+-- Description: Free-form product tags and curated collections (manual or rule-based)
+
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS product_tags (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, tag_id)
+);
+
+-- The tag filter looks up the products of one tag
+CREATE INDEX IF NOT EXISTS idx_product_tags_tag ON product_tags(tag_id);
+
+-- A manual collection lists hand-picked products by position; a smart one holds the
+-- products matching its rules (JSON: category_id, tag_id, min_price, max_price,
+-- newer_than_days, in_stock), sorted by sort and capped at max_products (0 = all)
+CREATE TABLE IF NOT EXISTS collections (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    image_url VARCHAR(500),
+    type VARCHAR(10) NOT NULL DEFAULT 'manual' CHECK (type IN ('manual', 'smart')),
+    rules JSONB,
+    sort VARCHAR(20) NOT NULL DEFAULT '',
+    max_products INTEGER NOT NULL DEFAULT 0 CHECK (max_products >= 0),
+    "order" INTEGER DEFAULT 0,
+    active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS collection_products (
+    id SERIAL PRIMARY KEY,
+    collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (collection_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_products_collection ON collection_products(collection_id, position);
+CREATE INDEX IF NOT EXISTS idx_collection_products_product ON collection_products(product_id);
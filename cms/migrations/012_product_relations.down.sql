@@ -0,0 +1,4 @@
+-- Migration: 012_product_relations.down.sql
+-- Description: Drop the product relations table
+
+DROP TABLE IF EXISTS product_relations;
@@ -0,0 +1,18 @@
+-- Migration: 012_product_relations.up.sql
+-- Description: Explicit product-to-product relations managed in the CMS
+
+-- One relation per pair of products; position orders the relations of each type
+CREATE TABLE IF NOT EXISTS product_relations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('related', 'accessory', 'alternative', 'upgrade')),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, related_id),
+    CHECK (product_id <> related_id)
+);
+
+-- Deleting a product removes the relations pointing to it
+CREATE INDEX IF NOT EXISTS idx_product_relations_related ON product_relations(related_id);
@@ -0,0 +1,2 @@
+-- Migration: 013_remove_default_admin.down.sql
+-- Description: Nothing to undo; a user with a known password is never restored
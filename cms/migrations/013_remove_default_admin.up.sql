@@ -0,0 +1,8 @@
+-- Migration: 013_remove_default_admin.up.sql
+-- Description: Remove the default admin user seeded by 001 while it still has the published password
+
+-- 001 cannot be edited once applied (checksums), so fresh databases get the row and lose it here.
+-- The first super_admin is created with: websitectl user create -role super_admin
+DELETE FROM users
+WHERE username = 'admin'
+  AND password = '$2a$14$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi';
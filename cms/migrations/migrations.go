@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS scripts SQL versionados (NNN_nombre.up.sql / NNN_nombre.down.sql)
+// embebidos en los binarios; los aplica el runner de backend/migrate
+//
+//go:embed *.sql
+var FS embed.FS
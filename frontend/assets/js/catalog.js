@@ -22,20 +22,33 @@ class CatalogManager {
 
     async loadProducts() {
         try {
-            const response = await fetch('/api/products');
-            if (response.ok) {
-                this.products = await response.json();
-                this.filteredProducts = [...this.products];
-                this.extractCategories();
-                this.renderProducts();
-                this.updatePagination();
-            }
+            this.products = await this.fetchAllProducts();
+            this.filteredProducts = [...this.products];
+            this.extractCategories();
+            this.renderProducts();
+            this.updatePagination();
         } catch (error) {
             console.error('Error cargando productos:', error);
             this.showNotification('Error al cargar los productos', 'error');
         }
     }
 
+    // La API pagina los listados: sigue links.next hasta la última página
+    async fetchAllProducts() {
+        const products = [];
+        let url = '/api/products?limit=100';
+        while (url) {
+            const response = await fetch(url);
+            if (!response.ok) {
+                throw new Error(`HTTP ${response.status}`);
+            }
+            const page = await response.json();
+            products.push(...page.data);
+            url = page.links.next;
+        }
+        return products;
+    }
+
     extractCategories() {
         const categorySet = new Set();
         this.products.forEach(product => {
@@ -7,6 +7,7 @@ document.addEventListener('DOMContentLoaded', function() {
     initScrollEffects();
     initContactForm();
     initSearchFunctionality();
+    initProductGallery();
 });
 
 // Mobile Menu Toggle
@@ -182,6 +183,20 @@ function initContactForm() {
     }
 }
 
+// Product Gallery: clicking a thumbnail shows it as the main image
+function initProductGallery() {
+    const mainImage = document.querySelector('#productMainImage');
+    if (!mainImage) {
+        return;
+    }
+    // Thumbnails and variants with their own image
+    document.querySelectorAll('.product-gallery-thumb, .product-variant[data-image]').forEach(thumb => {
+        thumb.addEventListener('click', function() {
+            mainImage.src = this.dataset.image;
+        });
+    });
+}
+
 // Search Functionality
 function initSearchFunctionality() {
     const searchInput = document.querySelector('#searchInput');
@@ -216,32 +231,38 @@ function initSearchFunctionality() {
 // Search API call
 async function performSearch(query) {
     try {
-        const response = await fetch(`/api/search?q=${encodeURIComponent(query)}`);
+        const response = await fetch(`/api/search?q=${encodeURIComponent(query)}&limit=5`);
+        if (!response.ok) {
+            return;
+        }
         const results = await response.json();
         
-        displaySearchResults(results);
+        displaySearchResults(results, query);
     } catch (error) {
         console.error('Search error:', error);
     }
 }
 
-// Display search results
-function displaySearchResults(results) {
+// Display search results (name_html and snippet come escaped from the server with <mark>)
+function displaySearchResults(results, query) {
     const searchResults = document.querySelector('#searchResults');
     
-    if (results.length === 0) {
+    if (results.data.length === 0) {
         searchResults.innerHTML = '<div class="no-results">No se encontraron resultados</div>';
     } else {
-        const resultsHTML = results.map(item => `
+        const resultsHTML = results.data.map(item => `
             <div class="search-result-item">
-                <a href="${item.url}">
-                    <h4>${item.title}</h4>
-                    <p>${item.description}</p>
+                <a href="/productos/${encodeURIComponent(item.category.slug)}/${encodeURIComponent(item.slug)}">
+                    <h4>${item.name_html}</h4>
+                    <p>${item.snippet}</p>
                 </a>
             </div>
         `).join('');
         
-        searchResults.innerHTML = resultsHTML;
+        const moreHTML = results.meta.total > results.data.length
+            ? `<a class="search-more" href="/buscar?q=${encodeURIComponent(query)}">Ver los ${results.meta.total} resultados</a>`
+            : '';
+        searchResults.innerHTML = resultsHTML + moreHTML;
     }
     
     searchResults.style.display = 'block';
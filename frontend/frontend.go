@@ -0,0 +1,9 @@
+package frontend
+
+import "embed"
+
+// FS plantillas (templates/) y assets estáticos (assets/) del sitio público
+// embebidos en el binario; en desarrollo se leen del disco (ver backend/assets)
+//
+//go:embed templates assets
+var FS embed.FS
@@ -33,14 +33,15 @@ fi
 echo "📦 Creando base de datos '$DB_NAME' si no existe..."
 PGPASSWORD=$DB_PASSWORD psql -h $DB_HOST -p $DB_PORT -U $DB_USER -d postgres -c "CREATE DATABASE $DB_NAME;" 2>/dev/null || echo "Base de datos ya existe"
 
-# Ejecutar migraciones
+# Ejecutar migraciones versionadas (embebidas en el binario)
 echo "🔄 Ejecutando migraciones..."
-for migration in cms/migrations/*.sql; do
-    if [ -f "$migration" ]; then
-        echo "Ejecutando: $migration"
-        PGPASSWORD=$DB_PASSWORD psql -h $DB_HOST -p $DB_PORT -U $DB_USER -d $DB_NAME -f "$migration"
-    fi
-done
+export CMS_DB_HOST=${CMS_DB_HOST:-$DB_HOST}
+export CMS_DB_USER=${CMS_DB_USER:-$DB_USER}
+export CMS_DB_PASSWORD=${CMS_DB_PASSWORD:-$DB_PASSWORD}
+export CMS_DB_NAME=${CMS_DB_NAME:-$DB_NAME}
+export CMS_DB_PORT=${CMS_DB_PORT:-$DB_PORT}
+go run ./cmd/website migrate up
+go run ./cmd/website migrate status
 
 echo "✅ Base de datos inicializada correctamente!"
 echo ""
@@ -50,11 +51,9 @@ echo "   Puerto: $DB_PORT"
 echo "   Base de datos: $DB_NAME"
 echo "   Usuario: $DB_USER"
 echo ""
-echo "🔑 Usuario admin por defecto:"
-echo "   Username: admin"
-echo "   Password: admin123"
-echo "   Email: admin@example.com"
+echo "🔑 No se crea ningún usuario por defecto; crea el primer super_admin con:"
+echo "   go run ./cmd/websitectl user create -username <usuario> -email <email> -role super_admin"
 echo ""
 echo "🚀 Puedes ahora ejecutar:"
-echo "   go run backend/main.go    # Para el backend"
-echo "   go run cms/main.go        # Para el CMS" 
\ No newline at end of file
+echo "   go run ./cmd/website serve all         # Sitio (3000) y CMS (4000)"
+echo "   go run ./cmd/website -dev serve all    # Plantillas y assets desde disco, con recarga" 
\ No newline at end of file